@@ -0,0 +1,148 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// ResultField names a part of the standings returned by the read APIs that
+// an AccessPolicy may hide. The member and the ghost flag are always shown.
+type ResultField string
+
+const (
+	// ResultFieldScore is the score
+	ResultFieldScore ResultField = "score"
+	// ResultFieldDisplayScore is the score formatted by result transformers
+	ResultFieldDisplayScore ResultField = "displayScore"
+	// ResultFieldRank is the rank
+	ResultFieldRank ResultField = "rank"
+	// ResultFieldRankChange is the previous rank and the rank delta
+	ResultFieldRankChange ResultField = "rankChange"
+	// ResultFieldTier is the tier label
+	ResultFieldTier ResultField = "tier"
+	// ResultFieldMilestones is the milestones reached
+	ResultFieldMilestones ResultField = "milestones"
+)
+
+// AccessPolicy authorizes standings reads for the caller identified with
+// WithCaller, empty for anonymous callers, so services exposing the helper
+// through public APIs share one filtering layer. Reads by trusted callers
+// (see WithTrustedCaller) skip it. It is consulted on every read and should
+// cache whatever it looks up.
+type AccessPolicy interface {
+	// CanRead reports whether the caller may read the leaderboard's
+	// standings. Reads it refuses fail with ErrAccessDenied.
+	CanRead(ctx context.Context, caller string, leaderboardID string) (bool, error)
+	// VisibleFields returns the fields of each standing the caller may
+	// see on the leaderboard; the others are cleared. Nil shows every
+	// field.
+	VisibleFields(ctx context.Context, caller string, leaderboardID string) []ResultField
+}
+
+// checkAccess returns ErrAccessDenied unless the access policy, if any,
+// lets the caller of a read made with ctx read the leaderboard
+func (l *IndividualLeaderboardHelper) checkAccess(ctx context.Context) error {
+	if l.accessPolicy == nil {
+		return nil
+	}
+	if trusted, _ := ctx.Value(trustedCallerKey{}).(bool); trusted {
+		return nil
+	}
+
+	caller, _ := ctx.Value(callerKey{}).(string)
+	allowed, err := l.accessPolicy.CanRead(ctx, caller, l.policyLeaderboardID())
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrAccessDenied
+	}
+
+	return nil
+}
+
+// visibleFields returns the fields the caller of a read made with ctx may
+// see, nil for all of them
+func (l *IndividualLeaderboardHelper) visibleFields(ctx context.Context) map[ResultField]bool {
+	if l.accessPolicy == nil {
+		return nil
+	}
+	if trusted, _ := ctx.Value(trustedCallerKey{}).(bool); trusted {
+		return nil
+	}
+
+	caller, _ := ctx.Value(callerKey{}).(string)
+	fields := l.accessPolicy.VisibleFields(ctx, caller, l.policyLeaderboardID())
+	if fields == nil {
+		return nil
+	}
+
+	visible := make(map[ResultField]bool, len(fields))
+	for _, field := range fields {
+		visible[field] = true
+	}
+
+	return visible
+}
+
+// policyLeaderboardID returns the leaderboard ID the access policy is
+// asked about. Skill bracket sub-boards are authorized as their main board.
+func (l *IndividualLeaderboardHelper) policyLeaderboardID() string {
+	if l.parentLeaderboardID != "" {
+		return l.parentLeaderboardID
+	}
+
+	return l.leaderboardID
+}
+
+// hideFields clears the fields of an entry not in visible
+func hideFields(entry *customTypes.MemberScore, visible map[ResultField]bool) {
+	if !visible[ResultFieldScore] {
+		entry.Score = 0
+	}
+	if !visible[ResultFieldDisplayScore] {
+		entry.DisplayScore = ""
+	}
+	if !visible[ResultFieldRank] {
+		entry.Rank = 0
+	}
+	if !visible[ResultFieldRankChange] {
+		entry.PreviousRank = 0
+		entry.RankDelta = 0
+	}
+	if !visible[ResultFieldTier] {
+		entry.Tier = ""
+	}
+	if !visible[ResultFieldMilestones] {
+		entry.Milestones = nil
+	}
+}
+
+// AuthorizeRead returns the error a standings read made with ctx would fail
+// with, or nil if its caller may read the leaderboard. Services serving
+// standings read once to many callers, such as streams, check each caller
+// with it.
+func (l *IndividualLeaderboardHelper) AuthorizeRead(ctx context.Context) error {
+	return l.checkVisibility(ctx)
+}
+
+// NewVisibleTopNResponse builds the response for entries read from the
+// helper's leaderboard with the fields the access policy keeps from the
+// caller of ctx cleared. entries are left untouched.
+func (l *IndividualLeaderboardHelper) NewVisibleTopNResponse(
+	ctx context.Context,
+	entries []customTypes.MemberScore,
+) *TopNResponse {
+	visible := l.visibleFields(ctx)
+	if visible == nil {
+		return l.NewTopNResponse(entries)
+	}
+
+	hidden := append([]customTypes.MemberScore{}, entries...)
+	for i := range hidden {
+		hideFields(&hidden[i], visible)
+	}
+
+	return l.NewTopNResponse(hidden)
+}
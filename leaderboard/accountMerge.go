@@ -0,0 +1,129 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// AccountMergeStrategy decides how the scores of two merged accounts are
+// combined
+type AccountMergeStrategy = customTypes.AccountMergeStrategy
+
+const (
+	// AccountMergeSum gives the primary account the sum of both scores
+	AccountMergeSum = customTypes.AccountMergeSum
+	// AccountMergeMax gives the primary account the higher of both scores
+	AccountMergeMax = customTypes.AccountMergeMax
+)
+
+// MergeParticipants folds one of the helper's client's users into another,
+// for account linking. The primary user's score becomes the sum or the
+// higher of both scores, as strategy says; it keeps the earlier time of
+// every milestone either reached and the update history of both. The
+// secondary user is removed from the leaderboard in the same DynamoDB
+// transaction, and from its skill bracket and the lifetime leaderboard if
+// configured. Rank history and named stats of the secondary user are not
+// carried over. It returns the primary user's new standing, with a zero
+// rank while the cached leaderboard is being rebuilt, or
+// ErrParticipantNotFound if the secondary user is not on the leaderboard.
+func (l *IndividualLeaderboardHelper) MergeParticipants(
+	ctx context.Context,
+	primaryUserID string,
+	secondaryUserID string,
+	strategy AccountMergeStrategy,
+) (*customTypes.MemberScore, error) {
+	primary, err := l.memberID(primaryUserID)
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := l.memberID(secondaryUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The secondary's bracket is gone with its items once merged
+	var secondaryBracket string
+	if l.brackets != nil {
+		secondaryBracket, err = l.memberBracket(ctx, secondary)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, gained, err := l.repo.MergeParticipants(ctx, l.storageID, primary, secondary, strategy, l.endTime())
+	if err != nil {
+		return nil, err
+	}
+
+	// The leaderboard is merged, so failures below only log
+	if l.lifetimeLeaderboard {
+		_, _, err := l.repo.MergeParticipants(ctx, lifetimeStorageID(l.clientID), primary, secondary, strategy, time.Time{})
+		if err != nil && !errors.Is(err, ErrParticipantNotFound) {
+			fmt.Printf("%sError merging lifetime leaderboard: %v\n", utils.LogPrefix(ctx), err)
+		}
+	}
+	if secondaryBracket != "" {
+		bracket := l.bracketLeaderboard(secondaryBracket)
+		if err := l.repo.LeaveLeaderboard(ctx, bracket.storageID, secondary); err != nil {
+			fmt.Printf("%sError removing merged participant from skill bracket: %v\n", utils.LogPrefix(ctx), err)
+		} else {
+			bracket.publishRemoval(ctx, secondary)
+		}
+		l.brackets.mu.Lock()
+		delete(l.brackets.members, secondary)
+		l.brackets.mu.Unlock()
+	}
+
+	l.publishRemoval(ctx, secondary)
+	l.publishCacheUpdate(ctx, primary, gained)
+	l.recordMilestones(ctx, primary, gained, &result)
+	l.creditBracket(ctx, primary, gained)
+	l.recordWrites(ctx, 1)
+	l.publishChanges(ctx, primary, secondary)
+
+	return &result, nil
+}
+
+// MergeParticipants merges one of the manager's client's users into
+// another, as IndividualLeaderboardHelper.MergeParticipants does, on each
+// of the given leaderboards. Participants are stored per leaderboard, so
+// the caller lists the leaderboards the accounts may be on; those the
+// secondary user is not on are skipped. It returns the primary user's new
+// standing keyed by leaderboard ID, together with the failures of the
+// leaderboards that could not be merged.
+func (m *Manager) MergeParticipants(
+	ctx context.Context,
+	primaryUserID string,
+	secondaryUserID string,
+	strategy AccountMergeStrategy,
+	leaderboardIDs ...string,
+) (map[string]customTypes.MemberScore, error) {
+	results := make(map[string]customTypes.MemberScore, len(leaderboardIDs))
+	var errs []error
+	for _, leaderboardID := range leaderboardIDs {
+		helper, err := m.Helper(ctx, leaderboardID)
+		if err == nil {
+			var result *customTypes.MemberScore
+			result, err = helper.MergeParticipants(ctx, primaryUserID, secondaryUserID, strategy)
+			if err == nil {
+				results[leaderboardID] = *result
+				continue
+			}
+		}
+		if errors.Is(err, ErrParticipantNotFound) {
+			continue
+		}
+		errs = append(errs, fmt.Errorf(
+			"failed to merge participants on leaderboard %s: %w",
+			leaderboardID,
+			err,
+		))
+	}
+
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,143 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// LeaderboardState is where a leaderboard is in its lifecycle
+type LeaderboardState = customTypes.LeaderboardState
+
+const (
+	// LeaderboardScheduled accepts joins but rejects score updates until the
+	// start time (see WithScheduledStart)
+	LeaderboardScheduled = customTypes.LeaderboardScheduled
+	// LeaderboardActive accepts joins and score updates
+	LeaderboardActive = customTypes.LeaderboardActive
+	// LeaderboardEnded is past its end time
+	LeaderboardEnded = customTypes.LeaderboardEnded
+)
+
+// leaderboardEnd holds a helper's end time, which ExtendLeaderboard may
+// move while the helper is in use
+type leaderboardEnd struct {
+	mu   sync.RWMutex
+	time time.Time
+}
+
+// endTime returns the leaderboard's end time, zero if it never ends
+func (l *IndividualLeaderboardHelper) endTime() time.Time {
+	l.end.mu.RLock()
+	defer l.end.mu.RUnlock()
+
+	return l.end.time
+}
+
+// ExtendLeaderboard moves the leaderboard's end time to newEndTime and
+// pushes the expiry of its cached keys out to match. The new end time is
+// recorded in Redis, so instances still holding the old one keep accepting
+// writes and never shorten the cache's expiry; their helpers report the
+// new end time once rebuilt, e.g. after Manager.Evict.
+func (l *IndividualLeaderboardHelper) ExtendLeaderboard(
+	ctx context.Context,
+	newEndTime time.Time,
+) error {
+	current := l.endTime()
+	if current.IsZero() {
+		return fmt.Errorf("leaderboard %s has no end time to extend", l.leaderboardID)
+	}
+	if !newEndTime.After(current) {
+		return fmt.Errorf(
+			"new end time %s must be after the current end time %s",
+			newEndTime.Format(time.RFC3339),
+			current.Format(time.RFC3339),
+		)
+	}
+
+	if err := l.repo.ExtendLeaderboard(ctx, l.storageID, newEndTime); err != nil {
+		return err
+	}
+
+	l.end.mu.Lock()
+	l.end.time = newEndTime
+	l.end.mu.Unlock()
+
+	return nil
+}
+
+// State returns the leaderboard's current lifecycle state
+func (l *IndividualLeaderboardHelper) State() LeaderboardState {
+	return l.stateAt(utils.GetCurrTimeStamp())
+}
+
+// stateAt returns the lifecycle state at time t. Only leaderboards created
+// with WithScheduledStart are ever SCHEDULED.
+func (l *IndividualLeaderboardHelper) stateAt(t time.Time) LeaderboardState {
+	if l.scheduledStart && t.Before(l.startTime) {
+		return LeaderboardScheduled
+	}
+	if endTime := l.endTime(); !endTime.IsZero() && t.After(endTime) {
+		return LeaderboardEnded
+	}
+
+	return LeaderboardActive
+}
+
+// checkEventTime rejects score events timestamped outside the leaderboard's
+// active window. Unset bounds are not enforced.
+func (l *IndividualLeaderboardHelper) checkEventTime(eventTime time.Time) error {
+	if !l.startTime.IsZero() && eventTime.Before(l.startTime) {
+		return ErrLeaderboardNotStarted
+	}
+	if endTime := l.endTime(); !endTime.IsZero() && eventTime.After(endTime) {
+		return ErrLeaderboardEnded
+	}
+	if l.inFreezeWindow(eventTime) {
+		return ErrLeaderboardFrozen
+	}
+
+	return nil
+}
+
+// inFreezeWindow reports whether t falls in the configured freeze window
+func (l *IndividualLeaderboardHelper) inFreezeWindow(t time.Time) bool {
+	if l.freezeStart.IsZero() || t.Before(l.freezeStart) {
+		return false
+	}
+
+	return l.freezeEnd.IsZero() || t.Before(l.freezeEnd)
+}
+
+// EndTimePolicy decides what happens to writes arriving after the
+// leaderboard's end time
+type EndTimePolicy = customTypes.EndTimePolicy
+
+const (
+	// EndTimeReject rejects late writes with ErrLeaderboardEnded
+	EndTimeReject = customTypes.EndTimeReject
+	// EndTimeFlag applies late writes and records the late part of each
+	// participant's score in the lateScoreDelta attribute
+	EndTimeFlag = customTypes.EndTimeFlag
+	// EndTimeGrace applies and flags writes within the grace period after
+	// the end, and rejects later ones
+	EndTimeGrace = customTypes.EndTimeGrace
+)
+
+// CacheExpiryPolicy decides when a leaderboard's cached Redis keys expire
+type CacheExpiryPolicy = customTypes.CacheExpiryPolicy
+
+const (
+	// CacheExpireAfterEnd expires cached keys the retention period after the
+	// leaderboard's end time
+	CacheExpireAfterEnd = customTypes.CacheExpireAfterEnd
+	// CacheExpireAfterAccess also keeps cached keys for the retention period
+	// after the leaderboard's last read or write
+	CacheExpireAfterAccess = customTypes.CacheExpireAfterAccess
+	// CacheNeverExpire keeps cached keys until the leaderboard is deleted
+	CacheNeverExpire = customTypes.CacheNeverExpire
+)
@@ -0,0 +1,169 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// AnomalyVerdict is an anomaly detector's decision on a score update
+type AnomalyVerdict = customTypes.AnomalyVerdict
+
+const (
+	// VerdictAllow applies the update
+	VerdictAllow = customTypes.VerdictAllow
+	// VerdictFlag applies the update and marks it as suspicious in
+	// ScoreUpdate.Verdict for the after hooks
+	VerdictFlag = customTypes.VerdictFlag
+	// VerdictQuarantine holds the update back for review with WithQuarantine,
+	// failing it with ErrUpdateQuarantined. Without it the update is rejected.
+	VerdictQuarantine = customTypes.VerdictQuarantine
+	// VerdictReject refuses the update with ErrAnomalousUpdate
+	VerdictReject = customTypes.VerdictReject
+)
+
+// UpdateHistory summarises a participant's recent score updates on the
+// leaderboard
+type UpdateHistory = customTypes.UpdateHistory
+
+// AnomalyDetector inspects every score update, with the statistics of the
+// participant's recent updates, and decides whether it is applied
+type AnomalyDetector interface {
+	Inspect(ctx context.Context, update ScoreUpdate, history UpdateHistory) (AnomalyVerdict, error)
+}
+
+// AnomalyDetectorFunc adapts a function to the AnomalyDetector interface
+type AnomalyDetectorFunc func(ctx context.Context, update ScoreUpdate, history UpdateHistory) (AnomalyVerdict, error)
+
+// Inspect calls f
+func (f AnomalyDetectorFunc) Inspect(
+	ctx context.Context,
+	update ScoreUpdate,
+	history UpdateHistory,
+) (AnomalyVerdict, error) {
+	return f(ctx, update, history)
+}
+
+// ZScoreDetector judges an update by how many standard deviations its delta
+// lies from the participant's mean delta. Each threshold applies from that
+// z-score upwards; zero disables it.
+type ZScoreDetector struct {
+	// MinSamples is the history needed before any update is judged
+	MinSamples int64
+	// FlagAt flags updates at or above this z-score
+	FlagAt float64
+	// QuarantineAt quarantines updates at or above this z-score
+	QuarantineAt float64
+	// RejectAt rejects updates at or above this z-score
+	RejectAt float64
+}
+
+// DefaultZScoreDetector flags deltas 3 standard deviations from a
+// participant's mean, quarantines them at 5 and rejects them at 8, once the
+// participant has 10 updates
+func DefaultZScoreDetector() *ZScoreDetector {
+	return &ZScoreDetector{
+		MinSamples:   10,
+		FlagAt:       3,
+		QuarantineAt: 5,
+		RejectAt:     8,
+	}
+}
+
+// Inspect implements AnomalyDetector
+func (d *ZScoreDetector) Inspect(
+	ctx context.Context,
+	update ScoreUpdate,
+	history UpdateHistory,
+) (AnomalyVerdict, error) {
+	if history.Count < d.MinSamples {
+		return VerdictAllow, nil
+	}
+
+	deviation := math.Abs(update.ScoreDelta - history.Mean)
+	z := math.Inf(1)
+	if history.StdDev > 0 {
+		z = deviation / history.StdDev
+	} else if deviation == 0 {
+		z = 0
+	}
+
+	switch {
+	case d.RejectAt > 0 && z >= d.RejectAt:
+		return VerdictReject, nil
+	case d.QuarantineAt > 0 && z >= d.QuarantineAt:
+		return VerdictQuarantine, nil
+	case d.FlagAt > 0 && z >= d.FlagAt:
+		return VerdictFlag, nil
+	}
+
+	return VerdictAllow, nil
+}
+
+// screenUpdate runs the anomaly detector on an update, recording its
+// verdict on the update and failing updates that must not be applied
+func (l *IndividualLeaderboardHelper) screenUpdate(
+	ctx context.Context,
+	update *ScoreUpdate,
+) error {
+	if err := l.inspectUpdate(ctx, update); err != nil {
+		return err
+	}
+
+	if update.Verdict == VerdictQuarantine {
+		if err := l.quarantineUpdate(ctx, update); err != nil {
+			return err
+		}
+		return ErrUpdateQuarantined
+	}
+
+	return nil
+}
+
+// inspectUpdate runs the anomaly detector on an update and records its
+// verdict on the update, failing rejected updates, and quarantined ones
+// when quarantine is off. Quarantined updates are left to the caller to
+// hold back.
+func (l *IndividualLeaderboardHelper) inspectUpdate(
+	ctx context.Context,
+	update *ScoreUpdate,
+) error {
+	history, err := l.repo.GetUpdateHistory(ctx, l.storageID, update.NamespacedUserID)
+	if err != nil {
+		return err
+	}
+
+	update.Verdict, err = l.anomalyDetector.Inspect(ctx, *update, history)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to inspect score update: %w",
+			err,
+		)
+	}
+
+	switch update.Verdict {
+	case VerdictReject:
+		return ErrAnomalousUpdate
+	case VerdictQuarantine:
+		if !l.quarantine {
+			return ErrAnomalousUpdate
+		}
+	}
+
+	return nil
+}
+
+// recordUpdateHistory adds an applied update to the participant's history
+func (l *IndividualLeaderboardHelper) recordUpdateHistory(
+	ctx context.Context,
+	update *ScoreUpdate,
+) {
+	err := l.repo.RecordUpdateHistory(ctx, l.storageID, update.NamespacedUserID, update.ScoreDelta)
+	if err != nil {
+		// The write is durable, so only log; the history is advisory
+		fmt.Printf("%sError recording update history: %v\n", utils.LogPrefix(ctx), err)
+	}
+}
@@ -0,0 +1,159 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// DefaultAuditBufferSize is how many records an Auditor holds per sink
+// before dropping the oldest
+const DefaultAuditBufferSize = 100000
+
+// AuditRecord is one score mutation in the audit trail, flattened for
+// querying
+type AuditRecord = customTypes.AuditRecord
+
+// AuditSink stores batches of audit records, e.g. in S3, CloudWatch Logs
+// or Firehose. A failed batch is offered again on the next flush.
+type AuditSink interface {
+	WriteAudit(ctx context.Context, records []AuditRecord) error
+}
+
+// auditQueue holds the records not yet written to one sink
+type auditQueue struct {
+	sink    AuditSink
+	pending []AuditRecord
+}
+
+// Auditor exports every attempted score update to audit sinks, so score
+// mutations can be queried without touching the leaderboard's tables.
+// Records are buffered in process and written by Flush or Run. It is safe
+// for concurrent use.
+type Auditor struct {
+	mu         sync.Mutex
+	queues     []*auditQueue
+	bufferSize int
+}
+
+// NewAuditor creates an auditor writing to every given sink
+func NewAuditor(sinks ...AuditSink) *Auditor {
+	auditor := &Auditor{bufferSize: DefaultAuditBufferSize}
+	for _, sink := range sinks {
+		auditor.queues = append(auditor.queues, &auditQueue{sink: sink})
+	}
+
+	return auditor
+}
+
+// WithBufferSize sets how many records are held per sink while it cannot
+// be written to; the oldest are dropped beyond that
+func (a *Auditor) WithBufferSize(size int) *Auditor {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.bufferSize = size
+	return a
+}
+
+// Hook returns the hook recording updates in the audit trail, to be added
+// with WithAfterUpdate
+func (a *Auditor) Hook() AfterUpdateHook {
+	return func(ctx context.Context, update ScoreUpdate, err error) {
+		record := AuditRecord{
+			Time:             utils.GetCurrTimeStamp(),
+			LeaderboardID:    update.LeaderboardID,
+			ClientID:         update.ClientID,
+			UserID:           update.UserID,
+			NamespacedUserID: update.NamespacedUserID,
+			ScoreDelta:       update.ScoreDelta,
+			Verdict:          update.Verdict.String(),
+			RequestID:        update.RequestID,
+		}
+		if !update.EventTime.IsZero() {
+			eventTime := update.EventTime
+			record.EventTime = &eventTime
+		}
+		if err != nil {
+			record.Error = err.Error()
+		}
+
+		a.Record(record)
+	}
+}
+
+// Record adds a record to the audit trail
+func (a *Auditor) Record(record AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, queue := range a.queues {
+		queue.pending = append(queue.pending, record)
+		if dropped := len(queue.pending) - a.bufferSize; a.bufferSize > 0 && dropped > 0 {
+			fmt.Printf("Error buffering audit records: dropped %d oldest\n", dropped)
+			queue.pending = append([]AuditRecord(nil), queue.pending[dropped:]...)
+		}
+	}
+}
+
+// Flush writes the buffered records to every sink. Records a sink fails to
+// take stay buffered for it; the returned error joins the failures.
+func (a *Auditor) Flush(ctx context.Context) error {
+	// Take the pending records so writes do not hold up recording
+	a.mu.Lock()
+	batches := make([][]AuditRecord, len(a.queues))
+	for i, queue := range a.queues {
+		batches[i] = queue.pending
+		queue.pending = nil
+	}
+	a.mu.Unlock()
+
+	var errs []error
+	for i, queue := range a.queues {
+		if len(batches[i]) == 0 {
+			continue
+		}
+		if err := queue.sink.WriteAudit(ctx, batches[i]); err != nil {
+			errs = append(errs, fmt.Errorf(
+				"failed to write %d audit records: %w",
+				len(batches[i]),
+				err,
+			))
+
+			// Put the batch back ahead of anything recorded meanwhile
+			a.mu.Lock()
+			queue.pending = append(batches[i], queue.pending...)
+			a.mu.Unlock()
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run calls Flush once per interval until ctx is cancelled, then flushes a
+// last time
+func (a *Auditor) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := a.Flush(context.WithoutCancel(ctx)); err != nil {
+				fmt.Printf("Error flushing audit records: %v\n", err)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if err := a.Flush(ctx); err != nil {
+			// Failed records stay buffered, so only log
+			fmt.Printf("Error flushing audit records: %v\n", err)
+		}
+	}
+}
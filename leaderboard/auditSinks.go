@@ -0,0 +1,193 @@
+package leaderboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// The audit sinks write through the small interfaces below rather than the
+// AWS SDK clients, which this module does not depend on; each is a thin
+// adapter over the matching SDK call.
+
+// S3ObjectPutter stores an object, e.g. with the S3 client's PutObject
+type S3ObjectPutter interface {
+	PutObject(ctx context.Context, key string, body []byte) error
+}
+
+// FirehoseRecordPutter sends records to a Firehose delivery stream, e.g.
+// with the Firehose client's PutRecordBatch, failing if any record was not
+// accepted
+type FirehoseRecordPutter interface {
+	PutRecordBatch(ctx context.Context, records [][]byte) error
+}
+
+// CloudWatchLogEvent is one event of a CloudWatch Logs batch
+type CloudWatchLogEvent struct {
+	Timestamp time.Time
+	Message   string
+}
+
+// CloudWatchLogsPutter writes events, ordered by time, to a log stream,
+// e.g. with the CloudWatch Logs client's PutLogEvents
+type CloudWatchLogsPutter interface {
+	PutLogEvents(ctx context.Context, events []CloudWatchLogEvent) error
+}
+
+// firehoseBatchLimit and cloudWatchBatchLimit are the most records the
+// services take per call
+const (
+	firehoseBatchLimit   = 500
+	cloudWatchBatchLimit = 10000
+)
+
+// encodeAuditRecord returns a record as one line of JSON
+func encodeAuditRecord(record AuditRecord) ([]byte, error) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to encode audit record: %w",
+			err,
+		)
+	}
+
+	return append(line, '\n'), nil
+}
+
+// s3AuditSink writes JSON lines objects partitioned by date and leaderboard
+type s3AuditSink struct {
+	putter S3ObjectPutter
+	prefix string
+	seq    atomic.Int64
+}
+
+// NewS3AuditSink creates a sink writing one JSON lines object per day and
+// leaderboard on each flush, under
+// prefix/dt=2006-01-02/leaderboard=<id>/<unix nanos>-<seq>.jsonl, so
+// Athena can prune partitions by date and leaderboard
+func NewS3AuditSink(putter S3ObjectPutter, prefix string) AuditSink {
+	return &s3AuditSink{putter: putter, prefix: prefix}
+}
+
+// WriteAudit implements AuditSink
+func (s *s3AuditSink) WriteAudit(ctx context.Context, records []AuditRecord) error {
+	objects := make(map[string]*bytes.Buffer)
+	var keys []string
+	for _, record := range records {
+		partition := fmt.Sprintf(
+			"dt=%s/leaderboard=%s",
+			record.Time.UTC().Format(time.DateOnly),
+			url.PathEscape(record.LeaderboardID),
+		)
+		object, ok := objects[partition]
+		if !ok {
+			object = &bytes.Buffer{}
+			objects[partition] = object
+			keys = append(keys, partition)
+		}
+		line, err := encodeAuditRecord(record)
+		if err != nil {
+			return err
+		}
+		object.Write(line)
+	}
+
+	now := time.Now().UnixNano()
+	for _, partition := range keys {
+		key := fmt.Sprintf("%s/%d-%d.jsonl", partition, now, s.seq.Add(1))
+		if s.prefix != "" {
+			key = s.prefix + "/" + key
+		}
+		if err := s.putter.PutObject(ctx, key, objects[partition].Bytes()); err != nil {
+			return fmt.Errorf(
+				"failed to put audit object %s: %w",
+				key,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// firehoseAuditSink sends each record as a JSON line
+type firehoseAuditSink struct {
+	putter FirehoseRecordPutter
+}
+
+// NewFirehoseAuditSink creates a sink sending each record to Firehose as a
+// JSON line, for delivery to S3 in a queryable layout
+func NewFirehoseAuditSink(putter FirehoseRecordPutter) AuditSink {
+	return &firehoseAuditSink{putter: putter}
+}
+
+// WriteAudit implements AuditSink
+func (s *firehoseAuditSink) WriteAudit(ctx context.Context, records []AuditRecord) error {
+	for start := 0; start < len(records); start += firehoseBatchLimit {
+		end := min(start+firehoseBatchLimit, len(records))
+		batch := make([][]byte, 0, end-start)
+		for _, record := range records[start:end] {
+			line, err := encodeAuditRecord(record)
+			if err != nil {
+				return err
+			}
+			batch = append(batch, line)
+		}
+		if err := s.putter.PutRecordBatch(ctx, batch); err != nil {
+			return fmt.Errorf(
+				"failed to put audit records to Firehose: %w",
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// cloudWatchAuditSink writes each record as a JSON log event
+type cloudWatchAuditSink struct {
+	putter CloudWatchLogsPutter
+}
+
+// NewCloudWatchAuditSink creates a sink writing each record as a JSON log
+// event, queryable with CloudWatch Logs Insights
+func NewCloudWatchAuditSink(putter CloudWatchLogsPutter) AuditSink {
+	return &cloudWatchAuditSink{putter: putter}
+}
+
+// WriteAudit implements AuditSink
+func (s *cloudWatchAuditSink) WriteAudit(ctx context.Context, records []AuditRecord) error {
+	events := make([]CloudWatchLogEvent, 0, len(records))
+	for _, record := range records {
+		line, err := encodeAuditRecord(record)
+		if err != nil {
+			return err
+		}
+		events = append(events, CloudWatchLogEvent{
+			Timestamp: record.Time,
+			Message:   string(bytes.TrimSuffix(line, []byte("\n"))),
+		})
+	}
+
+	// CloudWatch Logs only takes batches in time order
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	for start := 0; start < len(events); start += cloudWatchBatchLimit {
+		end := min(start+cloudWatchBatchLimit, len(events))
+		if err := s.putter.PutLogEvents(ctx, events[start:end]); err != nil {
+			return fmt.Errorf(
+				"failed to put audit events to CloudWatch Logs: %w",
+				err,
+			)
+		}
+	}
+
+	return nil
+}
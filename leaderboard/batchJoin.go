@@ -0,0 +1,152 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// JoinLeaderboardBatch adds a party of the helper's client's users to the
+// leaderboard together, for party-based sign-ups. Each participant gives
+// the UserID, the initial Score and optionally Attributes and Metadata; a
+// ClientID other than the helper's fails with ErrClientMismatch. Global
+// leaderboards take each participant's ClientID, which is then required.
+// The participants are written in one DynamoDB transaction and one Redis
+// pipeline, so the party joins as a whole or not at all.
+//
+// The returned errors hold one result per participant, in order: nil if it
+// joined, ErrAlreadyJoined if it was already on the leaderboard, or
+// ErrBatchCancelled if another participant's failure kept it out. Under
+// WithIdempotentJoin participants already joined count as joined and the
+// rest of the party still joins, unless the leaderboard is paid. Paid
+// leaderboards (see WithEntryFee) hold every participant's entry fee first
+// and release the fees of those that did not join. Like JoinLeaderboard, it
+// assigns no skill bracket (see WithSkillBrackets); rated participants join
+// with JoinLeaderboardWithRating. The error is set when the batch could not
+// be attempted.
+func (l *IndividualLeaderboardHelper) JoinLeaderboardBatch(
+	ctx context.Context,
+	participants []*ParticipantModel,
+) ([]error, error) {
+	if len(participants) > MaxBatchUpdateSize {
+		return nil, fmt.Errorf(
+			"%w: cannot join more than %d participants at once",
+			ErrBatchTooLarge,
+			MaxBatchUpdateSize,
+		)
+	}
+
+	// Validate every participant before writing anything
+	party := make([]*models.ParticipantModel, len(participants))
+	for i, participant := range participants {
+		if participant == nil {
+			return nil, fmt.Errorf("participant %d is nil", i)
+		}
+		if participant.ClientID != "" && l.clientID != "" && participant.ClientID != l.clientID {
+			return nil, &ClientMismatchError{
+				Expected: l.clientID,
+				Actual:   participant.ClientID,
+			}
+		}
+
+		// Global leaderboards take each participant's own client
+		clientID := l.clientID
+		if clientID == "" {
+			clientID = participant.ClientID
+		}
+		if clientID == "" {
+			return nil, ErrClientRequired
+		}
+
+		member, err := models.NewNamespacedParticipantModel(
+			l.namespacer,
+			l.storageID,
+			clientID,
+			participant.UserID,
+			participant.Score,
+		)
+		if err != nil {
+			return nil, err
+		}
+		member.Attributes = participant.Attributes
+		member.Metadata = participant.Metadata
+		party[i] = member
+	}
+
+	// Hold every entry fee up front, giving back the ones already held if
+	// one of them fails
+	if l.escrow != nil {
+		for i, participant := range party {
+			holdID, err := l.escrow.Hold(ctx, EscrowHold{
+				LeaderboardID:    l.leaderboardID,
+				ClientID:         participant.ClientID,
+				UserID:           participant.UserID,
+				NamespacedUserID: participant.NamespacedUserID,
+				Amount:           l.entryFee,
+			})
+			if err != nil {
+				holdErr := fmt.Errorf(
+					"failed to hold entry fee: %w",
+					err,
+				)
+				return nil, errors.Join(holdErr, l.releaseHolds(ctx, party[:i], nil))
+			}
+			participant.EscrowHoldID = holdID
+			participant.EscrowState = EscrowHeld
+		}
+	}
+
+	results, err := l.repo.JoinLeaderboardBatch(ctx, l.storageID, party, l.endTime())
+	if err != nil {
+		if l.escrow != nil {
+			return nil, errors.Join(err, l.releaseHolds(ctx, party, nil))
+		}
+		return nil, err
+	}
+
+	if l.escrow != nil {
+		if err := l.releaseHolds(ctx, party, results); err != nil {
+			// The provider is left holding the fees; surface the failure
+			return results, err
+		}
+	}
+
+	var joined []string
+	for i, participant := range party {
+		if results[i] == nil {
+			joined = append(joined, participant.NamespacedUserID)
+			l.rankCache.invalidate(participant.NamespacedUserID)
+		}
+	}
+	if len(joined) > 0 {
+		l.publishChanges(ctx, joined...)
+	}
+
+	return results, nil
+}
+
+// releaseHolds releases the entry fees held for participants that did not
+// join, i.e. those with a result error, or all of them when results is nil
+func (l *IndividualLeaderboardHelper) releaseHolds(
+	ctx context.Context,
+	participants []*models.ParticipantModel,
+	results []error,
+) error {
+	var errs []error
+	for i, participant := range participants {
+		if participant.EscrowHoldID == "" || (results != nil && results[i] == nil) {
+			continue
+		}
+		if err := l.escrow.Release(context.WithoutCancel(ctx), participant.EscrowHoldID); err != nil {
+			errs = append(errs, fmt.Errorf(
+				"failed to release entry fee %s: %w",
+				participant.EscrowHoldID,
+				err,
+			))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,176 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// MaxBatchUpdateSize is the most users UpdateScores takes at once. It is
+// halved on leaderboards credited to a lifetime leaderboard, and again with
+// the outbox.
+const MaxBatchUpdateSize = 100
+
+// UpdateScores adds each delta to the score of one of the helper's client's
+// users, keyed by userID, and returns their new totals and ranks keyed the
+// same way. Each update goes through the metadata resolver, the update
+// hooks and the anomaly detector as with UpdateScore, and every one is
+// screened before any is written: an update a hook or the detector
+// rejects fails the whole batch. Quarantined updates are held for review
+// and left out of the results. The other writes, with their outbox events,
+// form a single DynamoDB transaction, so either every user is credited or
+// none is; ranks are zero while the cached leaderboard is being rebuilt.
+func (l *IndividualLeaderboardHelper) UpdateScores(
+	ctx context.Context,
+	deltas map[string]float64,
+) (map[string]customTypes.MemberScore, error) {
+	if len(deltas) == 0 {
+		return map[string]customTypes.MemberScore{}, nil
+	}
+	if l.clientID == "" {
+		return nil, ErrClientRequired
+	}
+
+	// Scheduled leaderboards only take joins until they open
+	if l.stateAt(utils.GetCurrTimeStamp()) == LeaderboardScheduled {
+		return nil, ErrLeaderboardNotStarted
+	}
+
+	limit := MaxBatchUpdateSize
+	if l.lifetimeLeaderboard {
+		limit /= 2
+	}
+	if l.outbox {
+		limit /= 2
+	}
+	if len(deltas) > limit {
+		return nil, fmt.Errorf(
+			"%w: cannot update more than %d participants at once",
+			ErrBatchTooLarge,
+			limit,
+		)
+	}
+
+	// Validate every user before writing anything, in a stable order
+	userIDs := make([]string, 0, len(deltas))
+	for userID := range deltas {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	updates := make([]*ScoreUpdate, len(userIDs))
+	for i, userID := range userIDs {
+		participant, err := models.NewNamespacedParticipantModel(
+			l.namespacer,
+			l.leaderboardID,
+			l.clientID,
+			userID,
+			deltas[userID],
+		)
+		if err != nil {
+			return nil, err
+		}
+		updates[i], err = l.newScoreUpdate(ctx, participant, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Screen every update before writing any, so a rejection leaves the
+	// whole batch unapplied
+	var applied, quarantined []*ScoreUpdate
+	for _, update := range updates {
+		if err := l.runBeforeUpdateHooks(ctx, update); err != nil {
+			return nil, fmt.Errorf(
+				"failed to update score of user %s: %w",
+				update.UserID,
+				err,
+			)
+		}
+		if l.anomalyDetector != nil {
+			if err := l.inspectUpdate(ctx, update); err != nil {
+				l.runAfterUpdateHooks(ctx, *update, err)
+				return nil, fmt.Errorf(
+					"failed to update score of user %s: %w",
+					update.UserID,
+					err,
+				)
+			}
+		}
+		if update.Verdict == VerdictQuarantine {
+			quarantined = append(quarantined, update)
+			continue
+		}
+		applied = append(applied, update)
+	}
+
+	writes := make([]repos.ScoreWrite, 0, len(applied))
+	for _, update := range applied {
+		writes = append(writes, repos.ScoreWrite{
+			LeaderboardID:      l.storageID,
+			NamespacedUserID:   update.NamespacedUserID,
+			ScoreDelta:         update.ScoreDelta,
+			LeaderboardEndTime: l.endTime(),
+			Attributes:         update.Attributes,
+		})
+	}
+	if l.lifetimeLeaderboard {
+		for _, update := range applied {
+			writes = append(writes, repos.ScoreWrite{
+				LeaderboardID:    lifetimeStorageID(l.clientID),
+				NamespacedUserID: update.NamespacedUserID,
+				ScoreDelta:       update.ScoreDelta,
+			})
+		}
+	}
+
+	var results []customTypes.MemberScore
+	var err error
+	if l.outbox {
+		results, err = l.repo.UpdateScoresWithEvents(ctx, l.storageID, writes, OutboxEventScoreUpdated)
+	} else {
+		results, err = l.repo.UpdateScoresAtomically(ctx, writes)
+	}
+	if err != nil {
+		for _, update := range applied {
+			l.runAfterUpdateHooks(ctx, *update, err)
+		}
+		return nil, err
+	}
+
+	scores := make(map[string]customTypes.MemberScore, len(applied))
+	members := make([]string, len(applied))
+	for i, update := range applied {
+		members[i] = update.NamespacedUserID
+		result := l.scoreUpdateApplied(ctx, update, &results[i])
+		if l.anomalyDetector != nil {
+			l.recordUpdateHistory(ctx, update)
+		}
+		l.runAfterUpdateHooks(ctx, *update, nil)
+		scores[update.UserID] = *result
+	}
+	if len(applied) > 0 {
+		l.recordWrites(ctx, int64(len(applied)))
+		l.publishChanges(ctx, members...)
+	}
+
+	// Hold quarantined updates back only once the batch is written, so a
+	// retried batch never quarantines them twice
+	for _, update := range quarantined {
+		err := ErrUpdateQuarantined
+		if quarantineErr := l.quarantineUpdate(ctx, update); quarantineErr != nil {
+			// The rest of the batch is durable, so only log
+			fmt.Printf("%sError quarantining score update: %v\n", utils.LogPrefix(ctx), quarantineErr)
+			err = quarantineErr
+		}
+		l.runAfterUpdateHooks(ctx, *update, err)
+	}
+
+	return scores, nil
+}
@@ -0,0 +1,17 @@
+package leaderboard
+
+import (
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+)
+
+// BatchWriteLimits bound the rate of bulk writes such as SeedParticipants
+// and DeleteLeaderboard. The writer halves its rate whenever DynamoDB
+// throttles and recovers gradually, staying between MinRate and MaxRate
+// items per second, and optionally under CapacityPerSecond write units.
+type BatchWriteLimits = repos.BatchWriteLimits
+
+// DefaultBatchWriteLimits starts bulk writes at 500 items per second and
+// adapts between 25 and 5,000
+func DefaultBatchWriteLimits() BatchWriteLimits {
+	return repos.DefaultBatchWriteLimits()
+}
@@ -0,0 +1,220 @@
+// Package bench generates configurable read and write load against a
+// leaderboard and reports throughput and latency percentiles, for sizing
+// Redis and DynamoDB capacity ahead of launches.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+)
+
+// Operation is a kind of leaderboard call made by the load generator
+type Operation string
+
+const (
+	// OpUpdateScore increments a random participant's score
+	OpUpdateScore Operation = "update_score"
+	// OpTopN reads the top of the leaderboard
+	OpTopN Operation = "top_n"
+	// OpRank reads a random participant's score and rank
+	OpRank Operation = "rank"
+)
+
+// Config describes the load to generate
+type Config struct {
+	// ClientID owns the generated participants
+	ClientID string
+	// Namespacer builds member IDs; it must match the helper's
+	Namespacer leaderboard.Namespacer
+	// Users is the number of distinct participants the load is spread over
+	Users int
+	// Workers is the number of concurrent callers
+	Workers int
+	// Duration bounds the run. Zero runs until Operations calls are made.
+	Duration time.Duration
+	// Operations bounds the number of calls. Zero runs for Duration.
+	Operations int64
+	// Mix weighs how often each operation is picked
+	Mix map[Operation]int
+	// TopN is the page size of top-N reads
+	TopN int64
+	// MaxScoreDelta bounds the random whole-number score increments
+	MaxScoreDelta int
+	// Seed writes every participant once before measuring, so rank reads
+	// never miss
+	Seed bool
+	// RandSeed makes the generated load reproducible
+	RandSeed int64
+}
+
+// DefaultConfig returns a read-heavy mix of 20% writes, 40% top-10 reads and
+// 40% rank reads over 10,000 participants for one minute
+func DefaultConfig() Config {
+	return Config{
+		ClientID:      "bench",
+		Namespacer:    leaderboard.DefaultNamespacer(),
+		Users:         10000,
+		Workers:       16,
+		Duration:      time.Minute,
+		Mix:           map[Operation]int{OpUpdateScore: 20, OpTopN: 40, OpRank: 40},
+		TopN:          10,
+		MaxScoreDelta: 100,
+		Seed:          true,
+		RandSeed:      1,
+	}
+}
+
+// Run generates the configured load against the helper and reports the
+// outcome. Cancelling ctx stops the run early and reports what was measured.
+func Run(
+	ctx context.Context,
+	helper *leaderboard.IndividualLeaderboardHelper,
+	cfg Config,
+) (*Report, error) {
+	if cfg.Users <= 0 || cfg.Workers <= 0 {
+		return nil, fmt.Errorf("users and workers must be positive")
+	}
+	if cfg.Duration <= 0 && cfg.Operations <= 0 {
+		return nil, fmt.Errorf("either a duration or an operation count is required")
+	}
+	if cfg.Namespacer == nil {
+		cfg.Namespacer = leaderboard.DefaultNamespacer()
+	}
+	if cfg.MaxScoreDelta <= 0 {
+		cfg.MaxScoreDelta = 1
+	}
+
+	members := make([]string, cfg.Users)
+	for i := range members {
+		member, err := cfg.Namespacer.Join(cfg.ClientID, fmt.Sprintf("user-%d", i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build member ID: %w", err)
+		}
+		members[i] = member
+	}
+
+	picker, err := newOperationPicker(cfg.Mix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make every participant known before measuring
+	if cfg.Seed {
+		for _, member := range members {
+			if _, err := helper.UpdateScore(ctx, member, 1); err != nil {
+				return nil, fmt.Errorf("failed to seed participants: %w", err)
+			}
+		}
+	}
+
+	runCtx := ctx
+	if cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
+	var issued int64
+	var issuedMu sync.Mutex
+	nextOperation := func() bool {
+		if cfg.Operations <= 0 {
+			return runCtx.Err() == nil
+		}
+		issuedMu.Lock()
+		defer issuedMu.Unlock()
+		if issued >= cfg.Operations || runCtx.Err() != nil {
+			return false
+		}
+		issued++
+		return true
+	}
+
+	recorders := make([]*recorder, cfg.Workers)
+	startedAt := time.Now()
+
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Workers; w++ {
+		recorders[w] = newRecorder()
+		wg.Add(1)
+		go func(rec *recorder, rng *rand.Rand) {
+			defer wg.Done()
+			for nextOperation() {
+				op := picker.pick(rng)
+				member := members[rng.Intn(len(members))]
+
+				began := time.Now()
+				var err error
+				switch op {
+				case OpUpdateScore:
+					_, err = helper.UpdateScore(runCtx, member, float64(rng.Intn(cfg.MaxScoreDelta)+1))
+				case OpTopN:
+					_, err = helper.GetTopNParticipants(runCtx, cfg.TopN)
+				case OpRank:
+					_, err = helper.GetParticipantScoreAndRank(runCtx, member)
+				}
+
+				// Calls cut short by the end of the run are not measured
+				if err != nil && runCtx.Err() != nil {
+					return
+				}
+				rec.record(op, time.Since(began), err)
+			}
+		}(recorders[w], rand.New(rand.NewSource(cfg.RandSeed+int64(w))))
+	}
+	wg.Wait()
+
+	return newReport(time.Since(startedAt), recorders), nil
+}
+
+// operationPicker chooses operations according to the mix weights
+type operationPicker struct {
+	operations []Operation
+	cumulative []int
+	total      int
+}
+
+// newOperationPicker validates the mix and prepares it for weighted picks
+func newOperationPicker(mix map[Operation]int) (*operationPicker, error) {
+	p := &operationPicker{}
+
+	// Iterate in a fixed order so runs are reproducible
+	for _, op := range []Operation{OpUpdateScore, OpTopN, OpRank} {
+		weight := mix[op]
+		if weight < 0 {
+			return nil, fmt.Errorf("negative weight for %s", op)
+		}
+		if weight == 0 {
+			continue
+		}
+		p.total += weight
+		p.operations = append(p.operations, op)
+		p.cumulative = append(p.cumulative, p.total)
+	}
+	for op := range mix {
+		if op != OpUpdateScore && op != OpTopN && op != OpRank {
+			return nil, fmt.Errorf("unknown operation %q", op)
+		}
+	}
+	if p.total == 0 {
+		return nil, fmt.Errorf("the operation mix is empty")
+	}
+
+	return p, nil
+}
+
+// pick returns a random operation
+func (p *operationPicker) pick(rng *rand.Rand) Operation {
+	n := rng.Intn(p.total)
+	for i, bound := range p.cumulative {
+		if n < bound {
+			return p.operations[i]
+		}
+	}
+
+	return p.operations[len(p.operations)-1]
+}
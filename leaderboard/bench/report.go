@@ -0,0 +1,131 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// recorder collects the latencies measured by a single worker
+type recorder struct {
+	latencies map[Operation][]time.Duration
+	errors    map[Operation]int64
+}
+
+// newRecorder creates an empty recorder
+func newRecorder() *recorder {
+	return &recorder{
+		latencies: make(map[Operation][]time.Duration),
+		errors:    make(map[Operation]int64),
+	}
+}
+
+// record adds one call's outcome
+func (r *recorder) record(op Operation, latency time.Duration, err error) {
+	if err != nil {
+		r.errors[op]++
+		return
+	}
+	r.latencies[op] = append(r.latencies[op], latency)
+}
+
+// OperationStats summarises the calls made for one operation
+type OperationStats struct {
+	Operation  Operation
+	Count      int64
+	Errors     int64
+	Throughput float64
+	Mean       time.Duration
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+}
+
+// Report is the outcome of a benchmark run
+type Report struct {
+	Elapsed    time.Duration
+	Operations []OperationStats
+}
+
+// newReport merges the workers' recorders into per-operation statistics
+func newReport(elapsed time.Duration, recorders []*recorder) *Report {
+	report := &Report{Elapsed: elapsed}
+
+	for _, op := range []Operation{OpUpdateScore, OpTopN, OpRank} {
+		var latencies []time.Duration
+		var errors int64
+		for _, rec := range recorders {
+			latencies = append(latencies, rec.latencies[op]...)
+			errors += rec.errors[op]
+		}
+		if len(latencies) == 0 && errors == 0 {
+			continue
+		}
+
+		stats := OperationStats{
+			Operation: op,
+			Count:     int64(len(latencies)),
+			Errors:    errors,
+		}
+		if elapsed > 0 {
+			stats.Throughput = float64(stats.Count) / elapsed.Seconds()
+		}
+		if len(latencies) > 0 {
+			sort.Slice(latencies, func(i, j int) bool {
+				return latencies[i] < latencies[j]
+			})
+			var total time.Duration
+			for _, latency := range latencies {
+				total += latency
+			}
+			stats.Mean = total / time.Duration(len(latencies))
+			stats.P50 = percentile(latencies, 0.50)
+			stats.P90 = percentile(latencies, 0.90)
+			stats.P99 = percentile(latencies, 0.99)
+			stats.Max = latencies[len(latencies)-1]
+		}
+		report.Operations = append(report.Operations, stats)
+	}
+
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	index := int(p*float64(len(sorted))+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+
+	return sorted[index]
+}
+
+// Print writes the report as a table
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "elapsed: %v\n\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(
+		w,
+		"%-14s %10s %8s %10s %10s %10s %10s %10s %10s\n",
+		"operation", "ok", "errors", "ops/s", "mean", "p50", "p90", "p99", "max",
+	)
+	for _, s := range r.Operations {
+		fmt.Fprintf(
+			w,
+			"%-14s %10d %8d %10.1f %10v %10v %10v %10v %10v\n",
+			s.Operation,
+			s.Count,
+			s.Errors,
+			s.Throughput,
+			s.Mean.Round(time.Microsecond),
+			s.P50.Round(time.Microsecond),
+			s.P90.Round(time.Microsecond),
+			s.P99.Round(time.Microsecond),
+			s.Max.Round(time.Microsecond),
+		)
+	}
+}
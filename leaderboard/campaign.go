@@ -0,0 +1,154 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// Campaign groups several leaderboards of a client, for example one per
+// game mode, and ranks participants across them by the placement points
+// they earn on each
+type Campaign struct {
+	ID             string
+	LeaderboardIDs []string
+	Points         PlacementPoints
+
+	manager *Manager
+}
+
+// CampaignBoardStanding is a participant's standing on one leaderboard of a
+// campaign
+type CampaignBoardStanding struct {
+	LeaderboardID string
+	Score         float64
+	// Rank is zero when the participant is not on the leaderboard
+	Rank   int64
+	Points float64
+}
+
+// CampaignStanding is a participant's aggregate standing across a campaign
+type CampaignStanding struct {
+	CampaignID  string
+	UserID      string
+	TotalScore  float64
+	TotalPoints float64
+	Boards      []CampaignBoardStanding
+}
+
+// NewCampaign creates a campaign over leaderboards served by the manager,
+// scoring placements with points
+func (m *Manager) NewCampaign(
+	id string,
+	leaderboardIDs []string,
+	points PlacementPoints,
+) *Campaign {
+	return &Campaign{
+		ID:             id,
+		LeaderboardIDs: leaderboardIDs,
+		Points:         points,
+		manager:        m,
+	}
+}
+
+// GetUserStanding returns one of the manager's client's users' score, rank
+// and placement points on each leaderboard of the campaign, and their
+// totals
+func (c *Campaign) GetUserStanding(
+	ctx context.Context,
+	userID string,
+) (*CampaignStanding, error) {
+	namespacedUserID, err := c.manager.options.namespacer.Join(c.manager.clientID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	standing := &CampaignStanding{
+		CampaignID: c.ID,
+		UserID:     userID,
+		Boards:     make([]CampaignBoardStanding, len(c.LeaderboardIDs)),
+	}
+	for i, leaderboardID := range c.LeaderboardIDs {
+		helper, err := c.manager.Helper(ctx, leaderboardID)
+		if err != nil {
+			return nil, err
+		}
+
+		standing.Boards[i].LeaderboardID = leaderboardID
+		entry, err := helper.GetParticipantScoreAndRank(ctx, namespacedUserID)
+		if errors.Is(err, customTypes.ErrParticipantNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to get standing on leaderboard %s: %w",
+				leaderboardID,
+				err,
+			)
+		}
+
+		standing.Boards[i].Score = entry.Score
+		standing.Boards[i].Rank = entry.Rank
+		standing.Boards[i].Points = c.Points.For(entry.Rank)
+		standing.TotalScore += entry.Score
+		standing.TotalPoints += standing.Boards[i].Points
+	}
+
+	return standing, nil
+}
+
+// GetTopNParticipants returns the campaign leaderboard: participants ranked
+// by the placement points summed over the campaign's leaderboards, ties
+// broken like the leaderboards themselves. Scores are the points totals.
+// Ghost participants earn no points.
+func (c *Campaign) GetTopNParticipants(
+	ctx context.Context,
+	n int64,
+) ([]customTypes.MemberScore, error) {
+	points := make(map[string]float64)
+	for _, leaderboardID := range c.LeaderboardIDs {
+		helper, err := c.manager.Helper(ctx, leaderboardID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only placements that earn points matter
+		entries, err := helper.GetTopNParticipants(ctx, int64(len(c.Points)))
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to get placements on leaderboard %s: %w",
+				leaderboardID,
+				err,
+			)
+		}
+		for _, entry := range entries {
+			if entry.Ghost {
+				continue
+			}
+			points[entry.Member] += c.Points.For(entry.Rank)
+		}
+	}
+
+	standings := make([]customTypes.MemberScore, 0, len(points))
+	for member, total := range points {
+		standings = append(standings, customTypes.MemberScore{Member: member, Score: total})
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Score != standings[j].Score {
+			return standings[i].Score > standings[j].Score
+		}
+		return standings[i].Member > standings[j].Member
+	})
+
+	if n >= 0 && int64(len(standings)) > n {
+		standings = standings[:n]
+	}
+	for i := range standings {
+		standings[i].Rank = int64(i + 1)
+	}
+
+	return standings, nil
+}
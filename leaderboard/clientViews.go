@@ -0,0 +1,122 @@
+package leaderboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/redis/go-redis/v9"
+)
+
+// ClientFilterAttribute is the filter attribute holding each participant's
+// clientID under WithClientViews
+const ClientFilterAttribute = "clientID"
+
+// NewGlobalLeaderboardHelper creates a helper for a leaderboard spanning
+// every client, e.g. for an ecosystem-wide competition. It accepts any
+// client's users and keeps a view per client, as with WithClientViews, so
+// each game can still show its own standings. APIs taking namespaced user
+// IDs work on it directly; those taking a userID fail with
+// ErrClientRequired and are used through ForClient.
+func NewGlobalLeaderboardHelper(
+	dynamoClient *dynamodb.Client,
+	redisClient *redis.Client,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+	opts ...Option,
+) *IndividualLeaderboardHelper {
+	opts = append(append([]Option(nil), opts...), WithClientViews())
+	return NewIndividualLeaderboardHelper(
+		dynamoClient,
+		redisClient,
+		"",
+		leaderboardID,
+		leaderboardEndTime,
+		opts...,
+	)
+}
+
+// ForClient returns a helper for one client's users on a global
+// leaderboard, sharing its storage, so the APIs taking a userID, such as
+// JoinLeaderboard, UpdateScores or TransferScore, can be used on it. On a
+// client's own helper it returns the helper itself for the same client and
+// fails with ErrClientMismatch for any other.
+func (l *IndividualLeaderboardHelper) ForClient(clientID string) (*IndividualLeaderboardHelper, error) {
+	if l.clientID != "" {
+		if clientID != l.clientID {
+			return nil, &ClientMismatchError{
+				Expected: l.clientID,
+				Actual:   clientID,
+			}
+		}
+		return l, nil
+	}
+	if clientID == "" {
+		return nil, ErrClientRequired
+	}
+
+	client := *l
+	client.clientID = clientID
+
+	return &client, nil
+}
+
+// memberID returns the member of one of the helper's client's users. Global
+// helpers have no client and fail with ErrClientRequired.
+func (l *IndividualLeaderboardHelper) memberID(userID string) (string, error) {
+	if l.clientID == "" {
+		return "", ErrClientRequired
+	}
+
+	return l.namespacer.Join(l.clientID, userID)
+}
+
+// GetTopNParticipantsForClient retrieves the top N participants of one
+// client, ranked among that client's participants. It needs
+// WithClientViews.
+func (l *IndividualLeaderboardHelper) GetTopNParticipantsForClient(
+	ctx context.Context,
+	clientID string,
+	n int64,
+) ([]customTypes.MemberScore, error) {
+	if !l.clientViews {
+		return nil, ErrClientViewsDisabled
+	}
+
+	return l.GetTopNFilteredParticipants(ctx, n, Filter{ClientFilterAttribute: clientID})
+}
+
+// GetParticipantRankForClient retrieves a participant's score and rank
+// among the participants of their own client. It needs WithClientViews.
+func (l *IndividualLeaderboardHelper) GetParticipantRankForClient(
+	ctx context.Context,
+	namespacedUserID string,
+) (*customTypes.MemberScore, error) {
+	if !l.clientViews {
+		return nil, ErrClientViewsDisabled
+	}
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	clientID, _, err := l.validateNamespacedUserID(namespacedUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := l.repo.GetFilteredParticipantRank(
+		ctx,
+		l.storageID,
+		ClientFilterAttribute,
+		clientID,
+		namespacedUserID,
+		l.endTime(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	participant.Ghost = clientID == GhostClientID
+	return l.transformResult(ctx, participant), nil
+}
@@ -0,0 +1,47 @@
+package leaderboard
+
+import (
+	"context"
+)
+
+// CloneOptions controls what CloneLeaderboard copies besides the
+// configuration
+type CloneOptions struct {
+	// Participants copies every participant of the source, apart from
+	// ghosts, with a zero score, keeping their filter attributes and
+	// metadata
+	Participants bool
+}
+
+// CloneLeaderboard stamps out newID from sourceID as a template, for
+// example the next round of a weekly event, and returns its helper. The
+// configuration, such as tiers, integer scores and filters, is the
+// manager's options and so carries over to every leaderboard it serves;
+// the new leaderboard's end time comes from the manager's end time
+// resolver. With opts.Participants set the source's participants are
+// copied with zeroed scores, which fails if newID already has participants.
+func (m *Manager) CloneLeaderboard(
+	ctx context.Context,
+	sourceID string,
+	newID string,
+	opts CloneOptions,
+) (*IndividualLeaderboardHelper, error) {
+	source, err := m.Helper(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := m.Helper(ctx, newID)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Participants {
+		_, err := m.repo.CloneParticipants(ctx, source.storageID, target.storageID, target.endTime())
+		if err != nil {
+			return nil, err
+		}
+		target.publishChanges(ctx)
+	}
+
+	return target, nil
+}
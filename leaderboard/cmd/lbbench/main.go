@@ -0,0 +1,115 @@
+// Command lbbench generates a configurable mix of leaderboard reads and
+// writes against real or local backends and reports throughput and latency
+// percentiles.
+//
+// Usage:
+//
+//	lbbench -leaderboard bench-lb [flags]
+//
+// Point -dynamo-endpoint at DynamoDB Local to benchmark without AWS.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+	"github.com/kgen-protocol/platform-libs/leaderboard/bench"
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "lbbench: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the flags, generates the load and prints the report
+func run(ctx context.Context, args []string) error {
+	cfg := bench.DefaultConfig()
+
+	fs := flag.NewFlagSet("lbbench", flag.ExitOnError)
+	redisAddr := fs.String("redis-addr", "localhost:6379", "Redis address")
+	redisPassword := fs.String("redis-password", os.Getenv("REDIS_PASSWORD"), "Redis password (defaults to $REDIS_PASSWORD)")
+	redisDB := fs.Int("redis-db", 0, "Redis database number")
+	awsRegion := fs.String("aws-region", "", "AWS region (defaults to the SDK's configuration)")
+	dynamoEndpoint := fs.String("dynamo-endpoint", "", "DynamoDB endpoint override, e.g. http://localhost:8000 for DynamoDB Local")
+	tableName := fs.String("table", "", "DynamoDB table (defaults to the library's table)")
+	leaderboardID := fs.String("leaderboard", "", "leaderboard ID to load (required)")
+	endTime := fs.Duration("end-in", 24*time.Hour, "leaderboard end time, relative to now")
+	fs.StringVar(&cfg.ClientID, "client", cfg.ClientID, "client ID owning the generated participants")
+	fs.IntVar(&cfg.Users, "users", cfg.Users, "number of distinct participants")
+	fs.IntVar(&cfg.Workers, "workers", cfg.Workers, "number of concurrent callers")
+	fs.DurationVar(&cfg.Duration, "duration", cfg.Duration, "how long to run; 0 runs until -ops calls are made")
+	fs.Int64Var(&cfg.Operations, "ops", 0, "number of calls to make; 0 runs for -duration")
+	writes := fs.Int("writes", cfg.Mix[bench.OpUpdateScore], "relative weight of score updates")
+	topReads := fs.Int("top-reads", cfg.Mix[bench.OpTopN], "relative weight of top-N reads")
+	rankReads := fs.Int("rank-reads", cfg.Mix[bench.OpRank], "relative weight of rank reads")
+	fs.Int64Var(&cfg.TopN, "top-n", cfg.TopN, "page size of top-N reads")
+	fs.IntVar(&cfg.MaxScoreDelta, "max-delta", cfg.MaxScoreDelta, "largest score increment")
+	fs.BoolVar(&cfg.Seed, "seed", cfg.Seed, "write every participant once before measuring")
+	fs.Int64Var(&cfg.RandSeed, "rand-seed", cfg.RandSeed, "seed for the generated load")
+	fs.Parse(args)
+
+	if *leaderboardID == "" {
+		return fmt.Errorf("-leaderboard is required")
+	}
+	cfg.Mix = map[bench.Operation]int{
+		bench.OpUpdateScore: *writes,
+		bench.OpTopN:        *topReads,
+		bench.OpRank:        *rankReads,
+	}
+
+	var loadOpts []func(*config.LoadOptions) error
+	if *awsRegion != "" {
+		loadOpts = append(loadOpts, config.WithRegion(*awsRegion))
+	}
+	awsConfig, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	dynamoClient := dynamodb.NewFromConfig(awsConfig, func(o *dynamodb.Options) {
+		if *dynamoEndpoint != "" {
+			o.BaseEndpoint = aws.String(*dynamoEndpoint)
+		}
+	})
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     *redisAddr,
+		Password: *redisPassword,
+		DB:       *redisDB,
+	})
+	defer redisClient.Close()
+
+	var opts []leaderboard.Option
+	if *tableName != "" {
+		opts = append(opts, leaderboard.WithTableName(*tableName))
+	}
+	helper := leaderboard.NewIndividualLeaderboardHelper(
+		dynamoClient,
+		redisClient,
+		cfg.ClientID,
+		*leaderboardID,
+		time.Now().Add(*endTime),
+		opts...,
+	)
+
+	report, err := bench.Run(ctx, helper, cfg)
+	if err != nil {
+		return err
+	}
+	report.Print(os.Stdout)
+
+	return nil
+}
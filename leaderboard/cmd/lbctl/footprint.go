@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+)
+
+// runFootprint implements "lbctl footprint"
+func runFootprint(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("footprint", flag.ExitOnError)
+	var conn connectionFlags
+	conn.register(fs)
+	perKey := fs.Bool("keys", false, "break the memory down by Redis key")
+	fs.Parse(args)
+
+	helper, err := conn.helper(ctx)
+	if err != nil {
+		return err
+	}
+
+	footprint, err := helper.GetCacheFootprint(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"%s: %d members, %d bytes (%.1f per member), projected %d bytes\n",
+		footprint.LeaderboardID,
+		footprint.Members,
+		footprint.Bytes,
+		footprint.BytesPerMember,
+		footprint.ProjectedBytes,
+	)
+	if *perKey {
+		keys := make([]string, 0, len(footprint.KeyBytes))
+		for key := range footprint.KeyBytes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("  %-60s %d\n", key, footprint.KeyBytes[key])
+		}
+	}
+
+	return nil
+}
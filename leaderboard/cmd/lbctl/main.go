@@ -0,0 +1,153 @@
+// Command lbctl performs operational tasks against leaderboards.
+//
+// Usage:
+//
+//	lbctl <command> [flags]
+//
+// Run "lbctl <command> -h" for the flags of a command.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+	"github.com/redis/go-redis/v9"
+)
+
+// command is a single lbctl subcommand
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+// commands lists every available subcommand by name
+var commands = map[string]command{
+	"footprint": {
+		summary: "report the Redis memory used by a leaderboard's cache",
+		run:     runFootprint,
+	},
+	"prune": {
+		summary: "remove participants that have not scored for a while",
+		run:     runPrune,
+	},
+	"verify": {
+		summary: "compare cached scores against DynamoDB and optionally repair drift",
+		run:     runVerify,
+	},
+	"warm": {
+		summary: "load a leaderboard from DynamoDB into Redis ahead of traffic",
+		run:     runWarm,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "lbctl: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.run(ctx, os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "lbctl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// usage prints the list of subcommands
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: lbctl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
+
+// connectionFlags are the flags shared by every subcommand for reaching the
+// backing stores
+type connectionFlags struct {
+	redisAddr     string
+	redisPassword string
+	redisDB       int
+	awsRegion     string
+	leaderboardID string
+	clientID      string
+	endTime       string
+}
+
+// register adds the connection flags to a flag set
+func (c *connectionFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.redisAddr, "redis-addr", "localhost:6379", "Redis address")
+	fs.StringVar(&c.redisPassword, "redis-password", os.Getenv("REDIS_PASSWORD"), "Redis password (defaults to $REDIS_PASSWORD)")
+	fs.IntVar(&c.redisDB, "redis-db", 0, "Redis database number")
+	fs.StringVar(&c.awsRegion, "aws-region", "", "AWS region (defaults to the SDK's configuration)")
+	fs.StringVar(&c.leaderboardID, "leaderboard", "", "leaderboard ID (required)")
+	fs.StringVar(&c.clientID, "client", "", "client ID owning the leaderboard")
+	fs.StringVar(&c.endTime, "end-time", "", "leaderboard end time in RFC3339, used for cache expiry")
+}
+
+// helper builds a leaderboard helper from the connection flags
+func (c *connectionFlags) helper(
+	ctx context.Context,
+	opts ...leaderboard.Option,
+) (*leaderboard.IndividualLeaderboardHelper, error) {
+	if c.leaderboardID == "" {
+		return nil, fmt.Errorf("-leaderboard is required")
+	}
+
+	var endTime time.Time
+	if c.endTime != "" {
+		parsed, err := time.Parse(time.RFC3339, c.endTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -end-time: %w", err)
+		}
+		endTime = parsed
+	}
+
+	var loadOpts []func(*config.LoadOptions) error
+	if c.awsRegion != "" {
+		loadOpts = append(loadOpts, config.WithRegion(c.awsRegion))
+	}
+	awsConfig, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     c.redisAddr,
+		Password: c.redisPassword,
+		DB:       c.redisDB,
+	})
+
+	return leaderboard.NewIndividualLeaderboardHelper(
+		dynamodb.NewFromConfig(awsConfig),
+		redisClient,
+		c.clientID,
+		c.leaderboardID,
+		endTime,
+		opts...,
+	), nil
+}
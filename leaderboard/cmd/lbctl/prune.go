@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// runPrune implements "lbctl prune"
+func runPrune(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	var conn connectionFlags
+	conn.register(fs)
+	inactiveFor := fs.Duration("inactive-for", 0, "remove participants that have not scored for this long (required)")
+	fs.Parse(args)
+
+	if *inactiveFor <= 0 {
+		return fmt.Errorf("-inactive-for must be positive")
+	}
+
+	helper, err := conn.helper(ctx)
+	if err != nil {
+		return err
+	}
+
+	inactiveSince := time.Now().Add(-*inactiveFor)
+	pruned, err := helper.PruneParticipants(ctx, inactiveSince)
+	fmt.Printf(
+		"%s: pruned %d participants inactive since %s\n",
+		conn.leaderboardID,
+		pruned,
+		inactiveSince.Format(time.RFC3339),
+	)
+
+	return err
+}
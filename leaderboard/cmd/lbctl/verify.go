@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runVerify implements "lbctl verify"
+func runVerify(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	var conn connectionFlags
+	conn.register(fs)
+	sampleSize := fs.Int("sample", 1000, "number of cached participants to compare")
+	repair := fs.Bool("repair", false, "overwrite drifted cache entries with DynamoDB scores")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Parse(args)
+
+	helper, err := conn.helper(ctx)
+	if err != nil {
+		return err
+	}
+
+	report, err := helper.VerifyConsistency(ctx, *sampleSize, *repair)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(report)
+	}
+
+	fmt.Printf("leaderboard:       %s\n", report.LeaderboardID)
+	fmt.Printf("sampled:           %d\n", report.Sampled)
+	fmt.Printf("mismatched:        %d\n", report.Mismatched)
+	fmt.Printf("missing in dynamo: %d\n", report.MissingInDynamo)
+	fmt.Printf("drift ratio:       %.4f\n", report.DriftRatio())
+	fmt.Printf("max abs drift:     %g\n", report.MaxAbsDrift)
+	fmt.Printf("mean abs drift:    %g\n", report.MeanAbsDrift)
+	if *repair {
+		fmt.Printf("repaired:          %d\n", report.Repaired)
+	}
+
+	return nil
+}
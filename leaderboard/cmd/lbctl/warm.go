@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+)
+
+// runWarm implements "lbctl warm"
+func runWarm(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("warm", flag.ExitOnError)
+	var conn connectionFlags
+	conn.register(fs)
+	force := fs.Bool("force", false, "reload the leaderboard even if it is already cached")
+	fs.Parse(args)
+
+	helper, err := conn.helper(ctx)
+	if err != nil {
+		return err
+	}
+
+	return helper.WarmCache(ctx, *force, func(p leaderboard.WarmProgress) {
+		if p.Done {
+			if p.Err == nil {
+				fmt.Printf("%s: warmed, %d items loaded\n", p.LeaderboardID, p.ItemsLoaded)
+			}
+			return
+		}
+		fmt.Printf("%s: %d items loaded\n", p.LeaderboardID, p.ItemsLoaded)
+	})
+}
@@ -0,0 +1,45 @@
+package leaderboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// CombineLeaderboards materialises a weighted union of the source
+// leaderboards as a new leaderboard, for example a composite ranking across
+// game modes or regions. Each participant's score on targetID is the sum of
+// their source scores multiplied by the matching weights (nil weighs every
+// source 1). The combined leaderboard is a snapshot kept in Redis for ttl
+// and is returned as a helper whose end time is its creation, so score
+// updates to it are rejected under the default end time policy. It is
+// empty once it expires. Calling it again
+// replaces the snapshot.
+func (m *Manager) CombineLeaderboards(
+	ctx context.Context,
+	targetID string,
+	sourceIDs []string,
+	weights []float64,
+	ttl time.Duration,
+) (*IndividualLeaderboardHelper, error) {
+	storageIDs := make([]string, len(sourceIDs))
+	endTimes := make([]time.Time, len(sourceIDs))
+	for i, sourceID := range sourceIDs {
+		helper, err := m.Helper(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		storageIDs[i] = helper.storageID
+		endTimes[i] = helper.endTime()
+	}
+
+	// The combined leaderboard ends as it is created, so it only serves reads
+	target := newHelper(m.repo, m.options, m.clientID, targetID, utils.GetCurrTimeStamp())
+	err := m.repo.CombineLeaderboards(ctx, target.storageID, storageIDs, endTimes, weights, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
@@ -0,0 +1,20 @@
+package leaderboard
+
+import "github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+
+// ConflictStats counts DynamoDB transaction conflicts seen by a
+// leaderboard's writes since the process started
+type ConflictStats = customTypes.ConflictStats
+
+// ConflictStats returns the transaction conflicts the helper's writes have
+// run into and how often they were retried, e.g. for export as metrics.
+// Helpers sharing a Manager share their counts.
+func (l *IndividualLeaderboardHelper) ConflictStats() ConflictStats {
+	return l.repo.ConflictStats()
+}
+
+// ConflictStats returns the transaction conflicts seen by writes to the
+// client's leaderboards
+func (m *Manager) ConflictStats() ConflictStats {
+	return m.repo.ConflictStats()
+}
@@ -0,0 +1,71 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// LeaderboardDelta is one leaderboard's score change in
+// UpdateScoreAcrossLeaderboards
+type LeaderboardDelta struct {
+	LeaderboardID string
+	ScoreDelta    float64
+}
+
+// UpdateScoreAcrossLeaderboards credits one of the manager's client's users
+// on several leaderboards at once, for example an event leaderboard and a
+// lifetime leaderboard. The DynamoDB writes form a single transaction, so
+// either every leaderboard is credited or none is; the cached leaderboards
+// are then updated in one Redis MULTI/EXEC block. Results follow the order
+// of deltas. Update hooks and metadata resolvers are not run, and each
+// leaderboard may appear only once.
+func (m *Manager) UpdateScoreAcrossLeaderboards(
+	ctx context.Context,
+	userID string,
+	deltas []LeaderboardDelta,
+) ([]customTypes.MemberScore, error) {
+	namespacedUserID, err := m.options.namespacer.Join(m.clientID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve every leaderboard before writing anything
+	helpers := make([]*IndividualLeaderboardHelper, len(deltas))
+	writes := make([]repos.ScoreWrite, len(deltas))
+	now := utils.GetCurrTimeStamp()
+	for i, delta := range deltas {
+		helper, err := m.Helper(ctx, delta.LeaderboardID)
+		if err != nil {
+			return nil, err
+		}
+		if helper.stateAt(now) == LeaderboardScheduled {
+			return nil, ErrLeaderboardNotStarted
+		}
+		helpers[i] = helper
+		writes[i] = repos.ScoreWrite{
+			LeaderboardID:      helper.storageID,
+			NamespacedUserID:   namespacedUserID,
+			ScoreDelta:         delta.ScoreDelta,
+			LeaderboardEndTime: helper.endTime(),
+		}
+	}
+
+	results, err := m.repo.UpdateScoresAtomically(ctx, writes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Let caches in other regions apply the same deltas
+	for i, helper := range helpers {
+		helper.publishCacheUpdate(ctx, namespacedUserID, deltas[i].ScoreDelta)
+		helper.recordMilestones(ctx, namespacedUserID, deltas[i].ScoreDelta, &results[i])
+		helper.creditBracket(ctx, namespacedUserID, deltas[i].ScoreDelta)
+		helper.recordWrites(ctx, 1)
+		helper.publishChanges(ctx, namespacedUserID)
+	}
+
+	return results, nil
+}
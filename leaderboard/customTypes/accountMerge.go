@@ -0,0 +1,12 @@
+package customTypes
+
+// AccountMergeStrategy decides how the scores of two merged accounts are
+// combined
+type AccountMergeStrategy int
+
+const (
+	// AccountMergeSum gives the primary account the sum of both scores
+	AccountMergeSum AccountMergeStrategy = iota
+	// AccountMergeMax gives the primary account the higher of both scores
+	AccountMergeMax
+)
@@ -0,0 +1,41 @@
+package customTypes
+
+// AnomalyVerdict is an anomaly detector's decision on a score update
+type AnomalyVerdict int
+
+const (
+	// VerdictAllow applies the update
+	VerdictAllow AnomalyVerdict = iota
+	// VerdictFlag applies the update and marks it as suspicious
+	VerdictFlag
+	// VerdictQuarantine holds the update back instead of applying it
+	VerdictQuarantine
+	// VerdictReject refuses the update
+	VerdictReject
+)
+
+// String returns the verdict's name
+func (v AnomalyVerdict) String() string {
+	switch v {
+	case VerdictAllow:
+		return "allow"
+	case VerdictFlag:
+		return "flag"
+	case VerdictQuarantine:
+		return "quarantine"
+	case VerdictReject:
+		return "reject"
+	}
+
+	return "unknown"
+}
+
+// UpdateHistory summarises a participant's recent score updates
+type UpdateHistory struct {
+	// Count is the number of recent updates
+	Count int64
+	// Mean is the mean score delta of the recent updates
+	Mean float64
+	// StdDev is the standard deviation of the recent score deltas
+	StdDev float64
+}
@@ -0,0 +1,20 @@
+package customTypes
+
+import "time"
+
+// AuditRecord is one score mutation in the audit trail, flattened for
+// querying, e.g. with Athena over JSON lines
+type AuditRecord struct {
+	Time             time.Time `json:"time"`
+	LeaderboardID    string    `json:"leaderboardID"`
+	ClientID         string    `json:"clientID"`
+	UserID           string    `json:"userID"`
+	NamespacedUserID string    `json:"namespacedUserID"`
+	ScoreDelta       float64   `json:"scoreDelta"`
+	// EventTime is when the scoring event happened, if given
+	EventTime *time.Time `json:"eventTime,omitempty"`
+	Verdict   string     `json:"verdict,omitempty"`
+	RequestID string     `json:"requestID,omitempty"`
+	// Error is why the update failed, empty if it was applied
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,17 @@
+package customTypes
+
+// CacheExpiryPolicy decides when a leaderboard's cached Redis keys expire
+type CacheExpiryPolicy int
+
+const (
+	// CacheExpireAfterEnd expires the keys a retention period after the
+	// leaderboard's end time
+	CacheExpireAfterEnd CacheExpiryPolicy = iota
+	// CacheExpireAfterAccess also keeps the keys for a retention period
+	// after the leaderboard was last read or written, so boards in active
+	// use are never expired
+	CacheExpireAfterAccess
+	// CacheNeverExpire keeps the keys until the leaderboard is deleted, e.g.
+	// for all-time leaderboards
+	CacheNeverExpire
+)
@@ -0,0 +1,29 @@
+package customTypes
+
+// CacheFootprint is the Redis memory a leaderboard's cache uses
+type CacheFootprint struct {
+	LeaderboardID string
+	// Members is the number of members of the cached leaderboard
+	Members int64
+	// Bytes is the memory used by all of the leaderboard's keys
+	Bytes int64
+	// KeyBytes breaks Bytes down by key
+	KeyBytes map[string]int64
+	// BytesPerMember is Bytes spread over the members
+	BytesPerMember float64
+	// MembersPerHour is the rate members joined at since the leaderboard
+	// started, zero when its start time is unknown
+	MembersPerHour float64
+	// ProjectedBytes extrapolates Bytes to the leaderboard's end time at
+	// MembersPerHour
+	ProjectedBytes int64
+}
+
+// CacheFootprintReport aggregates the cache footprints of several
+// leaderboards
+type CacheFootprintReport struct {
+	Leaderboards   []CacheFootprint
+	Members        int64
+	Bytes          int64
+	ProjectedBytes int64
+}
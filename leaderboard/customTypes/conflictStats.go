@@ -0,0 +1,13 @@
+package customTypes
+
+// ConflictStats counts DynamoDB transaction conflicts seen by a
+// leaderboard's writes since the process started
+type ConflictStats struct {
+	// Conflicts is how many transactions were cancelled because another
+	// write touched the same items
+	Conflicts int64
+	// Retries is how many of those were retried
+	Retries int64
+	// Exhausted is how many writes failed after using up their retries
+	Exhausted int64
+}
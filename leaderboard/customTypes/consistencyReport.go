@@ -0,0 +1,27 @@
+package customTypes
+
+// ConsistencyReport summarises how far a cached leaderboard has drifted from
+// DynamoDB over a sample of participants
+type ConsistencyReport struct {
+	LeaderboardID string
+	// Sampled is the number of participants compared
+	Sampled int
+	// Mismatched counts participants whose scores differ between stores
+	Mismatched int
+	// MissingInDynamo counts cached participants with no DynamoDB item
+	MissingInDynamo int
+	// MaxAbsDrift and MeanAbsDrift are measured over mismatched participants
+	MaxAbsDrift  float64
+	MeanAbsDrift float64
+	// Repaired counts cache entries corrected from DynamoDB
+	Repaired int
+}
+
+// DriftRatio returns the fraction of sampled participants that mismatched
+func (c ConsistencyReport) DriftRatio() float64 {
+	if c.Sampled == 0 {
+		return 0
+	}
+
+	return float64(c.Mismatched+c.MissingInDynamo) / float64(c.Sampled)
+}
@@ -0,0 +1,14 @@
+package customTypes
+
+// DeletionReport describes what a leaderboard deletion removed, or would
+// remove when run as a dry run
+type DeletionReport struct {
+	LeaderboardID    string
+	DryRun           bool
+	ParticipantItems int64
+	RankHistoryItems int64
+	SnapshotItems    int64
+	PendingItems     int64
+	OutboxItems      int64
+	RedisKeys        []string
+}
@@ -0,0 +1,17 @@
+package customTypes
+
+// EndTimePolicy decides what happens to writes that arrive after a
+// leaderboard's end time
+type EndTimePolicy int
+
+const (
+	// EndTimeReject rejects late writes with ErrLeaderboardEnded, so final
+	// standings cannot change
+	EndTimeReject EndTimePolicy = iota
+	// EndTimeFlag applies late writes but records the late part of each
+	// participant's score separately for review
+	EndTimeFlag
+	// EndTimeGrace applies and flags writes arriving within a grace period
+	// after the end, and rejects writes after it
+	EndTimeGrace
+)
@@ -0,0 +1,282 @@
+package customTypes
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidScore is matched by every score validation failure
+	ErrInvalidScore = errors.New("invalid score")
+	// ErrNonFiniteScore is returned for NaN and infinite scores
+	ErrNonFiniteScore = fmt.Errorf("%w: score must be a finite number", ErrInvalidScore)
+	// ErrFractionalScore is returned for fractional scores on integer leaderboards
+	ErrFractionalScore = fmt.Errorf("%w: score must be a whole number", ErrInvalidScore)
+)
+
+// ErrInvalidUserID is returned for malformed or unsplittable user IDs
+var ErrInvalidUserID = errors.New("invalid namespaced user ID format")
+
+// ErrParticipantNotFound is returned when a participant is not part of the
+// leaderboard
+var ErrParticipantNotFound = errors.New("participant not found in leaderboard")
+
+// ErrClientMismatch is matched when a user of another client is used with a
+// client-scoped leaderboard helper
+var ErrClientMismatch = errors.New("user belongs to a different client")
+
+// ErrAlreadyJoined is returned when joining a participant that is already
+// on the leaderboard
+var ErrAlreadyJoined = errors.New("participant already joined the leaderboard")
+
+// ErrLeaderboardRebuilding is returned when another instance is rebuilding
+// the cached leaderboard and it did not become available in time. Callers
+// may retry shortly or serve a degraded response.
+var ErrLeaderboardRebuilding = errors.New("leaderboard cache is being rebuilt")
+
+var (
+	// ErrAnomalousUpdate is matched by every score update held back by the
+	// anomaly detector
+	ErrAnomalousUpdate = errors.New("score update rejected as anomalous")
+	// ErrUpdateQuarantined is returned for updates quarantined by the
+	// anomaly detector instead of being applied
+	ErrUpdateQuarantined = fmt.Errorf("%w: update quarantined for review", ErrAnomalousUpdate)
+)
+
+// ErrPendingUpdateNotFound is returned when reviewing a quarantined update
+// that does not exist or was already reviewed
+var ErrPendingUpdateNotFound = errors.New("pending score update not found")
+
+// ErrRollingWindowDisabled is returned by rolling window reads on
+// leaderboards without a rolling window
+var ErrRollingWindowDisabled = errors.New("rolling window is not enabled for the leaderboard")
+
+// ErrInvalidCursor is returned for pagination cursors that cannot be decoded
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// ErrParticipantFrozen is matched when a score update targets a participant
+// whose score is frozen
+var ErrParticipantFrozen = errors.New("participant's score is frozen")
+
+// ErrLeaderboardNotVisible is returned by reads the leaderboard's visibility
+// does not allow for the caller
+var ErrLeaderboardNotVisible = errors.New("leaderboard is not visible to the caller")
+
+// ErrShadowLeaderboard is returned by reads of a leaderboard in shadow mode
+// that were not made with the shadow read flag
+var ErrShadowLeaderboard = errors.New("leaderboard is in shadow mode")
+
+// ErrSnapshotNotFound is returned when no standings snapshot was taken at
+// or before the requested time
+var ErrSnapshotNotFound = errors.New("standings snapshot not found")
+
+// ErrUnknownStat is returned for named stats the leaderboard is not
+// configured with
+var ErrUnknownStat = errors.New("unknown stat")
+
+// ErrNoPayoutTable is returned by reward projections on leaderboards
+// without a payout table
+var ErrNoPayoutTable = errors.New("leaderboard has no payout table")
+
+// ErrClientViewsDisabled is returned by per-client reads of leaderboards
+// that do not keep a view per client
+var ErrClientViewsDisabled = errors.New("per-client views are not enabled")
+
+// ErrClientRequired is returned by operations taking a userID on a helper
+// that spans every client
+var ErrClientRequired = errors.New("operation needs a client; use ForClient on global leaderboards")
+
+// ErrLeaderboardNotEnded is returned by operations that need final
+// standings before the leaderboard's end time
+var ErrLeaderboardNotEnded = errors.New("leaderboard has not ended")
+
+// ErrSkillBracketsDisabled is returned by skill bracket operations on
+// leaderboards without skill brackets
+var ErrSkillBracketsDisabled = errors.New("skill brackets are not configured")
+
+// ErrBatchCancelled is reported for members of a batch join that did not
+// join because another member's join failed
+var ErrBatchCancelled = errors.New("batch cancelled by another member")
+
+// ErrBatchTooLarge is matched when a batch holds more entries than one call
+// takes
+var ErrBatchTooLarge = errors.New("batch is too large")
+
+// ErrDuplicateBatchEntry is matched when a batch writes the same participant
+// more than once
+var ErrDuplicateBatchEntry = errors.New("participant appears more than once in the batch")
+
+// ErrInsufficientScore is returned when a score transfer would take more
+// than the sender's score
+var ErrInsufficientScore = errors.New("insufficient score for transfer")
+
+// ErrMergeConflict is returned when participants being merged keep changing
+// between reading and merging them
+var ErrMergeConflict = errors.New("participants changed during merge")
+
+// ErrAccessDenied is returned by reads the access policy refuses
+var ErrAccessDenied = errors.New("access denied by the leaderboard's access policy")
+
+// ErrUnknownFilterAttribute is returned for filters on attributes the
+// leaderboard is not configured to index
+var ErrUnknownFilterAttribute = errors.New("unknown filter attribute")
+
+// ErrReservedMetadataValue is returned for metadata values written in the
+// form encrypted values are stored in
+var ErrReservedMetadataValue = errors.New("metadata value uses the reserved encrypted value prefix")
+
+var (
+	// ErrEventOutsideWindow is matched by every rejection of a score event
+	// timestamped outside the leaderboard's active window
+	ErrEventOutsideWindow = errors.New("score event outside the leaderboard's active window")
+	// ErrLeaderboardNotStarted is returned for events before the start time
+	ErrLeaderboardNotStarted = fmt.Errorf("%w: leaderboard has not started", ErrEventOutsideWindow)
+	// ErrLeaderboardEnded is returned for events after the end time
+	ErrLeaderboardEnded = fmt.Errorf("%w: leaderboard has ended", ErrEventOutsideWindow)
+	// ErrLeaderboardFrozen is returned for events during a freeze window
+	ErrLeaderboardFrozen = fmt.Errorf("%w: leaderboard is frozen", ErrEventOutsideWindow)
+)
+
+// ScoreOutOfRangeError is returned when a score's magnitude exceeds what the
+// leaderboard can store exactly
+type ScoreOutOfRangeError struct {
+	Score float64
+	Limit float64
+}
+
+// Error implements the error interface
+func (e *ScoreOutOfRangeError) Error() string {
+	return fmt.Sprintf(
+		"invalid score: %v exceeds the allowed magnitude of %v",
+		e.Score,
+		e.Limit,
+	)
+}
+
+// Unwrap lets errors.Is match ErrInvalidScore
+func (e *ScoreOutOfRangeError) Unwrap() error {
+	return ErrInvalidScore
+}
+
+// ErrStoreTimeout is matched when a DynamoDB or Redis operation exceeds its
+// configured timeout
+var ErrStoreTimeout = errors.New("store operation timed out")
+
+// StoreTimeoutError is returned when a single store operation exceeds its
+// configured timeout. It matches ErrStoreTimeout and the underlying error.
+type StoreTimeoutError struct {
+	Store     string
+	Operation string
+	Timeout   time.Duration
+	Err       error
+}
+
+// Error implements the error interface
+func (e *StoreTimeoutError) Error() string {
+	return fmt.Sprintf(
+		"%s %s timed out after %v: %v",
+		e.Store,
+		e.Operation,
+		e.Timeout,
+		e.Err,
+	)
+}
+
+// Is lets errors.Is match ErrStoreTimeout
+func (e *StoreTimeoutError) Is(target error) bool {
+	return target == ErrStoreTimeout
+}
+
+// Unwrap returns the error reported by the store
+func (e *StoreTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// SyncCancelledError is returned when the caller's context ends while a
+// leaderboard is being loaded from DynamoDB into Redis. The partial rebuild
+// is dropped. It matches the context's error.
+type SyncCancelledError struct {
+	LeaderboardID string
+	// ItemsLoaded is how many items were read before the sync stopped
+	ItemsLoaded int64
+	Err         error
+}
+
+// Error implements the error interface
+func (e *SyncCancelledError) Error() string {
+	return fmt.Sprintf(
+		"sync of leaderboard %s cancelled after %d items: %v",
+		e.LeaderboardID,
+		e.ItemsLoaded,
+		e.Err,
+	)
+}
+
+// Unwrap returns the context's error
+func (e *SyncCancelledError) Unwrap() error {
+	return e.Err
+}
+
+// ClientMismatchError is returned when a namespaced user ID belongs to a
+// different client than the one the leaderboard helper serves
+type ClientMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+// Error implements the error interface
+func (e *ClientMismatchError) Error() string {
+	return fmt.Sprintf(
+		"user belongs to client %q, not %q",
+		e.Actual,
+		e.Expected,
+	)
+}
+
+// Is lets errors.Is match ErrClientMismatch
+func (e *ClientMismatchError) Is(target error) bool {
+	return target == ErrClientMismatch
+}
+
+// ParticipantFrozenError is returned for score updates to a participant
+// frozen with FreezeParticipant
+type ParticipantFrozenError struct {
+	Member   string
+	Reason   string
+	FrozenAt time.Time
+}
+
+// Error implements the error interface
+func (e *ParticipantFrozenError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("score of %s is frozen", e.Member)
+	}
+
+	return fmt.Sprintf("score of %s is frozen: %s", e.Member, e.Reason)
+}
+
+// Is lets errors.Is match ErrParticipantFrozen
+func (e *ParticipantFrozenError) Is(target error) bool {
+	return target == ErrParticipantFrozen
+}
+
+// InvalidIDError is returned when a clientID or userID breaks the ID rules
+// of the leaderboard helper. It matches ErrInvalidUserID.
+type InvalidIDError struct {
+	// Field names the offending ID, "clientID", "userID" or
+	// "namespacedUserID"
+	Field  string
+	ID     string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *InvalidIDError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Field, e.ID, e.Reason)
+}
+
+// Is lets errors.Is match ErrInvalidUserID
+func (e *InvalidIDError) Is(target error) bool {
+	return target == ErrInvalidUserID
+}
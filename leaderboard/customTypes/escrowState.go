@@ -0,0 +1,13 @@
+package customTypes
+
+// EscrowState is where a participant's held entry fee stands
+type EscrowState string
+
+const (
+	// EscrowHeld fees are held until the leaderboard is settled or refunded
+	EscrowHeld EscrowState = "HELD"
+	// EscrowSettled fees were handed to the provider's settlement
+	EscrowSettled EscrowState = "SETTLED"
+	// EscrowReleased fees were returned to the participant
+	EscrowReleased EscrowState = "RELEASED"
+)
@@ -0,0 +1,19 @@
+package customTypes
+
+// VariantReport compares one experiment variant with the control
+type VariantReport struct {
+	Name string
+	// Comparison sets the control's top participants against the
+	// variant's, the control as live and the variant as shadow
+	Comparison StandingsComparison
+	// Stats summarises the variant's score distribution
+	Stats *LeaderboardStats
+}
+
+// ExperimentReport compares every variant of a scoring experiment with the
+// control leaderboard
+type ExperimentReport struct {
+	// Control summarises the control's score distribution
+	Control  *LeaderboardStats
+	Variants []VariantReport
+}
@@ -0,0 +1,38 @@
+package customTypes
+
+import "time"
+
+// DependencyHealth is the outcome of checking one store
+type DependencyHealth struct {
+	Healthy bool
+	Latency time.Duration
+	// Error is why the check failed, empty if it passed
+	Error string
+}
+
+// CacheHealth describes how fresh a cached leaderboard is
+type CacheHealth struct {
+	LeaderboardID string
+	// Cached reports whether the leaderboard is loaded in Redis. Unloaded
+	// leaderboards are loaded by their next read and count as healthy.
+	Cached bool
+	// SyncedAt is when the cache was last loaded or refreshed from
+	// DynamoDB, zero if unknown
+	SyncedAt time.Time
+	// Age is how long ago SyncedAt was
+	Age     time.Duration
+	Healthy bool
+	Error   string
+}
+
+// HealthReport is the outcome of a health check, for readiness probes and
+// dashboards
+type HealthReport struct {
+	// Healthy reports whether every check passed
+	Healthy   bool
+	CheckedAt time.Time
+	Redis     DependencyHealth
+	DynamoDB  DependencyHealth
+	// Cache is only set when cache freshness was checked
+	Cache *CacheHealth
+}
@@ -0,0 +1,14 @@
+package customTypes
+
+// LeaderboardState is where a leaderboard is in its lifecycle
+type LeaderboardState string
+
+const (
+	// LeaderboardScheduled accepts joins but rejects score updates until the
+	// start time
+	LeaderboardScheduled LeaderboardState = "SCHEDULED"
+	// LeaderboardActive accepts joins and score updates
+	LeaderboardActive LeaderboardState = "ACTIVE"
+	// LeaderboardEnded is past its end time
+	LeaderboardEnded LeaderboardState = "ENDED"
+)
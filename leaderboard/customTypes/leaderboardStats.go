@@ -0,0 +1,19 @@
+package customTypes
+
+// HistogramBucket counts the participants whose score falls in [Min, Max).
+// The last bucket of a histogram also includes Max.
+type HistogramBucket struct {
+	Min   float64
+	Max   float64
+	Count int64
+}
+
+// LeaderboardStats summarises the score distribution of a leaderboard
+type LeaderboardStats struct {
+	Count     int64
+	Mean      float64
+	Median    float64
+	Min       float64
+	Max       float64
+	Histogram []HistogramBucket
+}
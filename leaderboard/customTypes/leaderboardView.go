@@ -0,0 +1,16 @@
+package customTypes
+
+// LeaderboardView is the composite read behind most leaderboard screens:
+// the top of the standings plus one participant's own entry and
+// neighborhood
+type LeaderboardView struct {
+	// Top holds the first entries of the standings
+	Top []MemberScore
+	// Entry is the participant's own standing, nil if they are not ranked
+	Entry *MemberScore
+	// Around holds the entries ranked just above and below the participant,
+	// including the participant; empty if not requested or not ranked
+	Around []MemberScore
+	// Count is the number of ranked participants
+	Count int64
+}
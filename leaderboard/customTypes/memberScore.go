@@ -0,0 +1,39 @@
+// Package customTypes holds the data types returned by the leaderboard
+// package, such as MemberScore, so consumers can name them in their own
+// signatures.
+package customTypes
+
+import (
+	"math"
+	"time"
+)
+
+type MemberScore struct {
+	Member string
+	Score  float64
+	Rank   int64
+	// PreviousRank is the rank at the last rank snapshot, zero if unknown
+	PreviousRank int64
+	// RankDelta is how many places the member climbed since the snapshot;
+	// negative values mean it dropped
+	RankDelta int64
+	// Ghost marks system-seeded entries, which must be excluded from rewards
+	Ghost bool
+	// Tier is the label of the narrowest configured tier the rank falls in,
+	// empty when none applies
+	Tier string
+	// ComputedAt is when the standing was read from the leaderboard. Results
+	// served from a local cache keep the time they were first read.
+	ComputedAt time.Time
+	// DisplayScore is the score formatted for display by a result
+	// transformer, empty when none sets it
+	DisplayScore string
+	// Milestones are the configured score thresholds the update returning
+	// this standing reached for the first time
+	Milestones []Milestone
+}
+
+// IntScore returns the score as an integer, for integer-score leaderboards
+func (m MemberScore) IntScore() int64 {
+	return int64(math.Round(m.Score))
+}
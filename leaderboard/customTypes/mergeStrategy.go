@@ -0,0 +1,15 @@
+package customTypes
+
+// MergeStrategy decides how concurrent score updates from different regions
+// are reconciled
+type MergeStrategy int
+
+const (
+	// MergeLastWriterWins keeps a single item per participant and relies on
+	// DynamoDB Global Tables last-writer-wins replication
+	MergeLastWriterWins MergeStrategy = iota
+	// MergeAdditive keeps one delta item per participant per region and sums
+	// them on read, so concurrent increments from different regions never
+	// overwrite each other
+	MergeAdditive
+)
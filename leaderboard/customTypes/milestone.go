@@ -0,0 +1,10 @@
+package customTypes
+
+import "time"
+
+// Milestone is a score threshold a participant reached and when it was
+// first reached
+type Milestone struct {
+	Threshold float64
+	ReachedAt time.Time
+}
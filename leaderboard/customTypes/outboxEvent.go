@@ -0,0 +1,17 @@
+package customTypes
+
+import "time"
+
+// OutboxEvent is a leaderboard event recorded in the outbox together with
+// the write it describes
+type OutboxEvent struct {
+	// EventID orders events by creation time and identifies them to
+	// consumers deduplicating redeliveries
+	EventID          string
+	Type             string
+	NamespacedUserID string
+	ScoreDelta       float64
+	CreatedAt        time.Time
+	// RequestID is the request ID of the write, set with WithRequestID
+	RequestID string
+}
@@ -0,0 +1,8 @@
+package customTypes
+
+// Page is one page of a cursor-paginated leaderboard read
+type Page struct {
+	Entries []MemberScore
+	// NextCursor resumes after the last entry, empty on the last page
+	NextCursor string
+}
@@ -0,0 +1,12 @@
+package customTypes
+
+import "time"
+
+// PendingUpdate is a quarantined score update awaiting review
+type PendingUpdate struct {
+	UpdateID         string
+	NamespacedUserID string
+	ScoreDelta       float64
+	Attributes       map[string]string
+	QuarantinedAt    time.Time
+}
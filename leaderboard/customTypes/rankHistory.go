@@ -0,0 +1,11 @@
+package customTypes
+
+import "time"
+
+// RankHistoryPoint is a participant's recorded rank and score at one point
+// in time
+type RankHistoryPoint struct {
+	At    time.Time
+	Rank  int64
+	Score float64
+}
@@ -0,0 +1,17 @@
+package customTypes
+
+// RoundingMode decides how scores are rounded to the display precision
+type RoundingMode int
+
+const (
+	// RoundHalfAwayFromZero rounds to the nearest value, ties away from zero
+	RoundHalfAwayFromZero RoundingMode = iota
+	// RoundHalfEven rounds to the nearest value, ties to the even neighbour
+	RoundHalfEven
+	// RoundTowardZero truncates extra decimal places
+	RoundTowardZero
+	// RoundFloor rounds toward negative infinity
+	RoundFloor
+	// RoundCeil rounds toward positive infinity
+	RoundCeil
+)
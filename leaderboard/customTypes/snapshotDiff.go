@@ -0,0 +1,28 @@
+package customTypes
+
+import "time"
+
+// Mover is a participant's standing in two snapshots. RankDelta is positive
+// for participants who climbed.
+type Mover struct {
+	Member        string
+	PreviousRank  int64
+	Rank          int64
+	RankDelta     int64
+	PreviousScore float64
+	Score         float64
+	Ghost         bool
+}
+
+// SnapshotDiff compares two standings snapshots
+type SnapshotDiff struct {
+	// From and To are when the compared snapshots were taken
+	From time.Time
+	To   time.Time
+	// Climbers moved up, biggest climb first
+	Climbers []Mover
+	// Fallers moved down, biggest fall first
+	Fallers []Mover
+	// Entered were not ranked in the earlier snapshot, ordered by rank
+	Entered []Mover
+}
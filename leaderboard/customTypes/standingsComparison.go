@@ -0,0 +1,24 @@
+package customTypes
+
+// ComparedStanding is a participant's standing on two leaderboards. A rank
+// of zero means the participant is outside the compared range of that
+// leaderboard.
+type ComparedStanding struct {
+	Member      string
+	LiveRank    int64
+	LiveScore   float64
+	ShadowRank  int64
+	ShadowScore float64
+}
+
+// StandingsComparison compares the top of a live leaderboard with a shadow
+// leaderboard fed the same updates
+type StandingsComparison struct {
+	// Standings lists every participant in the top of either leaderboard,
+	// live rank first, then those only in the shadow's top by shadow rank
+	Standings []ComparedStanding
+	// Overlap is how many participants are in the top of both
+	Overlap int
+	// Unchanged is how many participants hold the same rank on both
+	Unchanged int
+}
@@ -0,0 +1,8 @@
+package customTypes
+
+// Tier labels the members ranked within the top TopPercent percent of a
+// leaderboard, e.g. Tier{Label: "top 1%", TopPercent: 1}
+type Tier struct {
+	Label      string
+	TopPercent float64
+}
@@ -0,0 +1,15 @@
+package customTypes
+
+// Visibility decides who may read a leaderboard's standings
+type Visibility string
+
+const (
+	// VisibilityPublic lets anyone read the standings
+	VisibilityPublic Visibility = "PUBLIC"
+	// VisibilityParticipantsOnly lets only the leaderboard's participants
+	// read the standings
+	VisibilityParticipantsOnly Visibility = "PARTICIPANTS_ONLY"
+	// VisibilityHiddenUntilFinalized hides the standings until the
+	// leaderboard has ended and stopped taking late writes
+	VisibilityHiddenUntilFinalized Visibility = "HIDDEN_UNTIL_FINALIZED"
+)
@@ -0,0 +1,16 @@
+package customTypes
+
+import "time"
+
+// WriteRate summarises a leaderboard's recent score write rate, in writes
+// per second
+type WriteRate struct {
+	// Current is the rate over the last complete minute
+	Current float64
+	// Mean is the mean rate over the window
+	Mean float64
+	// Peak is the rate of the busiest minute in the window
+	Peak float64
+	// Window is the period Mean and Peak cover
+	Window time.Duration
+}
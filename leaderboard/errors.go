@@ -0,0 +1,128 @@
+package leaderboard
+
+import (
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+var (
+	// ErrInvalidScore is matched by every score validation failure
+	ErrInvalidScore = customTypes.ErrInvalidScore
+	// ErrNonFiniteScore is returned for NaN and infinite scores
+	ErrNonFiniteScore = customTypes.ErrNonFiniteScore
+	// ErrFractionalScore is returned for fractional scores on integer leaderboards
+	ErrFractionalScore = customTypes.ErrFractionalScore
+	// ErrInvalidUserID is returned for malformed or unsplittable user IDs
+	ErrInvalidUserID = customTypes.ErrInvalidUserID
+	// ErrParticipantNotFound is returned when a participant is not part of
+	// the leaderboard
+	ErrParticipantNotFound = customTypes.ErrParticipantNotFound
+	// ErrClientMismatch is matched when a user of another client is used
+	// with the helper
+	ErrClientMismatch = customTypes.ErrClientMismatch
+	// ErrAlreadyJoined is returned when joining a participant that is
+	// already on the leaderboard, unless WithIdempotentJoin is set
+	ErrAlreadyJoined = customTypes.ErrAlreadyJoined
+	// ErrLeaderboardRebuilding is returned when another instance is still
+	// rebuilding the cached leaderboard; callers may retry or degrade
+	ErrLeaderboardRebuilding = customTypes.ErrLeaderboardRebuilding
+	// ErrStoreTimeout is matched when a DynamoDB or Redis operation exceeds
+	// the timeout set with WithTimeouts
+	ErrStoreTimeout = customTypes.ErrStoreTimeout
+	// ErrAnomalousUpdate is matched by every score update the anomaly
+	// detector rejects or quarantines
+	ErrAnomalousUpdate = customTypes.ErrAnomalousUpdate
+	// ErrUpdateQuarantined is returned for updates the anomaly detector
+	// quarantined instead of applying
+	ErrUpdateQuarantined = customTypes.ErrUpdateQuarantined
+	// ErrPendingUpdateNotFound is returned by ReviewPendingUpdates for
+	// updates that do not exist or were already reviewed
+	ErrPendingUpdateNotFound = customTypes.ErrPendingUpdateNotFound
+	// ErrRollingWindowDisabled is returned by rolling window reads unless
+	// WithRollingWindow is set
+	ErrRollingWindowDisabled = customTypes.ErrRollingWindowDisabled
+	// ErrInvalidCursor is returned for pagination cursors that cannot be
+	// decoded
+	ErrInvalidCursor = customTypes.ErrInvalidCursor
+	// ErrEventOutsideWindow is matched by every rejection of a score event
+	// timestamped outside the leaderboard's active window
+	ErrEventOutsideWindow = customTypes.ErrEventOutsideWindow
+	// ErrLeaderboardNotStarted is returned for events before the start time
+	ErrLeaderboardNotStarted = customTypes.ErrLeaderboardNotStarted
+	// ErrLeaderboardEnded is returned for events after the end time
+	ErrLeaderboardEnded = customTypes.ErrLeaderboardEnded
+	// ErrLeaderboardFrozen is returned for events during a freeze window
+	ErrLeaderboardFrozen = customTypes.ErrLeaderboardFrozen
+	// ErrParticipantFrozen is matched by score updates rejected because the
+	// participant was frozen with FreezeParticipant
+	ErrParticipantFrozen = customTypes.ErrParticipantFrozen
+	// ErrLeaderboardNotVisible is returned by standings reads the
+	// visibility set with WithVisibility does not allow for the caller
+	ErrLeaderboardNotVisible = customTypes.ErrLeaderboardNotVisible
+	// ErrShadowLeaderboard is returned by standings reads of a leaderboard
+	// in shadow mode that were not made with ShadowReads
+	ErrShadowLeaderboard = customTypes.ErrShadowLeaderboard
+	// ErrSnapshotNotFound is returned by DiffSnapshots when no snapshot
+	// was taken at or before a requested time
+	ErrSnapshotNotFound = customTypes.ErrSnapshotNotFound
+	// ErrUnknownStat is returned for stats not configured with WithStats
+	ErrUnknownStat = customTypes.ErrUnknownStat
+	// ErrNoPayoutTable is returned by ProjectReward unless WithPayoutTable
+	// is set
+	ErrNoPayoutTable = customTypes.ErrNoPayoutTable
+	// ErrClientViewsDisabled is returned by per-client reads unless
+	// WithClientViews is set
+	ErrClientViewsDisabled = customTypes.ErrClientViewsDisabled
+	// ErrClientRequired is returned by APIs taking a userID on a global
+	// leaderboard helper; use ForClient
+	ErrClientRequired = customTypes.ErrClientRequired
+	// ErrLeaderboardNotEnded is returned by SettleEscrow before the end
+	// time
+	ErrLeaderboardNotEnded = customTypes.ErrLeaderboardNotEnded
+	// ErrSkillBracketsDisabled is returned by skill bracket operations
+	// unless WithSkillBrackets is set
+	ErrSkillBracketsDisabled = customTypes.ErrSkillBracketsDisabled
+	// ErrBatchCancelled is reported by JoinLeaderboardBatch for members
+	// kept out by another member's failure
+	ErrBatchCancelled = customTypes.ErrBatchCancelled
+	// ErrBatchTooLarge is matched when UpdateScores or
+	// JoinLeaderboardBatch is given more participants than it takes
+	ErrBatchTooLarge = customTypes.ErrBatchTooLarge
+	// ErrDuplicateBatchEntry is matched when a batch writes the same
+	// participant more than once
+	ErrDuplicateBatchEntry = customTypes.ErrDuplicateBatchEntry
+	// ErrInsufficientScore is returned by TransferScore when the sender's
+	// score is below the amount
+	ErrInsufficientScore = customTypes.ErrInsufficientScore
+	// ErrMergeConflict is returned by MergeParticipants when concurrent
+	// writes keep changing the participants being merged
+	ErrMergeConflict = customTypes.ErrMergeConflict
+	// ErrAccessDenied is returned by standings reads the access policy
+	// set with WithAccessPolicy refuses
+	ErrAccessDenied = customTypes.ErrAccessDenied
+	// ErrUnknownFilterAttribute is returned for filters on attributes not
+	// configured with WithFilterAttributes
+	ErrUnknownFilterAttribute = customTypes.ErrUnknownFilterAttribute
+	// ErrReservedMetadataValue is returned for metadata string values
+	// starting with the prefix encrypted values are stored with
+	ErrReservedMetadataValue = customTypes.ErrReservedMetadataValue
+)
+
+// ScoreOutOfRangeError is returned when a score's magnitude exceeds what the
+// leaderboard can store exactly. It matches ErrInvalidScore.
+type ScoreOutOfRangeError = customTypes.ScoreOutOfRangeError
+
+// StoreTimeoutError describes which store operation timed out. It matches
+// ErrStoreTimeout.
+type StoreTimeoutError = customTypes.StoreTimeoutError
+
+// SyncCancelledError reports how far a cancelled cache sync got. It matches
+// the context's error.
+type SyncCancelledError = customTypes.SyncCancelledError
+
+// ClientMismatchError names the helper's client and the user's client. It
+// matches ErrClientMismatch.
+type ClientMismatchError = customTypes.ClientMismatchError
+
+// ParticipantFrozenError names the frozen participant and why it was
+// frozen. It matches ErrParticipantFrozen.
+type ParticipantFrozenError = customTypes.ParticipantFrozenError
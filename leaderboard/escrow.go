@@ -0,0 +1,193 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// EscrowState is where a participant's held entry fee stands
+type EscrowState = customTypes.EscrowState
+
+const (
+	// EscrowHeld fees are held until the leaderboard is settled or refunded
+	EscrowHeld = customTypes.EscrowHeld
+	// EscrowSettled fees were handed to the provider's settlement
+	EscrowSettled = customTypes.EscrowSettled
+	// EscrowReleased fees were returned to the participant
+	EscrowReleased = customTypes.EscrowReleased
+)
+
+// EscrowHold describes the entry fee taken from a user joining a paid
+// leaderboard
+type EscrowHold struct {
+	LeaderboardID    string
+	ClientID         string
+	UserID           string
+	NamespacedUserID string
+	Amount           float64
+}
+
+// EscrowSettlement is one held entry fee settled once the leaderboard has
+// ended, with the participant's final standing and the payout it earned
+type EscrowSettlement struct {
+	LeaderboardID    string
+	NamespacedUserID string
+	HoldID           string
+	Standing         customTypes.MemberScore
+	// Payout is the tier of the payout table the final rank falls in, nil
+	// when it wins nothing
+	Payout *PayoutTier
+}
+
+// EscrowProvider integrates paid leaderboards with a wallet or payment
+// service. Release and Settle may see a hold again after a failure and
+// should deduplicate by its ID.
+type EscrowProvider interface {
+	// Hold takes the entry fee and returns an ID for the hold
+	Hold(ctx context.Context, hold EscrowHold) (string, error)
+	// Release returns a held fee, e.g. when the join fails or the
+	// leaderboard is cancelled
+	Release(ctx context.Context, holdID string) error
+	// Settle consumes a held fee at the end of the leaderboard and pays out
+	// the participant's winnings
+	Settle(ctx context.Context, settlement EscrowSettlement) error
+}
+
+// joinWithEscrow holds the entry fee, joins the participant with the hold
+// recorded on its item, and releases the fee again if the join fails
+func (l *IndividualLeaderboardHelper) joinWithEscrow(
+	ctx context.Context,
+	participant *models.ParticipantModel,
+) error {
+	holdID, err := l.escrow.Hold(ctx, EscrowHold{
+		LeaderboardID:    l.leaderboardID,
+		ClientID:         participant.ClientID,
+		UserID:           participant.UserID,
+		NamespacedUserID: participant.NamespacedUserID,
+		Amount:           l.entryFee,
+	})
+	if err != nil {
+		return fmt.Errorf(
+			"failed to hold entry fee: %w",
+			err,
+		)
+	}
+
+	participant.EscrowHoldID = holdID
+	participant.EscrowState = EscrowHeld
+	joinErr := l.repo.JoinLeaderboard(ctx, participant, l.endTime())
+	if joinErr == nil {
+		return nil
+	}
+
+	if err := l.escrow.Release(context.WithoutCancel(ctx), holdID); err != nil {
+		// The provider is left holding the fee; surface both
+		return errors.Join(joinErr, fmt.Errorf(
+			"failed to release entry fee %s: %w",
+			holdID,
+			err,
+		))
+	}
+	if errors.Is(joinErr, ErrAlreadyJoined) && l.idempotentJoin {
+		return nil
+	}
+
+	return joinErr
+}
+
+// SettleEscrow settles every entry fee still held once the leaderboard has
+// ended, handing each participant's final standing and payout, from the
+// payout table set with WithPayoutTable, to the provider. Ghost
+// participants win nothing. It returns how many fees were settled; fees
+// the provider fails to settle stay held for the next call. It returns
+// ErrLeaderboardNotEnded before the end time.
+func (l *IndividualLeaderboardHelper) SettleEscrow(ctx context.Context) (int, error) {
+	if l.escrow == nil {
+		return 0, nil
+	}
+	if l.State() != LeaderboardEnded {
+		return 0, ErrLeaderboardNotEnded
+	}
+
+	return l.resolveHeldEscrows(ctx, EscrowSettled, func(member, holdID string) error {
+		settlement := EscrowSettlement{
+			LeaderboardID:    l.leaderboardID,
+			NamespacedUserID: member,
+			HoldID:           holdID,
+			Standing:         customTypes.MemberScore{Member: member},
+		}
+
+		// Participants removed since joining settle without a standing
+		standing, err := l.repo.GetParticipantScoreAndRank(ctx, l.storageID, member, l.endTime())
+		if err != nil && !errors.Is(err, ErrParticipantNotFound) {
+			return err
+		}
+		if err == nil {
+			clientID, _, _ := l.namespacer.Split(member)
+			standing.Ghost = clientID == GhostClientID
+			settlement.Standing = *standing
+			if tier, ok := l.payoutTable.For(standing.Rank); ok && !standing.Ghost {
+				settlement.Payout = &tier
+			}
+		}
+
+		return l.escrow.Settle(ctx, settlement)
+	})
+}
+
+// RefundEscrow releases every entry fee still held, e.g. when a paid
+// leaderboard is cancelled, and returns how many were released
+func (l *IndividualLeaderboardHelper) RefundEscrow(ctx context.Context) (int, error) {
+	if l.escrow == nil {
+		return 0, nil
+	}
+
+	return l.resolveHeldEscrows(ctx, EscrowReleased, func(member, holdID string) error {
+		return l.escrow.Release(ctx, holdID)
+	})
+}
+
+// resolveHeldEscrows calls resolve for every held entry fee and moves the
+// fees it succeeds for to state. Failures are collected so one fee cannot
+// hold up the others.
+func (l *IndividualLeaderboardHelper) resolveHeldEscrows(
+	ctx context.Context,
+	state EscrowState,
+	resolve func(member, holdID string) error,
+) (int, error) {
+	held, err := l.repo.ListHeldEscrows(ctx, l.storageID)
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := 0
+	var errs []error
+	for _, escrow := range held {
+		if err := resolve(escrow.NamespacedUserID, escrow.HoldID); err != nil {
+			errs = append(errs, fmt.Errorf(
+				"failed to resolve entry fee %s: %w",
+				escrow.HoldID,
+				err,
+			))
+			continue
+		}
+
+		moved, err := l.repo.SetEscrowState(ctx, l.storageID, escrow.NamespacedUserID, EscrowHeld, state)
+		if err != nil {
+			// The provider deduplicates, so the next call may resolve it again
+			fmt.Printf("%sError recording escrow state: %v\n", utils.LogPrefix(ctx), err)
+			errs = append(errs, err)
+			continue
+		}
+		if moved {
+			resolved++
+		}
+	}
+
+	return resolved, errors.Join(errs...)
+}
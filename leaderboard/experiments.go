@@ -0,0 +1,153 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// ExperimentReport compares every variant of a scoring experiment with the
+// control leaderboard
+type ExperimentReport = customTypes.ExperimentReport
+
+// VariantReport compares one experiment variant with the control
+type VariantReport = customTypes.VariantReport
+
+// Variant is an alternative scoring of a leaderboard's updates. Variants
+// sum their deltas like the control does.
+type Variant struct {
+	// Name identifies the variant within its experiment
+	Name string
+	// Multiplier scales each update's delta. Zero leaves it unscaled.
+	Multiplier float64
+	// Formula, when set, computes each update's delta instead of
+	// Multiplier, e.g. to cap or weight deltas by attributes
+	Formula ShadowFormula
+}
+
+// delta returns the delta the variant takes for an update
+func (v Variant) delta(update ScoreUpdate) float64 {
+	if v.Formula != nil {
+		return v.Formula(update)
+	}
+	if v.Multiplier == 0 {
+		return update.ScoreDelta
+	}
+
+	return update.ScoreDelta * v.Multiplier
+}
+
+// Experiment runs scoring variants alongside a control leaderboard on live
+// traffic (see AttachExperiment)
+type Experiment struct {
+	control  *IndividualLeaderboardHelper
+	variants []Variant
+	helpers  map[string]*IndividualLeaderboardHelper
+}
+
+// AttachExperiment starts applying every successful score update of the
+// leaderboard, the control, to a shadow leaderboard per variant as well,
+// so game designers can evaluate scoring changes with Report before
+// switching. Variant leaderboards share the control's stores and end time
+// and are only read with ShadowReads. Attach experiments before the helper
+// serves updates; only updates made after attaching reach the variants.
+func (l *IndividualLeaderboardHelper) AttachExperiment(variants ...Variant) (*Experiment, error) {
+	experiment := &Experiment{
+		control:  l,
+		variants: variants,
+		helpers:  make(map[string]*IndividualLeaderboardHelper, len(variants)),
+	}
+
+	hooks := make([]AfterUpdateHook, 0, len(variants))
+	for _, variant := range variants {
+		if variant.Name == "" {
+			return nil, fmt.Errorf("experiment variants must be named")
+		}
+		if _, ok := experiment.helpers[variant.Name]; ok {
+			return nil, fmt.Errorf("duplicate experiment variant %q", variant.Name)
+		}
+
+		helper := l.variantLeaderboard(variant.Name)
+		experiment.helpers[variant.Name] = helper
+		hooks = append(hooks, MirrorToShadow(helper, variant.delta))
+	}
+
+	// Copy the hooks, which may be shared with other helpers of a manager
+	l.afterUpdateHooks = append(append([]AfterUpdateHook(nil), l.afterUpdateHooks...), hooks...)
+
+	return experiment, nil
+}
+
+// variantLeaderboard returns a shadow helper for an experiment variant of
+// the leaderboard. Updates reach it already screened and hooked by the
+// control, so it runs none of its own.
+func (l *IndividualLeaderboardHelper) variantLeaderboard(name string) *IndividualLeaderboardHelper {
+	variant := *l
+	variant.leaderboardID = l.leaderboardID + ":variant:" + name
+	variant.storageID = l.storageID + ":variant:" + name
+	variant.beforeUpdateHooks = nil
+	variant.afterUpdateHooks = nil
+	variant.metadataResolver = nil
+	variant.anomalyDetector = nil
+	variant.quarantine = false
+	variant.outbox = false
+	variant.changeNotifications = false
+	variant.lifetimeLeaderboard = false
+	variant.rankCache = nil
+	variant.brackets = nil
+	variant.visibility = &leaderboardVisibility{
+		visibility: l.Visibility(),
+		shadow:     true,
+	}
+
+	return &variant
+}
+
+// Variant returns the shadow leaderboard of the named variant
+func (e *Experiment) Variant(name string) (*IndividualLeaderboardHelper, bool) {
+	helper, ok := e.helpers[name]
+	return helper, ok
+}
+
+// Report compares the top n participants and the score distribution of
+// every variant with the control
+func (e *Experiment) Report(ctx context.Context, n int64) (*ExperimentReport, error) {
+	control, err := e.control.GetLeaderboardStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read control stats: %w",
+			err,
+		)
+	}
+
+	report := &ExperimentReport{Control: control}
+	for _, variant := range e.variants {
+		helper := e.helpers[variant.Name]
+
+		comparison, err := CompareStandings(ctx, e.control, helper, n)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to compare variant %q: %w",
+				variant.Name,
+				err,
+			)
+		}
+		stats, err := helper.GetLeaderboardStats(ShadowReads(ctx))
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to read stats of variant %q: %w",
+				variant.Name,
+				err,
+			)
+		}
+
+		report.Variants = append(report.Variants, VariantReport{
+			Name:       variant.Name,
+			Comparison: *comparison,
+			Stats:      stats,
+		})
+	}
+
+	return report, nil
+}
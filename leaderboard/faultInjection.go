@@ -0,0 +1,17 @@
+//go:build leaderboardfaults
+
+package leaderboard
+
+import (
+	"github.com/kgen-protocol/platform-libs/leaderboard/faults"
+)
+
+// WithFaultInjector routes the helper's DynamoDB and Redis calls through
+// injector, beneath the timeouts of WithTimeouts. It is only available in
+// builds with the leaderboardfaults tag.
+func WithFaultInjector(injector *faults.Injector) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.DynamoAPIOptions = append(o.repoConfig.DynamoAPIOptions, injector.DynamoAPIOption())
+		o.repoConfig.RedisHooks = append(o.repoConfig.RedisHooks, injector.RedisHook())
+	}
+}
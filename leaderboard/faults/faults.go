@@ -0,0 +1,224 @@
+// Package faults injects failures into the leaderboard's DynamoDB and Redis
+// calls, so tests can verify the dual-write and fallback behaviour under
+// partial failures. An Injector is wired into a helper with
+// leaderboard.WithFaultInjector, which only exists in builds with the
+// leaderboardfaults tag:
+//
+//	go test -tags leaderboardfaults ./...
+package faults
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInjected is returned by calls failed by an Injector
+var ErrInjected = errors.New("injected fault")
+
+// dynamoFault fails one upcoming DynamoDB call
+type dynamoFault struct {
+	operation string
+	remaining int
+	err       error
+}
+
+// Injector holds the faults to inject. Faults are armed with its methods
+// and consumed as calls are made. It is safe for concurrent use.
+type Injector struct {
+	mu               sync.Mutex
+	dynamoFaults     []*dynamoFault
+	dynamoCalls      int
+	redisDelay       time.Duration
+	droppedPipelines int
+}
+
+// New creates an injector with no faults armed
+func New() *Injector {
+	return &Injector{}
+}
+
+// FailDynamoCall fails the nth DynamoDB call from now with err, counting
+// only calls to operation (e.g. "TransactWriteItems") unless it is empty. A
+// nil err fails it with ErrInjected.
+func (i *Injector) FailDynamoCall(operation string, n int, err error) *Injector {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if err == nil {
+		err = ErrInjected
+	}
+	i.dynamoFaults = append(i.dynamoFaults, &dynamoFault{
+		operation: operation,
+		remaining: n,
+		err:       err,
+	})
+	return i
+}
+
+// DelayRedis delays every Redis command and pipeline by delay, or until the
+// call's context is done. Zero removes the delay.
+func (i *Injector) DelayRedis(delay time.Duration) *Injector {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.redisDelay = delay
+	return i
+}
+
+// DropPipelineExec drops the next n Redis pipelines and transactions: none
+// of their commands reach Redis and each fails with ErrInjected
+func (i *Injector) DropPipelineExec(n int) *Injector {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.droppedPipelines += n
+	return i
+}
+
+// DynamoCalls returns the number of DynamoDB calls made through the injector
+func (i *Injector) DynamoCalls() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.dynamoCalls
+}
+
+// Reset disarms every fault
+func (i *Injector) Reset() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.dynamoFaults = nil
+	i.dynamoCalls = 0
+	i.redisDelay = 0
+	i.droppedPipelines = 0
+}
+
+// dynamoError counts a DynamoDB call and returns the fault it triggers
+func (i *Injector) dynamoError(operation string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.dynamoCalls++
+	var triggered error
+	armed := i.dynamoFaults[:0]
+	for _, fault := range i.dynamoFaults {
+		if fault.operation != "" && fault.operation != operation {
+			armed = append(armed, fault)
+			continue
+		}
+		fault.remaining--
+		if fault.remaining > 0 {
+			armed = append(armed, fault)
+			continue
+		}
+		if triggered == nil {
+			triggered = fault.err
+		}
+	}
+	i.dynamoFaults = armed
+
+	return triggered
+}
+
+// DynamoAPIOption returns the DynamoDB client API option failing the armed
+// calls before they are sent
+func (i *Injector) DynamoAPIOption() func(*middleware.Stack) error {
+	faultMiddleware := middleware.InitializeMiddlewareFunc(
+		"LeaderboardFaultInjection",
+		func(
+			ctx context.Context,
+			in middleware.InitializeInput,
+			next middleware.InitializeHandler,
+		) (middleware.InitializeOutput, middleware.Metadata, error) {
+			if err := i.dynamoError(awsmiddleware.GetOperationName(ctx)); err != nil {
+				return middleware.InitializeOutput{}, middleware.Metadata{}, err
+			}
+			return next.HandleInitialize(ctx, in)
+		},
+	)
+
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(faultMiddleware, middleware.After)
+	}
+}
+
+// RedisHook returns the Redis hook delaying and dropping calls
+func (i *Injector) RedisHook() redis.Hook {
+	return redisFaultHook{injector: i}
+}
+
+// delay waits out the Redis delay, or until ctx is done
+func (i *Injector) delay(ctx context.Context) error {
+	i.mu.Lock()
+	delay := i.redisDelay
+	i.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dropPipeline reports whether the next pipeline is dropped
+func (i *Injector) dropPipeline() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.droppedPipelines <= 0 {
+		return false
+	}
+	i.droppedPipelines--
+	return true
+}
+
+// redisFaultHook applies an injector's Redis faults
+type redisFaultHook struct {
+	injector *Injector
+}
+
+// DialHook leaves dialing untouched
+func (h redisFaultHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook delays a single command
+func (h redisFaultHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.injector.delay(ctx); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+// ProcessPipelineHook delays or drops a pipeline or transaction
+func (h redisFaultHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := h.injector.delay(ctx)
+		if err == nil && h.injector.dropPipeline() {
+			err = ErrInjected
+		}
+		if err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		return next(ctx, cmds)
+	}
+}
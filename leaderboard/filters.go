@@ -0,0 +1,56 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// MetadataResolver looks up a participant's descriptive attributes, such as
+// region or platform, used to maintain filtered leaderboard views
+type MetadataResolver interface {
+	ResolveMetadata(ctx context.Context, clientID, userID string) (map[string]string, error)
+}
+
+// MetadataResolverFunc adapts a function to the MetadataResolver interface
+type MetadataResolverFunc func(ctx context.Context, clientID, userID string) (map[string]string, error)
+
+// ResolveMetadata calls f
+func (f MetadataResolverFunc) ResolveMetadata(
+	ctx context.Context,
+	clientID, userID string,
+) (map[string]string, error) {
+	return f(ctx, clientID, userID)
+}
+
+// Filter restricts a leaderboard query to participants whose attributes
+// match every entry, e.g. Filter{"region": "EU"}
+type Filter map[string]string
+
+// GetTopNFilteredParticipants retrieves the top N participants matching the
+// filter, ranked within the filtered view. Attributes must be configured
+// with WithFilterAttributes; others fail with ErrUnknownFilterAttribute.
+func (l *IndividualLeaderboardHelper) GetTopNFilteredParticipants(
+	ctx context.Context,
+	n int64,
+	filter Filter,
+) ([]customTypes.MemberScore, error) {
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	participants, err := l.repo.GetTopNFilteredParticipants(
+		ctx,
+		l.storageID,
+		n,
+		filter,
+		l.endTime(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	l.markGhosts(participants)
+	l.transformResults(ctx, participants)
+	return participants, nil
+}
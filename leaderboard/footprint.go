@@ -0,0 +1,86 @@
+package leaderboard
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// CacheFootprint is the Redis memory a leaderboard's cache uses
+type CacheFootprint = customTypes.CacheFootprint
+
+// CacheFootprintReport aggregates the cache footprints of several
+// leaderboards
+type CacheFootprintReport = customTypes.CacheFootprintReport
+
+// GetCacheFootprint returns the leaderboard's cached member count and the
+// Redis memory of its keys, with the growth estimated from how fast members
+// joined since the start time set with WithStartTime or WithScheduledStart
+func (l *IndividualLeaderboardHelper) GetCacheFootprint(ctx context.Context) (*CacheFootprint, error) {
+	footprint, err := l.repo.GetCacheFootprint(ctx, l.storageID)
+	if err != nil {
+		return nil, err
+	}
+	footprint.LeaderboardID = l.leaderboardID
+
+	// Extrapolate the join rate so far to the end of the leaderboard
+	now := utils.GetCurrTimeStamp()
+	if l.startTime.IsZero() || !now.After(l.startTime) {
+		return footprint, nil
+	}
+	footprint.MembersPerHour = float64(footprint.Members) / now.Sub(l.startTime).Hours()
+	if l.endTime().After(now) {
+		remainingHours := l.endTime().Sub(now).Hours()
+		growth := footprint.MembersPerHour * remainingHours * footprint.BytesPerMember
+		footprint.ProjectedBytes = footprint.Bytes + int64(growth)
+	}
+
+	return footprint, nil
+}
+
+// GetCacheFootprint returns the cache footprint of one of the client's
+// leaderboards
+func (m *Manager) GetCacheFootprint(
+	ctx context.Context,
+	leaderboardID string,
+) (*CacheFootprint, error) {
+	helper, err := m.Helper(ctx, leaderboardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.GetCacheFootprint(ctx)
+}
+
+// GetCacheFootprintReport measures the given leaderboards, or every
+// leaderboard the manager has a helper for when none are given, and totals
+// their footprints
+func (m *Manager) GetCacheFootprintReport(
+	ctx context.Context,
+	leaderboardIDs ...string,
+) (*CacheFootprintReport, error) {
+	if len(leaderboardIDs) == 0 {
+		m.mu.Lock()
+		for leaderboardID := range m.entries {
+			leaderboardIDs = append(leaderboardIDs, leaderboardID)
+		}
+		m.mu.Unlock()
+		sort.Strings(leaderboardIDs)
+	}
+
+	report := &CacheFootprintReport{}
+	for _, leaderboardID := range leaderboardIDs {
+		footprint, err := m.GetCacheFootprint(ctx, leaderboardID)
+		if err != nil {
+			return nil, err
+		}
+		report.Leaderboards = append(report.Leaderboards, *footprint)
+		report.Members += footprint.Members
+		report.Bytes += footprint.Bytes
+		report.ProjectedBytes += footprint.ProjectedBytes
+	}
+
+	return report, nil
+}
@@ -0,0 +1,61 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// GhostClientID is the client that seeded ghost participants are namespaced
+// under, keeping them apart from every real client's users
+const GhostClientID = "system-ghost"
+
+// SeedEntry is a ghost participant and the score it is seeded with
+type SeedEntry struct {
+	UserID string
+	Score  float64
+}
+
+// SeedParticipants inserts system-controlled ghost participants, for example
+// to make a new leaderboard feel populated or to set target scores. Ghosts
+// are stored with the ghost flag and namespaced under GhostClientID, and are
+// returned with MemberScore.Ghost set so rewards can skip them. Seeding a
+// ghost again replaces its score.
+func (l *IndividualLeaderboardHelper) SeedParticipants(
+	ctx context.Context,
+	entries []SeedEntry,
+) error {
+	participants := make([]*models.ParticipantModel, len(entries))
+	members := make([]string, len(entries))
+	for i, entry := range entries {
+		participant, err := models.NewNamespacedParticipantModel(
+			l.namespacer,
+			l.storageID,
+			GhostClientID,
+			entry.UserID,
+			entry.Score,
+		)
+		if err != nil {
+			return err
+		}
+		participant.Ghost = true
+		participants[i] = participant
+		members[i] = participant.NamespacedUserID
+	}
+
+	if err := l.repo.SeedParticipants(ctx, l.storageID, participants, l.endTime()); err != nil {
+		return err
+	}
+	l.publishChanges(ctx, members...)
+
+	return nil
+}
+
+// markGhosts sets the Ghost flag of entries seeded by SeedParticipants
+func (l *IndividualLeaderboardHelper) markGhosts(entries []customTypes.MemberScore) {
+	for i := range entries {
+		clientID, _, err := l.namespacer.Split(entries[i].Member)
+		entries[i].Ghost = err == nil && clientID == GhostClientID
+	}
+}
@@ -0,0 +1,82 @@
+package leaderboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// HealthReport is the outcome of HealthCheck
+type HealthReport = customTypes.HealthReport
+
+// DependencyHealth is the outcome of checking one store
+type DependencyHealth = customTypes.DependencyHealth
+
+// CacheHealth describes how fresh a cached leaderboard is
+type CacheHealth = customTypes.CacheHealth
+
+// HealthCheck verifies that Redis answers and the DynamoDB table is
+// reachable and active, for readiness probes and dashboards. With a
+// positive maxCacheAge it also checks that the leaderboard's cache, if
+// loaded, was synced from DynamoDB within maxCacheAge. Failures are
+// reported rather than returned; bound the check with ctx.
+func (l *IndividualLeaderboardHelper) HealthCheck(
+	ctx context.Context,
+	maxCacheAge time.Duration,
+) *HealthReport {
+	report := &HealthReport{
+		CheckedAt: utils.GetCurrTimeStamp(),
+		Redis:     checkDependency(func() error { return l.repo.PingRedis(ctx) }),
+		DynamoDB:  checkDependency(func() error { return l.repo.CheckTable(ctx) }),
+	}
+	report.Healthy = report.Redis.Healthy && report.DynamoDB.Healthy
+
+	if maxCacheAge > 0 {
+		report.Cache = l.checkCache(ctx, maxCacheAge, report.CheckedAt)
+		report.Healthy = report.Healthy && report.Cache.Healthy
+	}
+
+	return report
+}
+
+// checkDependency times a check
+func checkDependency(check func() error) DependencyHealth {
+	start := time.Now()
+	err := check()
+	health := DependencyHealth{
+		Healthy: err == nil,
+		Latency: time.Since(start),
+	}
+	if err != nil {
+		health.Error = err.Error()
+	}
+
+	return health
+}
+
+// checkCache reports how fresh the leaderboard's cache is. Caches without
+// a sync watermark, built before it was recorded, pass.
+func (l *IndividualLeaderboardHelper) checkCache(
+	ctx context.Context,
+	maxCacheAge time.Duration,
+	now time.Time,
+) *CacheHealth {
+	health := &CacheHealth{LeaderboardID: l.leaderboardID}
+
+	cached, syncedAt, err := l.repo.CacheSyncedAt(ctx, l.storageID)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	health.Cached = cached
+	health.SyncedAt = syncedAt
+	health.Healthy = true
+	if !syncedAt.IsZero() {
+		health.Age = now.Sub(syncedAt)
+		health.Healthy = health.Age <= maxCacheAge
+	}
+
+	return health
+}
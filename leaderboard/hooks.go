@@ -0,0 +1,60 @@
+package leaderboard
+
+import (
+	"context"
+	"time"
+)
+
+// ScoreUpdate describes a score update as seen by update hooks
+type ScoreUpdate struct {
+	LeaderboardID    string
+	ClientID         string
+	UserID           string
+	NamespacedUserID string
+	ScoreDelta       float64
+	// Attributes are the participant's filterable attributes, as returned by
+	// the MetadataResolver
+	Attributes map[string]string
+	// EventTime is when the scoring event happened, as given to
+	// UpdateScoreAt. It is zero for updates without an event time.
+	EventTime time.Time
+	// Verdict is the anomaly detector's decision on the update, VerdictAllow
+	// when no detector is configured
+	Verdict AnomalyVerdict
+	// RequestID is the request ID set with WithRequestID, empty if none
+	RequestID string
+}
+
+// BeforeUpdateHook runs before a score update is written. It may modify
+// the update's ScoreDelta or reject the update by returning an error.
+type BeforeUpdateHook func(ctx context.Context, update *ScoreUpdate) error
+
+// AfterUpdateHook runs after a score update has been attempted. err is the
+// outcome of the write, nil on success.
+type AfterUpdateHook func(ctx context.Context, update ScoreUpdate, err error)
+
+// runBeforeUpdateHooks runs the before hooks in order, stopping at the
+// first rejection
+func (l *IndividualLeaderboardHelper) runBeforeUpdateHooks(
+	ctx context.Context,
+	update *ScoreUpdate,
+) error {
+	for _, hook := range l.beforeUpdateHooks {
+		if err := hook(ctx, update); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runAfterUpdateHooks runs the after hooks in order
+func (l *IndividualLeaderboardHelper) runAfterUpdateHooks(
+	ctx context.Context,
+	update ScoreUpdate,
+	err error,
+) {
+	for _, hook := range l.afterUpdateHooks {
+		hook(ctx, update, err)
+	}
+}
@@ -0,0 +1,24 @@
+package leaderboard
+
+import (
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// IDRules constrain the length and characters of clientIDs and userIDs
+// (see WithIDRules)
+type IDRules = models.IDRules
+
+// InvalidIDError names the ID that broke the ID rules and why. It matches
+// ErrInvalidUserID.
+type InvalidIDError = customTypes.InvalidIDError
+
+// DefaultIDMaxLength is the longest ID, in bytes, accepted under
+// DefaultIDRules
+const DefaultIDMaxLength = models.DefaultIDMaxLength
+
+// DefaultIDRules accepts IDs of up to DefaultIDMaxLength bytes made of
+// ASCII letters, digits and "-_.:@"
+func DefaultIDRules() IDRules {
+	return models.DefaultIDRules()
+}
@@ -2,22 +2,57 @@ package leaderboard
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/kgen-protocol/platform-libs/leaderboard/internal/customTypes"
-	"github.com/kgen-protocol/platform-libs/leaderboard/internal/models"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
 	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
 	"github.com/redis/go-redis/v9"
 )
 
 // IndividualLeaderboardHelper handles the business logic for leaderboard operations
 type IndividualLeaderboardHelper struct {
-	repo               *repos.ParticipantRepo
-	clientID           string
-	leaderboardID      string
-	leaderboardEndTime time.Time
+	repo                *repos.ParticipantRepo
+	clientID            string
+	leaderboardID       string
+	storageID           string
+	end                 *leaderboardEnd
+	region              string
+	invalidationBus     InvalidationBus
+	namespacer          Namespacer
+	beforeUpdateHooks   []BeforeUpdateHook
+	afterUpdateHooks    []AfterUpdateHook
+	metadataResolver    MetadataResolver
+	anomalyDetector     AnomalyDetector
+	quarantine          bool
+	outbox              bool
+	scalingHints        bool
+	changeNotifications bool
+	scalingThresholds   ScalingThresholds
+	scalingHintFunc     ScalingHintFunc
+	startTime           time.Time
+	scheduledStart      bool
+	freezeStart         time.Time
+	freezeEnd           time.Time
+	lifetimeLeaderboard bool
+	rankCache           *rankCache
+	strictIDs           bool
+	visibility          *leaderboardVisibility
+	finalizationDelay   time.Duration
+	payoutTable         PayoutTable
+	clientViews         bool
+	escrow              EscrowProvider
+	entryFee            float64
+	idempotentJoin      bool
+	resultTransformers  []ResultTransformer
+	brackets            *skillBrackets
+	accessPolicy        AccessPolicy
+	// parentLeaderboardID is the main board of a skill bracket sub-board
+	parentLeaderboardID string
 }
 
 // NewIndividualLeaderboardHelper creates a new leaderboard service instance
@@ -27,62 +62,388 @@ func NewIndividualLeaderboardHelper(
 	clientID string,
 	leaderboardID string,
 	leaderboardEndTime time.Time,
+	opts ...Option,
 ) *IndividualLeaderboardHelper {
-	repo := repos.NewParticipantRepo(dynamoClient, redisClient)
-	return &IndividualLeaderboardHelper{
-		repo:               repo,
-		clientID:           clientID,
-		leaderboardID:      leaderboardID,
-		leaderboardEndTime: leaderboardEndTime,
+	options := applyOptions(opts)
+
+	repo := repos.NewParticipantRepo(dynamoClient, redisClient, options.repoConfig)
+	return newHelper(repo, options, clientID, leaderboardID, leaderboardEndTime)
+}
+
+// newHelper creates a helper for one leaderboard on top of a shared repo
+func newHelper(
+	repo *repos.ParticipantRepo,
+	options *helperOptions,
+	clientID string,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+) *IndividualLeaderboardHelper {
+	helper := &IndividualLeaderboardHelper{
+		repo:                repo,
+		clientID:            clientID,
+		leaderboardID:       leaderboardID,
+		storageID:           leaderboardID,
+		end:                 &leaderboardEnd{time: leaderboardEndTime},
+		region:              options.repoConfig.Region,
+		invalidationBus:     options.invalidationBus,
+		namespacer:          options.namespacer,
+		beforeUpdateHooks:   options.beforeUpdateHooks,
+		afterUpdateHooks:    options.afterUpdateHooks,
+		metadataResolver:    options.metadataResolver,
+		anomalyDetector:     options.anomalyDetector,
+		quarantine:          options.quarantine,
+		outbox:              options.outbox,
+		scalingHints:        options.scalingHints,
+		changeNotifications: options.changeNotifications,
+		scalingThresholds:   options.scalingThresholds,
+		scalingHintFunc:     options.scalingHintFunc,
+		startTime:           options.startTime,
+		scheduledStart:      options.scheduledStart,
+		freezeStart:         options.freezeStart,
+		freezeEnd:           options.freezeEnd,
+		lifetimeLeaderboard: options.lifetimeLeaderboard && clientID != "",
+		rankCache: newRankCache(
+			options.rankCacheTTL,
+			options.rankCacheMaxEntries,
+			options.staleRankWindow,
+			options.staleRankMaxInFlight,
+		),
+		strictIDs: options.idRules != nil,
+		visibility: &leaderboardVisibility{
+			visibility: options.visibility,
+			shadow:     options.shadowMode,
+		},
+		finalizationDelay:  finalizationDelay(options),
+		payoutTable:        options.payoutTable,
+		clientViews:        options.clientViews,
+		escrow:             options.escrow,
+		entryFee:           options.entryFee,
+		idempotentJoin:     options.repoConfig.IdempotentJoin,
+		resultTransformers: options.resultTransformers,
+		brackets:           newSkillBrackets(options.skillBrackets),
+		accessPolicy:       options.accessPolicy,
+	}
+
+	// Keep each client's data under its own Redis keys and partitions
+	if options.tenantIsolation && clientID != "" {
+		helper.storageID = tenantScopedID(clientID, leaderboardID)
 	}
+
+	return helper
 }
 
-// validateNamespacedUserID validates and splits the namespacedUserID
+// validateNamespacedUserID validates and splits the namespacedUserID,
+// rejecting users of other clients when the helper has a clientID
 func (l *IndividualLeaderboardHelper) validateNamespacedUserID(
 	namespacedUserID string,
 ) (string, string, error) {
-	clientID, userID := models.SplitNamespacedUserID(namespacedUserID)
-	if clientID == "" || userID == "" {
-		return "", "", fmt.Errorf("invalid namespaced user ID format")
+	clientID, userID, err := l.namespacer.Split(namespacedUserID)
+	if err != nil {
+		return "", "", err
+	}
+	if l.clientID != "" && clientID != l.clientID {
+		return "", "", &ClientMismatchError{
+			Expected: l.clientID,
+			Actual:   clientID,
+		}
+	}
+
+	// Rebuilding the member applies the ID rules and rejects members that
+	// only split by accident
+	if l.strictIDs {
+		rejoined, err := l.namespacer.Join(clientID, userID)
+		if err != nil {
+			return "", "", err
+		}
+		if rejoined != namespacedUserID {
+			return "", "", &InvalidIDError{
+				Field:  "namespacedUserID",
+				ID:     namespacedUserID,
+				Reason: "does not match the namespace scheme",
+			}
+		}
 	}
 
 	return clientID, userID, nil
 }
 
-// UpdateScore updates a participant's score in the leaderboard
+// UpdateScore updates a participant's score in the leaderboard and returns
+// the participant's new total score and rank. The rank is zero while the
+// cached leaderboard is being rebuilt.
 func (l *IndividualLeaderboardHelper) UpdateScore(
 	ctx context.Context,
 	namespacedUserID string,
 	scoreDelta float64,
-) error {
-	_, userID, err := l.validateNamespacedUserID(namespacedUserID)
+) (*customTypes.MemberScore, error) {
+	return l.updateScore(ctx, namespacedUserID, scoreDelta, time.Time{})
+}
+
+// UpdateScoreAt updates a participant's score for an event that happened at
+// eventTime, rejecting events outside the leaderboard's active window with
+// ErrLeaderboardNotStarted, ErrLeaderboardEnded or ErrLeaderboardFrozen
+func (l *IndividualLeaderboardHelper) UpdateScoreAt(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta float64,
+	eventTime time.Time,
+) (*customTypes.MemberScore, error) {
+	if err := l.checkEventTime(eventTime); err != nil {
+		return nil, err
+	}
+
+	return l.updateScore(ctx, namespacedUserID, scoreDelta, eventTime)
+}
+
+// updateScore runs a score update through the hooks and writes it
+func (l *IndividualLeaderboardHelper) updateScore(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta float64,
+	eventTime time.Time,
+) (*customTypes.MemberScore, error) {
+	// Scheduled leaderboards only take joins until they open
+	if l.stateAt(utils.GetCurrTimeStamp()) == LeaderboardScheduled {
+		return nil, ErrLeaderboardNotStarted
+	}
+
+	// Global helpers take the client from the member
+	clientID, userID, err := l.validateNamespacedUserID(namespacedUserID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	participant := models.NewParticipantModel(
+	participant, err := models.NewNamespacedParticipantModel(
+		l.namespacer,
 		l.leaderboardID,
-		l.clientID,
+		clientID,
 		userID,
 		scoreDelta,
 	)
-	return l.repo.UpdateScore(
-		ctx,
-		l.leaderboardID,
-		participant.NamespacedUserID,
-		participant.Score,
-		l.leaderboardEndTime,
-	)
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := l.newScoreUpdate(ctx, participant, eventTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Give hooks a chance to transform or reject the update
+	if err := l.runBeforeUpdateHooks(ctx, update); err != nil {
+		return nil, err
+	}
+
+	// Screen the update for cheating
+	if l.anomalyDetector != nil {
+		if err := l.screenUpdate(ctx, update); err != nil {
+			l.runAfterUpdateHooks(ctx, *update, err)
+			return nil, err
+		}
+	}
+
+	result, err := l.applyScoreUpdate(ctx, update)
+	if err == nil && l.anomalyDetector != nil {
+		l.recordUpdateHistory(ctx, update)
+	}
+	if err == nil {
+		l.recordWrites(ctx, 1)
+		l.publishChanges(ctx, update.NamespacedUserID)
+	}
+	l.runAfterUpdateHooks(ctx, *update, err)
+
+	return result, err
+}
+
+// newScoreUpdate describes a participant's score update for the hooks,
+// with the attributes its filtered views are keyed on
+func (l *IndividualLeaderboardHelper) newScoreUpdate(
+	ctx context.Context,
+	participant *models.ParticipantModel,
+	eventTime time.Time,
+) (*ScoreUpdate, error) {
+	update := &ScoreUpdate{
+		LeaderboardID:    l.leaderboardID,
+		ClientID:         participant.ClientID,
+		UserID:           participant.UserID,
+		NamespacedUserID: participant.NamespacedUserID,
+		ScoreDelta:       participant.Score,
+		EventTime:        eventTime,
+		RequestID:        utils.RequestID(ctx),
+	}
+
+	// Look up the attributes the filtered views are keyed on
+	if l.metadataResolver != nil {
+		var err error
+		update.Attributes, err = l.metadataResolver.ResolveMetadata(ctx, update.ClientID, update.UserID)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to resolve participant metadata: %w",
+				err,
+			)
+		}
+	}
+	if l.clientViews {
+		attributes := make(map[string]string, len(update.Attributes)+1)
+		for name, value := range update.Attributes {
+			attributes[name] = value
+		}
+		attributes[ClientFilterAttribute] = update.ClientID
+		update.Attributes = attributes
+	}
+
+	return update, nil
+}
+
+// applyScoreUpdate writes a score update to the stores and notifies other
+// regions
+func (l *IndividualLeaderboardHelper) applyScoreUpdate(
+	ctx context.Context,
+	update *ScoreUpdate,
+) (*customTypes.MemberScore, error) {
+	var result *customTypes.MemberScore
+	var err error
+	if l.outbox {
+		result, err = l.applyWithOutbox(ctx, update)
+	} else if l.lifetimeLeaderboard {
+		result, err = l.applyWithLifetime(ctx, update)
+	} else {
+		result, err = l.repo.UpdateScore(
+			ctx,
+			l.storageID,
+			update.NamespacedUserID,
+			update.ScoreDelta,
+			update.Attributes,
+			l.endTime(),
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return l.scoreUpdateApplied(ctx, update, result), nil
+}
+
+// scoreUpdateApplied follows up a written score update: it makes the write
+// visible to a caller reading its own writes and notifies other regions. It
+// returns the participant's standing.
+func (l *IndividualLeaderboardHelper) scoreUpdateApplied(
+	ctx context.Context,
+	update *ScoreUpdate,
+	result *customTypes.MemberScore,
+) *customTypes.MemberScore {
+	// Writes made during a rebuild bypass the cache; make them visible now
+	// when the caller needs to read them back
+	if result.Rank == 0 && readYourWrites(ctx) {
+		synced, err := l.repo.SyncParticipant(ctx, l.storageID, update.NamespacedUserID, l.endTime())
+		if err != nil {
+			// The write is durable, so only log; a retry would apply it twice
+			fmt.Printf("%sError syncing participant after rebuild: %v\n", utils.LogPrefix(ctx), err)
+		} else {
+			result = synced
+		}
+	}
+
+	// Let caches in other regions apply the same delta
+	l.publishCacheUpdate(ctx, update.NamespacedUserID, update.ScoreDelta)
+	l.recordMilestones(ctx, update.NamespacedUserID, update.ScoreDelta, result)
+	l.creditBracket(ctx, update.NamespacedUserID, update.ScoreDelta)
+
+	return result
+}
+
+// publishCacheUpdate notifies caches in other regions of an applied score
+// delta, if an invalidation bus is configured, and drops the member from the
+// in-process rank cache
+func (l *IndividualLeaderboardHelper) publishCacheUpdate(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta float64,
+) {
+	l.rankCache.invalidate(namespacedUserID)
+
+	increment, _ := l.repo.CacheIncrement(scoreDelta)
+	l.publish(ctx, CacheUpdate{
+		NamespacedUserID: namespacedUserID,
+		ScoreDelta:       increment,
+	})
+}
+
+// publishRemoval notifies caches in other regions that a participant was
+// removed from the leaderboard
+func (l *IndividualLeaderboardHelper) publishRemoval(
+	ctx context.Context,
+	namespacedUserID string,
+) {
+	l.rankCache.invalidate(namespacedUserID)
+	l.publish(ctx, CacheUpdate{
+		NamespacedUserID: namespacedUserID,
+		Removed:          true,
+	})
+}
+
+// publishInvalidation tells caches in other regions to drop the leaderboard,
+// after changes too broad to replay member by member
+func (l *IndividualLeaderboardHelper) publishInvalidation(ctx context.Context) {
+	l.rankCache.clear()
+	l.publish(ctx, CacheUpdate{Invalidate: true})
+}
+
+// publish sends update for the leaderboard on the invalidation bus, if one
+// is configured
+func (l *IndividualLeaderboardHelper) publish(
+	ctx context.Context,
+	update CacheUpdate,
+) {
+	if l.invalidationBus == nil {
+		return
+	}
+
+	update.Region = l.region
+	update.ClientID = l.clientID
+	update.LeaderboardID = l.storageID
+	update.RequestID = utils.RequestID(ctx)
+	if err := l.invalidationBus.Publish(ctx, update); err != nil {
+		// The write is durable, so only log; remote caches catch up on rebuild
+		fmt.Printf("%sError publishing cache update: %v\n", utils.LogPrefix(ctx), err)
+	}
+}
+
+// UpdateScoreInt updates a participant's score on an integer leaderboard
+// (see WithIntegerScores)
+func (l *IndividualLeaderboardHelper) UpdateScoreInt(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta int64,
+) (*customTypes.MemberScore, error) {
+	if scoreDelta > models.MaxExactScore || scoreDelta < -models.MaxExactScore {
+		return nil, &ScoreOutOfRangeError{
+			Score: float64(scoreDelta),
+			Limit: models.MaxExactScore,
+		}
+	}
+
+	return l.UpdateScore(ctx, namespacedUserID, float64(scoreDelta))
 }
 
 // GetTopNParticipants retrieves the top N participants from the leaderboard
 func (l *IndividualLeaderboardHelper) GetTopNParticipants(ctx context.Context, n int64) ([]customTypes.MemberScore, error) {
-	return l.repo.GetTopNParticipants(
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	participants, err := l.repo.GetTopNParticipants(
 		ctx,
-		l.leaderboardID,
+		l.storageID,
 		n,
-		l.leaderboardEndTime,
+		l.endTime(),
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	l.markGhosts(participants)
+	l.transformResults(ctx, participants)
+	return participants, nil
 }
 
 // GetParticipantScoreAndRank retrieves a specific participant's score and rank
@@ -91,15 +452,92 @@ func (l *IndividualLeaderboardHelper) GetParticipantScoreAndRank(
 	ctx context.Context,
 	namespacedUserID string,
 ) (*customTypes.MemberScore, error) {
-	_, _, err := l.validateNamespacedUserID(namespacedUserID)
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	clientID, _, err := l.validateNamespacedUserID(namespacedUserID)
 	if err != nil {
 		return nil, err
 	}
 
-	return l.repo.GetParticipantScoreAndRank(
+	// Cached standings are copies, transformed afresh on every read
+	if cached, ok := l.rankCache.get(namespacedUserID); ok {
+		return l.transformResult(ctx, cached), nil
+	}
+
+	// Shed load onto a recently expired standing while the stores are busy
+	if l.rankCache.overloaded() {
+		if stale, ok := l.rankCache.getStale(namespacedUserID); ok {
+			return l.transformResult(ctx, stale), nil
+		}
+	}
+
+	done := l.rankCache.begin()
+	participant, err := l.repo.GetParticipantScoreAndRank(
 		ctx,
-		l.leaderboardID,
+		l.storageID,
 		namespacedUserID,
-		l.leaderboardEndTime,
+		l.endTime(),
 	)
+	done()
+	if err != nil {
+		if errors.Is(err, ErrStoreTimeout) || errors.Is(err, context.DeadlineExceeded) {
+			if stale, ok := l.rankCache.getStale(namespacedUserID); ok {
+				return l.transformResult(ctx, stale), nil
+			}
+		}
+		return nil, err
+	}
+
+	participant.Ghost = clientID == GhostClientID
+	l.rankCache.put(namespacedUserID, participant)
+	return l.transformResult(ctx, participant), nil
+}
+
+// SweepExpiredParticipants removes participants whose TTL has passed from
+// the cached leaderboard and returns how many were removed. It is a no-op
+// unless WithParticipantTTL is set, and is meant to be run periodically.
+func (l *IndividualLeaderboardHelper) SweepExpiredParticipants(ctx context.Context) (int64, error) {
+	return l.repo.SweepExpiredParticipants(ctx, l.storageID)
+}
+
+// DeleteLeaderboard tears down the leaderboard: every participant item in
+// DynamoDB, the rank history, standings snapshots, quarantined updates,
+// undispatched outbox events and every Redis key. With dryRun set nothing is removed and the
+// report lists what would be.
+func (l *IndividualLeaderboardHelper) DeleteLeaderboard(
+	ctx context.Context,
+	dryRun bool,
+) (*customTypes.DeletionReport, error) {
+	report, err := l.repo.DeleteLeaderboard(ctx, l.storageID, dryRun)
+	if err == nil && !dryRun {
+		l.publishInvalidation(ctx)
+	}
+
+	return report, err
+}
+
+// VerifyConsistency compares sampleSize random cached participants against
+// DynamoDB and reports drift statistics. With repair set, drifted cache
+// entries are corrected from DynamoDB.
+func (l *IndividualLeaderboardHelper) VerifyConsistency(
+	ctx context.Context,
+	sampleSize int,
+	repair bool,
+) (*customTypes.ConsistencyReport, error) {
+	return l.repo.VerifyConsistency(ctx, l.storageID, sampleSize, repair)
+}
+
+// TakeRankSnapshot records the current standings as the baseline for rank
+// deltas, for callers that prefer to snapshot on their own schedule (for
+// example at the start of each day) rather than on WithRankDeltas' interval
+func (l *IndividualLeaderboardHelper) TakeRankSnapshot(ctx context.Context) error {
+	return l.repo.TakeRankSnapshot(ctx, l.storageID, l.endTime())
+}
+
+// tenantScopedID returns the storage ID of a client's leaderboard under
+// tenant isolation
+func tenantScopedID(clientID string, leaderboardID string) string {
+	return clientID + ":" + leaderboardID
 }
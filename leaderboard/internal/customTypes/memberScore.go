@@ -1,7 +0,0 @@
-package customTypes
-
-type MemberScore struct {
-	Member string
-	Score  float64
-	Rank   int64
-}
@@ -0,0 +1,35 @@
+package repos
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// withAssumedRole returns a copy of client that signs every request with
+// temporary credentials of roleARN, assumed with the client's own
+// credentials and refreshed before they expire
+func withAssumedRole(
+	client *dynamodb.Client,
+	roleARN string,
+	externalID string,
+) *dynamodb.Client {
+	base := client.Options()
+	stsClient := sts.New(sts.Options{
+		Region:      base.Region,
+		Credentials: base.Credentials,
+		HTTPClient:  base.HTTPClient,
+		Logger:      base.Logger,
+	})
+	provider := stscreds.NewAssumeRoleProvider(stsClient, roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = "platform-leaderboard"
+		if externalID != "" {
+			o.ExternalID = aws.String(externalID)
+		}
+	})
+
+	return dynamodb.New(base, func(o *dynamodb.Options) {
+		o.Credentials = aws.NewCredentialsCache(provider)
+	})
+}
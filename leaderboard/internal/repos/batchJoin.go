@@ -0,0 +1,204 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// JoinLeaderboardBatch adds participants to a leaderboard together, in one
+// DynamoDB transaction and one Redis pipeline, so either the whole batch
+// joins or nobody does. It returns one result per participant: nil if the
+// participant joined, ErrAlreadyJoined if it was already on the
+// leaderboard, or ErrBatchCancelled if another participant kept the batch
+// from joining. With IdempotentJoin, participants already joined are
+// skipped and the others still join. Participants carrying an escrow hold
+// are never skipped, so their hold can be given back.
+func (r *ParticipantRepo) JoinLeaderboardBatch(
+	ctx context.Context,
+	leaderboardID string,
+	participants []*models.ParticipantModel,
+	leaderboardEndTime time.Time,
+) ([]error, error) {
+	results := make([]error, len(participants))
+	if len(participants) == 0 {
+		return results, nil
+	}
+	if len(participants) > maxTransactItems {
+		return nil, fmt.Errorf(
+			"%w: cannot join more than %d participants in one batch",
+			customTypes.ErrBatchTooLarge,
+			maxTransactItems,
+		)
+	}
+
+	// Joins after the end are subject to the same policy as score updates
+	if _, err := r.checkWriteDeadline(ctx, leaderboardID, leaderboardEndTime, utils.GetCurrTimeStamp()); err != nil {
+		return nil, err
+	}
+
+	// Ensure Redis key exists before writing
+	cacheReady, err := r.prepareCacheForWrite(ctx, leaderboardID, leaderboardEndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build every item up front, rejecting scores that cannot be stored
+	storedScores := make([]float64, len(participants))
+	expiries := make([]time.Time, len(participants))
+	ttlEnabled := false
+	items := make([]types.TransactWriteItem, len(participants))
+	seen := make(map[string]bool, len(participants))
+	for i, participant := range participants {
+		if seen[participant.NamespacedUserID] {
+			return nil, fmt.Errorf(
+				"participant %s is joined more than once",
+				participant.NamespacedUserID,
+			)
+		}
+		seen[participant.NamespacedUserID] = true
+
+		storedScores[i], err = r.storedScore(participant.Score)
+		if err != nil {
+			return nil, err
+		}
+
+		var item map[string]types.AttributeValue
+		item, expiries[i], ttlEnabled, err = r.participantItem(participant, storedScores[i], leaderboardEndTime)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = types.TransactWriteItem{
+			Put: &types.Put{
+				TableName:           aws.String(r.tableName),
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(#pk)"),
+				ExpressionAttributeNames: map[string]string{
+					"#pk": r.config.KeySchema.PartitionKey,
+				},
+			},
+		}
+	}
+
+	// Put the items unless a participant already exists, dropping those
+	// that may be skipped and trying again with the rest
+	skipped := make([]bool, len(participants))
+	pending := make([]int, len(participants))
+	for i := range pending {
+		pending[i] = i
+	}
+	for len(pending) > 0 {
+		transactItems := make([]types.TransactWriteItem, len(pending))
+		for j, i := range pending {
+			transactItems[j] = items[i]
+		}
+
+		err := r.retryConflicts(ctx, func() error {
+			_, err := r.dynamoClient.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+				TransactItems: transactItems,
+			})
+			return err
+		})
+		if err == nil {
+			break
+		}
+
+		var cancelledErr *types.TransactionCanceledException
+		if !errors.As(err, &cancelledErr) {
+			return nil, fmt.Errorf(
+				"failed to join participants in DynamoDB: %w",
+				err,
+			)
+		}
+
+		var joined []int
+		skippable := true
+		for j, reason := range cancelledErr.CancellationReasons {
+			if j >= len(pending) {
+				break
+			}
+			i := pending[j]
+			if reason.Code != nil && *reason.Code == "ConditionalCheckFailed" {
+				results[i] = customTypes.ErrAlreadyJoined
+				if !r.config.IdempotentJoin || participants[i].EscrowHoldID != "" {
+					skippable = false
+				}
+				continue
+			}
+			joined = append(joined, i)
+		}
+		if !skippable || len(joined) == len(pending) {
+			for _, i := range joined {
+				results[i] = customTypes.ErrBatchCancelled
+			}
+			if len(joined) == len(pending) {
+				return nil, fmt.Errorf(
+					"failed to join participants in DynamoDB: %w",
+					err,
+				)
+			}
+			return results, nil
+		}
+
+		// Already joined participants are a no-op under IdempotentJoin
+		for _, i := range pending {
+			if results[i] != nil {
+				results[i] = nil
+				skipped[i] = true
+			}
+		}
+		pending = joined
+	}
+
+	// Skipped participants keep their accumulated score
+	var members []string
+	for i, participant := range participants {
+		if !skipped[i] {
+			members = append(members, participant.NamespacedUserID)
+		}
+	}
+
+	// A rebuild still in progress picks the participants up from DynamoDB
+	if !cacheReady {
+		r.syncSkippedWrites(ctx, leaderboardID, leaderboardEndTime, members...)
+		return results, nil
+	}
+
+	// Add the joined participants to the cache together, unless it was
+	// dropped since the check
+	pipe := r.redisClient.TxPipeline()
+	r.noteRebuildWrites(ctx, leaderboardID, pipe, members...)
+	joined := make([]redis.Z, 0, len(members))
+	for i, participant := range participants {
+		if skipped[i] {
+			continue
+		}
+		joined = append(joined, redis.Z{
+			Score:  storedScores[i],
+			Member: participant.NamespacedUserID,
+		})
+		if ttlEnabled {
+			r.trackParticipantExpiry(ctx, leaderboardID, participant.NamespacedUserID, expiries[i], leaderboardEndTime, pipe)
+		}
+	}
+
+	r.addIfCached(ctx, leaderboardID, joined, pipe)
+
+	// The participants are stored, so a cache failure is repaired later
+	if len(members) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			r.queueRepair(leaderboardID, err, members...)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,258 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// maxBatchWriteItems is the DynamoDB limit for a single BatchWriteItem call
+	maxBatchWriteItems = 25
+	// maxUnprocessedRetries bounds how often unprocessed items are resubmitted
+	maxUnprocessedRetries = 8
+)
+
+// BatchWriteLimits bound the rate of bulk writes such as seeding, leaderboard
+// deletion and migration, so they leave capacity for live traffic
+type BatchWriteLimits struct {
+	// InitialRate is the items per second a writer starts at
+	InitialRate float64
+	// MinRate is the items per second a writer never slows below
+	MinRate float64
+	// MaxRate is the items per second a writer never speeds above
+	MaxRate float64
+	// CapacityPerSecond caps the write capacity units consumed per second,
+	// as reported by DynamoDB. Zero leaves consumption uncapped.
+	CapacityPerSecond float64
+}
+
+// DefaultBatchWriteLimits starts at 500 items per second and adapts between
+// 25 and 5,000
+func DefaultBatchWriteLimits() BatchWriteLimits {
+	return BatchWriteLimits{
+		InitialRate: 500,
+		MinRate:     25,
+		MaxRate:     5000,
+	}
+}
+
+// withDefaults fills unset limits from DefaultBatchWriteLimits
+func (l BatchWriteLimits) withDefaults() BatchWriteLimits {
+	defaults := DefaultBatchWriteLimits()
+	if l.MaxRate <= 0 {
+		l.MaxRate = defaults.MaxRate
+	}
+	if l.MinRate <= 0 {
+		l.MinRate = defaults.MinRate
+	}
+	if l.MinRate > l.MaxRate {
+		l.MinRate = l.MaxRate
+	}
+	if l.InitialRate <= 0 {
+		l.InitialRate = defaults.InitialRate
+	}
+	if l.InitialRate > l.MaxRate {
+		l.InitialRate = l.MaxRate
+	}
+	if l.InitialRate < l.MinRate {
+		l.InitialRate = l.MinRate
+	}
+
+	return l
+}
+
+// BatchWriter submits BatchWriteItem requests at an adaptive rate: it halves
+// its rate whenever DynamoDB throttles or leaves items unprocessed, and
+// creeps back up while batches go through cleanly. With a capacity cap it
+// also paces batches by the capacity they consumed. It is safe for
+// concurrent use; concurrent writes share the rate.
+type BatchWriter struct {
+	client *dynamodb.Client
+	limits BatchWriteLimits
+
+	mu   sync.Mutex
+	rate float64
+	next time.Time
+}
+
+// NewBatchWriter creates a batch writer for the client
+func NewBatchWriter(client *dynamodb.Client, limits BatchWriteLimits) *BatchWriter {
+	limits = limits.withDefaults()
+
+	return &BatchWriter{
+		client: client,
+		limits: limits,
+		rate:   limits.InitialRate,
+	}
+}
+
+// Rate returns the writer's current rate in items per second
+func (w *BatchWriter) Rate() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.rate
+}
+
+// Write submits write requests to the table in batches of 25, resubmitting
+// unprocessed and throttled items with backoff
+func (w *BatchWriter) Write(
+	ctx context.Context,
+	tableName string,
+	requests []types.WriteRequest,
+) error {
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		pending := requests[start:end]
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt > maxUnprocessedRetries {
+				return fmt.Errorf(
+					"failed to write %d items after %d attempts",
+					len(pending),
+					attempt,
+				)
+			}
+
+			if err := w.wait(ctx, len(pending)); err != nil {
+				return err
+			}
+			output, err := w.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]types.WriteRequest{
+					tableName: pending,
+				},
+				ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+			})
+			if err != nil {
+				// Throttled batches are retried at a lower rate
+				if isThrottle(err) {
+					w.slowDown()
+					continue
+				}
+				return fmt.Errorf(
+					"failed to batch write items: %w",
+					err,
+				)
+			}
+
+			w.charge(output.ConsumedCapacity)
+			pending = output.UnprocessedItems[tableName]
+			if len(pending) > 0 {
+				w.slowDown()
+				continue
+			}
+			w.speedUp()
+		}
+	}
+
+	return nil
+}
+
+// wait blocks until n more items may be written or ctx is done
+func (w *BatchWriter) wait(ctx context.Context, n int) error {
+	// Reserve a slot for the n items and work out when it starts
+	w.mu.Lock()
+	now := time.Now()
+	if w.next.Before(now) {
+		w.next = now
+	}
+	start := w.next
+	w.next = w.next.Add(time.Duration(float64(n) / w.rate * float64(time.Second)))
+	w.mu.Unlock()
+
+	if delay := start.Sub(now); delay > 0 {
+		return sleepContext(ctx, delay)
+	}
+
+	return nil
+}
+
+// charge delays later batches so consumed capacity stays under the cap
+func (w *BatchWriter) charge(consumed []types.ConsumedCapacity) {
+	if w.limits.CapacityPerSecond <= 0 {
+		return
+	}
+
+	var units float64
+	for _, capacity := range consumed {
+		units += aws.ToFloat64(capacity.CapacityUnits)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	budget := time.Duration(units / w.limits.CapacityPerSecond * float64(time.Second))
+	if earliest := time.Now().Add(budget); w.next.Before(earliest) {
+		w.next = earliest
+	}
+}
+
+// slowDown halves the rate and pushes the next batch out by a second's
+// worth of the new rate
+func (w *BatchWriter) slowDown() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.rate /= 2
+	if w.rate < w.limits.MinRate {
+		w.rate = w.limits.MinRate
+	}
+	w.next = time.Now().Add(time.Duration(maxBatchWriteItems / w.rate * float64(time.Second)))
+}
+
+// speedUp raises the rate by a twentieth after a clean batch
+func (w *BatchWriter) speedUp() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.rate *= 1.05
+	if w.rate > w.limits.MaxRate {
+		w.rate = w.limits.MaxRate
+	}
+}
+
+// isThrottle reports whether DynamoDB rejected a request for exceeding the
+// table's or account's capacity
+func isThrottle(err error) bool {
+	var throughputErr *types.ProvisionedThroughputExceededException
+	var limitErr *types.RequestLimitExceeded
+	if errors.As(err, &throughputErr) || errors.As(err, &limitErr) {
+		return true
+	}
+
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "ThrottlingException"
+}
+
+// batchWrite submits write requests to the participant table through the
+// repo's adaptive batch writer
+func (r *ParticipantRepo) batchWrite(
+	ctx context.Context,
+	requests []types.WriteRequest,
+) error {
+	return r.batchWriter.Write(ctx, r.tableName, requests)
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
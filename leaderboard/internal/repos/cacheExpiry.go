@@ -0,0 +1,264 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultCacheRetention is how long a leaderboard's cached keys outlive its
+// end time unless configured otherwise
+const DefaultCacheRetention = 24 * time.Hour
+
+// cacheRetention returns how long cached keys outlive the leaderboard's end
+// time, or its last access under CacheExpireAfterAccess
+func (r *ParticipantRepo) cacheRetention() time.Duration {
+	if r.config.CacheRetention > 0 {
+		return r.config.CacheRetention
+	}
+
+	return DefaultCacheRetention
+}
+
+// cacheExpiry returns when a leaderboard's cached keys expire as of now,
+// and whether they expire at all. Leaderboards without an end time only
+// expire under CacheExpireAfterAccess.
+func (r *ParticipantRepo) cacheExpiry(
+	leaderboardEndTime time.Time,
+	now time.Time,
+) (time.Time, bool) {
+	if r.config.CacheExpiryPolicy == customTypes.CacheNeverExpire {
+		return time.Time{}, false
+	}
+
+	var expiresAt time.Time
+	if !leaderboardEndTime.IsZero() {
+		expiresAt = leaderboardEndTime.Add(r.cacheRetention())
+	}
+	if r.config.CacheExpiryPolicy == customTypes.CacheExpireAfterAccess {
+		if renewed := now.Add(r.cacheRetention()); renewed.After(expiresAt) {
+			expiresAt = renewed
+		}
+	}
+
+	return expiresAt, !expiresAt.IsZero()
+}
+
+// cacheRenewals remembers when each leaderboard's cache expiry was last
+// renewed, so renewals are not issued on every access
+type cacheRenewals struct {
+	mu        sync.Mutex
+	renewedAt map[string]time.Time
+}
+
+// due reports whether a leaderboard's expiry should be renewed at now,
+// marking it renewed if so
+func (c *cacheRenewals) due(leaderboardID string, now time.Time, interval time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.renewedAt == nil {
+		c.renewedAt = make(map[string]time.Time)
+	}
+	if last, ok := c.renewedAt[leaderboardID]; ok && now.Sub(last) < interval {
+		return false
+	}
+	c.renewedAt[leaderboardID] = now
+
+	return true
+}
+
+// forget clears a leaderboard's renewal mark, so the next access reconciles
+// its expiry
+func (c *cacheRenewals) forget(leaderboardID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.renewedAt, leaderboardID)
+}
+
+const (
+	// reconcileInterval is how often an instance checks a leaderboard's
+	// cache expiry under CacheExpireAfterEnd
+	reconcileInterval = time.Minute
+	// expiryDriftTolerance is how far a key's remaining lifetime may drift
+	// from the expected one before it is rewritten
+	expiryDriftTolerance = time.Minute
+)
+
+// getEndTimeKey returns the Redis key holding the end time recorded by
+// ExtendLeaderboard, in Unix milliseconds
+func (r *ParticipantRepo) getEndTimeKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":endTime"
+}
+
+// storedEndTime returns the end time recorded by ExtendLeaderboard, or zero
+// if the leaderboard was never extended
+func (r *ParticipantRepo) storedEndTime(
+	ctx context.Context,
+	leaderboardID string,
+) (time.Time, error) {
+	millis, err := r.redisClient.Get(ctx, r.getEndTimeKey(leaderboardID)).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf(
+			"failed to get stored end time: %w",
+			err,
+		)
+	}
+
+	return time.UnixMilli(millis), nil
+}
+
+// cacheKeys returns every cached key of a leaderboard whose expiry follows
+// the policy. Markers with their own lifetimes, like the rank snapshot
+// freshness key, are left out.
+func (r *ParticipantRepo) cacheKeys(
+	ctx context.Context,
+	leaderboardID string,
+) ([]string, error) {
+	filterKeys, err := r.registeredFilterKeys(ctx, leaderboardID)
+	if err != nil {
+		return nil, err
+	}
+
+	filterKeys = append(filterKeys, r.statKeys(leaderboardID)...)
+	return append([]string{
+		r.getRedisKey(leaderboardID),
+		r.getExpiriesKey(leaderboardID),
+		r.getSyncedAtKey(leaderboardID),
+		r.getFilterRegistryKey(leaderboardID),
+		r.getRankSnapshotKey(leaderboardID),
+		r.getMetadataKey(leaderboardID),
+		r.getRollingKey(leaderboardID),
+		r.getEndTimeKey(leaderboardID),
+		r.getCachedMarkerKey(leaderboardID),
+	}, filterKeys...), nil
+}
+
+// expireCacheKeys sets the expiry of every cached key of a leaderboard from
+// its end time
+func (r *ParticipantRepo) expireCacheKeys(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+) error {
+	keys, err := r.cacheKeys(ctx, leaderboardID)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.redisClient.Pipeline()
+	for _, key := range keys {
+		r.setupLeaderboardExpiry(ctx, key, leaderboardEndTime, pipe)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf(
+			"failed to set cache expiry: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// reconcileCacheExpiry brings the expiry of an already cached leaderboard in
+// line with the policy and the latest known end time. Under
+// CacheExpireAfterAccess it pushes the expiry forward at most once per tenth
+// of the retention period per instance; under CacheExpireAfterEnd it checks
+// at most once a minute and only rewrites expiries that have drifted, e.g.
+// after the leaderboard was extended.
+func (r *ParticipantRepo) reconcileCacheExpiry(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+	now time.Time,
+) error {
+	interval := reconcileInterval
+	switch r.config.CacheExpiryPolicy {
+	case customTypes.CacheNeverExpire:
+		return nil
+	case customTypes.CacheExpireAfterAccess:
+		interval = r.cacheRetention() / 10
+	}
+	if !r.renewals.due(leaderboardID, now, interval) {
+		return nil
+	}
+
+	// Prefer an end time recorded by another instance's extension
+	storedEndTime, err := r.storedEndTime(ctx, leaderboardID)
+	if err != nil {
+		return err
+	}
+	if storedEndTime.After(leaderboardEndTime) {
+		leaderboardEndTime = storedEndTime
+	}
+
+	expiresAt, expires := r.cacheExpiry(leaderboardEndTime, now)
+	if !expires || !expiresAt.After(now) {
+		return nil
+	}
+
+	// The cached marker is the one key every cached leaderboard has
+	markerKey := r.getCachedMarkerKey(leaderboardID)
+	ttl, err := r.redisClient.PTTL(ctx, markerKey).Result()
+	if err != nil {
+		return fmt.Errorf(
+			"failed to get cache expiry: %w",
+			err,
+		)
+	}
+
+	// Caches built before the marker existed gain one here
+	if ttl == -2 {
+		pipe := r.redisClient.Pipeline()
+		r.markCached(ctx, leaderboardID, leaderboardEndTime, pipe)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf(
+				"failed to mark leaderboard cached: %w",
+				err,
+			)
+		}
+	}
+
+	// Leave expiries that already match the end time alone
+	if r.config.CacheExpiryPolicy == customTypes.CacheExpireAfterEnd {
+		drift := expiresAt.Sub(now) - ttl
+		if ttl > 0 && drift <= expiryDriftTolerance && drift >= -expiryDriftTolerance {
+			return nil
+		}
+	}
+
+	return r.expireCacheKeys(ctx, leaderboardID, leaderboardEndTime)
+}
+
+// ExtendLeaderboard records a later end time for a leaderboard and moves the
+// expiry of its cached keys to match. Writes on instances still holding the
+// earlier end time are checked against the recorded one.
+func (r *ParticipantRepo) ExtendLeaderboard(
+	ctx context.Context,
+	leaderboardID string,
+	newEndTime time.Time,
+) error {
+	// Record the end time, expiring with the rest of the cache
+	endTimeKey := r.getEndTimeKey(leaderboardID)
+	if err := r.redisClient.Set(ctx, endTimeKey, newEndTime.UnixMilli(), 0).Err(); err != nil {
+		return fmt.Errorf(
+			"failed to store end time: %w",
+			err,
+		)
+	}
+
+	if err := r.expireCacheKeys(ctx, leaderboardID, newEndTime); err != nil {
+		return err
+	}
+	r.renewals.forget(leaderboardID)
+
+	return nil
+}
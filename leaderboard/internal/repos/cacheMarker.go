@@ -0,0 +1,155 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CachedMarkerKey returns the Redis key marking a leaderboard as cached.
+// An empty leaderboard has no sorted set, so the marker is what tells it
+// apart from one that was never loaded.
+func CachedMarkerKey(leaderboardID string) string {
+	return RedisKey(leaderboardID) + ":cached"
+}
+
+// getCachedMarkerKey returns the Redis key marking a leaderboard as cached
+func (r *ParticipantRepo) getCachedMarkerKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":cached"
+}
+
+// isCached reports whether a leaderboard has been loaded into Redis. Only
+// the marker counts: a sorted set without it may be the partial work of a
+// write racing a failed rebuild, and caches built before the marker existed
+// are rebuilt once.
+func (r *ParticipantRepo) isCached(
+	ctx context.Context,
+	leaderboardID string,
+) (bool, error) {
+	exists, err := r.redisClient.Exists(ctx, r.getCachedMarkerKey(leaderboardID)).Result()
+	if err != nil {
+		return false, fmt.Errorf(
+			"failed to check if Redis key exists: %w",
+			err,
+		)
+	}
+
+	return exists > 0, nil
+}
+
+// markCached sets the cached marker, expiring with the leaderboard's other
+// keys
+func (r *ParticipantRepo) markCached(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+	pipe redis.Pipeliner,
+) {
+	markerKey := r.getCachedMarkerKey(leaderboardID)
+	pipe.Set(ctx, markerKey, 1, 0)
+	r.setupLeaderboardExpiry(ctx, markerKey, leaderboardEndTime, pipe)
+}
+
+// incrementIfCachedScript increments a member only while the leaderboard's
+// cached marker exists, so a write racing a failed or cancelled rebuild
+// never starts a partial sorted set that would pass for the whole board.
+// While a forced rebuild holds the lock, the member is also noted for the
+// rebuild to sync once it swaps its keys in. It replies with the new score
+// and zero-based rank, or nil when the leaderboard is not cached. KEYS[1]
+// is the leaderboard, KEYS[2] its marker, KEYS[3] the rebuild lock and
+// KEYS[4] the members noted during a rebuild; ARGV[1] is the increment,
+// ARGV[2] the member and ARGV[3] the noted members' TTL in seconds.
+var incrementIfCachedScript = newScript(`
+if redis.call("EXISTS", KEYS[2]) == 0 then
+	return false
+end
+if redis.call("EXISTS", KEYS[3]) == 1 then
+	redis.call("SADD", KEYS[4], ARGV[2])
+	redis.call("EXPIRE", KEYS[4], ARGV[3])
+end
+local score = redis.call("ZINCRBY", KEYS[1], ARGV[1], ARGV[2])
+return {score, redis.call("ZREVRANK", KEYS[1], ARGV[2])}
+`)
+
+// incrementIfCached queues the increment of a member's cached score,
+// skipped unless the leaderboard is cached. Read its result with
+// cachedIncrement.
+func (r *ParticipantRepo) incrementIfCached(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	storedDelta float64,
+	pipe redis.Pipeliner,
+) *redis.Cmd {
+	return incrementIfCachedScript.Eval(
+		ctx,
+		pipe,
+		[]string{
+			r.getRedisKey(leaderboardID),
+			r.getCachedMarkerKey(leaderboardID),
+			r.getRebuildLockKey(leaderboardID),
+			r.getRebuildWritesKey(leaderboardID),
+		},
+		formatStoredScore(storedDelta),
+		namespacedUserID,
+		int64(shadowKeyTTL.Seconds()),
+	)
+}
+
+// addIfCachedScript adds members at their scores only while the
+// leaderboard's cached marker exists, for the same reason as
+// incrementIfCachedScript. KEYS[1] is the leaderboard and KEYS[2] its
+// marker; ARGV holds score and member pairs.
+var addIfCachedScript = newScript(`
+if redis.call("EXISTS", KEYS[2]) == 0 then
+	return false
+end
+for i = 1, #ARGV, 2 do
+	redis.call("ZADD", KEYS[1], ARGV[i], ARGV[i + 1])
+end
+return 1
+`)
+
+// addIfCached queues adding members at their stored scores, skipped unless
+// the leaderboard is cached
+func (r *ParticipantRepo) addIfCached(
+	ctx context.Context,
+	leaderboardID string,
+	members []redis.Z,
+	pipe redis.Pipeliner,
+) {
+	if len(members) == 0 {
+		return
+	}
+
+	args := make([]any, 0, 2*len(members))
+	for _, member := range members {
+		args = append(args, formatStoredScore(member.Score), member.Member)
+	}
+	addIfCachedScript.Eval(
+		ctx,
+		pipe,
+		[]string{r.getRedisKey(leaderboardID), r.getCachedMarkerKey(leaderboardID)},
+		args...,
+	)
+}
+
+// cachedIncrement returns the new stored score and zero-based rank an
+// increment queued with incrementIfCached replied with, and false when the
+// leaderboard was not cached
+func cachedIncrement(cmd *redis.Cmd) (float64, int64, bool) {
+	reply, err := cmd.Slice()
+	if err != nil || len(reply) != 2 {
+		return 0, 0, false
+	}
+	units, err := strconv.ParseFloat(fmt.Sprint(reply[0]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	rank, ok := reply[1].(int64)
+
+	return units, rank, ok
+}
@@ -0,0 +1,80 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// getChangesChannel returns the pub/sub channel announcing writes to a
+// leaderboard
+func (r *ParticipantRepo) getChangesChannel(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":changes"
+}
+
+// PublishChange announces that a leaderboard's standings may have changed,
+// naming the participants written. Announcements without participants are
+// for writes that may have changed any of them.
+func (r *ParticipantRepo) PublishChange(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserIDs ...string,
+) error {
+	var payload string
+	if len(namespacedUserIDs) > 0 {
+		encoded, err := json.Marshal(namespacedUserIDs)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to encode leaderboard change: %w",
+				err,
+			)
+		}
+		payload = string(encoded)
+	}
+
+	if err := r.redisClient.Publish(ctx, r.getChangesChannel(leaderboardID), payload).Err(); err != nil {
+		return fmt.Errorf(
+			"failed to publish leaderboard change: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// SubscribeChanges subscribes to a leaderboard's change announcements. The
+// caller must close the subscription.
+func (r *ParticipantRepo) SubscribeChanges(
+	ctx context.Context,
+	leaderboardID string,
+) (*redis.PubSub, error) {
+	pubsub := r.redisClient.Subscribe(ctx, r.getChangesChannel(leaderboardID))
+
+	// Wait for the subscription to be confirmed before returning
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf(
+			"failed to subscribe to leaderboard changes: %w",
+			err,
+		)
+	}
+
+	return pubsub, nil
+}
+
+// ChangedParticipants returns the participants named by a change
+// announcement, and false if the change may have touched any of them
+func ChangedParticipants(payload string) ([]string, bool) {
+	if payload == "" {
+		return nil, false
+	}
+
+	var namespacedUserIDs []string
+	if err := json.Unmarshal([]byte(payload), &namespacedUserIDs); err != nil {
+		return nil, false
+	}
+
+	return namespacedUserIDs, true
+}
@@ -0,0 +1,104 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// CloneParticipants copies every participant of the source leaderboard to
+// the target leaderboard with a zero score, keeping their filter attributes
+// and metadata, and returns how many were copied. Ghost participants are
+// not copied. The target must not have any participants yet.
+func (r *ParticipantRepo) CloneParticipants(
+	ctx context.Context,
+	sourceID string,
+	targetID string,
+	targetEndTime time.Time,
+) (int64, error) {
+	// Refuse to reset the scores of a leaderboard already in use
+	for _, partitionKey := range r.readPartitionKeys(targetID) {
+		input := r.syncQueryInput(partitionKey, "#sk", r.config.KeySchema.KeyNames())
+		input.Limit = aws.Int32(1)
+		output, err := r.dynamoClient.Query(ctx, input)
+		if err != nil {
+			return 0, fmt.Errorf(
+				"failed to query DynamoDB table: %w",
+				err,
+			)
+		}
+		if len(output.Items) > 0 {
+			return 0, fmt.Errorf("leaderboard %s already has participants", targetID)
+		}
+	}
+
+	var (
+		mu     sync.Mutex
+		seen   = make(map[string]bool)
+		copied int64
+	)
+	buildInput := func(partitionKey string) *dynamodb.QueryInput {
+		keyCondition, keyValues := r.config.KeySchema.PartitionQuery(partitionKey)
+		return &dynamodb.QueryInput{
+			TableName:                 aws.String(r.tableName),
+			KeyConditionExpression:    aws.String(keyCondition),
+			ExpressionAttributeValues: keyValues,
+			ExpressionAttributeNames:  r.config.KeySchema.KeyNames(),
+		}
+	}
+	handle := func(items []map[string]types.AttributeValue) error {
+		participants := make([]*models.ParticipantModel, 0, len(items))
+
+		mu.Lock()
+		for _, item := range items {
+			// Regional deltas of the same participant are copied once
+			member, ok := r.config.KeySchema.memberFromItem(item)
+			if !ok || seen[member] {
+				continue
+			}
+
+			var stored models.ParticipantModel
+			if err := attributevalue.UnmarshalMap(item, &stored); err != nil {
+				mu.Unlock()
+				return fmt.Errorf(
+					"failed to unmarshal participant: %w",
+					err,
+				)
+			}
+			if stored.Ghost {
+				continue
+			}
+			seen[member] = true
+
+			participants = append(participants, &models.ParticipantModel{
+				LeaderboardID:    targetID,
+				NamespacedUserID: member,
+				ClientID:         stored.ClientID,
+				UserID:           stored.UserID,
+				Attributes:       stored.Attributes,
+				Metadata:         stored.Metadata,
+			})
+		}
+		copied += int64(len(participants))
+		mu.Unlock()
+
+		return r.SeedParticipants(ctx, targetID, participants, targetEndTime)
+	}
+
+	if err := r.queryPartitions(ctx, r.readPartitionKeys(sourceID), buildInput, handle); err != nil {
+		return 0, fmt.Errorf(
+			"failed to clone participants of leaderboard %s: %w",
+			sourceID,
+			err,
+		)
+	}
+
+	return copied, nil
+}
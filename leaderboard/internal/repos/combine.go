@@ -0,0 +1,61 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CombineLeaderboards stores the weighted union of the source leaderboards
+// under the target's Redis key, expiring after ttl. A participant's combined
+// score is the weighted sum of their source scores. The combined leaderboard
+// lives in Redis only.
+func (r *ParticipantRepo) CombineLeaderboards(
+	ctx context.Context,
+	targetID string,
+	sourceIDs []string,
+	sourceEndTimes []time.Time,
+	weights []float64,
+	ttl time.Duration,
+) error {
+	if len(sourceIDs) == 0 {
+		return fmt.Errorf("at least one source leaderboard is required")
+	}
+	if weights != nil && len(weights) != len(sourceIDs) {
+		return fmt.Errorf(
+			"got %d weights for %d source leaderboards",
+			len(weights),
+			len(sourceIDs),
+		)
+	}
+
+	// Make sure every source is cached before combining them
+	sourceKeys := make([]string, len(sourceIDs))
+	for i, sourceID := range sourceIDs {
+		if err := r.ensureLeaderboardExists(ctx, sourceID, sourceEndTimes[i]); err != nil {
+			return err
+		}
+		sourceKeys[i] = r.getRedisKey(sourceID)
+	}
+
+	targetKey := r.getRedisKey(targetID)
+	pipe := r.redisClient.TxPipeline()
+	pipe.ZUnionStore(ctx, targetKey, &redis.ZStore{
+		Keys:      sourceKeys,
+		Weights:   weights,
+		Aggregate: "SUM",
+	})
+	if ttl > 0 {
+		pipe.Expire(ctx, targetKey, ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf(
+			"failed to combine leaderboards: %w",
+			err,
+		)
+	}
+
+	return nil
+}
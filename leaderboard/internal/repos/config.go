@@ -0,0 +1,189 @@
+package repos
+
+import (
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/redis/go-redis/v9"
+)
+
+// Config holds the settings a ParticipantRepo operates with
+type Config struct {
+	// TableName is the DynamoDB table holding participant items
+	TableName string
+	// AssumeRoleARN is an IAM role every DynamoDB request is made as,
+	// assumed with the DynamoDB client's credentials. Scoping the role's
+	// policy to a tenant's tables keeps the repo off every other table.
+	AssumeRoleARN string
+	// AssumeRoleExternalID is passed when assuming AssumeRoleARN, if the
+	// role's trust policy requires one
+	AssumeRoleExternalID string
+	// RedisKeyPrefix is prepended to every Redis key of a leaderboard,
+	// keeping tenants that share a Redis apart
+	RedisKeyPrefix string
+	// LegacyRedisKeys keys leaderboards by LegacyRedisKey rather than
+	// RedisKey, for rolling upgrades from releases that used it. The old
+	// keys share no Redis Cluster slot, so it only suits a single Redis.
+	LegacyRedisKeys bool
+	// KeySchema names the table's key attributes and how their values are
+	// built from the leaderboard and member IDs
+	KeySchema KeySchema
+	// Region is the region this instance writes from. Writes are tagged with
+	// it when set.
+	Region string
+	// Regions lists every region writing to the leaderboards. It is required
+	// for the additive merge strategy so that all regional deltas are read.
+	Regions []string
+	// MergeStrategy decides how writes from different regions are reconciled
+	MergeStrategy customTypes.MergeStrategy
+	// WriteShards spreads a leaderboard's items over this many DynamoDB
+	// partitions, keyed leaderboardID#shardN, with each participant in a
+	// fixed shard. Zero or one keeps a single partition. It must not change
+	// once a leaderboard has items.
+	WriteShards int
+	// ParticipantTTL expires participants this long after their last write.
+	// Zero keeps participants forever.
+	ParticipantTTL time.Duration
+	// RetentionPeriod expires participant and pending update items this
+	// long after the leaderboard's end time. Zero keeps them forever.
+	RetentionPeriod time.Duration
+	// TTLAttributeName is the DynamoDB TTL attribute of the table
+	TTLAttributeName string
+	// ScaledScores stores scores as integers scaled by 10^ScorePrecision so
+	// DynamoDB and Redis agree exactly
+	ScaledScores bool
+	// ScorePrecision is the number of decimal places kept with ScaledScores
+	ScorePrecision int
+	// IntegerScores only accepts whole-number scores and guards the stored
+	// totals so they stay exactly representable in Redis. It implies
+	// ScaledScores with a precision of zero.
+	IntegerScores bool
+	// UpdatedAtIndexName is a GSI keyed on the partition key and updated_at. When
+	// set, cache refreshes only read items changed since the last sync.
+	UpdatedAtIndexName string
+	// CacheExpiryPolicy decides when cached keys expire
+	CacheExpiryPolicy customTypes.CacheExpiryPolicy
+	// CacheRetention is how long cached keys outlive the leaderboard's end
+	// time, or its last access under CacheExpireAfterAccess. Zero uses
+	// DefaultCacheRetention.
+	CacheRetention time.Duration
+	// RebuildLockTTL bounds how long a cache rebuild may hold its lock
+	RebuildLockTTL time.Duration
+	// RebuildWaitTimeout is how long to wait for another instance's rebuild
+	// before failing with ErrLeaderboardRebuilding. Negative never waits.
+	RebuildWaitTimeout time.Duration
+	// SyncConcurrency is how many partitions are read and pages processed at
+	// once when loading a leaderboard. Zero uses DefaultSyncConcurrency.
+	SyncConcurrency int
+	// SyncBatchSize is how many members are added per ZADD when loading a
+	// leaderboard. Zero uses DefaultSyncBatchSize.
+	SyncBatchSize int
+	// PipelineChunkSize is how many commands loading a leaderboard queues
+	// before flushing them to Redis. Zero uses DefaultPipelineChunkSize and
+	// negative queues everything into a single pipeline.
+	PipelineChunkSize int
+	// FilterAttributes lists the member attributes that get a filtered view
+	// of the leaderboard maintained on write
+	FilterAttributes []string
+	// Stats lists the named stats, such as kills or coins, tracked per
+	// participant alongside the score, each ranked in its own leaderboard
+	Stats []string
+	// RankDeltas reports each member's rank movement since the rank snapshot
+	RankDeltas bool
+	// RankSnapshotInterval is how often reads refresh the rank snapshot. Zero
+	// leaves snapshots to explicit TakeRankSnapshot calls.
+	RankSnapshotInterval time.Duration
+	// EndTimePolicy decides what happens to writes arriving after the
+	// leaderboard's end time
+	EndTimePolicy customTypes.EndTimePolicy
+	// EndTimeGracePeriod is how long after the end time EndTimeGrace still
+	// accepts writes
+	EndTimeGracePeriod time.Duration
+	// IdempotentJoin makes joining an existing participant a no-op instead
+	// of failing with ErrAlreadyJoined
+	IdempotentJoin bool
+	// DynamoTimeout bounds each DynamoDB operation, retries included. Zero
+	// relies on the caller's context and the client's own timeouts.
+	DynamoTimeout time.Duration
+	// RedisTimeout bounds each Redis command or pipeline. Zero relies on the
+	// caller's context and the client's own timeouts.
+	RedisTimeout time.Duration
+	// DynamoAPIOptions are added to the DynamoDB client beneath the
+	// operation timeout, e.g. for fault injection in tests
+	DynamoAPIOptions []func(*middleware.Stack) error
+	// RedisHooks are added to the Redis client beneath the operation
+	// timeout, e.g. for fault injection in tests
+	RedisHooks []redis.Hook
+	// MaxScoreDelta caps the magnitude of a single score write. Zero uses the
+	// largest value that can be stored exactly.
+	MaxScoreDelta float64
+	// RoundDisplayScores rounds every score returned to DisplayPrecision
+	// decimal places with DisplayRounding. Stored scores are unaffected.
+	RoundDisplayScores bool
+	// DisplayPrecision is the number of decimal places scores are returned
+	// with
+	DisplayPrecision int
+	// DisplayRounding decides how returned scores are rounded
+	DisplayRounding customTypes.RoundingMode
+	// RollingWindow keeps a view of the scores written in the trailing
+	// window, bucketed by RollingBucket. Zero disables it.
+	RollingWindow time.Duration
+	// RollingBucket is the granularity of the rolling window
+	RollingBucket time.Duration
+	// CacheMetadata keeps participants' display metadata in a Redis hash
+	// next to the leaderboard
+	CacheMetadata bool
+	// EncryptedMetadataFields lists the display metadata attributes, such
+	// as usernames or emails, encrypted with MetadataCipher before they are
+	// written to DynamoDB or Redis
+	EncryptedMetadataFields []string
+	// MetadataCipher encrypts EncryptedMetadataFields. Nil stores them in
+	// the clear.
+	MetadataCipher FieldCipher
+	// PendingTableName is the DynamoDB table holding quarantined score
+	// updates, keyed by leaderboardID and updateID
+	PendingTableName string
+	// BatchWriteLimits bound the rate of bulk writes like seeding and
+	// deleting leaderboards. Zero fields use DefaultBatchWriteLimits.
+	BatchWriteLimits BatchWriteLimits
+	// ConflictRetries is how often a transaction cancelled by concurrent
+	// writes is retried. Zero uses DefaultConflictRetries and negative never
+	// retries.
+	ConflictRetries int
+	// ConflictBackoff is the delay before the first retry, doubling with
+	// each further one. Zero uses DefaultConflictBackoff.
+	ConflictBackoff time.Duration
+	// OutboxTableName is the DynamoDB table holding undispatched events,
+	// keyed by leaderboardID and eventID
+	OutboxTableName string
+	// RankHistoryTableName is the DynamoDB table holding participants' rank
+	// history, keyed by historyID and bucketStart
+	RankHistoryTableName string
+	// RankHistoryInterval is the least time between two recordings of rank
+	// history. Zero records on every call.
+	RankHistoryInterval time.Duration
+	// SnapshotTableName is the DynamoDB table holding standings snapshots,
+	// keyed by leaderboardID and snapshotID
+	SnapshotTableName string
+	// Tiers label query results by percent rank, sorted by TopPercent
+	// ascending
+	Tiers []customTypes.Tier
+	// SchemaMigrations upgrade participant items between schema versions,
+	// the i-th from version i to i+1. Items are migrated when read and by
+	// MigrateSchema.
+	SchemaMigrations []SchemaMigration
+	// Milestones are the score thresholds recorded, with the time, when a
+	// participant first reaches them, sorted ascending
+	Milestones []float64
+}
+
+// DefaultConfig returns the single-region configuration
+func DefaultConfig() Config {
+	return Config{
+		TableName:        DefaultTableName,
+		KeySchema:        DefaultKeySchema(),
+		MergeStrategy:    customTypes.MergeLastWriterWins,
+		TTLAttributeName: DefaultTTLAttributeName,
+	}
+}
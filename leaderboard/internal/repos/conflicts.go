@@ -0,0 +1,118 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+const (
+	// DefaultConflictRetries is how often a conflicting transaction is
+	// retried unless configured otherwise
+	DefaultConflictRetries = 3
+	// DefaultConflictBackoff is the delay before the first retry of a
+	// conflicting transaction unless configured otherwise. It doubles with
+	// each further retry.
+	DefaultConflictBackoff = 20 * time.Millisecond
+)
+
+// conflictCounters accumulates ConflictStats
+type conflictCounters struct {
+	conflicts atomic.Int64
+	retries   atomic.Int64
+	exhausted atomic.Int64
+}
+
+// conflictRetries returns how often a conflicting transaction is retried.
+// Negative disables retries.
+func (r *ParticipantRepo) conflictRetries() int {
+	if r.config.ConflictRetries < 0 {
+		return 0
+	}
+	if r.config.ConflictRetries > 0 {
+		return r.config.ConflictRetries
+	}
+
+	return DefaultConflictRetries
+}
+
+// conflictBackoff returns the delay before the first retry
+func (r *ParticipantRepo) conflictBackoff() time.Duration {
+	if r.config.ConflictBackoff > 0 {
+		return r.config.ConflictBackoff
+	}
+
+	return DefaultConflictBackoff
+}
+
+// isTransactionConflict reports whether a write was rejected only because
+// of concurrent transactional writes to its items, so retrying may succeed
+func isTransactionConflict(err error) bool {
+	var conflictErr *types.TransactionConflictException
+	if errors.As(err, &conflictErr) {
+		return true
+	}
+
+	var cancelledErr *types.TransactionCanceledException
+	if !errors.As(err, &cancelledErr) {
+		return false
+	}
+
+	conflict := false
+	for _, reason := range cancelledErr.CancellationReasons {
+		if reason.Code == nil || *reason.Code == "None" {
+			continue
+		}
+		if *reason.Code != "TransactionConflict" {
+			return false
+		}
+		conflict = true
+	}
+
+	return conflict
+}
+
+// retryConflicts calls write until it succeeds, fails with something other
+// than a transaction conflict, or runs out of retries, backing off with
+// jitter between attempts. A write rejected for a conflict applied nothing,
+// so retrying it cannot apply it twice.
+func (r *ParticipantRepo) retryConflicts(
+	ctx context.Context,
+	write func() error,
+) error {
+	retries := r.conflictRetries()
+	backoff := r.conflictBackoff()
+	for attempt := 0; ; attempt++ {
+		err := write()
+		if err == nil || !isTransactionConflict(err) {
+			return err
+		}
+
+		r.conflicts.conflicts.Add(1)
+		if attempt >= retries {
+			r.conflicts.exhausted.Add(1)
+			return err
+		}
+		r.conflicts.retries.Add(1)
+
+		// Full jitter keeps contending writers from retrying in lockstep
+		delay := time.Duration(rand.Int63n(int64(backoff<<attempt) + 1))
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// ConflictStats returns the transaction conflicts seen by the repo's writes
+func (r *ParticipantRepo) ConflictStats() customTypes.ConflictStats {
+	return customTypes.ConflictStats{
+		Conflicts: r.conflicts.conflicts.Load(),
+		Retries:   r.conflicts.retries.Load(),
+		Exhausted: r.conflicts.exhausted.Load(),
+	}
+}
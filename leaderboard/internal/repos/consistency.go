@@ -0,0 +1,230 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// maxBatchGetItems is the DynamoDB limit for a single BatchGetItem call
+	maxBatchGetItems = 100
+	// driftTolerance absorbs float rounding when comparing scores
+	driftTolerance = 1e-9
+)
+
+// repairSampledScript sets or removes a sampled member's cached score only
+// while the leaderboard is cached and the score is still the sampled one,
+// so a write landing after the sample was taken is never overwritten. It
+// replies 1 if the member was repaired. KEYS[1] is the leaderboard and
+// KEYS[2] its marker; ARGV[1] is the sampled score, ARGV[2] the DynamoDB
+// score, empty to remove the member, and ARGV[3] the member.
+var repairSampledScript = newScript(`
+if redis.call("EXISTS", KEYS[2]) == 0 then
+	return 0
+end
+local current = redis.call("ZSCORE", KEYS[1], ARGV[3])
+if not current or tonumber(current) ~= tonumber(ARGV[1]) then
+	return 0
+end
+if ARGV[2] == "" then
+	redis.call("ZREM", KEYS[1], ARGV[3])
+else
+	redis.call("ZADD", KEYS[1], ARGV[2], ARGV[3])
+end
+return 1
+`)
+
+// VerifyConsistency compares a random sample of cached participants against
+// DynamoDB and reports the drift. With repair set, drifted cache entries are
+// overwritten with the DynamoDB score and entries missing from DynamoDB are
+// removed from the cache, unless a write changed the cached score since it
+// was sampled.
+func (r *ParticipantRepo) VerifyConsistency(
+	ctx context.Context,
+	leaderboardID string,
+	sampleSize int,
+	repair bool,
+) (*customTypes.ConsistencyReport, error) {
+	redisKey := r.getRedisKey(leaderboardID)
+	report := &customTypes.ConsistencyReport{LeaderboardID: leaderboardID}
+
+	// Sample random members together with their cached scores
+	sample, err := r.redisClient.ZRandMemberWithScores(ctx, redisKey, sampleSize).Result()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to sample cached participants: %w",
+			err,
+		)
+	}
+	if len(sample) == 0 {
+		return report, nil
+	}
+
+	members := make([]string, len(sample))
+	for i, z := range sample {
+		members[i] = z.Member.(string)
+	}
+
+	stored, err := r.getStoredScores(ctx, leaderboardID, members)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare the stores and queue repairs
+	keys := []string{redisKey, r.getCachedMarkerKey(leaderboardID)}
+	pipe := r.redisClient.Pipeline()
+	var repairs []*redis.Cmd
+	var totalDrift float64
+	for _, z := range sample {
+		member := z.Member.(string)
+		report.Sampled++
+
+		dynamoScore, ok := stored[member]
+		if !ok {
+			report.MissingInDynamo++
+			if repair {
+				repairs = append(repairs, repairSampledScript.Eval(
+					ctx,
+					pipe,
+					keys,
+					formatStoredScore(z.Score),
+					"",
+					member,
+				))
+			}
+			continue
+		}
+
+		drift := math.Abs(dynamoScore - z.Score)
+		if drift <= driftTolerance*math.Max(1, math.Abs(dynamoScore)) {
+			continue
+		}
+
+		report.Mismatched++
+		totalDrift += drift
+		report.MaxAbsDrift = math.Max(report.MaxAbsDrift, r.unscaleScore(drift))
+		if repair {
+			repairs = append(repairs, repairSampledScript.Eval(
+				ctx,
+				pipe,
+				keys,
+				formatStoredScore(z.Score),
+				formatStoredScore(dynamoScore),
+				member,
+			))
+		}
+	}
+	if report.Mismatched > 0 {
+		report.MeanAbsDrift = r.unscaleScore(totalDrift / float64(report.Mismatched))
+	}
+
+	if len(repairs) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf(
+				"failed to repair cached participants: %w",
+				err,
+			)
+		}
+		for _, cmd := range repairs {
+			if repaired, _ := cmd.Int(); repaired == 1 {
+				report.Repaired++
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// getStoredScores reads the DynamoDB score of each member in stored units,
+// summing regional deltas under the additive strategy. Members without any
+// item are absent from the result.
+func (r *ParticipantRepo) getStoredScores(
+	ctx context.Context,
+	leaderboardID string,
+	members []string,
+) (map[string]float64, error) {
+	keySchema := r.config.KeySchema
+	var keys []map[string]types.AttributeValue
+	for _, member := range members {
+		for _, partitionKey := range r.memberPartitionKeys(leaderboardID, member) {
+			keys = append(keys, keySchema.ItemKey(partitionKey, member))
+		}
+	}
+
+	scores := make(map[string]float64, len(members))
+	for start := 0; start < len(keys); start += maxBatchGetItems {
+		end := start + maxBatchGetItems
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		pending := &types.KeysAndAttributes{
+			Keys:                 keys[start:end],
+			ProjectionExpression: aws.String("#sk, score"),
+			ExpressionAttributeNames: map[string]string{
+				"#sk": keySchema.SortKey,
+			},
+			ConsistentRead: aws.Bool(true),
+		}
+		backoff := 50 * time.Millisecond
+		for attempt := 0; pending != nil && len(pending.Keys) > 0; attempt++ {
+			if attempt > maxUnprocessedRetries {
+				return nil, fmt.Errorf(
+					"failed to read %d items after %d attempts",
+					len(pending.Keys),
+					attempt,
+				)
+			}
+			if attempt > 0 {
+				if err := sleepContext(ctx, backoff); err != nil {
+					return nil, err
+				}
+				backoff *= 2
+			}
+
+			output, err := r.dynamoClient.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: map[string]types.KeysAndAttributes{
+					r.tableName: *pending,
+				},
+			})
+			if err != nil {
+				return nil, fmt.Errorf(
+					"failed to read participant scores: %w",
+					err,
+				)
+			}
+
+			for _, item := range output.Responses[r.tableName] {
+				member, ok := keySchema.memberFromItem(item)
+				if !ok {
+					continue
+				}
+				var score float64
+				if err := attributevalue.Unmarshal(item["score"], &score); err != nil {
+					return nil, fmt.Errorf(
+						"failed to unmarshal participant score: %w",
+						err,
+					)
+				}
+				scores[member] += score
+			}
+
+			unprocessed, ok := output.UnprocessedKeys[r.tableName]
+			if !ok {
+				break
+			}
+			pending = &unprocessed
+		}
+	}
+
+	return scores, nil
+}
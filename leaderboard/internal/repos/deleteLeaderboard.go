@@ -0,0 +1,290 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// DeleteLeaderboard removes every participant item of a leaderboard from
+// DynamoDB (including regional delta partitions), its rank history,
+// standings snapshots, quarantined updates and undispatched outbox events,
+// and all of its Redis keys. With dryRun set nothing is deleted and the
+// report describes what would be.
+func (r *ParticipantRepo) DeleteLeaderboard(
+	ctx context.Context,
+	leaderboardID string,
+	dryRun bool,
+) (*customTypes.DeletionReport, error) {
+	report := &customTypes.DeletionReport{
+		LeaderboardID: leaderboardID,
+		DryRun:        dryRun,
+	}
+
+	// Remember the members to remove their update histories as well
+	var members []string
+	seen := make(map[string]bool)
+	for _, partitionKey := range r.readPartitionKeys(leaderboardID) {
+		keyCondition, keyValues := r.config.KeySchema.PartitionQuery(partitionKey)
+		input := &dynamodb.QueryInput{
+			TableName:                 aws.String(r.tableName),
+			KeyConditionExpression:    aws.String(keyCondition),
+			ExpressionAttributeValues: keyValues,
+			ProjectionExpression:      aws.String("#pk, #sk"),
+			ExpressionAttributeNames:  r.config.KeySchema.KeyNames(),
+		}
+
+		// Delete page by page so memory stays bounded on large boards
+		paginator := dynamodb.NewQueryPaginator(r.dynamoClient, input)
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"failed to query DynamoDB table: %w",
+					err,
+				)
+			}
+
+			report.ParticipantItems += int64(len(page.Items))
+			for _, item := range page.Items {
+				if member, ok := r.config.KeySchema.memberFromItem(item); ok && !seen[member] {
+					seen[member] = true
+					members = append(members, member)
+				}
+			}
+			if dryRun || len(page.Items) == 0 {
+				continue
+			}
+
+			requests := make([]types.WriteRequest, len(page.Items))
+			for i, key := range page.Items {
+				requests[i] = types.WriteRequest{
+					DeleteRequest: &types.DeleteRequest{Key: key},
+				}
+			}
+			if err := r.batchWrite(ctx, requests); err != nil {
+				return nil, fmt.Errorf(
+					"failed to delete participant items: %w",
+					err,
+				)
+			}
+		}
+	}
+
+	rankHistoryItems, err := r.deleteRankHistory(ctx, leaderboardID, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	report.RankHistoryItems = rankHistoryItems
+
+	tables := []struct {
+		name    string
+		sortKey string
+		items   *int64
+	}{
+		{r.snapshotTableName(), "snapshotID", &report.SnapshotItems},
+		{r.pendingTableName(), "updateID", &report.PendingItems},
+		{r.outboxTableName(), "eventID", &report.OutboxItems},
+	}
+	for _, table := range tables {
+		items, err := r.deleteLeaderboardItems(ctx, table.name, table.sortKey, leaderboardID, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		*table.items = items
+	}
+
+	// Report and remove every Redis key belonging to the leaderboard,
+	// including the filtered views, the write rate counters and the update
+	// histories of its members. Histories of members who left expire on
+	// their own.
+	filterKeys, err := r.registeredFilterKeys(ctx, leaderboardID)
+	if err != nil {
+		return nil, err
+	}
+	redisKeys := append(r.leaderboardRedisKeys(leaderboardID), filterKeys...)
+	redisKeys = append(redisKeys, r.writeRateKeys(leaderboardID, utils.GetCurrTimeStamp())...)
+	for _, member := range members {
+		redisKeys = append(redisKeys, r.getUpdateHistoryKey(leaderboardID, member))
+	}
+	existing, err := r.existingKeys(ctx, redisKeys)
+	if err != nil {
+		return nil, err
+	}
+	report.RedisKeys = existing
+	if dryRun {
+		return report, nil
+	}
+	if err := r.deleteKeys(ctx, existing); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// deleteLeaderboardItems deletes the items a leaderboard keeps in a table
+// keyed by leaderboardID and sortKey, and returns how many there were. A
+// missing table, for a feature the deployment does not use, holds none.
+// With dryRun set the items are only counted.
+func (r *ParticipantRepo) deleteLeaderboardItems(
+	ctx context.Context,
+	tableName string,
+	sortKey string,
+	leaderboardID string,
+	dryRun bool,
+) (int64, error) {
+	paginator := dynamodb.NewQueryPaginator(r.dynamoClient, &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		KeyConditionExpression: aws.String("leaderboardID = :leaderboardID"),
+		ProjectionExpression:   aws.String("leaderboardID, #sk"),
+		ExpressionAttributeNames: map[string]string{
+			"#sk": sortKey,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":leaderboardID": &types.AttributeValueMemberS{Value: leaderboardID},
+		},
+	})
+
+	var items int64
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if isMissingTable(err) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf(
+				"failed to query %s: %w",
+				tableName,
+				err,
+			)
+		}
+
+		items += int64(len(page.Items))
+		if dryRun || len(page.Items) == 0 {
+			continue
+		}
+		if err := r.deleteItemKeys(ctx, tableName, page.Items); err != nil {
+			return 0, err
+		}
+	}
+
+	return items, nil
+}
+
+// deleteItemKeys deletes the items with the given keys from a table
+func (r *ParticipantRepo) deleteItemKeys(
+	ctx context.Context,
+	tableName string,
+	keys []map[string]types.AttributeValue,
+) error {
+	requests := make([]types.WriteRequest, len(keys))
+	for i, key := range keys {
+		requests[i] = types.WriteRequest{
+			DeleteRequest: &types.DeleteRequest{Key: key},
+		}
+	}
+	if err := r.batchWriter.Write(ctx, tableName, requests); err != nil {
+		return fmt.Errorf(
+			"failed to delete items from %s: %w",
+			tableName,
+			err,
+		)
+	}
+
+	return nil
+}
+
+// existingKeys returns the Redis keys that exist, checking a pipeline chunk
+// of them per round trip
+func (r *ParticipantRepo) existingKeys(ctx context.Context, keys []string) ([]string, error) {
+	var existing []string
+	for start := 0; start < len(keys); {
+		pipe := r.redisClient.Pipeline()
+		checks := make([]*redis.IntCmd, 0)
+		for _, key := range keys[start:] {
+			checks = append(checks, pipe.Exists(ctx, key))
+			if r.pipelineChunkFull(pipe) {
+				break
+			}
+		}
+		if err := execPipeline(ctx, pipe); err != nil {
+			return nil, fmt.Errorf(
+				"failed to check if Redis keys exist: %w",
+				err,
+			)
+		}
+
+		for i, check := range checks {
+			if check.Val() > 0 {
+				existing = append(existing, keys[start+i])
+			}
+		}
+		start += len(checks)
+	}
+
+	return existing, nil
+}
+
+// deleteKeys deletes Redis keys a pipeline chunk at a time
+func (r *ParticipantRepo) deleteKeys(ctx context.Context, keys []string) error {
+	pipe := r.redisClient.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, key)
+		if !r.pipelineChunkFull(pipe) {
+			continue
+		}
+		if err := execPipeline(ctx, pipe); err != nil {
+			return fmt.Errorf(
+				"failed to delete Redis keys: %w",
+				err,
+			)
+		}
+	}
+	if pipe.Len() == 0 {
+		return nil
+	}
+	if err := execPipeline(ctx, pipe); err != nil {
+		return fmt.Errorf(
+			"failed to delete Redis keys: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// isMissingTable reports whether a DynamoDB request failed because its
+// table does not exist, as for features a deployment does not use
+func isMissingTable(err error) bool {
+	var notFound *types.ResourceNotFoundException
+	return errors.As(err, &notFound)
+}
+
+// leaderboardRedisKeys returns every Redis key the repo maintains for a
+// leaderboard
+func (r *ParticipantRepo) leaderboardRedisKeys(leaderboardID string) []string {
+	keys := []string{
+		r.getRedisKey(leaderboardID),
+		r.getExpiriesKey(leaderboardID),
+		r.getSyncedAtKey(leaderboardID),
+		r.getFilterRegistryKey(leaderboardID),
+		r.getRankSnapshotKey(leaderboardID),
+		r.getRankSnapshotFreshKey(leaderboardID),
+		r.getRankHistoryFreshKey(leaderboardID),
+		r.getSnapshotFreshKey(leaderboardID),
+		r.getMetadataKey(leaderboardID),
+		r.getEndTimeKey(leaderboardID),
+		r.getCachedMarkerKey(leaderboardID),
+		r.getRebuildWritesKey(leaderboardID),
+	}
+
+	keys = append(keys, r.statKeys(leaderboardID)...)
+	return append(keys, r.rollingKeys(leaderboardID)...)
+}
@@ -0,0 +1,50 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// lateScoreAttribute accumulates the part of a participant's score written
+// after the leaderboard ended
+const lateScoreAttribute = "lateScoreDelta"
+
+// checkWriteDeadline applies the end time policy to a write arriving at now.
+// It reports whether an accepted write is late. Leaderboards without an end
+// time accept every write. A write past the caller's end time is checked
+// against the end time recorded by ExtendLeaderboard, if later.
+func (r *ParticipantRepo) checkWriteDeadline(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+	now time.Time,
+) (bool, error) {
+	if leaderboardEndTime.IsZero() || !now.After(leaderboardEndTime) {
+		return false, nil
+	}
+
+	// The leaderboard may have been extended by another instance
+	storedEndTime, err := r.storedEndTime(ctx, leaderboardID)
+	if err != nil {
+		return false, err
+	}
+	if storedEndTime.After(leaderboardEndTime) {
+		leaderboardEndTime = storedEndTime
+		if !now.After(leaderboardEndTime) {
+			return false, nil
+		}
+	}
+
+	switch r.config.EndTimePolicy {
+	case customTypes.EndTimeFlag:
+		return true, nil
+	case customTypes.EndTimeGrace:
+		if !now.After(leaderboardEndTime.Add(r.config.EndTimeGracePeriod)) {
+			return true, nil
+		}
+	}
+
+	return false, customTypes.ErrLeaderboardEnded
+}
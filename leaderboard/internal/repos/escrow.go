@@ -0,0 +1,125 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+const (
+	// escrowHoldIDAttribute holds the escrow provider's ID for the
+	// participant's entry fee, set on join
+	escrowHoldIDAttribute = "escrowHoldID"
+	// escrowStateAttribute holds the customTypes.EscrowState of the fee
+	escrowStateAttribute = "escrowState"
+)
+
+// HeldEscrow is a participant whose entry fee is still held
+type HeldEscrow struct {
+	NamespacedUserID string
+	HoldID           string
+}
+
+// ListHeldEscrows returns the participants of a leaderboard whose entry
+// fee is still held
+func (r *ParticipantRepo) ListHeldEscrows(
+	ctx context.Context,
+	leaderboardID string,
+) ([]HeldEscrow, error) {
+	var (
+		mu   sync.Mutex
+		held []HeldEscrow
+	)
+	err := r.queryPartitions(
+		ctx,
+		r.shardPartitionKeys(leaderboardID),
+		func(partitionKey string) *dynamodb.QueryInput {
+			keyCondition, keyValues := r.config.KeySchema.PartitionQuery(partitionKey)
+			keyValues[":held"] = &types.AttributeValueMemberS{Value: string(customTypes.EscrowHeld)}
+			keyNames := r.config.KeySchema.conditionNames(keyCondition)
+			keyNames["#sk"] = r.config.KeySchema.SortKey
+			keyNames["#state"] = escrowStateAttribute
+			keyNames["#holdID"] = escrowHoldIDAttribute
+
+			return &dynamodb.QueryInput{
+				TableName:                 aws.String(r.tableName),
+				KeyConditionExpression:    aws.String(keyCondition),
+				FilterExpression:          aws.String("#state = :held"),
+				ProjectionExpression:      aws.String("#sk, #holdID"),
+				ExpressionAttributeNames:  keyNames,
+				ExpressionAttributeValues: keyValues,
+			}
+		},
+		func(items []map[string]types.AttributeValue) error {
+			for _, item := range items {
+				member, ok := r.config.KeySchema.memberFromItem(item)
+				if !ok {
+					continue
+				}
+				holdID, ok := item[escrowHoldIDAttribute].(*types.AttributeValueMemberS)
+				if !ok {
+					continue
+				}
+
+				mu.Lock()
+				held = append(held, HeldEscrow{
+					NamespacedUserID: member,
+					HoldID:           holdID.Value,
+				})
+				mu.Unlock()
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to list held entry fees: %w",
+			err,
+		)
+	}
+
+	return held, nil
+}
+
+// SetEscrowState moves a participant's entry fee from one escrow state to
+// another. It returns false without writing if the fee is no longer in the
+// from state.
+func (r *ParticipantRepo) SetEscrowState(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	from customTypes.EscrowState,
+	to customTypes.EscrowState,
+) (bool, error) {
+	_, err := r.dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 r.config.KeySchema.ItemKey(r.shardPartitionKey(leaderboardID, namespacedUserID), namespacedUserID),
+		UpdateExpression:    aws.String("SET #state = :to"),
+		ConditionExpression: aws.String("#state = :from"),
+		ExpressionAttributeNames: map[string]string{
+			"#state": escrowStateAttribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":from": &types.AttributeValueMemberS{Value: string(from)},
+			":to":   &types.AttributeValueMemberS{Value: string(to)},
+		},
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return false, nil
+		}
+		return false, fmt.Errorf(
+			"failed to update escrow state in DynamoDB: %w",
+			err,
+		)
+	}
+
+	return true, nil
+}
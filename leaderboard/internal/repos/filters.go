@@ -0,0 +1,423 @@
+package repos
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// filterAttributesName is the DynamoDB map attribute holding a participant's
+// filterable attributes, kept so cache rebuilds can restore filtered views
+const filterAttributesName = "filterAttrs"
+
+// indexMemberScript copies a member's current score from the leaderboard
+// into the filtered view for its attribute value, moving it out of the view
+// for its previous value if the attribute changed.
+// KEYS[1] is the leaderboard, KEYS[2] the attribute's member->value hash and
+// KEYS[3] the view key prefix, which shares the leaderboard's hash tag.
+// ARGV[1] is the member and ARGV[2] the value.
+var indexMemberScript = newScript(`
+local score = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if not score then
+	return 0
+end
+local previous = redis.call("HGET", KEYS[2], ARGV[1])
+if previous and previous ~= ARGV[2] then
+	redis.call("ZREM", KEYS[3] .. previous, ARGV[1])
+end
+redis.call("HSET", KEYS[2], ARGV[1], ARGV[2])
+redis.call("ZADD", KEYS[3] .. ARGV[2], score, ARGV[1])
+return 1
+`)
+
+// unindexMemberScript removes a member from the filtered view of its current
+// attribute value. KEYS[1] is the attribute's member->value hash and KEYS[2]
+// the view key prefix; ARGV[1] is the member.
+var unindexMemberScript = newScript(`
+local value = redis.call("HGET", KEYS[1], ARGV[1])
+if value then
+	redis.call("ZREM", KEYS[2] .. value, ARGV[1])
+	redis.call("HDEL", KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// unindexMember queues the removal of a member from every filtered view and
+// stat leaderboard
+func (r *ParticipantRepo) unindexMember(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	pipe redis.Pipeliner,
+) {
+	for _, attribute := range r.config.FilterAttributes {
+		unindexMemberScript.Eval(
+			ctx,
+			pipe,
+			[]string{
+				r.getFilterValuesKey(leaderboardID, attribute),
+				r.getFilterKeyPrefix(leaderboardID, attribute),
+			},
+			namespacedUserID,
+		)
+	}
+	r.removeMemberStats(ctx, leaderboardID, namespacedUserID, pipe)
+}
+
+// getFilterKeyPrefix returns the prefix of the filtered views of an attribute
+func (r *ParticipantRepo) getFilterKeyPrefix(leaderboardID, attribute string) string {
+	return r.getRedisKey(leaderboardID) + ":filter:" + attribute + ":"
+}
+
+// getFilterKey returns the filtered view of an attribute value
+func (r *ParticipantRepo) getFilterKey(leaderboardID, attribute, value string) string {
+	return r.getFilterKeyPrefix(leaderboardID, attribute) + value
+}
+
+// getFilterValuesKey returns the hash mapping members to their current value
+// of an attribute
+func (r *ParticipantRepo) getFilterValuesKey(leaderboardID, attribute string) string {
+	return r.getRedisKey(leaderboardID) + ":filterValues:" + attribute
+}
+
+// getFilterRegistryKey returns the set listing every filter key of a
+// leaderboard, so rebuilds and deletion can find them
+func (r *ParticipantRepo) getFilterRegistryKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":filterKeys"
+}
+
+// filterAttributes keeps only the configured filterable attributes
+func (r *ParticipantRepo) filterAttributes(attributes map[string]string) map[string]string {
+	if len(r.config.FilterAttributes) == 0 || len(attributes) == 0 {
+		return nil
+	}
+
+	filtered := make(map[string]string, len(r.config.FilterAttributes))
+	for _, name := range r.config.FilterAttributes {
+		if value, ok := attributes[name]; ok && value != "" {
+			filtered[name] = value
+		}
+	}
+
+	return filtered
+}
+
+// indexMemberAttributes queues the filtered view updates for a member whose
+// score has just changed in the leaderboard
+func (r *ParticipantRepo) indexMemberAttributes(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	attributes map[string]string,
+	leaderboardEndTime time.Time,
+	pipe redis.Pipeliner,
+) {
+	registryKey := r.getFilterRegistryKey(leaderboardID)
+	for attribute, value := range attributes {
+		valuesKey := r.getFilterValuesKey(leaderboardID, attribute)
+		filterKey := r.getFilterKey(leaderboardID, attribute, value)
+
+		indexMemberScript.Eval(
+			ctx,
+			pipe,
+			[]string{
+				r.getRedisKey(leaderboardID),
+				valuesKey,
+				r.getFilterKeyPrefix(leaderboardID, attribute),
+			},
+			namespacedUserID,
+			value,
+		)
+		pipe.SAdd(ctx, registryKey, valuesKey, filterKey)
+		r.setupLeaderboardExpiry(ctx, valuesKey, leaderboardEndTime, pipe)
+		r.setupLeaderboardExpiry(ctx, filterKey, leaderboardEndTime, pipe)
+	}
+	r.setupLeaderboardExpiry(ctx, registryKey, leaderboardEndTime, pipe)
+}
+
+// filterRebuild accumulates the filtered views while a leaderboard is
+// rebuilt into shadow keys
+type filterRebuild struct {
+	repo          *ParticipantRepo
+	leaderboardID string
+	additive      bool
+	keys          map[string]struct{}
+}
+
+// newFilterRebuild starts collecting filtered views for a rebuild
+func (r *ParticipantRepo) newFilterRebuild(leaderboardID string, additive bool) *filterRebuild {
+	return &filterRebuild{
+		repo:          r,
+		leaderboardID: leaderboardID,
+		additive:      additive,
+		keys:          make(map[string]struct{}),
+	}
+}
+
+// add queues a rebuilt item into the shadow filtered views
+func (f *filterRebuild) add(
+	ctx context.Context,
+	pipe redis.Pipeliner,
+	namespacedUserID string,
+	score float64,
+	attributes map[string]interface{},
+) {
+	for _, attribute := range f.repo.config.FilterAttributes {
+		value, ok := attributes[attribute].(string)
+		if !ok || value == "" {
+			continue
+		}
+
+		valuesKey := f.repo.getFilterValuesKey(f.leaderboardID, attribute)
+		filterKey := f.repo.getFilterKey(f.leaderboardID, attribute, value)
+		if _, seen := f.keys[filterKey]; !seen {
+			pipe.Del(ctx, shadowKey(filterKey))
+			f.keys[filterKey] = struct{}{}
+		}
+		if _, seen := f.keys[valuesKey]; !seen {
+			pipe.Del(ctx, shadowKey(valuesKey))
+			f.keys[valuesKey] = struct{}{}
+		}
+
+		pipe.HSet(ctx, shadowKey(valuesKey), namespacedUserID, value)
+		if f.additive {
+			pipe.ZIncrBy(ctx, shadowKey(filterKey), score, namespacedUserID)
+			continue
+		}
+		pipe.ZAdd(ctx, shadowKey(filterKey), redis.Z{
+			Score:  score,
+			Member: namespacedUserID,
+		})
+	}
+}
+
+// expireShadowKeys bounds how long the shadow filtered views built so far
+// linger if the rebuild dies
+func (f *filterRebuild) expireShadowKeys(ctx context.Context, pipe redis.Pipeliner) {
+	for key := range f.keys {
+		pipe.Expire(ctx, shadowKey(key), shadowKeyTTL)
+	}
+}
+
+// swapKeys returns the shadow/live key pairs to swap once the rebuild is
+// complete. Live keys from the previous build that were not rebuilt are
+// paired with a missing shadow key so the swap removes them.
+func (f *filterRebuild) swapKeys(
+	ctx context.Context,
+	pipe redis.Pipeliner,
+	previous []string,
+) []string {
+	var pairs []string
+	live := make([]string, 0, len(f.keys))
+	for key := range f.keys {
+		live = append(live, key)
+	}
+	sort.Strings(live)
+	for _, key := range live {
+		pipe.Expire(ctx, shadowKey(key), shadowKeyTTL)
+		pairs = append(pairs, shadowKey(key), key)
+	}
+	for _, key := range previous {
+		if _, rebuilt := f.keys[key]; !rebuilt {
+			pairs = append(pairs, shadowKey(key), key)
+		}
+	}
+
+	// Replace the registry with the rebuilt keys
+	registryKey := f.repo.getFilterRegistryKey(f.leaderboardID)
+	pipe.Del(ctx, registryKey)
+	if len(live) > 0 {
+		members := make([]interface{}, len(live))
+		for i, key := range live {
+			members[i] = key
+		}
+		pipe.SAdd(ctx, registryKey, members...)
+	}
+
+	return pairs
+}
+
+// setupExpiry queues the expiry of every rebuilt filter key
+func (f *filterRebuild) setupExpiry(
+	ctx context.Context,
+	leaderboardEndTime time.Time,
+	pipe redis.Pipeliner,
+) {
+	for key := range f.keys {
+		f.repo.setupLeaderboardExpiry(ctx, key, leaderboardEndTime, pipe)
+	}
+	f.repo.setupLeaderboardExpiry(ctx, f.repo.getFilterRegistryKey(f.leaderboardID), leaderboardEndTime, pipe)
+}
+
+// registeredFilterKeys returns every filter key currently registered for a
+// leaderboard
+func (r *ParticipantRepo) registeredFilterKeys(
+	ctx context.Context,
+	leaderboardID string,
+) ([]string, error) {
+	keys, err := r.redisClient.SMembers(ctx, r.getFilterRegistryKey(leaderboardID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to list filter keys: %w",
+			err,
+		)
+	}
+
+	return keys, nil
+}
+
+// checkFilterAttribute returns ErrUnknownFilterAttribute unless attribute
+// is configured, as only configured attributes have filtered views
+func (r *ParticipantRepo) checkFilterAttribute(attribute string) error {
+	for _, configured := range r.config.FilterAttributes {
+		if configured == attribute {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", customTypes.ErrUnknownFilterAttribute, attribute)
+}
+
+// GetTopNFilteredParticipants retrieves the top N participants matching
+// every attribute of the filter, ranked within the filtered view
+func (r *ParticipantRepo) GetTopNFilteredParticipants(
+	ctx context.Context,
+	leaderboardID string,
+	n int64,
+	filter map[string]string,
+	leaderboardEndTime time.Time,
+) ([]customTypes.MemberScore, error) {
+	if len(filter) == 0 {
+		return r.GetTopNParticipants(ctx, leaderboardID, n, leaderboardEndTime)
+	}
+	for attribute := range filter {
+		if err := r.checkFilterAttribute(attribute); err != nil {
+			return nil, err
+		}
+	}
+
+	// Ensure the leaderboard exists in Redis
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(filter))
+	for attribute, value := range filter {
+		keys = append(keys, r.getFilterKey(leaderboardID, attribute, value))
+	}
+	sort.Strings(keys)
+
+	var results []redis.Z
+	var viewSize int64
+	var err error
+	if len(keys) == 1 {
+		pipe := r.redisClient.Pipeline()
+		rangeCmd := pipe.ZRevRangeWithScores(ctx, keys[0], 0, n-1)
+		cardCmd := pipe.ZCard(ctx, keys[0])
+		_, err = pipe.Exec(ctx)
+		results, viewSize = rangeCmd.Val(), cardCmd.Val()
+	} else {
+		// Intersect the views into a short-lived key; every view carries the
+		// same score for a member, so MAX keeps it unchanged
+		suffix := make([]byte, 8)
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, fmt.Errorf(
+				"failed to generate temporary key: %w",
+				err,
+			)
+		}
+		tmpKey := r.getRedisKey(leaderboardID) + ":filterTmp:" + hex.EncodeToString(suffix)
+
+		pipe := r.redisClient.Pipeline()
+		pipe.ZInterStore(ctx, tmpKey, &redis.ZStore{
+			Keys:      keys,
+			Aggregate: "MAX",
+		})
+		pipe.Expire(ctx, tmpKey, 10*time.Second)
+		rangeCmd := pipe.ZRevRangeWithScores(ctx, tmpKey, 0, n-1)
+		cardCmd := pipe.ZCard(ctx, tmpKey)
+		pipe.Del(ctx, tmpKey)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf(
+				"failed to intersect filtered views: %w",
+				err,
+			)
+		}
+		results, viewSize = rangeCmd.Val(), cardCmd.Val()
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get top N filtered participants from Redis: %w",
+			err,
+		)
+	}
+
+	participants := make([]customTypes.MemberScore, len(results))
+	for i, result := range results {
+		participants[i] = customTypes.MemberScore{
+			Member:     result.Member.(string),
+			Score:      r.displayScore(result.Score),
+			ComputedAt: utils.GetCurrTimeStamp(),
+			Rank:       int64(i + 1),
+		}
+	}
+
+	// Tiers are relative to the filtered view
+	r.assignTiers(participants, viewSize)
+
+	return participants, nil
+}
+
+// GetFilteredParticipantRank retrieves a participant's score and rank
+// within the filtered view of one attribute value
+func (r *ParticipantRepo) GetFilteredParticipantRank(
+	ctx context.Context,
+	leaderboardID string,
+	attribute string,
+	value string,
+	namespacedUserID string,
+	leaderboardEndTime time.Time,
+) (*customTypes.MemberScore, error) {
+	if err := r.checkFilterAttribute(attribute); err != nil {
+		return nil, err
+	}
+
+	// Ensure the leaderboard exists in Redis
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return nil, err
+	}
+
+	filterKey := r.getFilterKey(leaderboardID, attribute, value)
+	pipe := r.redisClient.Pipeline()
+	scoreCmd := pipe.ZScore(ctx, filterKey, namespacedUserID)
+	rankCmd := pipe.ZRevRank(ctx, filterKey, namespacedUserID)
+	cardCmd := pipe.ZCard(ctx, filterKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if err == redis.Nil {
+			return nil, customTypes.ErrParticipantNotFound
+		}
+		return nil, fmt.Errorf(
+			"failed to get filtered participant rank from Redis: %w",
+			err,
+		)
+	}
+
+	participant := []customTypes.MemberScore{{
+		Member:     namespacedUserID,
+		Score:      r.displayScore(scoreCmd.Val()),
+		ComputedAt: utils.GetCurrTimeStamp(),
+		Rank:       rankCmd.Val() + 1,
+	}}
+
+	// Tiers are relative to the filtered view
+	r.assignTiers(participant, cardCmd.Val())
+
+	return &participant[0], nil
+}
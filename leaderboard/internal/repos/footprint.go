@@ -0,0 +1,57 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetCacheFootprint measures the Redis memory of every key the repo keeps
+// for a leaderboard with MEMORY USAGE. The cache is not rebuilt first, so a
+// cold leaderboard reports no members.
+func (r *ParticipantRepo) GetCacheFootprint(
+	ctx context.Context,
+	leaderboardID string,
+) (*customTypes.CacheFootprint, error) {
+	filterKeys, err := r.registeredFilterKeys(ctx, leaderboardID)
+	if err != nil {
+		return nil, err
+	}
+	keys := append(r.leaderboardRedisKeys(leaderboardID), filterKeys...)
+
+	// Measure every key in one round trip
+	pipe := r.redisClient.Pipeline()
+	countCmd := pipe.ZCard(ctx, r.getRedisKey(leaderboardID))
+	usageCmds := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		usageCmds[i] = pipe.MemoryUsage(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf(
+			"failed to measure cache footprint: %w",
+			err,
+		)
+	}
+
+	footprint := &customTypes.CacheFootprint{
+		LeaderboardID: leaderboardID,
+		Members:       countCmd.Val(),
+		KeyBytes:      make(map[string]int64),
+	}
+	for i, key := range keys {
+		// Missing keys report redis.Nil
+		if usageCmds[i].Err() != nil {
+			continue
+		}
+		footprint.KeyBytes[key] = usageCmds[i].Val()
+		footprint.Bytes += usageCmds[i].Val()
+	}
+	if footprint.Members > 0 {
+		footprint.BytesPerMember = float64(footprint.Bytes) / float64(footprint.Members)
+	}
+	footprint.ProjectedBytes = footprint.Bytes
+
+	return footprint, nil
+}
@@ -0,0 +1,74 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/redis/go-redis/v9"
+)
+
+// PingRedis checks that Redis answers
+func (r *ParticipantRepo) PingRedis(ctx context.Context) error {
+	if err := r.redisClient.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf(
+			"failed to ping Redis: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// CheckTable checks that the participant table can be reached and is
+// active. It needs the dynamodb:DescribeTable permission.
+func (r *ParticipantRepo) CheckTable(ctx context.Context) error {
+	output, err := r.dynamoClient.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(r.tableName),
+	})
+	if err != nil {
+		return fmt.Errorf(
+			"failed to describe DynamoDB table %s: %w",
+			r.tableName,
+			err,
+		)
+	}
+	if status := output.Table.TableStatus; status != types.TableStatusActive && status != types.TableStatusUpdating {
+		return fmt.Errorf("DynamoDB table %s is %s", r.tableName, status)
+	}
+
+	return nil
+}
+
+// CacheSyncedAt reports whether a leaderboard is cached and when it was
+// last loaded or refreshed from DynamoDB, zero if that is unknown
+func (r *ParticipantRepo) CacheSyncedAt(
+	ctx context.Context,
+	leaderboardID string,
+) (bool, time.Time, error) {
+	cached, err := r.isCached(ctx, leaderboardID)
+	if err != nil || !cached {
+		return false, time.Time{}, err
+	}
+
+	value, err := r.redisClient.Get(ctx, r.getSyncedAtKey(leaderboardID)).Result()
+	if err == redis.Nil {
+		return true, time.Time{}, nil
+	}
+	if err != nil {
+		return true, time.Time{}, fmt.Errorf(
+			"failed to get sync watermark: %w",
+			err,
+		)
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return true, time.Time{}, nil
+	}
+
+	return true, time.Unix(seconds, 0).UTC(), nil
+}
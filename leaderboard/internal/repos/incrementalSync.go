@@ -0,0 +1,188 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// watermarkOverlap is re-read before the watermark on every incremental
+// sync, since updated_at only has second resolution and writes may land
+// slightly out of order
+const watermarkOverlap = 2 * time.Second
+
+// getSyncedAtKey returns the Redis key holding the time up to which a
+// leaderboard's cache reflects DynamoDB
+func (r *ParticipantRepo) getSyncedAtKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":syncedAt"
+}
+
+// recordSyncWatermark queues the watermark update for a completed sync
+func (r *ParticipantRepo) recordSyncWatermark(
+	ctx context.Context,
+	leaderboardID string,
+	syncedAt time.Time,
+	leaderboardEndTime time.Time,
+	pipe redis.Pipeliner,
+) {
+	syncedAtKey := r.getSyncedAtKey(leaderboardID)
+	pipe.Set(ctx, syncedAtKey, syncedAt.Unix(), 0)
+	r.setupLeaderboardExpiry(ctx, syncedAtKey, leaderboardEndTime, pipe)
+}
+
+// RefreshLeaderboard brings the cached leaderboard up to date with
+// DynamoDB. When an updated_at index is configured and the cache has a
+// watermark, only items changed since the watermark are read; otherwise the
+// leaderboard is fully reloaded. Incremental refreshes never clear the key,
+// so readers keep seeing a complete board.
+func (r *ParticipantRepo) RefreshLeaderboard(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+) error {
+	if r.config.UpdatedAtIndexName == "" {
+		return r.WarmLeaderboard(ctx, leaderboardID, leaderboardEndTime, true, nil)
+	}
+
+	// Fall back to a full load if the cache or its watermark is missing
+	watermark, err := r.redisClient.Get(ctx, r.getSyncedAtKey(leaderboardID)).Int64()
+	if err == redis.Nil {
+		return r.WarmLeaderboard(ctx, leaderboardID, leaderboardEndTime, true, nil)
+	}
+	if err != nil {
+		return fmt.Errorf(
+			"failed to read sync watermark: %w",
+			err,
+		)
+	}
+	cached, err := r.isCached(ctx, leaderboardID)
+	if err != nil {
+		return err
+	}
+	if !cached {
+		return r.WarmLeaderboard(ctx, leaderboardID, leaderboardEndTime, true, nil)
+	}
+
+	syncStartedAt := utils.GetCurrTimeStamp()
+	since := time.Unix(watermark, 0).Add(-watermarkOverlap)
+
+	changed, err := r.queryChangedSince(ctx, leaderboardID, since)
+	if err != nil {
+		return err
+	}
+
+	// Each member is read back whole, so the overlap re-reads members
+	// already synced, and set with the compare-and-set, so a write landing
+	// meanwhile is not overwritten by the older read. Regional deltas are
+	// summed across every partition by the read, and the member's views and
+	// stats follow its score.
+	for _, member := range changed {
+		_, err := r.syncMember(ctx, leaderboardID, member, leaderboardEndTime)
+		if err != nil && !errors.Is(err, customTypes.ErrParticipantNotFound) {
+			return fmt.Errorf(
+				"failed to apply incremental sync: %w",
+				err,
+			)
+		}
+	}
+
+	pipe := r.redisClient.Pipeline()
+	r.recordSyncWatermark(ctx, leaderboardID, syncStartedAt, leaderboardEndTime, pipe)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf(
+			"failed to record sync watermark: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// queryChangedSince returns every participant with an item updated at or
+// after since, read through the updated_at index. Items past their TTL are
+// left out; the expiry sweep removes them from the cache.
+func (r *ParticipantRepo) queryChangedSince(
+	ctx context.Context,
+	leaderboardID string,
+	since time.Time,
+) ([]string, error) {
+	keySchema := r.config.KeySchema
+	projection := "#sk"
+	projectionNames := keySchema.KeyNames()
+	expiryAttribute := ""
+	if r.itemsExpire() {
+		expiryAttribute = r.ttlAttributeName()
+		projection += ", #ttl"
+		projectionNames["#ttl"] = expiryAttribute
+	}
+	nowUnix := float64(utils.GetCurrTimeStamp().Unix())
+
+	seen := make(map[string]bool)
+	var changed []string
+	for _, partitionKey := range r.readPartitionKeys(leaderboardID) {
+		input := &dynamodb.QueryInput{
+			TableName: aws.String(r.tableName),
+			IndexName: aws.String(r.config.UpdatedAtIndexName),
+			KeyConditionExpression: aws.String(
+				"#pk = :lid AND updated_at >= :since",
+			),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":lid": &types.AttributeValueMemberS{
+					Value: keySchema.PartitionValue(partitionKey),
+				},
+				":since": &types.AttributeValueMemberN{
+					Value: strconv.FormatInt(since.Unix(), 10),
+				},
+			},
+			ProjectionExpression:     aws.String(projection),
+			ExpressionAttributeNames: projectionNames,
+		}
+
+		// The index sorts on updated_at, so other entities sharing the
+		// partition are filtered out instead
+		if prefix := keySchema.SortKeyPrefix(); prefix != "" {
+			input.FilterExpression = aws.String("begins_with(#sk, :skPrefix)")
+			input.ExpressionAttributeValues[":skPrefix"] = &types.AttributeValueMemberS{
+				Value: prefix,
+			}
+		}
+
+		paginator := dynamodb.NewQueryPaginator(r.dynamoClient, input)
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"failed to query updated_at index: %w",
+					err,
+				)
+			}
+
+			for _, item := range page.Items {
+				member, ok := keySchema.memberFromItem(item)
+				if !ok || seen[member] {
+					continue
+				}
+				// Items past their TTL may linger before DynamoDB deletes
+				// them
+				if value, ok := item[expiryAttribute]; ok && expiryAttribute != "" {
+					if expiresAt, err := decodeNumber(value); err == nil && expiresAt <= nowUnix {
+						continue
+					}
+				}
+				seen[member] = true
+				changed = append(changed, member)
+			}
+		}
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,183 @@
+package repos
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// leaderboardIDPlaceholder is replaced by the leaderboard partition in
+	// partition key templates
+	leaderboardIDPlaceholder = "{leaderboardID}"
+	// namespacedUserIDPlaceholder is replaced by the member in sort key
+	// templates
+	namespacedUserIDPlaceholder = "{namespacedUserID}"
+
+	// ParticipantEntityType tags participant items in single-table mode
+	ParticipantEntityType = "PARTICIPANT"
+	// DefaultEntityTypeAttribute holds each item's entity type in single-table
+	// mode
+	DefaultEntityTypeAttribute = "entityType"
+)
+
+// KeySchema describes how participant items are keyed in DynamoDB. The
+// templates wrap the raw IDs, e.g. "LB#{leaderboardID}" and
+// "USER#{namespacedUserID}" for single-table designs.
+type KeySchema struct {
+	PartitionKey         string
+	SortKey              string
+	PartitionKeyTemplate string
+	SortKeyTemplate      string
+	// EntityTypeAttribute tags every item with its entity type when set, so
+	// participants can be told apart from other entities sharing the table
+	EntityTypeAttribute string
+}
+
+// DefaultKeySchema keys items by their plain leaderboardID and
+// namespacedUserID attributes
+func DefaultKeySchema() KeySchema {
+	return KeySchema{
+		PartitionKey:         "leaderboardID",
+		SortKey:              "namespacedUserID",
+		PartitionKeyTemplate: leaderboardIDPlaceholder,
+		SortKeyTemplate:      namespacedUserIDPlaceholder,
+	}
+}
+
+// SingleTableKeySchema keys items by generic PK and SK attributes with
+// entity-type prefixes, so participants can share a table with the other
+// entities of a single-table design: participants live under
+// "LB#{leaderboardID}" with "PARTICIPANT#{namespacedUserID}" sort keys.
+func SingleTableKeySchema() KeySchema {
+	return KeySchema{
+		PartitionKey:         "PK",
+		SortKey:              "SK",
+		PartitionKeyTemplate: "LB#" + leaderboardIDPlaceholder,
+		SortKeyTemplate:      ParticipantEntityType + "#" + namespacedUserIDPlaceholder,
+		EntityTypeAttribute:  DefaultEntityTypeAttribute,
+	}
+}
+
+// WithDefaults fills any unset field from the default schema
+func (k KeySchema) WithDefaults() KeySchema {
+	defaults := DefaultKeySchema()
+	if k.PartitionKey == "" {
+		k.PartitionKey = defaults.PartitionKey
+	}
+	if k.SortKey == "" {
+		k.SortKey = defaults.SortKey
+	}
+	if k.PartitionKeyTemplate == "" {
+		k.PartitionKeyTemplate = defaults.PartitionKeyTemplate
+	}
+	if k.SortKeyTemplate == "" {
+		k.SortKeyTemplate = defaults.SortKeyTemplate
+	}
+
+	return k
+}
+
+// IsDefault reports whether the key attributes are the item's own
+// leaderboardID and namespacedUserID attributes
+func (k KeySchema) IsDefault() bool {
+	return k.WithDefaults() == DefaultKeySchema()
+}
+
+// SortKeyPrefix returns the constant prefix of every participant sort key
+func (k KeySchema) SortKeyPrefix() string {
+	prefix, _, _ := strings.Cut(k.SortKeyTemplate, namespacedUserIDPlaceholder)
+	return prefix
+}
+
+// PartitionQuery returns the key condition selecting the participant items
+// of a leaderboard partition, and its values. The condition refers to the
+// key attributes as #pk and #sk.
+func (k KeySchema) PartitionQuery(
+	leaderboardPartition string,
+) (string, map[string]types.AttributeValue) {
+	condition := "#pk = :lid"
+	values := map[string]types.AttributeValue{
+		":lid": &types.AttributeValueMemberS{
+			Value: k.PartitionValue(leaderboardPartition),
+		},
+	}
+
+	// Skip other entities sharing the partition
+	if prefix := k.SortKeyPrefix(); prefix != "" {
+		condition += " AND begins_with(#sk, :skPrefix)"
+		values[":skPrefix"] = &types.AttributeValueMemberS{Value: prefix}
+	}
+
+	return condition, values
+}
+
+// KeyNames returns the expression attribute names of the key attributes
+func (k KeySchema) KeyNames() map[string]string {
+	return map[string]string{
+		"#pk": k.PartitionKey,
+		"#sk": k.SortKey,
+	}
+}
+
+// PartitionValue returns the partition key value of a leaderboard partition
+func (k KeySchema) PartitionValue(leaderboardPartition string) string {
+	return strings.Replace(k.PartitionKeyTemplate, leaderboardIDPlaceholder, leaderboardPartition, 1)
+}
+
+// SortValue returns the sort key value of a member
+func (k KeySchema) SortValue(namespacedUserID string) string {
+	return strings.Replace(k.SortKeyTemplate, namespacedUserIDPlaceholder, namespacedUserID, 1)
+}
+
+// MemberFromSortValue extracts the member from a sort key value, reporting
+// false if the value does not match the template
+func (k KeySchema) MemberFromSortValue(sortValue string) (string, bool) {
+	prefix, suffix, _ := strings.Cut(k.SortKeyTemplate, namespacedUserIDPlaceholder)
+	if !strings.HasPrefix(sortValue, prefix) || !strings.HasSuffix(sortValue, suffix) {
+		return "", false
+	}
+	if len(sortValue) < len(prefix)+len(suffix) {
+		return "", false
+	}
+
+	return sortValue[len(prefix) : len(sortValue)-len(suffix)], true
+}
+
+// conditionNames returns the expression attribute names of the key
+// attributes used by a key condition, as DynamoDB rejects unused names
+func (k KeySchema) conditionNames(condition string) map[string]string {
+	names := k.KeyNames()
+	for name := range names {
+		if !strings.Contains(condition, name) {
+			delete(names, name)
+		}
+	}
+
+	return names
+}
+
+// ItemKey returns the DynamoDB key of a member's item in a partition
+func (k KeySchema) ItemKey(
+	leaderboardPartition string,
+	namespacedUserID string,
+) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		k.PartitionKey: &types.AttributeValueMemberS{
+			Value: k.PartitionValue(leaderboardPartition),
+		},
+		k.SortKey: &types.AttributeValueMemberS{
+			Value: k.SortValue(namespacedUserID),
+		},
+	}
+}
+
+// memberFromItem extracts the member from an item holding the sort key
+func (k KeySchema) memberFromItem(item map[string]types.AttributeValue) (string, bool) {
+	sortValue, ok := item[k.SortKey].(*types.AttributeValueMemberS)
+	if !ok {
+		return "", false
+	}
+
+	return k.MemberFromSortValue(sortValue.Value)
+}
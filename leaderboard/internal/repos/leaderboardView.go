@@ -0,0 +1,188 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// leaderboardViewScript reads the top N, a member's rank and score and the
+// window of members around it in one round trip. It returns
+// {count, top} for unranked members and {count, top, rank, score, around}
+// otherwise, with ranges flattened as member, score pairs.
+var leaderboardViewScript = newReadOnlyScript(`
+local count = redis.call('ZCARD', KEYS[1])
+local n = tonumber(ARGV[2])
+local top = {}
+if n > 0 then
+	top = redis.call('ZREVRANGE', KEYS[1], 0, n - 1, 'WITHSCORES')
+end
+
+local rank = redis.call('ZREVRANK', KEYS[1], ARGV[1])
+if not rank then
+	return {count, top}
+end
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+
+local window = tonumber(ARGV[3])
+local around = {}
+if window > 0 then
+	local first = rank - window
+	if first < 0 then
+		first = 0
+	end
+	around = redis.call('ZREVRANGE', KEYS[1], first, rank + window, 'WITHSCORES')
+end
+
+return {count, top, rank, score, around}
+`)
+
+// GetLeaderboardView reads the top n entries, the member's own standing and
+// the aroundWindow entries on either side of it. The cached reads happen in
+// a single Redis round trip; rank deltas, when enabled, take one more.
+func (r *ParticipantRepo) GetLeaderboardView(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	n int64,
+	aroundWindow int64,
+	leaderboardEndTime time.Time,
+) (*customTypes.LeaderboardView, error) {
+	redisKey := r.getRedisKey(leaderboardID)
+
+	// Ensure the leaderboard exists in Redis
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return nil, err
+	}
+
+	reply, err := leaderboardViewScript.Run(
+		ctx,
+		r.redisClient,
+		[]string{redisKey},
+		namespacedUserID,
+		n,
+		aroundWindow,
+	).Slice()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read leaderboard view from Redis: %w",
+			err,
+		)
+	}
+
+	view, err := r.parseLeaderboardView(namespacedUserID, aroundWindow, reply)
+	if err != nil {
+		return nil, err
+	}
+
+	// Annotate every returned entry in one pass
+	entries := make([]customTypes.MemberScore, 0, len(view.Top)+len(view.Around)+1)
+	entries = append(entries, view.Top...)
+	entries = append(entries, view.Around...)
+	if view.Entry != nil {
+		entries = append(entries, *view.Entry)
+	}
+	if err := r.annotateRankDeltas(ctx, leaderboardID, leaderboardEndTime, entries); err != nil {
+		return nil, err
+	}
+	r.assignTiers(entries, view.Count)
+
+	copy(view.Top, entries)
+	copy(view.Around, entries[len(view.Top):])
+	if view.Entry != nil {
+		*view.Entry = entries[len(entries)-1]
+	}
+
+	return view, nil
+}
+
+// parseLeaderboardView converts the reply of leaderboardViewScript
+func (r *ParticipantRepo) parseLeaderboardView(
+	namespacedUserID string,
+	aroundWindow int64,
+	reply []interface{},
+) (*customTypes.LeaderboardView, error) {
+	if len(reply) != 2 && len(reply) != 5 {
+		return nil, fmt.Errorf("unexpected leaderboard view reply of %d values", len(reply))
+	}
+
+	count, _ := reply[0].(int64)
+	view := &customTypes.LeaderboardView{Count: count}
+
+	top, err := r.parseRangeReply(reply[1], 1)
+	if err != nil {
+		return nil, err
+	}
+	view.Top = top
+	if len(reply) == 2 {
+		return view, nil
+	}
+
+	rank, _ := reply[2].(int64)
+	score, err := parseScoreReply(reply[3])
+	if err != nil {
+		return nil, err
+	}
+	view.Entry = &customTypes.MemberScore{
+		Member:     namespacedUserID,
+		Score:      r.displayScore(score),
+		ComputedAt: utils.GetCurrTimeStamp(),
+		Rank:       rank + 1, // Convert to 1-based rank
+	}
+
+	// The window starts aroundWindow places above the member, or at the top
+	first := rank - aroundWindow
+	if first < 0 {
+		first = 0
+	}
+	view.Around, err = r.parseRangeReply(reply[4], first+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
+// parseRangeReply converts a flattened member, score range starting at
+// firstRank into entries
+func (r *ParticipantRepo) parseRangeReply(
+	value interface{},
+	firstRank int64,
+) ([]customTypes.MemberScore, error) {
+	values, _ := value.([]interface{})
+	entries := make([]customTypes.MemberScore, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		member, _ := values[i].(string)
+		score, err := parseScoreReply(values[i+1])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, customTypes.MemberScore{
+			Member:     member,
+			Score:      r.displayScore(score),
+			ComputedAt: utils.GetCurrTimeStamp(),
+			Rank:       firstRank + int64(len(entries)),
+		})
+	}
+
+	return entries, nil
+}
+
+// parseScoreReply parses a score returned by a script as a bulk string
+func parseScoreReply(value interface{}) (float64, error) {
+	text, _ := value.(string)
+	score, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf(
+			"failed to parse score %q: %w",
+			text,
+			err,
+		)
+	}
+
+	return score, nil
+}
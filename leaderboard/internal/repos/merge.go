@@ -0,0 +1,416 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// mergeUpdateHistoryScript adds one participant's update history to
+// another's and deletes it. KEYS[1] is the primary's history, KEYS[2] the
+// secondary's and ARGV[1] the history TTL in seconds.
+var mergeUpdateHistoryScript = newScript(`
+if redis.call("EXISTS", KEYS[2]) == 0 then
+	return 0
+end
+for _, field in ipairs({"count", "sum", "sumSquares"}) do
+	local value = redis.call("HGET", KEYS[2], field)
+	if value then
+		redis.call("HINCRBYFLOAT", KEYS[1], field, value)
+	end
+end
+redis.call("DEL", KEYS[2])
+redis.call("EXPIRE", KEYS[1], ARGV[1])
+return 1
+`)
+
+// refreshMemberViewsScript copies a member's current score from the
+// leaderboard into the filtered views it is indexed in.
+// KEYS[1] is the leaderboard, followed by each filter attribute's
+// member->value hash and view key prefix. ARGV[1] is the member.
+var refreshMemberViewsScript = newScript(`
+local score = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if not score then
+	return 0
+end
+for j = 2, #KEYS, 2 do
+	local value = redis.call("HGET", KEYS[j], ARGV[1])
+	if value then
+		redis.call("ZADD", KEYS[j + 1] .. value, score, ARGV[1])
+	end
+end
+return 1
+`)
+
+// memberItem is one of a participant's stored items, in the partition it
+// was read from
+type memberItem struct {
+	partitionKey string
+	item         map[string]types.AttributeValue
+}
+
+// readMemberItems reads every item holding part of a participant's score
+func (r *ParticipantRepo) readMemberItems(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+) ([]memberItem, error) {
+	var items []memberItem
+	for _, partitionKey := range r.memberPartitionKeys(leaderboardID, namespacedUserID) {
+		output, err := r.dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName:      aws.String(r.tableName),
+			Key:            r.config.KeySchema.ItemKey(partitionKey, namespacedUserID),
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to get participant from DynamoDB: %w",
+				err,
+			)
+		}
+		if len(output.Item) > 0 {
+			items = append(items, memberItem{partitionKey: partitionKey, item: output.Item})
+		}
+	}
+
+	return items, nil
+}
+
+// unchangedCondition returns a condition holding while an item still has
+// the score it was read with
+func unchangedCondition(
+	item map[string]types.AttributeValue,
+	placeholder string,
+	values map[string]types.AttributeValue,
+) string {
+	score, ok := item["score"]
+	if !ok {
+		return "attribute_not_exists(score)"
+	}
+	values[placeholder] = score
+
+	return "score = " + placeholder
+}
+
+// MergeParticipants folds the secondary participant into the primary one,
+// e.g. when a user links two accounts. The primary's score becomes the sum
+// or the higher of both scores, as strategy says, and keeps the earlier
+// time of every milestone either reached; the secondary's items are
+// deleted in the same DynamoDB transaction. The cached leaderboard and the
+// update history follow. The transaction only applies while neither
+// participant changed since it was read; it is retried otherwise and fails
+// with ErrMergeConflict once retries run out. It returns the primary's new
+// standing, with a zero rank while the cache is being rebuilt, and the
+// score it gained. A missing secondary fails with ErrParticipantNotFound.
+func (r *ParticipantRepo) MergeParticipants(
+	ctx context.Context,
+	leaderboardID string,
+	primaryUserID string,
+	secondaryUserID string,
+	strategy customTypes.AccountMergeStrategy,
+	leaderboardEndTime time.Time,
+) (customTypes.MemberScore, float64, error) {
+	result := customTypes.MemberScore{Member: primaryUserID}
+	if primaryUserID == secondaryUserID {
+		return result, 0, fmt.Errorf(
+			"cannot merge participant %s into itself",
+			primaryUserID,
+		)
+	}
+
+	// Keep post-deadline merges from changing final standings
+	now := utils.GetCurrTimeStamp()
+	late, err := r.checkWriteDeadline(ctx, leaderboardID, leaderboardEndTime, now)
+	if err != nil {
+		return result, 0, err
+	}
+
+	// Ensure Redis key exists before writing
+	cacheReady, err := r.prepareCacheForWrite(ctx, leaderboardID, leaderboardEndTime)
+	if err != nil {
+		return result, 0, err
+	}
+
+	var storedDelta float64
+	var expiresAt time.Time
+	var ttlEnabled bool
+	for attempt := 0; ; attempt++ {
+		var merged bool
+		merged, storedDelta, expiresAt, ttlEnabled, err = r.mergeParticipants(
+			ctx,
+			leaderboardID,
+			primaryUserID,
+			secondaryUserID,
+			strategy,
+			late,
+			now,
+			leaderboardEndTime,
+		)
+		if err != nil {
+			return result, 0, err
+		}
+		if merged {
+			break
+		}
+		if attempt >= r.conflictRetries() {
+			return result, 0, customTypes.ErrMergeConflict
+		}
+	}
+
+	// Fold the secondary's update history in regardless of the cache
+	pipe := r.redisClient.TxPipeline()
+	mergeUpdateHistoryScript.Eval(
+		ctx,
+		pipe,
+		[]string{
+			r.getUpdateHistoryKey(leaderboardID, primaryUserID),
+			r.getUpdateHistoryKey(leaderboardID, secondaryUserID),
+		},
+		int64(updateHistoryTTL.Seconds()),
+	)
+
+	var incrementCmd *redis.Cmd
+	if cacheReady {
+		redisKey := r.getRedisKey(leaderboardID)
+		r.noteRebuildWrites(ctx, leaderboardID, pipe, secondaryUserID)
+		pipe.ZRem(ctx, redisKey, secondaryUserID)
+		pipe.ZRem(ctx, r.getExpiriesKey(leaderboardID), secondaryUserID)
+		r.unindexMember(ctx, leaderboardID, secondaryUserID, pipe)
+		incrementCmd = r.incrementIfCached(ctx, leaderboardID, primaryUserID, storedDelta, pipe)
+
+		keys := []string{redisKey}
+		for _, attribute := range r.config.FilterAttributes {
+			keys = append(
+				keys,
+				r.getFilterValuesKey(leaderboardID, attribute),
+				r.getFilterKeyPrefix(leaderboardID, attribute),
+			)
+		}
+		refreshMemberViewsScript.Eval(ctx, pipe, keys, primaryUserID)
+		if ttlEnabled {
+			r.trackParticipantExpiry(ctx, leaderboardID, primaryUserID, expiresAt, leaderboardEndTime, pipe)
+		}
+	}
+
+	// A rebuild still in progress picks the merge up from DynamoDB
+	if !cacheReady {
+		r.syncSkippedWrites(ctx, leaderboardID, leaderboardEndTime, primaryUserID, secondaryUserID)
+	}
+
+	// The merge is durable, so a cache failure is repaired later; a retry
+	// would apply it twice
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		if cacheReady {
+			r.queueRepair(leaderboardID, err, primaryUserID, secondaryUserID)
+		} else {
+			fmt.Printf("%sError merging update history: %v\n", utils.LogPrefix(ctx), err)
+		}
+		return result, r.unscaleScore(storedDelta), nil
+	}
+	if incrementCmd != nil {
+		if units, rank, ok := cachedIncrement(incrementCmd); ok {
+			result.Score = r.displayScore(units)
+			result.Rank = rank + 1
+		}
+	}
+
+	return result, r.unscaleScore(storedDelta), nil
+}
+
+// mergeParticipants reads both participants and merges them in one
+// transaction conditioned on the scores read. It reports false if either
+// participant changed in the meantime, so the caller may read them again.
+func (r *ParticipantRepo) mergeParticipants(
+	ctx context.Context,
+	leaderboardID string,
+	primaryUserID string,
+	secondaryUserID string,
+	strategy customTypes.AccountMergeStrategy,
+	late bool,
+	now time.Time,
+	leaderboardEndTime time.Time,
+) (bool, float64, time.Time, bool, error) {
+	primaryItems, err := r.readMemberItems(ctx, leaderboardID, primaryUserID)
+	if err != nil {
+		return false, 0, time.Time{}, false, err
+	}
+	secondaryItems, err := r.readMemberItems(ctx, leaderboardID, secondaryUserID)
+	if err != nil {
+		return false, 0, time.Time{}, false, err
+	}
+	if len(secondaryItems) == 0 {
+		return false, 0, time.Time{}, false, customTypes.ErrParticipantNotFound
+	}
+
+	// Total both scores and collect the earliest time of each milestone
+	totals := make([]float64, 2)
+	milestones := make([]map[float64]time.Time, 2)
+	for i, items := range [][]memberItem{primaryItems, secondaryItems} {
+		member := []string{primaryUserID, secondaryUserID}[i]
+		milestones[i] = make(map[float64]time.Time)
+		for _, item := range items {
+			if frozenErr := frozenError(member, item.item); frozenErr != nil {
+				return false, 0, time.Time{}, false, frozenErr
+			}
+			if score, ok := item.item["score"]; ok {
+				units, err := decodeNumber(score)
+				if err != nil {
+					return false, 0, time.Time{}, false, err
+				}
+				totals[i] += units
+			}
+			for threshold, reachedAt := range itemMilestones(item.item) {
+				if earliest, ok := milestones[i][threshold]; !ok || reachedAt.Before(earliest) {
+					milestones[i][threshold] = reachedAt
+				}
+			}
+		}
+	}
+
+	// A held entry fee would be lost with the secondary's items
+	for _, item := range secondaryItems {
+		if state, ok := item.item[escrowStateAttribute].(*types.AttributeValueMemberS); ok &&
+			customTypes.EscrowState(state.Value) == customTypes.EscrowHeld {
+			return false, 0, time.Time{}, false, fmt.Errorf(
+				"participant %s has a held entry fee; settle or refund it before merging",
+				secondaryUserID,
+			)
+		}
+	}
+
+	storedDelta := totals[1]
+	if strategy == customTypes.AccountMergeMax {
+		storedDelta = max(totals[1]-totals[0], 0)
+	}
+
+	credit, expiresAt, ttlEnabled, err := r.buildScoreUpdate(
+		leaderboardID,
+		primaryUserID,
+		storedDelta,
+		nil,
+		late,
+		now,
+		leaderboardEndTime,
+		utils.RequestID(ctx),
+	)
+	if err != nil {
+		return false, 0, time.Time{}, false, err
+	}
+
+	// Carry over milestones the secondary reached first
+	index := 0
+	for threshold, reachedAt := range milestones[1] {
+		if earliest, ok := milestones[0][threshold]; ok && !reachedAt.Before(earliest) {
+			continue
+		}
+		name := fmt.Sprintf("#merged%d", index)
+		value := fmt.Sprintf(":merged%d", index)
+		credit.ExpressionAttributeNames[name] = milestoneAttribute(threshold)
+		credit.ExpressionAttributeValues[value] = &types.AttributeValueMemberN{
+			Value: fmt.Sprintf("%d", reachedAt.Unix()),
+		}
+		credit.UpdateExpression = aws.String(fmt.Sprintf("%s, %s = %s", *credit.UpdateExpression, name, value))
+		index++
+	}
+
+	// Every item read must still hold the score it was read with
+	writePartition := r.writePartitionKey(leaderboardID, primaryUserID)
+	var creditItem map[string]types.AttributeValue
+	var checks []types.TransactWriteItem
+	for _, item := range primaryItems {
+		if item.partitionKey == writePartition {
+			creditItem = item.item
+			continue
+		}
+		values := make(map[string]types.AttributeValue)
+		condition := unchangedCondition(item.item, ":expected", values)
+		check := &types.ConditionCheck{
+			TableName:           aws.String(r.tableName),
+			Key:                 r.config.KeySchema.ItemKey(item.partitionKey, primaryUserID),
+			ConditionExpression: aws.String(condition),
+		}
+		if len(values) > 0 {
+			check.ExpressionAttributeValues = values
+		}
+		checks = append(checks, types.TransactWriteItem{ConditionCheck: check})
+	}
+	condition := "attribute_not_exists(#pk)"
+	if creditItem != nil {
+		condition = unchangedCondition(creditItem, ":expected", credit.ExpressionAttributeValues)
+	}
+	credit.ConditionExpression = aws.String(*credit.ConditionExpression + " AND " + condition)
+	for name, key := range r.config.KeySchema.conditionNames(condition) {
+		credit.ExpressionAttributeNames[name] = key
+	}
+
+	transactItems := append([]types.TransactWriteItem{{Update: transactUpdate(credit)}}, checks...)
+	for _, item := range secondaryItems {
+		values := make(map[string]types.AttributeValue)
+		condition := unchangedCondition(item.item, ":expected", values)
+		deletion := &types.Delete{
+			TableName:           aws.String(r.tableName),
+			Key:                 r.config.KeySchema.ItemKey(item.partitionKey, secondaryUserID),
+			ConditionExpression: aws.String(condition),
+		}
+		if len(values) > 0 {
+			deletion.ExpressionAttributeValues = values
+		}
+		transactItems = append(transactItems, types.TransactWriteItem{Delete: deletion})
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{TransactItems: transactItems}
+	err = r.retryConflicts(ctx, func() error {
+		_, err := r.dynamoClient.TransactWriteItems(ctx, input)
+		return err
+	})
+	if err != nil {
+		var cancelledErr *types.TransactionCanceledException
+		if errors.As(err, &cancelledErr) {
+			for i, reason := range cancelledErr.CancellationReasons {
+				if reason.Code == nil || *reason.Code != "ConditionalCheckFailed" {
+					continue
+				}
+				// An unchanged primary failed the frozen or range check
+				if i == 0 {
+					if frozenErr := frozenError(primaryUserID, reason.Item); frozenErr != nil {
+						return false, 0, time.Time{}, false, frozenErr
+					}
+					if sameScore(creditItem, reason.Item) {
+						return false, 0, time.Time{}, false, &customTypes.ScoreOutOfRangeError{
+							Score: r.unscaleScore(storedDelta),
+							Limit: models.MaxExactScore,
+						}
+					}
+				}
+				return false, 0, time.Time{}, false, nil
+			}
+		}
+		return false, 0, time.Time{}, false, fmt.Errorf(
+			"failed to merge participants in DynamoDB: %w",
+			err,
+		)
+	}
+
+	return true, storedDelta, expiresAt, ttlEnabled, nil
+}
+
+// sameScore reports whether two reads of an item hold the same score, an
+// absent item counting as one without a score
+func sameScore(read, current map[string]types.AttributeValue) bool {
+	readScore, readOK := read["score"].(*types.AttributeValueMemberN)
+	currentScore, currentOK := current["score"].(*types.AttributeValueMemberN)
+	if !readOK || !currentOK {
+		return readOK == currentOK
+	}
+
+	return readScore.Value == currentScore.Value
+}
@@ -0,0 +1,292 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// metadataAttributeName is the DynamoDB map attribute holding a
+// participant's display metadata
+const metadataAttributeName = "metadata"
+
+// getMetadataKey returns the Redis hash mapping members to their display
+// metadata as JSON
+func (r *ParticipantRepo) getMetadataKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":metadata"
+}
+
+// UpdateParticipantMetadata merges attrs into a participant's display
+// metadata, removing the attributes set to nil, and returns the resulting
+// metadata. Each attribute is set or removed on its own, so concurrent
+// updates of other attributes are kept. The score is left untouched. It
+// returns ErrParticipantNotFound if the participant has no item.
+func (r *ParticipantRepo) UpdateParticipantMetadata(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	attrs map[string]any,
+	leaderboardEndTime time.Time,
+) (map[string]any, error) {
+	participant, _, err := r.getParticipant(ctx, leaderboardID, namespacedUserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(attrs) == 0 {
+		return participant.Metadata, nil
+	}
+	if err := checkPlaintextMetadata(attrs); err != nil {
+		return nil, err
+	}
+
+	// PII leaves the process encrypted only
+	set := make(map[string]any, len(attrs))
+	for name, value := range attrs {
+		if value != nil {
+			set[name] = value
+		}
+	}
+	set, err = r.encryptMetadata(ctx, namespacedUserID, set)
+	if err != nil {
+		return nil, err
+	}
+
+	// Items without metadata need the map created first; retry the
+	// per-attribute update if another writer created it meanwhile
+	var stored map[string]any
+	updated := false
+	for attempt := 0; attempt < 2 && !updated; attempt++ {
+		stored, updated, err = r.updateMetadataAttributes(ctx, leaderboardID, namespacedUserID, attrs, set)
+		if err != nil {
+			return nil, err
+		}
+		if !updated {
+			stored, updated, err = r.createMetadata(ctx, leaderboardID, namespacedUserID, set)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !updated {
+		return nil, errors.New("participant metadata changed during update")
+	}
+
+	// Keep the cached metadata in step
+	if r.config.CacheMetadata {
+		encoded, err := json.Marshal(stored)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to encode participant metadata: %w",
+				err,
+			)
+		}
+		metadataKey := r.getMetadataKey(leaderboardID)
+		pipe := r.redisClient.Pipeline()
+		pipe.HSet(ctx, metadataKey, namespacedUserID, encoded)
+		r.setupLeaderboardExpiry(ctx, metadataKey, leaderboardEndTime, pipe)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf(
+				"failed to cache participant metadata: %w",
+				err,
+			)
+		}
+	}
+
+	if err := r.decryptMetadata(ctx, namespacedUserID, stored); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// updateMetadataAttributes sets and removes single attributes of a
+// participant's existing metadata map and returns the stored metadata. It
+// reports false if the participant has no metadata map yet.
+func (r *ParticipantRepo) updateMetadataAttributes(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	attrs map[string]any,
+	set map[string]any,
+) (map[string]any, bool, error) {
+	names := map[string]string{"#metadata": metadataAttributeName}
+	values := make(map[string]types.AttributeValue, len(set))
+	var setClauses, removeClauses []string
+	i := 0
+	for name := range attrs {
+		placeholder := fmt.Sprintf("a%d", i)
+		i++
+		names["#"+placeholder] = name
+
+		value, ok := set[name]
+		if !ok {
+			removeClauses = append(removeClauses, "#metadata.#"+placeholder)
+			continue
+		}
+		marshalled, err := attributevalue.Marshal(value)
+		if err != nil {
+			return nil, false, fmt.Errorf(
+				"failed to marshal participant metadata: %w",
+				err,
+			)
+		}
+		values[":"+placeholder] = marshalled
+		setClauses = append(setClauses, fmt.Sprintf("#metadata.#%s = :%s", placeholder, placeholder))
+	}
+
+	var expression []string
+	if len(setClauses) > 0 {
+		expression = append(expression, "SET "+strings.Join(setClauses, ", "))
+	}
+	if len(removeClauses) > 0 {
+		expression = append(expression, "REMOVE "+strings.Join(removeClauses, ", "))
+	}
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: r.config.KeySchema.ItemKey(
+			r.writePartitionKey(leaderboardID, namespacedUserID),
+			namespacedUserID,
+		),
+		UpdateExpression:         aws.String(strings.Join(expression, " ")),
+		ConditionExpression:      aws.String("attribute_exists(#metadata)"),
+		ExpressionAttributeNames: names,
+		ReturnValues:             types.ReturnValueAllNew,
+	}
+	if len(values) > 0 {
+		input.ExpressionAttributeValues = values
+	}
+
+	output, err := r.dynamoClient.UpdateItem(ctx, input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf(
+			"failed to update participant metadata in DynamoDB: %w",
+			err,
+		)
+	}
+
+	stored, err := storedMetadata(output.Attributes)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return stored, true, nil
+}
+
+// createMetadata creates the metadata map of a participant that has none.
+// It reports false if another writer created it first.
+func (r *ParticipantRepo) createMetadata(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	set map[string]any,
+) (map[string]any, bool, error) {
+	metadataValue, err := attributevalue.Marshal(set)
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"failed to marshal participant metadata: %w",
+			err,
+		)
+	}
+
+	_, err = r.dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: r.config.KeySchema.ItemKey(
+			r.writePartitionKey(leaderboardID, namespacedUserID),
+			namespacedUserID,
+		),
+		UpdateExpression:    aws.String("SET #metadata = :metadata"),
+		ConditionExpression: aws.String("attribute_not_exists(#metadata)"),
+		ExpressionAttributeNames: map[string]string{
+			"#metadata": metadataAttributeName,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":metadata": metadataValue,
+		},
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf(
+			"failed to update participant metadata in DynamoDB: %w",
+			err,
+		)
+	}
+
+	return set, true, nil
+}
+
+// storedMetadata decodes the metadata map of an item as stored, with
+// encrypted values left encrypted
+func storedMetadata(item map[string]types.AttributeValue) (map[string]any, error) {
+	stored := make(map[string]any)
+	value, ok := item[metadataAttributeName]
+	if !ok {
+		return stored, nil
+	}
+	if err := attributevalue.Unmarshal(value, &stored); err != nil {
+		return nil, fmt.Errorf(
+			"failed to unmarshal participant metadata: %w",
+			err,
+		)
+	}
+
+	return stored, nil
+}
+
+// GetCachedMetadata returns the cached display metadata of the members
+// that have any, keyed by member. Members whose metadata cannot be decoded
+// or decrypted are logged and left out.
+func (r *ParticipantRepo) GetCachedMetadata(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserIDs []string,
+) (map[string]map[string]any, error) {
+	metadata := make(map[string]map[string]any, len(namespacedUserIDs))
+	if len(namespacedUserIDs) == 0 {
+		return metadata, nil
+	}
+
+	values, err := r.redisClient.HMGet(ctx, r.getMetadataKey(leaderboardID), namespacedUserIDs...).Result()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf(
+			"failed to get cached metadata: %w",
+			err,
+		)
+	}
+
+	for i, value := range values {
+		encoded, ok := value.(string)
+		if !ok {
+			continue
+		}
+		// One member's unreadable metadata leaves the others readable
+		var attrs map[string]any
+		if err := json.Unmarshal([]byte(encoded), &attrs); err != nil {
+			fmt.Printf("%sError decoding cached metadata of %s: %v\n", utils.LogPrefix(ctx), namespacedUserIDs[i], err)
+			continue
+		}
+		if err := r.decryptMetadata(ctx, namespacedUserIDs[i], attrs); err != nil {
+			fmt.Printf("%sError decrypting cached metadata of %s: %v\n", utils.LogPrefix(ctx), namespacedUserIDs[i], err)
+			continue
+		}
+		metadata[namespacedUserIDs[i]] = attrs
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,152 @@
+package repos
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// encryptedValuePrefix marks metadata values stored encrypted
+const encryptedValuePrefix = "enc:v1:"
+
+// FieldCipher encrypts single metadata values. associatedData binds a
+// ciphertext to the member and attribute it was written for, so it cannot
+// be moved to another.
+type FieldCipher interface {
+	Encrypt(ctx context.Context, plaintext []byte, associatedData []byte) ([]byte, error)
+	Decrypt(ctx context.Context, ciphertext []byte, associatedData []byte) ([]byte, error)
+}
+
+// fieldAssociatedData returns the associated data of a member's attribute
+func fieldAssociatedData(namespacedUserID string, attribute string) []byte {
+	return []byte(namespacedUserID + "\x00" + attribute)
+}
+
+// isEncryptedField reports whether an attribute is stored encrypted
+func (r *ParticipantRepo) isEncryptedField(attribute string) bool {
+	if r.config.MetadataCipher == nil {
+		return false
+	}
+	for _, field := range r.config.EncryptedMetadataFields {
+		if field == attribute {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkPlaintextMetadata returns ErrReservedMetadataValue if a metadata
+// value written in plaintext could be mistaken for a ciphertext
+func checkPlaintextMetadata(metadata map[string]any) error {
+	for name, value := range metadata {
+		if stored, ok := value.(string); ok && strings.HasPrefix(stored, encryptedValuePrefix) {
+			return fmt.Errorf(
+				"metadata %s: %w",
+				name,
+				customTypes.ErrReservedMetadataValue,
+			)
+		}
+	}
+
+	return nil
+}
+
+// encryptMetadata returns a copy of a member's metadata with the encrypted
+// fields replaced by their ciphertexts
+func (r *ParticipantRepo) encryptMetadata(
+	ctx context.Context,
+	namespacedUserID string,
+	metadata map[string]any,
+) (map[string]any, error) {
+	if r.config.MetadataCipher == nil || len(metadata) == 0 {
+		return metadata, nil
+	}
+
+	encrypted := make(map[string]any, len(metadata))
+	for name, value := range metadata {
+		if !r.isEncryptedField(name) {
+			encrypted[name] = value
+			continue
+		}
+
+		plaintext, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to encode metadata %s: %w",
+				name,
+				err,
+			)
+		}
+		ciphertext, err := r.config.MetadataCipher.Encrypt(ctx, plaintext, fieldAssociatedData(namespacedUserID, name))
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to encrypt metadata %s: %w",
+				name,
+				err,
+			)
+		}
+		encrypted[name] = encryptedValuePrefix + base64.StdEncoding.EncodeToString(ciphertext)
+	}
+
+	return encrypted, nil
+}
+
+// decryptMetadata decrypts a member's values of the encrypted fields in
+// place. Values written before their field was encrypted, and values of
+// other fields, are returned as stored.
+func (r *ParticipantRepo) decryptMetadata(
+	ctx context.Context,
+	namespacedUserID string,
+	metadata map[string]any,
+) error {
+	if r.config.MetadataCipher == nil {
+		return nil
+	}
+
+	for name, value := range metadata {
+		if !r.isEncryptedField(name) {
+			continue
+		}
+		stored, ok := value.(string)
+		if !ok {
+			continue
+		}
+		encoded, ok := strings.CutPrefix(stored, encryptedValuePrefix)
+		if !ok {
+			continue
+		}
+
+		ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to decode encrypted metadata %s: %w",
+				name,
+				err,
+			)
+		}
+		plaintext, err := r.config.MetadataCipher.Decrypt(ctx, ciphertext, fieldAssociatedData(namespacedUserID, name))
+		if err != nil {
+			return fmt.Errorf(
+				"failed to decrypt metadata %s: %w",
+				name,
+				err,
+			)
+		}
+		var decrypted any
+		if err := json.Unmarshal(plaintext, &decrypted); err != nil {
+			return fmt.Errorf(
+				"failed to decode metadata %s: %w",
+				name,
+				err,
+			)
+		}
+		metadata[name] = decrypted
+	}
+
+	return nil
+}
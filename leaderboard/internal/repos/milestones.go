@@ -0,0 +1,152 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// milestoneAttributePrefix prefixes the top-level attributes holding when
+// each milestone was first reached, in unix seconds, e.g. milestone:10000
+const milestoneAttributePrefix = "milestone:"
+
+// milestoneAttribute returns the attribute recording a threshold
+func milestoneAttribute(threshold float64) string {
+	return milestoneAttributePrefix + strconv.FormatFloat(threshold, 'f', -1, 64)
+}
+
+// crossedMilestones returns the configured thresholds a score moving from
+// previous to total went past upwards
+func (r *ParticipantRepo) crossedMilestones(previous, total float64) []float64 {
+	var crossed []float64
+	for _, threshold := range r.config.Milestones {
+		if previous < threshold && threshold <= total {
+			crossed = append(crossed, threshold)
+		}
+	}
+
+	return crossed
+}
+
+// RecordMilestones records the configured thresholds a participant's score
+// crossed moving from previous to total, on the item the score was written
+// to. A threshold keeps the time it was first reached. It returns the
+// milestones reached for the first time.
+func (r *ParticipantRepo) RecordMilestones(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	previous float64,
+	total float64,
+) ([]customTypes.Milestone, error) {
+	crossed := r.crossedMilestones(previous, total)
+	if len(crossed) == 0 {
+		return nil, nil
+	}
+
+	// Build one SET clause per threshold, keeping existing times
+	reachedAt := utils.GetCurrTimeStamp().Truncate(time.Second)
+	condition := "attribute_exists(#pk)"
+	names := r.config.KeySchema.conditionNames(condition)
+	clauses := make([]string, len(crossed))
+	for i, threshold := range crossed {
+		name := fmt.Sprintf("#m%d", i)
+		names[name] = milestoneAttribute(threshold)
+		clauses[i] = fmt.Sprintf("%s = if_not_exists(%s, :reachedAt)", name, name)
+	}
+
+	output, err := r.dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String(r.tableName),
+		Key:                      r.config.KeySchema.ItemKey(r.writePartitionKey(leaderboardID, namespacedUserID), namespacedUserID),
+		UpdateExpression:         aws.String("SET " + strings.Join(clauses, ", ")),
+		ConditionExpression:      aws.String(condition),
+		ExpressionAttributeNames: names,
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":reachedAt": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", reachedAt.Unix())},
+		},
+		ReturnValues: types.ReturnValueUpdatedOld,
+	})
+	if err != nil {
+		// Items removed since the score write have nothing to record on
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(
+			"failed to record milestones in DynamoDB: %w",
+			err,
+		)
+	}
+
+	// Thresholds with an old value were reached before
+	var reached []customTypes.Milestone
+	for _, threshold := range crossed {
+		if _, ok := output.Attributes[milestoneAttribute(threshold)]; !ok {
+			reached = append(reached, customTypes.Milestone{
+				Threshold: threshold,
+				ReachedAt: reachedAt,
+			})
+		}
+	}
+
+	return reached, nil
+}
+
+// itemMilestones returns the milestones recorded on an item
+func itemMilestones(item map[string]types.AttributeValue) map[float64]time.Time {
+	var milestones map[float64]time.Time
+	for name, value := range item {
+		if !strings.HasPrefix(name, milestoneAttributePrefix) {
+			continue
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimPrefix(name, milestoneAttributePrefix), 64)
+		if err != nil {
+			continue
+		}
+		n, ok := value.(*types.AttributeValueMemberN)
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.ParseInt(n.Value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if milestones == nil {
+			milestones = make(map[float64]time.Time)
+		}
+		milestones[threshold] = time.Unix(seconds, 0).UTC()
+	}
+
+	return milestones
+}
+
+// sortedMilestones lists milestones by threshold
+func sortedMilestones(milestones map[float64]time.Time) []customTypes.Milestone {
+	if len(milestones) == 0 {
+		return nil
+	}
+
+	sorted := make([]customTypes.Milestone, 0, len(milestones))
+	for threshold, reachedAt := range milestones {
+		sorted = append(sorted, customTypes.Milestone{
+			Threshold: threshold,
+			ReachedAt: reachedAt,
+		})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Threshold < sorted[j].Threshold
+	})
+
+	return sorted
+}
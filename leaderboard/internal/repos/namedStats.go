@@ -0,0 +1,449 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// statAttributePrefix prefixes the top-level DynamoDB attribute holding each
+// named stat's total on a participant item
+const statAttributePrefix = "stat_"
+
+// statAttributeName returns the DynamoDB attribute of a named stat
+func statAttributeName(stat string) string {
+	return statAttributePrefix + stat
+}
+
+// getStatKey returns the Redis key of a named stat's leaderboard
+func (r *ParticipantRepo) getStatKey(leaderboardID, stat string) string {
+	return r.getRedisKey(leaderboardID) + ":stat:" + stat
+}
+
+// statKeys returns the Redis keys of every configured stat leaderboard
+func (r *ParticipantRepo) statKeys(leaderboardID string) []string {
+	keys := make([]string, len(r.config.Stats))
+	for i, stat := range r.config.Stats {
+		keys[i] = r.getStatKey(leaderboardID, stat)
+	}
+
+	return keys
+}
+
+// checkStat returns ErrUnknownStat unless stat is configured
+func (r *ParticipantRepo) checkStat(stat string) error {
+	for _, configured := range r.config.Stats {
+		if configured == stat {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", customTypes.ErrUnknownStat, stat)
+}
+
+// itemStats returns the named stats stored on a participant item, in stored
+// units
+func itemStats(item map[string]types.AttributeValue) map[string]float64 {
+	var stats map[string]float64
+	for name, value := range item {
+		stat, ok := strings.CutPrefix(name, statAttributePrefix)
+		if !ok {
+			continue
+		}
+		n, ok := value.(*types.AttributeValueMemberN)
+		if !ok {
+			continue
+		}
+		total, err := strconv.ParseFloat(n.Value, 64)
+		if err != nil {
+			continue
+		}
+		if stats == nil {
+			stats = make(map[string]float64)
+		}
+		stats[stat] = total
+	}
+
+	return stats
+}
+
+// removeMemberStats queues the removal of a member from every stat
+// leaderboard
+func (r *ParticipantRepo) removeMemberStats(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	pipe redis.Pipeliner,
+) {
+	for _, key := range r.statKeys(leaderboardID) {
+		pipe.ZRem(ctx, key, namespacedUserID)
+	}
+}
+
+// restoreMemberStats queues setting a member's cached stats from their
+// stored totals
+func (r *ParticipantRepo) restoreMemberStats(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	stats map[string]float64,
+	pipe redis.Pipeliner,
+) {
+	for _, stat := range r.config.Stats {
+		total, ok := stats[stat]
+		if !ok {
+			continue
+		}
+		pipe.ZAdd(ctx, r.getStatKey(leaderboardID, stat), redis.Z{
+			Score:  total,
+			Member: namespacedUserID,
+		})
+	}
+}
+
+// statRebuild accumulates the stat leaderboards while a leaderboard is
+// rebuilt into shadow keys
+type statRebuild struct {
+	repo          *ParticipantRepo
+	leaderboardID string
+	additive      bool
+}
+
+// newStatRebuild starts collecting stat leaderboards for a rebuild, clearing
+// any shadow keys left by an earlier one
+func (r *ParticipantRepo) newStatRebuild(
+	ctx context.Context,
+	leaderboardID string,
+	additive bool,
+	pipe redis.Pipeliner,
+) *statRebuild {
+	for _, key := range r.statKeys(leaderboardID) {
+		pipe.Del(ctx, shadowKey(key))
+	}
+
+	return &statRebuild{
+		repo:          r,
+		leaderboardID: leaderboardID,
+		additive:      additive,
+	}
+}
+
+// projection returns the projection expression naming every stat attribute,
+// adding the names it uses to names
+func (s *statRebuild) projection(names map[string]string) string {
+	placeholders := make([]string, len(s.repo.config.Stats))
+	for i, stat := range s.repo.config.Stats {
+		placeholder := fmt.Sprintf("#stat%d", i)
+		names[placeholder] = statAttributeName(stat)
+		placeholders[i] = placeholder
+	}
+
+	return strings.Join(placeholders, ", ")
+}
+
+// add queues a rebuilt item's stats into the shadow stat leaderboards
+func (s *statRebuild) add(
+	ctx context.Context,
+	pipe redis.Pipeliner,
+	namespacedUserID string,
+	stats map[string]float64,
+) {
+	for _, stat := range s.repo.config.Stats {
+		total, ok := stats[stat]
+		if !ok {
+			continue
+		}
+
+		key := shadowKey(s.repo.getStatKey(s.leaderboardID, stat))
+		if s.additive {
+			pipe.ZIncrBy(ctx, key, total, namespacedUserID)
+			continue
+		}
+		pipe.ZAdd(ctx, key, redis.Z{
+			Score:  total,
+			Member: namespacedUserID,
+		})
+	}
+}
+
+// expireShadowKeys bounds how long the shadow stat leaderboards built so far
+// linger if the rebuild dies
+func (s *statRebuild) expireShadowKeys(ctx context.Context, pipe redis.Pipeliner) {
+	for _, key := range s.repo.statKeys(s.leaderboardID) {
+		pipe.Expire(ctx, shadowKey(key), shadowKeyTTL)
+	}
+}
+
+// swapKeys returns the shadow/live key pairs to swap once the rebuild is
+// complete. Stats no member holds have no shadow key, so the swap clears
+// their live key.
+func (s *statRebuild) swapKeys() []string {
+	var pairs []string
+	for _, key := range s.repo.statKeys(s.leaderboardID) {
+		pairs = append(pairs, shadowKey(key), key)
+	}
+
+	return pairs
+}
+
+// setupExpiry queues the expiry of every rebuilt stat leaderboard
+func (s *statRebuild) setupExpiry(
+	ctx context.Context,
+	leaderboardEndTime time.Time,
+	pipe redis.Pipeliner,
+) {
+	for _, key := range s.repo.statKeys(s.leaderboardID) {
+		s.repo.setupLeaderboardExpiry(ctx, key, leaderboardEndTime, pipe)
+	}
+}
+
+// UpdateStats adds each delta to the participant's named stat in DynamoDB
+// and the stat's leaderboard in Redis, and returns the new total and rank
+// per stat. The participant's score is left unchanged, though participants
+// new to the leaderboard join it with a score of zero. While the cache is
+// being rebuilt the ranks are zero and the totals are the stored ones.
+func (r *ParticipantRepo) UpdateStats(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	deltas map[string]float64,
+	leaderboardEndTime time.Time,
+) (map[string]customTypes.MemberScore, error) {
+	if len(deltas) == 0 {
+		return map[string]customTypes.MemberScore{}, nil
+	}
+
+	// Reject unknown stats and deltas that cannot be stored exactly, in a
+	// fixed order so placeholders are stable
+	stats := make([]string, 0, len(deltas))
+	for stat := range deltas {
+		if err := r.checkStat(stat); err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+	sort.Strings(stats)
+	storedDeltas := make(map[string]float64, len(stats))
+	for _, stat := range stats {
+		storedDelta, err := r.storedScore(deltas[stat])
+		if err != nil {
+			return nil, err
+		}
+		storedDeltas[stat] = storedDelta
+	}
+
+	// Keep post-deadline writes from changing final standings
+	now := utils.GetCurrTimeStamp()
+	late, err := r.checkWriteDeadline(ctx, leaderboardID, leaderboardEndTime, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure Redis key exists before writing, so a cold rebuild never reads
+	// this write from DynamoDB and then has it applied a second time
+	cacheReady, err := r.prepareCacheForWrite(ctx, leaderboardID, leaderboardEndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build on the score update with a zero delta, so the item gets the
+	// same bookkeeping and frozen participants are rejected
+	input, expiresAt, ttlEnabled, err := r.buildScoreUpdate(
+		leaderboardID,
+		namespacedUserID,
+		0,
+		nil,
+		late,
+		now,
+		leaderboardEndTime,
+		utils.RequestID(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	updateExpression := *input.UpdateExpression
+	for i, stat := range stats {
+		name := fmt.Sprintf("#stat%d", i)
+		value := fmt.Sprintf(":stat%d", i)
+		updateExpression += fmt.Sprintf(", %s = if_not_exists(%s, :zero) + %s", name, name, value)
+		input.ExpressionAttributeNames[name] = statAttributeName(stat)
+		input.ExpressionAttributeValues[value] = &types.AttributeValueMemberN{
+			Value: formatStoredScore(storedDeltas[stat]),
+		}
+	}
+	input.UpdateExpression = &updateExpression
+
+	// Retry when an in-flight transaction holds the participant's item
+	var output *dynamodb.UpdateItemOutput
+	err = r.retryConflicts(ctx, func() error {
+		var err error
+		output, err = r.dynamoClient.UpdateItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			if frozenErr := frozenError(namespacedUserID, conditionErr.Item); frozenErr != nil {
+				return nil, frozenErr
+			}
+		}
+		return nil, fmt.Errorf(
+			"failed to update stats in DynamoDB: %w",
+			err,
+		)
+	}
+
+	storedTotals := make(map[string]float64, len(stats))
+	for _, stat := range stats {
+		var storedTotal float64
+		if err := attributevalue.Unmarshal(output.Attributes[statAttributeName(stat)], &storedTotal); err != nil {
+			return nil, fmt.Errorf(
+				"failed to unmarshal updated stat: %w",
+				err,
+			)
+		}
+		storedTotals[stat] = storedTotal
+	}
+	storedResults := func() map[string]customTypes.MemberScore {
+		results := make(map[string]customTypes.MemberScore, len(stats))
+		for _, stat := range stats {
+			results[stat] = customTypes.MemberScore{
+				Member:     namespacedUserID,
+				Score:      r.displayScore(storedTotals[stat]),
+				ComputedAt: utils.GetCurrTimeStamp(),
+			}
+		}
+		return results
+	}
+
+	// A rebuild still in progress picks the write up from DynamoDB
+	if !cacheReady {
+		r.syncSkippedWrites(ctx, leaderboardID, leaderboardEndTime, namespacedUserID)
+		return storedResults(), nil
+	}
+
+	// Update every stat leaderboard, reading the new standings in the same
+	// round trip. Members new to the leaderboard join it at zero.
+	pipe := r.redisClient.TxPipeline()
+	r.noteRebuildWrites(ctx, leaderboardID, pipe, namespacedUserID)
+	pipe.ZIncrBy(ctx, r.getRedisKey(leaderboardID), 0, namespacedUserID)
+	scoreCmds := make(map[string]*redis.FloatCmd, len(stats))
+	rankCmds := make(map[string]*redis.IntCmd, len(stats))
+	for _, stat := range stats {
+		statKey := r.getStatKey(leaderboardID, stat)
+		scoreCmds[stat] = pipe.ZIncrBy(ctx, statKey, storedDeltas[stat], namespacedUserID)
+		rankCmds[stat] = pipe.ZRevRank(ctx, statKey, namespacedUserID)
+		r.setupLeaderboardExpiry(ctx, statKey, leaderboardEndTime, pipe)
+	}
+	if ttlEnabled {
+		r.trackParticipantExpiry(ctx, leaderboardID, namespacedUserID, expiresAt, leaderboardEndTime, pipe)
+	}
+
+	// The write is durable, so a cache failure is repaired later rather
+	// than returned; a retry would apply it twice
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.queueRepair(leaderboardID, err, namespacedUserID)
+		return storedResults(), nil
+	}
+
+	results := make(map[string]customTypes.MemberScore, len(stats))
+	for _, stat := range stats {
+		results[stat] = customTypes.MemberScore{
+			Member:     namespacedUserID,
+			Score:      r.displayScore(scoreCmds[stat].Val()),
+			ComputedAt: utils.GetCurrTimeStamp(),
+			Rank:       rankCmds[stat].Val() + 1, // Convert to 1-based rank
+		}
+	}
+
+	return results, nil
+}
+
+// GetTopNByStat retrieves the top N participants ranked by a named stat
+func (r *ParticipantRepo) GetTopNByStat(
+	ctx context.Context,
+	leaderboardID string,
+	stat string,
+	n int64,
+	leaderboardEndTime time.Time,
+) ([]customTypes.MemberScore, error) {
+	if err := r.checkStat(stat); err != nil {
+		return nil, err
+	}
+
+	// Ensure the leaderboard exists in Redis
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return nil, err
+	}
+
+	results, err := r.redisClient.ZRevRangeWithScores(ctx, r.getStatKey(leaderboardID, stat), 0, n-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get top N participants by stat from Redis: %w",
+			err,
+		)
+	}
+
+	participants := make([]customTypes.MemberScore, len(results))
+	for i, result := range results {
+		participants[i] = customTypes.MemberScore{
+			Member:     result.Member.(string),
+			Score:      r.displayScore(result.Score),
+			ComputedAt: utils.GetCurrTimeStamp(),
+			Rank:       int64(i + 1),
+		}
+	}
+
+	return participants, nil
+}
+
+// GetParticipantRankByStat retrieves a participant's total and rank for a
+// named stat. Participants that never recorded the stat are not found.
+func (r *ParticipantRepo) GetParticipantRankByStat(
+	ctx context.Context,
+	leaderboardID string,
+	stat string,
+	namespacedUserID string,
+	leaderboardEndTime time.Time,
+) (*customTypes.MemberScore, error) {
+	if err := r.checkStat(stat); err != nil {
+		return nil, err
+	}
+
+	// Ensure the leaderboard exists in Redis
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return nil, err
+	}
+
+	statKey := r.getStatKey(leaderboardID, stat)
+	pipe := r.redisClient.Pipeline()
+	scoreCmd := pipe.ZScore(ctx, statKey, namespacedUserID)
+	rankCmd := pipe.ZRevRank(ctx, statKey, namespacedUserID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, customTypes.ErrParticipantNotFound
+		}
+		return nil, fmt.Errorf(
+			"failed to get participant stat rank: %w",
+			err,
+		)
+	}
+
+	return &customTypes.MemberScore{
+		Member:     namespacedUserID,
+		Score:      r.displayScore(scoreCmd.Val()),
+		ComputedAt: utils.GetCurrTimeStamp(),
+		Rank:       rankCmd.Val() + 1, // Convert to 1-based rank
+	}, nil
+}
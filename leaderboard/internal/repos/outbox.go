@@ -0,0 +1,164 @@
+package repos
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// DefaultOutboxTableName is the DynamoDB table holding undispatched events
+const DefaultOutboxTableName = "PlatformLeaderboardOutbox"
+
+// outboxTableName returns the table holding undispatched events
+func (r *ParticipantRepo) outboxTableName() string {
+	if r.config.OutboxTableName != "" {
+		return r.config.OutboxTableName
+	}
+
+	return DefaultOutboxTableName
+}
+
+// outboxKey returns the DynamoDB key of an outbox event
+func outboxKey(leaderboardID string, eventID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"leaderboardID": &types.AttributeValueMemberS{Value: leaderboardID},
+		"eventID":       &types.AttributeValueMemberS{Value: eventID},
+	}
+}
+
+// UpdateScoresWithEvents applies the writes as UpdateScoresAtomically and
+// records an event for each write to leaderboardID in its outbox in the
+// same transaction, so the events exist if and only if the writes were
+// applied
+func (r *ParticipantRepo) UpdateScoresWithEvents(
+	ctx context.Context,
+	leaderboardID string,
+	writes []ScoreWrite,
+	eventType string,
+) ([]customTypes.MemberScore, error) {
+	// Order event IDs by creation time so dispatch sees the oldest first.
+	// A write retried under the same token must send the same events.
+	now := utils.GetCurrTimeStamp()
+	token, stampedAt, idempotent := utils.WriteToken(ctx)
+	if idempotent {
+		now = stampedAt
+	}
+	var items []types.TransactWriteItem
+	for _, write := range writes {
+		if write.LeaderboardID != leaderboardID {
+			continue
+		}
+
+		suffix := make([]byte, 8)
+		if idempotent {
+			sum := sha256.Sum256([]byte(token + "\x00" + write.NamespacedUserID))
+			copy(suffix, sum[:])
+		} else if _, err := rand.Read(suffix); err != nil {
+			return nil, fmt.Errorf(
+				"failed to generate event ID: %w",
+				err,
+			)
+		}
+		event := &models.OutboxEventModel{
+			LeaderboardID:    leaderboardID,
+			EventID:          fmt.Sprintf("%019d-%s", now.UnixNano(), hex.EncodeToString(suffix)),
+			Type:             eventType,
+			NamespacedUserID: write.NamespacedUserID,
+			ScoreDelta:       write.ScoreDelta,
+			CreatedAt:        now,
+			RequestID:        utils.RequestID(ctx),
+		}
+
+		item, err := attributevalue.MarshalMap(event)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to marshal outbox event: %w",
+				err,
+			)
+		}
+		items = append(items, types.TransactWriteItem{
+			Put: &types.Put{
+				TableName: aws.String(r.outboxTableName()),
+				Item:      item,
+			},
+		})
+	}
+
+	return r.updateScoresAtomically(ctx, writes, items)
+}
+
+// ListOutboxEvents returns up to limit undispatched events of the
+// leaderboard, oldest first
+func (r *ParticipantRepo) ListOutboxEvents(
+	ctx context.Context,
+	leaderboardID string,
+	limit int32,
+) ([]customTypes.OutboxEvent, error) {
+	output, err := r.dynamoClient.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.outboxTableName()),
+		KeyConditionExpression: aws.String("leaderboardID = :leaderboardID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":leaderboardID": &types.AttributeValueMemberS{Value: leaderboardID},
+		},
+		ConsistentRead: aws.Bool(true),
+		Limit:          aws.Int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to query outbox events from DynamoDB: %w",
+			err,
+		)
+	}
+
+	var items []models.OutboxEventModel
+	if err := attributevalue.UnmarshalListOfMaps(output.Items, &items); err != nil {
+		return nil, fmt.Errorf(
+			"failed to unmarshal outbox events: %w",
+			err,
+		)
+	}
+
+	events := make([]customTypes.OutboxEvent, len(items))
+	for i, item := range items {
+		events[i] = customTypes.OutboxEvent{
+			EventID:          item.EventID,
+			Type:             item.Type,
+			NamespacedUserID: item.NamespacedUserID,
+			ScoreDelta:       item.ScoreDelta,
+			CreatedAt:        item.CreatedAt,
+			RequestID:        item.RequestID,
+		}
+	}
+
+	return events, nil
+}
+
+// DeleteOutboxEvent removes a dispatched event from the outbox
+func (r *ParticipantRepo) DeleteOutboxEvent(
+	ctx context.Context,
+	leaderboardID string,
+	eventID string,
+) error {
+	_, err := r.dynamoClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.outboxTableName()),
+		Key:       outboxKey(leaderboardID, eventID),
+	})
+	if err != nil {
+		return fmt.Errorf(
+			"failed to delete outbox event from DynamoDB: %w",
+			err,
+		)
+	}
+
+	return nil
+}
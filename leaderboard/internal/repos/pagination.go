@@ -0,0 +1,160 @@
+package repos
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// pageCursor anchors a page to the (score, member) pair of the last entry
+// returned. Score is the stored Redis score, so it compares exactly.
+type pageCursor struct {
+	Score  float64 `json:"s"`
+	Member string  `json:"m"`
+}
+
+// encodeCursor returns the opaque form of a cursor
+func encodeCursor(cursor pageCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor parses a cursor produced by encodeCursor
+func decodeCursor(encoded string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, customTypes.ErrInvalidCursor
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Member == "" {
+		return nil, customTypes.ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// after reports whether z comes after the cursor in leaderboard order:
+// score descending, ties broken by member in reverse lexicographic order
+func (c *pageCursor) after(z redis.Z) bool {
+	if z.Score != c.Score {
+		return z.Score < c.Score
+	}
+
+	return z.Member.(string) < c.Member
+}
+
+// GetPage returns up to limit participants following the cursor, or from
+// the top when cursor is empty. Pages are anchored to the last entry's score
+// and member rather than an offset, so score changes between pages do not
+// produce duplicates or gaps among participants whose scores are unchanged.
+func (r *ParticipantRepo) GetPage(
+	ctx context.Context,
+	leaderboardID string,
+	cursor string,
+	limit int64,
+	leaderboardEndTime time.Time,
+) (*customTypes.Page, error) {
+	if limit <= 0 {
+		return &customTypes.Page{}, nil
+	}
+
+	var anchor *pageCursor
+	if cursor != "" {
+		var err error
+		anchor, err = decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	redisKey := r.getRedisKey(leaderboardID)
+
+	// Ensure the leaderboard exists in Redis
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return nil, err
+	}
+
+	// Read from the anchor's score down, skipping the members tied with it
+	// that were already returned. Extra entries are fetched so the skipped
+	// ties still leave a full page; one more tells whether a page follows.
+	maxScore := "+inf"
+	if anchor != nil {
+		maxScore = strconv.FormatFloat(anchor.Score, 'g', -1, 64)
+	}
+	var results []redis.Z
+	var offset int64
+	for int64(len(results)) <= limit {
+		batch, err := r.redisClient.ZRevRangeByScoreWithScores(ctx, redisKey, &redis.ZRangeBy{
+			Max:    maxScore,
+			Min:    "-inf",
+			Offset: offset,
+			Count:  limit + 1,
+		}).Result()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to get leaderboard page from Redis: %w",
+				err,
+			)
+		}
+		for _, z := range batch {
+			if anchor == nil || anchor.after(z) {
+				results = append(results, z)
+			}
+		}
+		if int64(len(batch)) < limit+1 {
+			break
+		}
+		offset += int64(len(batch))
+	}
+
+	page := &customTypes.Page{}
+	if int64(len(results)) > limit {
+		results = results[:limit]
+		last := results[limit-1]
+		page.NextCursor = encodeCursor(pageCursor{
+			Score:  last.Score,
+			Member: last.Member.(string),
+		})
+	}
+	if len(results) == 0 {
+		return page, nil
+	}
+
+	// Ranks count on from the first entry's current position
+	firstRank, err := r.redisClient.ZRevRank(ctx, redisKey, results[0].Member.(string)).Result()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf(
+			"failed to get participant rank: %w",
+			err,
+		)
+	}
+
+	page.Entries = make([]customTypes.MemberScore, len(results))
+	for i, result := range results {
+		page.Entries[i] = customTypes.MemberScore{
+			Member:     result.Member.(string),
+			Score:      r.displayScore(result.Score),
+			ComputedAt: utils.GetCurrTimeStamp(),
+		}
+		if err == nil {
+			page.Entries[i].Rank = firstRank + int64(i) + 1
+		}
+	}
+
+	// Report rank movement since the last snapshot
+	if err := r.annotateRankDeltas(ctx, leaderboardID, leaderboardEndTime, page.Entries); err != nil {
+		return nil, err
+	}
+	if err := r.annotateTiers(ctx, redisKey, page.Entries); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
@@ -0,0 +1,161 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// DefaultSyncConcurrency is how many partitions are read and pages
+	// processed at once during a sync unless configured otherwise
+	DefaultSyncConcurrency = 4
+	// DefaultSyncBatchSize is how many members a sync adds per ZADD unless
+	// configured otherwise
+	DefaultSyncBatchSize = 500
+)
+
+// syncConcurrency returns how many partitions are read and pages processed
+// at once during a sync
+func (r *ParticipantRepo) syncConcurrency() int {
+	if r.config.SyncConcurrency > 0 {
+		return r.config.SyncConcurrency
+	}
+
+	return DefaultSyncConcurrency
+}
+
+// syncBatchSize returns how many members a sync adds per ZADD
+func (r *ParticipantRepo) syncBatchSize() int {
+	if r.config.SyncBatchSize > 0 {
+		return r.config.SyncBatchSize
+	}
+
+	return DefaultSyncBatchSize
+}
+
+// queryPartitions reads every page of the given partitions, querying up to
+// syncConcurrency partitions at once and handing pages to as many workers.
+// Pages of one partition are fetched in order, but the next page is fetched
+// while earlier ones are processed. handle may be called concurrently. The
+// first error, from a query or from handle, stops the remaining reads and
+// is returned.
+func (r *ParticipantRepo) queryPartitions(
+	ctx context.Context,
+	partitionKeys []string,
+	buildInput func(partitionKey string) *dynamodb.QueryInput,
+	handle func(items []map[string]types.AttributeValue) error,
+) error {
+	concurrency := r.syncConcurrency()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+		cancel()
+	}
+
+	// Process pages as they arrive
+	pages := make(chan []map[string]types.AttributeValue, concurrency)
+	var workers sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for items := range pages {
+				// Drain the remaining pages once the sync has failed
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := handle(items); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	// Read up to concurrency partitions at once
+	var readers sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for _, partitionKey := range partitionKeys {
+		select {
+		case <-ctx.Done():
+		case slots <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		readers.Add(1)
+		go func(partitionKey string) {
+			defer readers.Done()
+			defer func() { <-slots }()
+
+			paginator := dynamodb.NewQueryPaginator(r.dynamoClient, buildInput(partitionKey))
+			for paginator.HasMorePages() {
+				page, err := paginator.NextPage(ctx)
+				if err != nil {
+					fail(fmt.Errorf(
+						"failed to query DynamoDB table: %w",
+						err,
+					))
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case pages <- page.Items:
+				}
+			}
+		}(partitionKey)
+	}
+
+	readers.Wait()
+	close(pages)
+	workers.Wait()
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// A cancelled caller stops the reads without a query error
+	return ctx.Err()
+}
+
+// syncQueryInput builds the query reading one partition during a sync
+func (r *ParticipantRepo) syncQueryInput(
+	partitionKey string,
+	projection string,
+	projectionNames map[string]string,
+) *dynamodb.QueryInput {
+	keyCondition, keyValues := r.config.KeySchema.PartitionQuery(partitionKey)
+
+	// An empty projection reads whole items
+	if projection == "" {
+		return &dynamodb.QueryInput{
+			TableName:                 aws.String(r.tableName),
+			KeyConditionExpression:    aws.String(keyCondition),
+			ExpressionAttributeValues: keyValues,
+			ExpressionAttributeNames:  r.config.KeySchema.conditionNames(keyCondition),
+		}
+	}
+
+	return &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		KeyConditionExpression:    aws.String(keyCondition),
+		ExpressionAttributeValues: keyValues,
+		ProjectionExpression:      aws.String(projection),
+		ExpressionAttributeNames:  projectionNames,
+	}
+}
@@ -2,6 +2,7 @@ package repos
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,29 +10,77 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"github.com/kgen-protocol/platform-libs/leaderboard/internal/customTypes"
-	"github.com/kgen-protocol/platform-libs/leaderboard/internal/models"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
 	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// DefaultTableName is the DynamoDB table holding participant scores
+const DefaultTableName = "PlatformLeaderboardScores"
+
 // ParticipantRepo handles data persistence for leaderboard participants
 type ParticipantRepo struct {
 	dynamoClient *dynamodb.Client
 	redisClient  *redis.Client
 	tableName    string
+	config       Config
+	renewals     *cacheRenewals
+	repairs      *repairQueue
+	conflicts    *conflictCounters
+	batchWriter  *BatchWriter
 }
 
 // NewParticipantRepo creates a new repository instance
 func NewParticipantRepo(
 	dynamoClient *dynamodb.Client,
 	redisClient *redis.Client,
+	config Config,
 ) *ParticipantRepo {
+	if config.TableName == "" {
+		config.TableName = DefaultTableName
+	}
+	config.KeySchema = config.KeySchema.WithDefaults()
+
+	// Act as the configured role, beneath the timeout so that credential
+	// refreshes count towards it
+	if config.AssumeRoleARN != "" {
+		dynamoClient = withAssumedRole(dynamoClient, config.AssumeRoleARN, config.AssumeRoleExternalID)
+	}
+
+	// Bound each store operation without changing the caller's clients
+	if config.DynamoTimeout > 0 {
+		dynamoClient = withDynamoTimeout(dynamoClient, config.DynamoTimeout)
+	}
+	if config.RedisTimeout > 0 {
+		redisClient = withRedisTimeout(redisClient, config.RedisTimeout)
+	}
+	if len(config.DynamoAPIOptions) > 0 {
+		dynamoClient = dynamodb.New(dynamoClient.Options(), func(o *dynamodb.Options) {
+			o.APIOptions = append(o.APIOptions, config.DynamoAPIOptions...)
+		})
+	}
+	if len(config.RedisHooks) > 0 {
+		// Hooks run in the order added, so these sit beneath the timeout
+		// hook. Without a timeout the caller's client is copied first.
+		if config.RedisTimeout <= 0 {
+			redisClient = redisClient.WithTimeout(redisClient.Options().ReadTimeout)
+		}
+		for _, hook := range config.RedisHooks {
+			redisClient.AddHook(hook)
+		}
+	}
+
 	return &ParticipantRepo{
 		dynamoClient: dynamoClient,
 		redisClient:  redisClient,
-		tableName:    "PlatformLeaderboardScores",
+		tableName:    config.TableName,
+		config:       config,
+		renewals:     &cacheRenewals{},
+		repairs:      &repairQueue{},
+		conflicts:    &conflictCounters{},
+		batchWriter:  NewBatchWriter(dynamoClient, config.BatchWriteLimits),
 	}
 }
 
@@ -67,12 +116,21 @@ func (r *ParticipantRepo) GetTopNParticipants(
 	participants := make([]customTypes.MemberScore, len(results))
 	for i, result := range results {
 		participants[i] = customTypes.MemberScore{
-			Member: result.Member.(string),
-			Score:  result.Score,
-			Rank:   int64(i + 1), // Redis ranks are 0-based, so add 1 for human-readable ranks
+			Member:     result.Member.(string),
+			Score:      r.displayScore(result.Score),
+			ComputedAt: utils.GetCurrTimeStamp(),
+			Rank:       int64(i + 1), // Redis ranks are 0-based, so add 1 for human-readable ranks
 		}
 	}
 
+	// Report rank movement since the last snapshot
+	if err := r.annotateRankDeltas(ctx, leaderboardID, leaderboardEndTime, participants); err != nil {
+		return nil, err
+	}
+	if err := r.annotateTiers(ctx, redisKey, participants); err != nil {
+		return nil, err
+	}
+
 	return participants, nil
 }
 
@@ -95,9 +153,7 @@ func (r *ParticipantRepo) GetParticipantScoreAndRank(
 	score, err := r.redisClient.ZScore(ctx, redisKey, namespacedUserID).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return nil, fmt.Errorf(
-				"participant not found in leaderboard",
-			)
+			return nil, customTypes.ErrParticipantNotFound
 		}
 		return nil, fmt.Errorf(
 			"failed to get participant score: %w",
@@ -114,81 +170,300 @@ func (r *ParticipantRepo) GetParticipantScoreAndRank(
 		)
 	}
 
-	return &customTypes.MemberScore{
-		Member: namespacedUserID,
-		Score:  score,
-		Rank:   rank + 1, // Convert to 1-based rank
-	}, nil
+	participant := []customTypes.MemberScore{{
+		Member:     namespacedUserID,
+		Score:      r.displayScore(score),
+		ComputedAt: utils.GetCurrTimeStamp(),
+		Rank:       rank + 1, // Convert to 1-based rank
+	}}
+
+	// Report rank movement since the last snapshot
+	if err := r.annotateRankDeltas(ctx, leaderboardID, leaderboardEndTime, participant); err != nil {
+		return nil, err
+	}
+	if err := r.annotateTiers(ctx, redisKey, participant); err != nil {
+		return nil, err
+	}
+
+	return &participant[0], nil
 }
 
-// UpdateScore updates a participant's score in both DynamoDB and Redis
+// UpdateScore updates a participant's score in both DynamoDB and Redis and
+// returns the new total and rank. While the cache is being rebuilt the rank
+// is zero and the score is the stored total.
 func (r *ParticipantRepo) UpdateScore(
 	ctx context.Context,
 	leaderboardID string,
 	namespacedUserID string,
 	scoreDelta float64,
+	attributes map[string]string,
 	leaderboardEndTime time.Time,
-) error {
-	redisKey := r.getRedisKey(leaderboardID)
+) (*customTypes.MemberScore, error) {
+	attributes = r.filterAttributes(attributes)
 
-	dynamoKey, err := attributevalue.MarshalMap(map[string]interface{}{
-		"leaderboardID":    leaderboardID,
-		"namespacedUserID": namespacedUserID,
-	})
+	// Reject deltas that cannot be stored exactly
+	storedDelta, err := r.storedScore(scoreDelta)
 	if err != nil {
-		return fmt.Errorf("failed to marshal key: %w", err)
+		return nil, err
 	}
 
+	// Keep post-deadline writes from changing final standings
 	now := utils.GetCurrTimeStamp()
+	late, err := r.checkWriteDeadline(ctx, leaderboardID, leaderboardEndTime, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure Redis key exists before writing, so a cold rebuild never reads
+	// this write from DynamoDB and then has it applied a second time
+	cacheReady, err := r.prepareCacheForWrite(ctx, leaderboardID, leaderboardEndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	input, expiresAt, ttlEnabled, err := r.buildScoreUpdate(
+		leaderboardID,
+		namespacedUserID,
+		storedDelta,
+		attributes,
+		late,
+		now,
+		leaderboardEndTime,
+		utils.RequestID(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retry when an in-flight transaction holds the participant's item
+	var output *dynamodb.UpdateItemOutput
+	err = r.retryConflicts(ctx, func() error {
+		var err error
+		output, err = r.dynamoClient.UpdateItem(ctx, input)
+		return err
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			if frozenErr := frozenError(namespacedUserID, conditionErr.Item); frozenErr != nil {
+				return nil, frozenErr
+			}
+			return nil, &customTypes.ScoreOutOfRangeError{
+				Score: scoreDelta,
+				Limit: models.MaxExactScore,
+			}
+		}
+		return nil, fmt.Errorf(
+			"failed to update score in DynamoDB: %w",
+			err,
+		)
+	}
+
+	var storedTotal float64
+	if err := attributevalue.Unmarshal(output.Attributes["score"], &storedTotal); err != nil {
+		return nil, fmt.Errorf(
+			"failed to unmarshal updated score: %w",
+			err,
+		)
+	}
+
+	// A rebuild still in progress picks the write up from DynamoDB
+	if !cacheReady {
+		r.syncSkippedWrites(ctx, leaderboardID, leaderboardEndTime, namespacedUserID)
+
+		// Rolling buckets are not rebuilt, so they take the write now
+		if r.config.RollingWindow > 0 {
+			pipe := r.redisClient.Pipeline()
+			r.recordRollingScore(ctx, leaderboardID, namespacedUserID, storedDelta, now, pipe)
+			if _, err := pipe.Exec(ctx); err != nil {
+				fmt.Printf("%sError recording rolling window score: %v\n", utils.LogPrefix(ctx), err)
+			}
+		}
+
+		return &customTypes.MemberScore{
+			Member:     namespacedUserID,
+			Score:      r.displayScore(storedTotal),
+			ComputedAt: utils.GetCurrTimeStamp(),
+		}, nil
+	}
+
+	// Create a pipeline for Redis operations
+	pipe := r.redisClient.Pipeline()
+
+	// Update Redis sorted set, reading the new standing in the same round trip
+	incrementCmd := r.incrementIfCached(ctx, leaderboardID, namespacedUserID, storedDelta, pipe)
+	r.recordRollingScore(ctx, leaderboardID, namespacedUserID, storedDelta, now, pipe)
+	if ttlEnabled {
+		r.trackParticipantExpiry(ctx, leaderboardID, namespacedUserID, expiresAt, leaderboardEndTime, pipe)
+	}
+	if len(attributes) > 0 {
+		r.indexMemberAttributes(ctx, leaderboardID, namespacedUserID, attributes, leaderboardEndTime, pipe)
+	}
+
+	// Execute all Redis operations. The write is durable, so a cache
+	// failure is repaired later rather than returned; a retry would apply
+	// it twice.
+	_, err = pipe.Exec(ctx)
+	if err != nil && err != redis.Nil {
+		r.queueRepair(leaderboardID, err, namespacedUserID)
+		return &customTypes.MemberScore{
+			Member:     namespacedUserID,
+			Score:      r.displayScore(storedTotal),
+			ComputedAt: utils.GetCurrTimeStamp(),
+		}, nil
+	}
+
+	// A cache dropped since the check picks the write up on its rebuild
+	cachedTotal, rank, ok := cachedIncrement(incrementCmd)
+	if !ok {
+		return &customTypes.MemberScore{
+			Member:     namespacedUserID,
+			Score:      r.displayScore(storedTotal),
+			ComputedAt: utils.GetCurrTimeStamp(),
+		}, nil
+	}
+
+	return &customTypes.MemberScore{
+		Member:     namespacedUserID,
+		Score:      r.displayScore(cachedTotal),
+		ComputedAt: utils.GetCurrTimeStamp(),
+		Rank:       rank + 1, // Convert to 1-based rank
+	}, nil
+}
+
+// buildScoreUpdate builds the DynamoDB update adding storedDelta to a
+// participant's item, along with the participant's new expiry when
+// participants expire
+func (r *ParticipantRepo) buildScoreUpdate(
+	leaderboardID string,
+	namespacedUserID string,
+	storedDelta float64,
+	attributes map[string]string,
+	late bool,
+	now time.Time,
+	leaderboardEndTime time.Time,
+	requestID string,
+) (*dynamodb.UpdateItemInput, time.Time, bool, error) {
+	// Regional deltas live in their own partition under the additive strategy
+	dynamoKey := r.config.KeySchema.ItemKey(
+		r.writePartitionKey(leaderboardID, namespacedUserID),
+		namespacedUserID,
+	)
 
 	// Prepare update expression and attribute values
 	updateExpression := "SET score = if_not_exists(score, :zero) + :incVal, updated_at = :updatedAt"
 	expressionAttributeValues := make(map[string]types.AttributeValue)
 	expressionAttributeValues[":incVal"] = &types.AttributeValueMemberN{
-		Value: fmt.Sprintf("%f", scoreDelta),
+		Value: formatStoredScore(storedDelta),
 	}
 	expressionAttributeValues[":zero"] = &types.AttributeValueMemberN{
 		Value: "0",
 	}
 	expressionAttributeValues[":updatedAt"] = &types.AttributeValueMemberN{
-		Value: now.Format(time.RFC3339),
+		Value: fmt.Sprintf("%d", now.Unix()),
+	}
+
+	// Tag the write with the originating region
+	if r.config.Region != "" {
+		updateExpression += ", #region = :region"
+		expressionAttributeValues[":region"] = &types.AttributeValueMemberS{
+			Value: r.config.Region,
+		}
+	}
+
+	expressionAttributeNames := make(map[string]string)
+	if r.config.Region != "" {
+		expressionAttributeNames["#region"] = "region"
+	}
+
+	// Keep the plain IDs on items whose key attributes are named differently
+	if r.config.KeySchema.PartitionKey != "leaderboardID" {
+		updateExpression += ", leaderboardID = :leaderboardID"
+		expressionAttributeValues[":leaderboardID"] = &types.AttributeValueMemberS{
+			Value: leaderboardID,
+		}
+	}
+	if r.config.KeySchema.SortKey != "namespacedUserID" {
+		updateExpression += ", namespacedUserID = :namespacedUserID"
+		expressionAttributeValues[":namespacedUserID"] = &types.AttributeValueMemberS{
+			Value: namespacedUserID,
+		}
+	}
+
+	// Tag the item's entity type in single-table mode
+	if r.config.KeySchema.EntityTypeAttribute != "" {
+		updateExpression += ", #entityType = :entityType"
+		expressionAttributeNames["#entityType"] = r.config.KeySchema.EntityTypeAttribute
+		expressionAttributeValues[":entityType"] = &types.AttributeValueMemberS{
+			Value: ParticipantEntityType,
+		}
+	}
+
+	// Record which request last changed the score, for tracing
+	if requestID != "" {
+		updateExpression += ", last_request_id = :requestID"
+		expressionAttributeValues[":requestID"] = &types.AttributeValueMemberS{
+			Value: requestID,
+		}
+	}
+
+	// Record the late part of the score separately for review
+	if late {
+		updateExpression += ", #lateScore = if_not_exists(#lateScore, :zero) + :incVal"
+		expressionAttributeNames["#lateScore"] = lateScoreAttribute
+	}
+
+	// Keep the filterable attributes on the item for cache rebuilds
+	if len(attributes) > 0 {
+		attributeValues, err := attributevalue.MarshalMap(attributes)
+		if err != nil {
+			return nil, time.Time{}, false, fmt.Errorf("failed to marshal filter attributes: %w", err)
+		}
+		updateExpression += ", #filterAttrs = :filterAttrs"
+		expressionAttributeNames["#filterAttrs"] = filterAttributesName
+		expressionAttributeValues[":filterAttrs"] = &types.AttributeValueMemberM{
+			Value: attributeValues,
+		}
+	}
+
+	// Push the participant's expiry forward on every write
+	expiresAt, ttlEnabled := r.participantExpiry(now, leaderboardEndTime)
+	if ttlEnabled {
+		updateExpression += ", #ttl = :expiresAt"
+		expressionAttributeNames["#ttl"] = r.ttlAttributeName()
+		expressionAttributeValues[":expiresAt"] = &types.AttributeValueMemberN{
+			Value: fmt.Sprintf("%d", expiresAt.Unix()),
+		}
 	}
 
 	// Update DynamoDB
-	_, err = r.dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+	input := &dynamodb.UpdateItemInput{
 		TableName:                 aws.String(r.tableName),
 		Key:                       dynamoKey,
 		UpdateExpression:          aws.String(updateExpression),
 		ExpressionAttributeValues: expressionAttributeValues,
-	})
-	if err != nil {
-		return fmt.Errorf(
-			"failed to update score in DynamoDB: %w",
-			err,
-		)
+		ReturnValues:              types.ReturnValueUpdatedNew,
 	}
-
-	// Create a pipeline for Redis operations
-	pipe := r.redisClient.Pipeline()
-
-	// Update Redis sorted set
-	pipe.ZIncrBy(ctx, redisKey, scoreDelta, namespacedUserID)
-
-	// Ensure Redis key exists and has proper expiry
-	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
-		return err
+	if len(expressionAttributeNames) > 0 {
+		input.ExpressionAttributeNames = expressionAttributeNames
 	}
 
-	// Execute all Redis operations
-	_, err = pipe.Exec(ctx)
-	if err != nil {
-		return fmt.Errorf(
-			"failed to update Redis sorted set: %w",
-			err,
-		)
+	// Frozen participants keep their score until unfrozen
+	condition := "attribute_not_exists(#frozenAt)"
+	expressionAttributeNames["#frozenAt"] = frozenAtAttribute
+	input.ExpressionAttributeNames = expressionAttributeNames
+
+	// Integer scores must stay exactly representable after the increment
+	if r.config.IntegerScores {
+		condition += " AND (" + r.totalBoundCondition(storedDelta) + ")"
+		expressionAttributeValues[":bound"] = &types.AttributeValueMemberN{
+			Value: formatStoredScore(totalBound(storedDelta)),
+		}
 	}
+	input.ConditionExpression = aws.String(condition)
+	input.ReturnValuesOnConditionCheckFailure = types.ReturnValuesOnConditionCheckFailureAllOld
 
-	return nil
+	return input, expiresAt, ttlEnabled, nil
 }
 
 // JoinLeaderboard adds a participant to the leaderboard
@@ -197,85 +472,153 @@ func (r *ParticipantRepo) JoinLeaderboard(
 	participant *models.ParticipantModel,
 	leaderboardEndTime time.Time,
 ) error {
-	redisKey := r.getRedisKey(participant.LeaderboardID)
+	// Reject initial scores that cannot be stored exactly
+	storedScore, err := r.storedScore(participant.Score)
+	if err != nil {
+		return err
+	}
 
-	// Check if the participant already exists in DynamoDB
-	dynamoKey, err := attributevalue.MarshalMap(map[string]interface{}{
-		"leaderboardID":    participant.LeaderboardID,
-		"namespacedUserID": participant.NamespacedUserID,
-	})
+	// Joins after the end are subject to the same policy as score updates
+	if _, err := r.checkWriteDeadline(
+		ctx,
+		participant.LeaderboardID,
+		leaderboardEndTime,
+		utils.GetCurrTimeStamp(),
+	); err != nil {
+		return err
+	}
+
+	// Ensure Redis key exists before writing
+	cacheReady, err := r.prepareCacheForWrite(ctx, participant.LeaderboardID, leaderboardEndTime)
 	if err != nil {
-		return fmt.Errorf(
-			"failed to marshal key: %w",
-			err,
-		)
+		return err
+	}
+
+	item, expiresAt, ttlEnabled, err := r.participantItem(participant, storedScore, leaderboardEndTime)
+	if err != nil {
+		return err
 	}
 
-	// Check if the participant exists
-	_, err = r.dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{
-		TableName: aws.String(r.tableName),
-		Key:       dynamoKey,
+	// Put the item in DynamoDB unless the participant already exists, so a
+	// rejoin never resets an accumulated score
+	_, err = r.dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(#pk)"),
+		ExpressionAttributeNames: map[string]string{
+			"#pk": r.config.KeySchema.PartitionKey,
+		},
 	})
 	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			// Paid joins must learn of it to give the new hold back
+			if r.config.IdempotentJoin && participant.EscrowHoldID == "" {
+				return nil
+			}
+			return customTypes.ErrAlreadyJoined
+		}
 		return fmt.Errorf(
-			"failed to check if participant exists: %w",
+			"failed to put item in DynamoDB: %w",
 			err,
 		)
 	}
 
+	// A rebuild still in progress picks the participant up from DynamoDB
+	if !cacheReady {
+		r.syncSkippedWrites(ctx, participant.LeaderboardID, leaderboardEndTime, participant.NamespacedUserID)
+		return nil
+	}
+
+	// Create a transaction for Redis operations, so a rebuild swapping in
+	// its keys either sees the join noted or comes after it
+	pipe := r.redisClient.TxPipeline()
+	r.noteRebuildWrites(ctx, participant.LeaderboardID, pipe, participant.NamespacedUserID)
+
+	// Add the participant to the Redis sorted set, unless the cache was
+	// dropped since the check
+	r.addIfCached(ctx, participant.LeaderboardID, []redis.Z{{
+		Score:  storedScore,
+		Member: participant.NamespacedUserID,
+	}}, pipe)
+	if ttlEnabled {
+		r.trackParticipantExpiry(ctx, participant.LeaderboardID, participant.NamespacedUserID, expiresAt, leaderboardEndTime, pipe)
+	}
+
+	// Execute all Redis operations; the participant is stored, so a cache
+	// failure is repaired later
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		r.queueRepair(participant.LeaderboardID, err, participant.NamespacedUserID)
+	}
+
+	return nil
+}
+
+// participantItem builds the DynamoDB item of a participant stored with
+// storedScore, keyed by the configured schema. It also returns the
+// participant's expiry when participants expire.
+func (r *ParticipantRepo) participantItem(
+	participant *models.ParticipantModel,
+	storedScore float64,
+	leaderboardEndTime time.Time,
+) (map[string]types.AttributeValue, time.Time, bool, error) {
+	dynamoKey := r.config.KeySchema.ItemKey(
+		r.shardPartitionKey(participant.LeaderboardID, participant.NamespacedUserID),
+		participant.NamespacedUserID,
+	)
+
+	if err := checkPlaintextMetadata(participant.Metadata); err != nil {
+		return nil, time.Time{}, false, err
+	}
+
 	// Update the participant's timestamp
 	participant.UpdatedAt = utils.GetCurrTimeStamp()
 
 	// Marshal the participant model directly
 	item, err := attributevalue.MarshalMap(participant)
 	if err != nil {
-		return fmt.Errorf(
+		return nil, time.Time{}, false, fmt.Errorf(
 			"failed to marshal participant model: %w",
 			err,
 		)
 	}
 
-	// Add created_at field
-	item["created_at"] = &types.AttributeValueMemberN{
-		Value: fmt.Sprintf("%d", participant.UpdatedAt.Unix()),
+	// Key the item by the configured schema
+	for name, value := range dynamoKey {
+		item[name] = value
 	}
-
-	// Put the item in DynamoDB
-	_, err = r.dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      item,
-	})
-	if err != nil {
-		return fmt.Errorf(
-			"failed to put item in DynamoDB: %w",
-			err,
-		)
+	if r.config.KeySchema.EntityTypeAttribute != "" {
+		item[r.config.KeySchema.EntityTypeAttribute] = &types.AttributeValueMemberS{
+			Value: ParticipantEntityType,
+		}
 	}
 
-	// Create a pipeline for Redis operations
-	pipe := r.redisClient.Pipeline()
-
-	// Add the participant to the Redis sorted set
-	pipe.ZAdd(ctx, redisKey, redis.Z{
-		Score:  participant.Score,
-		Member: participant.NamespacedUserID,
-	})
+	// Store the score in the leaderboard's units
+	item["score"] = &types.AttributeValueMemberN{
+		Value: formatStoredScore(storedScore),
+	}
 
-	// Ensure Redis key exists and has proper expiry
-	if err := r.ensureLeaderboardExists(ctx, participant.LeaderboardID, leaderboardEndTime); err != nil {
-		return err
+	// Add created_at field, and updated_at so the item is visible to
+	// incremental syncs
+	item["created_at"] = &types.AttributeValueMemberN{
+		Value: fmt.Sprintf("%d", participant.UpdatedAt.Unix()),
+	}
+	item["updated_at"] = &types.AttributeValueMemberN{
+		Value: fmt.Sprintf("%d", participant.UpdatedAt.Unix()),
 	}
 
-	// Execute all Redis operations
-	_, err = pipe.Exec(ctx)
-	if err != nil {
-		return fmt.Errorf(
-			"failed to update Redis sorted set: %w",
-			err,
-		)
+	// New items are written in the current schema
+	item[schemaVersionAttribute] = r.schemaVersionValue()
+
+	// Add the TTL attribute if participants expire
+	expiresAt, ttlEnabled := r.participantExpiry(participant.UpdatedAt, leaderboardEndTime)
+	if ttlEnabled {
+		item[r.ttlAttributeName()] = &types.AttributeValueMemberN{
+			Value: fmt.Sprintf("%d", expiresAt.Unix()),
+		}
 	}
 
-	return nil
+	return item, expiresAt, ttlEnabled, nil
 }
 
 // LeaveLeaderboard removes a participant from the leaderboard
@@ -291,6 +634,8 @@ func (r *ParticipantRepo) LeaveLeaderboard(
 
 	// Remove the participant from the Redis sorted set
 	pipe.ZRem(ctx, redisKey, namespacedUserID)
+	pipe.ZRem(ctx, r.getExpiriesKey(leaderboardID), namespacedUserID)
+	r.unindexMember(ctx, leaderboardID, namespacedUserID, pipe)
 
 	// Execute Redis operations
 	_, err := pipe.Exec(ctx)
@@ -301,27 +646,22 @@ func (r *ParticipantRepo) LeaveLeaderboard(
 		)
 	}
 
-	// Remove the participant from DynamoDB
-	dynamoKey, err := attributevalue.MarshalMap(map[string]interface{}{
-		"leaderboardID":    leaderboardID,
-		"namespacedUserID": namespacedUserID,
-	})
-	if err != nil {
-		return fmt.Errorf(
-			"failed to marshal key: %w",
-			err,
-		)
-	}
-
-	_, err = r.dynamoClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-		TableName: aws.String(r.tableName),
-		Key:       dynamoKey,
-	})
-	if err != nil {
-		return fmt.Errorf(
-			"failed to delete participant from DynamoDB: %w",
-			err,
-		)
+	// Remove the participant from DynamoDB, including any regional deltas
+	for _, partitionKey := range r.memberPartitionKeys(leaderboardID, namespacedUserID) {
+		dynamoKey := r.config.KeySchema.ItemKey(partitionKey, namespacedUserID)
+
+		_, err := r.dynamoClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(r.tableName),
+			Key:       dynamoKey,
+		})
+		if err != nil {
+			// The cache no longer lists a participant DynamoDB still holds
+			r.queueRepair(leaderboardID, err, namespacedUserID)
+			return fmt.Errorf(
+				"failed to delete participant from DynamoDB: %w",
+				err,
+			)
+		}
 	}
 
 	return nil
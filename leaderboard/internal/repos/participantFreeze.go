@@ -0,0 +1,121 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+const (
+	// frozenAtAttribute holds when a participant's score was frozen, in
+	// unix seconds. Score writes are conditioned on its absence.
+	frozenAtAttribute = "frozenAt"
+	// frozenReasonAttribute holds why the score was frozen
+	frozenReasonAttribute = "frozenReason"
+)
+
+// FreezeParticipant rejects further score writes to a participant until
+// UnfreezeParticipant is called. The participant keeps its score and rank.
+// Every item the participant may be written to is flagged, so the freeze
+// holds for writes from all regions. It returns ErrParticipantNotFound if
+// the participant has no item.
+func (r *ParticipantRepo) FreezeParticipant(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	reason string,
+) error {
+	if _, _, err := r.getParticipant(ctx, leaderboardID, namespacedUserID); err != nil {
+		return err
+	}
+
+	frozenAt := utils.GetCurrTimeStamp()
+	for _, partitionKey := range r.memberPartitionKeys(leaderboardID, namespacedUserID) {
+		_, err := r.dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:        aws.String(r.tableName),
+			Key:              r.config.KeySchema.ItemKey(partitionKey, namespacedUserID),
+			UpdateExpression: aws.String("SET #frozenAt = :frozenAt, #frozenReason = :frozenReason"),
+			ExpressionAttributeNames: map[string]string{
+				"#frozenAt":     frozenAtAttribute,
+				"#frozenReason": frozenReasonAttribute,
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":frozenAt":     &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", frozenAt.Unix())},
+				":frozenReason": &types.AttributeValueMemberS{Value: reason},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf(
+				"failed to freeze participant in DynamoDB: %w",
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// UnfreezeParticipant lets score writes reach a participant frozen with
+// FreezeParticipant again. Unfreezing a participant that is not frozen is a
+// no-op.
+func (r *ParticipantRepo) UnfreezeParticipant(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+) error {
+	for _, partitionKey := range r.memberPartitionKeys(leaderboardID, namespacedUserID) {
+		_, err := r.dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:           aws.String(r.tableName),
+			Key:                 r.config.KeySchema.ItemKey(partitionKey, namespacedUserID),
+			UpdateExpression:    aws.String("REMOVE #frozenAt, #frozenReason"),
+			ConditionExpression: aws.String("attribute_exists(#frozenAt)"),
+			ExpressionAttributeNames: map[string]string{
+				"#frozenAt":     frozenAtAttribute,
+				"#frozenReason": frozenReasonAttribute,
+			},
+		})
+		if err != nil {
+			// Items that were never frozen are left alone
+			var conditionErr *types.ConditionalCheckFailedException
+			if errors.As(err, &conditionErr) {
+				continue
+			}
+			return fmt.Errorf(
+				"failed to unfreeze participant in DynamoDB: %w",
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// frozenError returns the error for a write whose condition failed on item,
+// or nil if the item is not frozen
+func frozenError(
+	namespacedUserID string,
+	item map[string]types.AttributeValue,
+) *customTypes.ParticipantFrozenError {
+	frozenAt, ok := item[frozenAtAttribute].(*types.AttributeValueMemberN)
+	if !ok {
+		return nil
+	}
+
+	frozenErr := &customTypes.ParticipantFrozenError{Member: namespacedUserID}
+	if seconds, err := strconv.ParseInt(frozenAt.Value, 10, 64); err == nil {
+		frozenErr.FrozenAt = time.Unix(seconds, 0).UTC()
+	}
+	if reason, ok := item[frozenReasonAttribute].(*types.AttributeValueMemberS); ok {
+		frozenErr.Reason = reason.Value
+	}
+
+	return frozenErr
+}
@@ -2,20 +2,134 @@ package repos
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
 	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
 	"github.com/redis/go-redis/v9"
 )
 
+// RedisKey returns the Redis sorted set key for a specific leaderboard. The
+// leaderboard ID is a hash tag, so every key derived from it lands in the
+// same Redis Cluster slot and scripts may touch them together.
+//
+// Releases before the hash tag keyed leaderboards by LegacyRedisKey. Caches
+// under the old keys are not read: a leaderboard is rebuilt from DynamoDB
+// under the new keys on first use, and the old keys expire with the
+// leaderboard. Instances of both releases running side by side would each
+// miss the other's writes, so rolling upgrades keep LegacyRedisKeys set
+// until every instance runs this release, then clear it.
+func RedisKey(leaderboardID string) string {
+	return "leaderboard:{" + leaderboardID + "}"
+}
+
+// LegacyRedisKey returns the Redis sorted set key releases before RedisKey
+// used for a specific leaderboard
+func LegacyRedisKey(leaderboardID string) string {
+	return "leaderboard:" + leaderboardID
+}
+
 // getRedisKey returns the Redis key for a specific leaderboard
 func (r *ParticipantRepo) getRedisKey(leaderboardID string) string {
-	return "leaderboard:" + leaderboardID
+	if r.config.LegacyRedisKeys {
+		return r.config.RedisKeyPrefix + LegacyRedisKey(leaderboardID)
+	}
+	return r.config.RedisKeyPrefix + RedisKey(leaderboardID)
+}
+
+// RegionalPartitionKey returns the partition key holding one region's score
+// deltas for a leaderboard under the additive merge strategy
+func RegionalPartitionKey(leaderboardID, region string) string {
+	return leaderboardID + "@" + region
+}
+
+// writePartitionKey returns the partition key a participant's score
+// updates are written to
+func (r *ParticipantRepo) writePartitionKey(leaderboardID string, namespacedUserID string) string {
+	partitionKey := leaderboardID
+	if r.config.MergeStrategy == customTypes.MergeAdditive && r.config.Region != "" {
+		partitionKey = RegionalPartitionKey(leaderboardID, r.config.Region)
+	}
+
+	return r.shardPartitionKey(partitionKey, namespacedUserID)
+}
+
+// basePartitionKeys returns the unsharded partition keys contributing to a
+// leaderboard's scores
+func (r *ParticipantRepo) basePartitionKeys(leaderboardID string) []string {
+	partitionKeys := []string{leaderboardID}
+	if r.config.MergeStrategy != customTypes.MergeAdditive {
+		return partitionKeys
+	}
+
+	for _, region := range r.config.Regions {
+		partitionKeys = append(partitionKeys, RegionalPartitionKey(leaderboardID, region))
+	}
+
+	return partitionKeys
+}
+
+// readPartitionKeys returns every partition key contributing to a
+// leaderboard's scores, across all write shards
+func (r *ParticipantRepo) readPartitionKeys(leaderboardID string) []string {
+	var partitionKeys []string
+	for _, partitionKey := range r.basePartitionKeys(leaderboardID) {
+		partitionKeys = append(partitionKeys, r.shardPartitionKeys(partitionKey)...)
+	}
+
+	return partitionKeys
+}
+
+// memberPartitionKeys returns the partition keys that may hold one
+// participant's items
+func (r *ParticipantRepo) memberPartitionKeys(leaderboardID string, namespacedUserID string) []string {
+	partitionKeys := r.basePartitionKeys(leaderboardID)
+	for i, partitionKey := range partitionKeys {
+		partitionKeys[i] = r.shardPartitionKey(partitionKey, namespacedUserID)
+	}
+
+	return partitionKeys
+}
+
+// batchMembers splits members into batches of at most size
+func batchMembers(members []redis.Z, size int) [][]redis.Z {
+	var batches [][]redis.Z
+	for len(members) > size {
+		batches = append(batches, members[:size])
+		members = members[size:]
+	}
+	if len(members) > 0 {
+		batches = append(batches, members)
+	}
+
+	return batches
+}
+
+// shadowKeyTTL bounds how long an abandoned rebuild's shadow keys linger
+const shadowKeyTTL = 10 * time.Minute
+
+// swapShadowKeysScript renames each shadow key (odd KEYS) over its live key
+// (even KEYS). A shadow key that was never created means the rebuilt data
+// is empty, so the live key is removed instead.
+var swapShadowKeysScript = newScript(`
+for i = 1, #KEYS, 2 do
+	if redis.call("EXISTS", KEYS[i]) == 1 then
+		redis.call("RENAME", KEYS[i], KEYS[i + 1])
+	else
+		redis.call("DEL", KEYS[i + 1])
+	end
+end
+return #KEYS / 2
+`)
+
+// shadowKey returns the key a live key is rebuilt into before being swapped
+func shadowKey(liveKey string) string {
+	return liveKey + ":rebuilding"
 }
 
 // setupLeaderboardExpiry sets up the expiry for a leaderboard Redis key
@@ -25,84 +139,223 @@ func (r *ParticipantRepo) setupLeaderboardExpiry(
 	leaderboardEndTime time.Time,
 	pipe redis.Pipeliner,
 ) {
-	// Calculate time until expiry (24 hours after leaderboardEndTime)
-	expiryTime := leaderboardEndTime.Add(24 * time.Hour)
+	// Calculate when the cached keys expire under the expiry policy
 	now := utils.GetCurrTimeStamp()
+	expiryTime, expires := r.cacheExpiry(leaderboardEndTime, now)
 
 	// Only set expiry if it's in the future
-	if expiryTime.After(now) {
+	if expires && expiryTime.After(now) {
 		expiryDuration := expiryTime.Sub(now)
 		pipe.Expire(ctx, redisKey, expiryDuration)
 	}
 }
 
-// syncLeaderboard synchronizes the leaderboard data from DynamoDB to Redis
+// syncLeaderboard synchronizes the leaderboard data from DynamoDB to Redis.
+// progress, if not nil, is called after each page with the number of items
+// loaded so far. If ctx ends first, reading and queueing stop between pages
+// and a SyncCancelledError reports how far the sync got.
 func (r *ParticipantRepo) syncLeaderboard(
 	ctx context.Context,
 	leaderboardID string,
+	leaderboardEndTime time.Time,
 	pipe redis.Pipeliner,
+	progress func(itemsLoaded int64),
 ) error {
-	redisKey := r.getRedisKey(leaderboardID)
+	liveKey := r.getRedisKey(leaderboardID)
+	liveExpiriesKey := r.getExpiriesKey(leaderboardID)
+
+	// Build into shadow keys so readers keep seeing the live board until the
+	// rebuild is complete
+	redisKey := shadowKey(liveKey)
+	expiriesKey := shadowKey(liveExpiriesKey)
+	pipe.Del(ctx, redisKey, expiriesKey)
 
-	// Clear existing sorted set
-	pipe.Del(ctx, redisKey)
+	// Regional deltas of the same participant must be summed rather than
+	// replacing each other
+	additive := r.config.MergeStrategy == customTypes.MergeAdditive
 
-	// Create a function to process each page of results
-	processPage := func(page *dynamodb.QueryOutput, lastPage bool) bool {
-		// Unmarshal the items
-		var pageItems []map[string]interface{}
-		err := attributevalue.UnmarshalListOfMaps(page.Items, &pageItems)
+	// Items past their TTL may linger in DynamoDB for a while before being
+	// deleted, so they are skipped explicitly
+	ttlEnabled := r.itemsExpire()
+	ttlAttribute := r.ttlAttributeName()
+	nowUnix := float64(utils.GetCurrTimeStamp().Unix())
+	keySchema := r.config.KeySchema
+	projection := "#pk, #sk, score"
+	projectionNames := keySchema.KeyNames()
+	expiryAttribute := ""
+	if ttlEnabled {
+		projection += ", #ttl"
+		projectionNames["#ttl"] = ttlAttribute
+		expiryAttribute = ttlAttribute
+	}
+
+	// Filtered views are rebuilt alongside the leaderboard from the
+	// attributes stored on each item
+	var filters *filterRebuild
+	var previousFilterKeys []string
+	if len(r.config.FilterAttributes) > 0 {
+		var err error
+		previousFilterKeys, err = r.registeredFilterKeys(ctx, leaderboardID)
 		if err != nil {
-			// Log the error but continue processing
-			fmt.Printf("Error unmarshaling items: %v\n", err)
-			return true
-		}
-
-		// Add all items from this page to Redis pipeline
-		for _, item := range pageItems {
-			namespacedUserID := item["namespacedUserID"].(string)
-			score := item["score"].(float64)
-			pipe.ZAdd(ctx, redisKey, redis.Z{
-				Score:  score,
-				Member: namespacedUserID,
-			})
+			return err
 		}
+		filters = r.newFilterRebuild(leaderboardID, additive)
+		projection += ", " + filterAttributesName
+	}
 
-		// Continue to the next page
-		return !lastPage
+	// So are the named stat leaderboards
+	var stats *statRebuild
+	if len(r.config.Stats) > 0 {
+		stats = r.newStatRebuild(ctx, leaderboardID, additive, pipe)
+		projection += ", " + stats.projection(projectionNames)
 	}
 
-	// Create the query input
-	input := &dynamodb.QueryInput{
-		TableName: aws.String(r.tableName),
-		KeyConditionExpression: aws.String(
-			"leaderboardID = :lid",
-		),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":lid": &types.AttributeValueMemberS{
-				Value: leaderboardID,
-			},
-		},
-		ProjectionExpression: aws.String(
-			"namespacedUserID, score",
-		),
+	// Migrations may read any attribute, so items are read whole while
+	// schema migrations are registered
+	if len(r.config.SchemaMigrations) > 0 {
+		projection = ""
 	}
 
-	// Use the paginator to handle pagination
-	paginator := dynamodb.NewQueryPaginator(r.dynamoClient, input)
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx)
-		if err != nil {
-			return fmt.Errorf(
-				"failed to query DynamoDB table: %w",
-				err,
-			)
+	// Pages are unmarshalled concurrently, but the pipeline and filter
+	// rebuild are not safe for concurrent use
+	var (
+		mu          sync.Mutex
+		itemsLoaded int64
+	)
+	batchSize := r.syncBatchSize()
+
+	processPage := func(items []map[string]types.AttributeValue) error {
+		// Collect the page's members so they are added in batches
+		members := make([]redis.Z, 0, len(items))
+		expiries := make([]redis.Z, 0, len(items))
+		attributes := make([]map[string]interface{}, 0, len(items))
+		memberStats := make([]map[string]float64, 0, len(items))
+		for _, item := range items {
+			decoded, ok, err := r.decodeSyncItem(item, expiryAttribute)
+			if err != nil {
+				// One malformed item must not fail the rebuild, so log and
+				// skip it
+				fmt.Printf("Error decoding item of leaderboard %s, skipping: %v\n", leaderboardID, err)
+				continue
+			}
+			if !ok {
+				continue
+			}
+			if decoded.ExpiresAt != 0 {
+				if decoded.ExpiresAt <= nowUnix {
+					continue
+				}
+				expiries = append(expiries, redis.Z{
+					Score:  decoded.ExpiresAt,
+					Member: decoded.Member,
+				})
+			}
+			members = append(members, redis.Z{
+				Score:  decoded.Score,
+				Member: decoded.Member,
+			})
+			attributes = append(attributes, decoded.Attributes)
+			memberStats = append(memberStats, decoded.Stats)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Stop queueing once the caller has given up, even if other pages
+		// are still waiting for the lock
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
-		// Process the page
-		if !processPage(page, !paginator.HasMorePages()) {
-			break
+		// Add this page's items to the Redis pipeline
+		if filters != nil {
+			for i, member := range members {
+				filters.add(ctx, pipe, member.Member.(string), member.Score, attributes[i])
+			}
+		}
+		if stats != nil {
+			for i, member := range members {
+				stats.add(ctx, pipe, member.Member.(string), memberStats[i])
+			}
+		}
+		if additive {
+			for _, member := range members {
+				pipe.ZIncrBy(ctx, redisKey, member.Score, member.Member.(string))
+			}
+		} else {
+			for _, batch := range batchMembers(members, batchSize) {
+				pipe.ZAdd(ctx, redisKey, batch...)
+			}
 		}
+		for _, batch := range batchMembers(expiries, batchSize) {
+			pipe.ZAdd(ctx, expiriesKey, batch...)
+		}
+
+		// Flush large rebuilds in chunks; the shadow keys keep partial
+		// results invisible to readers, and expire if the rebuild dies
+		if r.pipelineChunkFull(pipe) {
+			pipe.Expire(ctx, redisKey, shadowKeyTTL)
+			pipe.Expire(ctx, expiriesKey, shadowKeyTTL)
+			if filters != nil {
+				filters.expireShadowKeys(ctx, pipe)
+			}
+			if stats != nil {
+				stats.expireShadowKeys(ctx, pipe)
+			}
+			if err := execPipeline(ctx, pipe); err != nil {
+				return err
+			}
+		}
+
+		itemsLoaded += int64(len(items))
+		if progress != nil {
+			progress(itemsLoaded)
+		}
+
+		return nil
+	}
+
+	// Read the partitions, processing pages as they arrive
+	err := r.queryPartitions(
+		ctx,
+		r.readPartitionKeys(leaderboardID),
+		func(partitionKey string) *dynamodb.QueryInput {
+			return r.syncQueryInput(partitionKey, projection, projectionNames)
+		},
+		processPage,
+	)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		mu.Lock()
+		defer mu.Unlock()
+		return &customTypes.SyncCancelledError{
+			LeaderboardID: leaderboardID,
+			ItemsLoaded:   itemsLoaded,
+			Err:           ctxErr,
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Guard against a rebuild that dies half way leaving shadow keys behind,
+	// then atomically swap them over the live keys. EVAL rather than EVALSHA
+	// since a pipeline cannot fall back when the script is not yet cached.
+	pipe.Expire(ctx, redisKey, shadowKeyTTL)
+	pipe.Expire(ctx, expiriesKey, shadowKeyTTL)
+	swapKeys := []string{redisKey, liveKey, expiriesKey, liveExpiriesKey}
+	if filters != nil {
+		swapKeys = append(swapKeys, filters.swapKeys(ctx, pipe, previousFilterKeys)...)
+	}
+	if stats != nil {
+		stats.expireShadowKeys(ctx, pipe)
+		swapKeys = append(swapKeys, stats.swapKeys()...)
+	}
+	swapShadowKeysScript.Eval(ctx, pipe, swapKeys)
+	if filters != nil {
+		filters.setupExpiry(ctx, leaderboardEndTime, pipe)
+	}
+	if stats != nil {
+		stats.setupExpiry(ctx, leaderboardEndTime, pipe)
 	}
 
 	return nil
@@ -116,39 +369,137 @@ func (r *ParticipantRepo) ensureLeaderboardExists(
 ) error {
 	redisKey := r.getRedisKey(leaderboardID)
 
-	// Check if the sorted set exists
-	exists, err := r.redisClient.Exists(ctx, redisKey).Result()
+	// Check if the leaderboard is cached
+	cached, err := r.isCached(ctx, leaderboardID)
 	if err != nil {
-		return fmt.Errorf(
-			"failed to check if Redis key exists: %w",
-			err,
-		)
+		return err
 	}
 
-	// If the sorted set doesn't exist, try to create it
-	if exists == 0 {
+	// If it isn't, try to load it
+	if !cached {
+		// Only one instance rebuilds; the others wait for it to finish
+		token, acquired, err := r.acquireRebuildLock(ctx, leaderboardID)
+		if err != nil {
+			return err
+		}
+		if !acquired {
+			return r.waitForRebuild(ctx, leaderboardID)
+		}
+		// Release even when the caller gave up, so the next one need not
+		// wait for the lock to expire
+		defer r.releaseRebuildLock(context.WithoutCancel(ctx), leaderboardID, token)
+		defer r.keepRebuildLock(ctx, leaderboardID, token)()
+
 		// Create a pipeline for Redis operations
 		pipe := r.redisClient.Pipeline()
 
 		// Try to sync data from DynamoDB
-		err = r.syncLeaderboard(ctx, leaderboardID, pipe)
+		syncStartedAt := utils.GetCurrTimeStamp()
+		err = r.syncLeaderboard(ctx, leaderboardID, leaderboardEndTime, pipe, nil)
+		var cancelled *customTypes.SyncCancelledError
+		if errors.As(err, &cancelled) {
+			// A cancelled sync says nothing about the data, so leave the
+			// leaderboard uncached for the next caller to load
+			pipe.Discard()
+			return err
+		}
 		if err != nil {
-			// If sync fails, create an empty sorted set
-			pipe.ZAdd(ctx, redisKey, redis.Z{})
+			// Drop the partial rebuild; shadow keys already flushed expire
+			// on their own. The leaderboard is served empty this once and
+			// stays uncached, so the next access retries the sync.
+			pipe.Discard()
+			fmt.Printf("Error syncing leaderboard %s: %v\n", leaderboardID, err)
+			return nil
 		}
+		r.recordSyncWatermark(ctx, leaderboardID, syncStartedAt, leaderboardEndTime, pipe)
+		r.markCached(ctx, leaderboardID, leaderboardEndTime, pipe)
 
 		// Set up expiry for the leaderboard
 		r.setupLeaderboardExpiry(ctx, redisKey, leaderboardEndTime, pipe)
+		r.setupLeaderboardExpiry(ctx, r.getExpiriesKey(leaderboardID), leaderboardEndTime, pipe)
 
 		// Execute all Redis operations
-		_, err = pipe.Exec(ctx)
+		if err := execPipeline(ctx, pipe); err != nil {
+			return err
+		}
+
+		// Writes that skipped the cache while the sync ran may be missing
+		// from what it read
+		r.replayRebuildWrites(ctx, leaderboardID, leaderboardEndTime, token)
+		return nil
+	}
+
+	// Repair members whose earlier writes missed the cache
+	if r.repairs.has(leaderboardID) {
+		if _, err := r.RepairCache(ctx, leaderboardID); err != nil {
+			// The members stay queued, so only log
+			fmt.Printf("Error repairing cached participants: %v\n", err)
+		}
+	}
+
+	// Keep the cache's expiry in line with the policy and end time
+	if err := r.reconcileCacheExpiry(ctx, leaderboardID, leaderboardEndTime, utils.GetCurrTimeStamp()); err != nil {
+		// The cache is still usable, so only log
+		fmt.Printf("Error reconciling cache expiry: %v\n", err)
+	}
+
+	return nil
+}
+
+// WarmLeaderboard loads a leaderboard from DynamoDB into Redis ahead of
+// traffic. Unlike the lazy path, sync failures are returned rather than
+// papered over. Leaderboards that are already cached are only reloaded when
+// force is set.
+func (r *ParticipantRepo) WarmLeaderboard(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+	force bool,
+	progress func(itemsLoaded int64),
+) error {
+	redisKey := r.getRedisKey(leaderboardID)
+
+	if !force {
+		cached, err := r.isCached(ctx, leaderboardID)
 		if err != nil {
-			return fmt.Errorf(
-				"failed to execute Redis pipeline: %w",
-				err,
-			)
+			return err
+		}
+		if cached {
+			return nil
 		}
 	}
 
+	// Never run alongside another instance's rebuild
+	token, acquired, err := r.acquireRebuildLock(ctx, leaderboardID)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return customTypes.ErrLeaderboardRebuilding
+	}
+	defer r.releaseRebuildLock(context.WithoutCancel(ctx), leaderboardID, token)
+	defer r.keepRebuildLock(ctx, leaderboardID, token)()
+
+	pipe := r.redisClient.Pipeline()
+	syncStartedAt := utils.GetCurrTimeStamp()
+	if err := r.syncLeaderboard(ctx, leaderboardID, leaderboardEndTime, pipe, progress); err != nil {
+		pipe.Discard()
+		return err
+	}
+	r.recordSyncWatermark(ctx, leaderboardID, syncStartedAt, leaderboardEndTime, pipe)
+	r.markCached(ctx, leaderboardID, leaderboardEndTime, pipe)
+
+	// Set up expiry for the leaderboard
+	r.setupLeaderboardExpiry(ctx, redisKey, leaderboardEndTime, pipe)
+	r.setupLeaderboardExpiry(ctx, r.getExpiriesKey(leaderboardID), leaderboardEndTime, pipe)
+
+	if err := execPipeline(ctx, pipe); err != nil {
+		return err
+	}
+
+	// The swap replaced the live keys, so writes made to them while the
+	// sync ran are synced again from DynamoDB
+	r.replayRebuildWrites(ctx, leaderboardID, leaderboardEndTime, token)
+
 	return nil
 }
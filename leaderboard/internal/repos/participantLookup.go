@@ -0,0 +1,305 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// IsParticipant reports whether the member is on the cached leaderboard
+func (r *ParticipantRepo) IsParticipant(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	leaderboardEndTime time.Time,
+) (bool, error) {
+	// Ensure the leaderboard exists in Redis
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return false, err
+	}
+
+	err := r.redisClient.ZScore(ctx, r.getRedisKey(leaderboardID), namespacedUserID).Err()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf(
+			"failed to check participant: %w",
+			err,
+		)
+	}
+
+	return true, nil
+}
+
+// GetParticipant reads a participant's stored record from DynamoDB,
+// summing regional deltas under the additive strategy. It returns
+// ErrParticipantNotFound if the participant has no item.
+func (r *ParticipantRepo) GetParticipant(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+) (*models.ParticipantModel, error) {
+	participant, storedTotal, err := r.getParticipant(ctx, leaderboardID, namespacedUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant.Score = r.displayScore(storedTotal)
+	for stat, total := range participant.Stats {
+		participant.Stats[stat] = r.displayScore(total)
+	}
+	return participant, nil
+}
+
+// getParticipant reads a participant's record from DynamoDB along with its
+// total score in stored units
+func (r *ParticipantRepo) getParticipant(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+) (*models.ParticipantModel, float64, error) {
+	var participant *models.ParticipantModel
+	var total float64
+	var statTotals map[string]float64
+	milestones := make(map[float64]time.Time)
+
+	for _, partitionKey := range r.memberPartitionKeys(leaderboardID, namespacedUserID) {
+		output, err := r.dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName:      aws.String(r.tableName),
+			Key:            r.config.KeySchema.ItemKey(partitionKey, namespacedUserID),
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf(
+				"failed to get participant from DynamoDB: %w",
+				err,
+			)
+		}
+		if len(output.Item) == 0 {
+			continue
+		}
+
+		if err := r.migrateOnRead(ctx, output.Item); err != nil {
+			return nil, 0, err
+		}
+
+		var stored models.ParticipantModel
+		if err := attributevalue.UnmarshalMap(output.Item, &stored); err != nil {
+			return nil, 0, fmt.Errorf(
+				"failed to unmarshal participant: %w",
+				err,
+			)
+		}
+		total += stored.Score
+
+		// Named stats are summed across regions like the score
+		for stat, value := range itemStats(output.Item) {
+			if statTotals == nil {
+				statTotals = make(map[string]float64)
+			}
+			statTotals[stat] += value
+		}
+
+		// updated_at is written on every path; keep the latest across regions
+		updatedAt := stored.UpdatedAt
+		if n, ok := output.Item["updated_at"].(*types.AttributeValueMemberN); ok {
+			if seconds, err := strconv.ParseInt(n.Value, 10, 64); err == nil {
+				updatedAt = time.Unix(seconds, 0).UTC()
+			}
+		}
+
+		if participant == nil {
+			participant = &stored
+		}
+		if updatedAt.After(participant.UpdatedAt) {
+			participant.UpdatedAt = updatedAt
+			if stored.LastRequestID != "" {
+				participant.LastRequestID = stored.LastRequestID
+			}
+		}
+		if stored.Attributes != nil {
+			participant.Attributes = stored.Attributes
+		}
+		if stored.Metadata != nil {
+			if err := r.decryptMetadata(ctx, namespacedUserID, stored.Metadata); err != nil {
+				return nil, 0, err
+			}
+			participant.Metadata = stored.Metadata
+		}
+		// Keep the earliest time each milestone was reached in any region
+		for threshold, reachedAt := range itemMilestones(output.Item) {
+			if earliest, ok := milestones[threshold]; !ok || reachedAt.Before(earliest) {
+				milestones[threshold] = reachedAt
+			}
+		}
+		if frozen := frozenError(namespacedUserID, output.Item); frozen != nil {
+			participant.FrozenAt = frozen.FrozenAt
+			participant.FrozenReason = frozen.Reason
+		}
+	}
+
+	if participant == nil {
+		return nil, 0, customTypes.ErrParticipantNotFound
+	}
+
+	// Items written only by score updates carry the key attributes alone
+	participant.LeaderboardID = leaderboardID
+	participant.NamespacedUserID = namespacedUserID
+	participant.Stats = statTotals
+	participant.Milestones = sortedMilestones(milestones)
+
+	return participant, total, nil
+}
+
+// syncMemberScript sets a member's cached score only while the leaderboard
+// is cached and the member's score is still the one read before DynamoDB
+// was, so increments landing in between are never overwritten. It replies
+// with the member's zero-based rank, -1 if the score changed, or nil when
+// the leaderboard is not cached. KEYS[1] is the leaderboard and KEYS[2] its
+// marker; ARGV[1] is the expected score, empty for an absent member,
+// ARGV[2] the new score, empty to remove the member, and ARGV[3] the
+// member. A removal replies 0.
+var syncMemberScript = newScript(`
+if redis.call("EXISTS", KEYS[2]) == 0 then
+	return false
+end
+local current = redis.call("ZSCORE", KEYS[1], ARGV[3])
+if (current or "") ~= ARGV[1] then
+	return -1
+end
+if ARGV[2] == "" then
+	redis.call("ZREM", KEYS[1], ARGV[3])
+	return 0
+end
+redis.call("ZADD", KEYS[1], ARGV[2], ARGV[3])
+return redis.call("ZREVRANK", KEYS[1], ARGV[3])
+`)
+
+// SyncParticipant waits for any rebuild of the cached leaderboard to finish
+// and then sets the participant's cached score, filtered views and named
+// stats from DynamoDB, so a write that bypassed the cache during the
+// rebuild is visible to the next read. It returns the participant's score
+// and rank. The rank is zero if the leaderboard is still not cached, or if
+// another write changed the cached score meanwhile, in which case the
+// participant is queued for repair instead.
+func (r *ParticipantRepo) SyncParticipant(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	leaderboardEndTime time.Time,
+) (*customTypes.MemberScore, error) {
+	// Wait until the rebuild has swapped in its keys, so they cannot
+	// overwrite the score set below
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return nil, err
+	}
+
+	return r.syncMember(ctx, leaderboardID, namespacedUserID, leaderboardEndTime)
+}
+
+// syncMember sets a member's cached score, filtered views and named stats
+// from DynamoDB unless another write changes the cached score meanwhile,
+// in which case the member is queued for repair. A member DynamoDB no
+// longer holds is removed from the cache, and ErrParticipantNotFound
+// returned. The rank is zero when the score was not set.
+func (r *ParticipantRepo) syncMember(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	leaderboardEndTime time.Time,
+) (*customTypes.MemberScore, error) {
+	// Read the cached score first; a write changing it after DynamoDB is
+	// read makes the script below back off
+	redisKey := r.getRedisKey(leaderboardID)
+	cachedScore, err := r.redisClient.Do(ctx, "ZSCORE", redisKey, namespacedUserID).Text()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf(
+			"failed to get cached score: %w",
+			err,
+		)
+	}
+
+	participant, storedTotal, err := r.getParticipant(ctx, leaderboardID, namespacedUserID)
+	if errors.Is(err, customTypes.ErrParticipantNotFound) {
+		pipe := r.redisClient.Pipeline()
+		removeCmd := syncMemberScript.Eval(
+			ctx,
+			pipe,
+			[]string{redisKey, r.getCachedMarkerKey(leaderboardID)},
+			cachedScore,
+			"",
+			namespacedUserID,
+		)
+		r.unindexMember(ctx, leaderboardID, namespacedUserID, pipe)
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return nil, fmt.Errorf(
+				"failed to remove participant from Redis: %w",
+				err,
+			)
+		}
+		if removed, err := removeCmd.Int64(); err == nil && removed < 0 {
+			r.queueRepair(leaderboardID, errors.New("cached score changed during sync"), namespacedUserID)
+		}
+		return nil, customTypes.ErrParticipantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := r.redisClient.Pipeline()
+	syncCmd := syncMemberScript.Eval(
+		ctx,
+		pipe,
+		[]string{redisKey, r.getCachedMarkerKey(leaderboardID)},
+		cachedScore,
+		formatStoredScore(storedTotal),
+		namespacedUserID,
+	)
+	// Views copy the member's cached score, so they follow whichever write
+	// won
+	r.indexMemberAttributes(
+		ctx,
+		leaderboardID,
+		namespacedUserID,
+		r.filterAttributes(participant.Attributes),
+		leaderboardEndTime,
+		pipe,
+	)
+	r.restoreMemberStats(ctx, leaderboardID, namespacedUserID, participant.Stats, pipe)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf(
+			"failed to sync participant to Redis: %w",
+			err,
+		)
+	}
+
+	result := &customTypes.MemberScore{
+		Member:     namespacedUserID,
+		Score:      r.displayScore(storedTotal),
+		ComputedAt: utils.GetCurrTimeStamp(),
+	}
+	rank, err := syncCmd.Int64()
+	if err != nil {
+		// Not cached; the next rebuild loads the participant
+		return result, nil
+	}
+	if rank < 0 {
+		r.queueRepair(leaderboardID, errors.New("cached score changed during sync"), namespacedUserID)
+		return result, nil
+	}
+	result.Rank = rank + 1
+
+	return result, nil
+}
@@ -0,0 +1,120 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultTTLAttributeName is the DynamoDB TTL attribute set on participant
+// items when a participant TTL is configured
+const DefaultTTLAttributeName = "expiresAt"
+
+// getExpiriesKey returns the Redis key tracking when each participant of a
+// leaderboard expires
+func (r *ParticipantRepo) getExpiriesKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":expiries"
+}
+
+// ttlAttributeName returns the configured DynamoDB TTL attribute name
+func (r *ParticipantRepo) ttlAttributeName() string {
+	if r.config.TTLAttributeName != "" {
+		return r.config.TTLAttributeName
+	}
+
+	return DefaultTTLAttributeName
+}
+
+// itemsExpire reports whether items are written with a TTL attribute
+func (r *ParticipantRepo) itemsExpire() bool {
+	return r.config.ParticipantTTL > 0 || r.config.RetentionPeriod > 0
+}
+
+// participantExpiry returns when a participant written at now expires, and
+// whether it expires at all. The earlier of the participant TTL and the end
+// of the leaderboard's retention period applies; leaderboards without an
+// end time are retained forever.
+func (r *ParticipantRepo) participantExpiry(
+	now time.Time,
+	leaderboardEndTime time.Time,
+) (time.Time, bool) {
+	var expiresAt time.Time
+	if r.config.ParticipantTTL > 0 {
+		expiresAt = now.Add(r.config.ParticipantTTL)
+	}
+	if r.config.RetentionPeriod > 0 && !leaderboardEndTime.IsZero() {
+		retainedUntil := leaderboardEndTime.Add(r.config.RetentionPeriod)
+		if expiresAt.IsZero() || retainedUntil.Before(expiresAt) {
+			expiresAt = retainedUntil
+		}
+	}
+
+	return expiresAt, !expiresAt.IsZero()
+}
+
+// trackParticipantExpiry records a participant's expiry in Redis so the
+// sweep can remove it from the sorted set once DynamoDB expires the item
+func (r *ParticipantRepo) trackParticipantExpiry(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	expiresAt time.Time,
+	leaderboardEndTime time.Time,
+	pipe redis.Pipeliner,
+) {
+	expiriesKey := r.getExpiriesKey(leaderboardID)
+	pipe.ZAdd(ctx, expiriesKey, redis.Z{
+		Score:  float64(expiresAt.Unix()),
+		Member: namespacedUserID,
+	})
+	r.setupLeaderboardExpiry(ctx, expiriesKey, leaderboardEndTime, pipe)
+}
+
+// SweepExpiredParticipants removes participants whose TTL has passed from
+// the Redis sorted set and returns how many were removed. DynamoDB deletes
+// the matching items on its own once their TTL attribute has passed.
+func (r *ParticipantRepo) SweepExpiredParticipants(
+	ctx context.Context,
+	leaderboardID string,
+) (int64, error) {
+	redisKey := r.getRedisKey(leaderboardID)
+	expiriesKey := r.getExpiriesKey(leaderboardID)
+	now := utils.GetCurrTimeStamp()
+
+	// Find every participant whose expiry is in the past
+	expired, err := r.redisClient.ZRangeByScore(ctx, expiriesKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf(
+			"failed to find expired participants: %w",
+			err,
+		)
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	members := make([]interface{}, len(expired))
+	for i, member := range expired {
+		members[i] = member
+	}
+
+	// Remove them from both the leaderboard and the expiry index
+	pipe := r.redisClient.TxPipeline()
+	removed := pipe.ZRem(ctx, redisKey, members...)
+	pipe.ZRem(ctx, expiriesKey, members...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf(
+			"failed to remove expired participants: %w",
+			err,
+		)
+	}
+
+	return removed.Val(), nil
+}
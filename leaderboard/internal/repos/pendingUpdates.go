@@ -0,0 +1,240 @@
+package repos
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// DefaultPendingTableName is the DynamoDB table holding quarantined score
+// updates
+const DefaultPendingTableName = "PlatformLeaderboardPendingUpdates"
+
+// PendingReview applies a review of quarantined updates: the approved
+// updates are credited and every reviewed update leaves the queue
+type PendingReview struct {
+	LeaderboardID      string
+	LeaderboardEndTime time.Time
+	Approved           []string
+	Rejected           []string
+	// ExtraWrites are credited in the same transaction, e.g. to a lifetime
+	// leaderboard
+	ExtraWrites func(member string, scoreDelta float64) []ScoreWrite
+}
+
+// pendingTableName returns the table holding quarantined updates
+func (r *ParticipantRepo) pendingTableName() string {
+	if r.config.PendingTableName != "" {
+		return r.config.PendingTableName
+	}
+
+	return DefaultPendingTableName
+}
+
+// pendingKey returns the DynamoDB key of a quarantined update
+func pendingKey(leaderboardID string, updateID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"leaderboardID": &types.AttributeValueMemberS{Value: leaderboardID},
+		"updateID":      &types.AttributeValueMemberS{Value: updateID},
+	}
+}
+
+// QuarantineUpdate stores a score update for review instead of applying it
+// and returns the stored update
+func (r *ParticipantRepo) QuarantineUpdate(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	scoreDelta float64,
+	attributes map[string]string,
+	leaderboardEndTime time.Time,
+) (*customTypes.PendingUpdate, error) {
+	// Order update IDs by quarantine time so reviews see the oldest first
+	now := utils.GetCurrTimeStamp()
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf(
+			"failed to generate update ID: %w",
+			err,
+		)
+	}
+	pending := &models.PendingUpdateModel{
+		LeaderboardID:    leaderboardID,
+		UpdateID:         fmt.Sprintf("%019d-%s", now.UnixNano(), hex.EncodeToString(suffix)),
+		NamespacedUserID: namespacedUserID,
+		ScoreDelta:       scoreDelta,
+		Attributes:       attributes,
+		QuarantinedAt:    now,
+	}
+
+	item, err := attributevalue.MarshalMap(pending)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to marshal pending update: %w",
+			err,
+		)
+	}
+
+	// Unreviewed updates are cleaned up with the leaderboard's other items
+	if r.config.RetentionPeriod > 0 && !leaderboardEndTime.IsZero() {
+		item[r.ttlAttributeName()] = &types.AttributeValueMemberN{
+			Value: fmt.Sprintf("%d", leaderboardEndTime.Add(r.config.RetentionPeriod).Unix()),
+		}
+	}
+	_, err = r.dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.pendingTableName()),
+		Item:      item,
+	})
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to store pending update in DynamoDB: %w",
+			err,
+		)
+	}
+
+	return toPendingUpdate(pending), nil
+}
+
+// ListPendingUpdates returns up to limit quarantined updates of the
+// leaderboard, oldest first
+func (r *ParticipantRepo) ListPendingUpdates(
+	ctx context.Context,
+	leaderboardID string,
+	limit int32,
+) ([]customTypes.PendingUpdate, error) {
+	output, err := r.dynamoClient.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.pendingTableName()),
+		KeyConditionExpression: aws.String("leaderboardID = :leaderboardID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":leaderboardID": &types.AttributeValueMemberS{Value: leaderboardID},
+		},
+		Limit: aws.Int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to query pending updates from DynamoDB: %w",
+			err,
+		)
+	}
+
+	var items []models.PendingUpdateModel
+	if err := attributevalue.UnmarshalListOfMaps(output.Items, &items); err != nil {
+		return nil, fmt.Errorf(
+			"failed to unmarshal pending updates: %w",
+			err,
+		)
+	}
+
+	updates := make([]customTypes.PendingUpdate, len(items))
+	for i := range items {
+		updates[i] = *toPendingUpdate(&items[i])
+	}
+
+	return updates, nil
+}
+
+// ReviewPendingUpdates credits the approved updates and removes every
+// reviewed update from the queue in a single transaction. Approved deltas
+// of the same participant are summed into one write. It returns the
+// approved participants' standings, in order of first approval, and the
+// approved updates.
+func (r *ParticipantRepo) ReviewPendingUpdates(
+	ctx context.Context,
+	review PendingReview,
+) ([]customTypes.MemberScore, []customTypes.PendingUpdate, error) {
+	// Read the approved updates to learn their deltas
+	approved := make([]customTypes.PendingUpdate, len(review.Approved))
+	for i, updateID := range review.Approved {
+		output, err := r.dynamoClient.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName:      aws.String(r.pendingTableName()),
+			Key:            pendingKey(review.LeaderboardID, updateID),
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"failed to get pending update from DynamoDB: %w",
+				err,
+			)
+		}
+		if output.Item == nil {
+			return nil, nil, customTypes.ErrPendingUpdateNotFound
+		}
+		var item models.PendingUpdateModel
+		if err := attributevalue.UnmarshalMap(output.Item, &item); err != nil {
+			return nil, nil, fmt.Errorf(
+				"failed to unmarshal pending update: %w",
+				err,
+			)
+		}
+		approved[i] = *toPendingUpdate(&item)
+	}
+
+	// A transaction may touch each participant only once
+	var writes []ScoreWrite
+	index := make(map[string]int)
+	for _, update := range approved {
+		i, ok := index[update.NamespacedUserID]
+		if !ok {
+			index[update.NamespacedUserID] = len(writes)
+			writes = append(writes, ScoreWrite{
+				LeaderboardID:      review.LeaderboardID,
+				NamespacedUserID:   update.NamespacedUserID,
+				ScoreDelta:         update.ScoreDelta,
+				LeaderboardEndTime: review.LeaderboardEndTime,
+				Attributes:         update.Attributes,
+			})
+			continue
+		}
+		writes[i].ScoreDelta += update.ScoreDelta
+		if len(update.Attributes) > 0 {
+			writes[i].Attributes = update.Attributes
+		}
+	}
+	credited := len(writes)
+	if review.ExtraWrites != nil {
+		for i := 0; i < credited; i++ {
+			writes = append(writes, review.ExtraWrites(writes[i].NamespacedUserID, writes[i].ScoreDelta)...)
+		}
+	}
+
+	// Remove every reviewed update, failing if another review got there first
+	reviewed := append(append([]string(nil), review.Approved...), review.Rejected...)
+	deletes := make([]types.TransactWriteItem, len(reviewed))
+	for i, updateID := range reviewed {
+		deletes[i] = types.TransactWriteItem{
+			Delete: &types.Delete{
+				TableName:           aws.String(r.pendingTableName()),
+				Key:                 pendingKey(review.LeaderboardID, updateID),
+				ConditionExpression: aws.String("attribute_exists(updateID)"),
+			},
+		}
+	}
+
+	results, err := r.updateScoresAtomically(ctx, writes, deletes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return results[:credited], approved, nil
+}
+
+// toPendingUpdate converts a stored pending update
+func toPendingUpdate(item *models.PendingUpdateModel) *customTypes.PendingUpdate {
+	return &customTypes.PendingUpdate{
+		UpdateID:         item.UpdateID,
+		NamespacedUserID: item.NamespacedUserID,
+		ScoreDelta:       item.ScoreDelta,
+		Attributes:       item.Attributes,
+		QuarantinedAt:    item.QuarantinedAt,
+	}
+}
@@ -0,0 +1,56 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultPipelineChunkSize is how many commands a sync queues before
+// flushing its pipeline unless configured otherwise
+const DefaultPipelineChunkSize = 10000
+
+// pipelineChunkSize returns how many commands a sync queues before flushing
+// its pipeline. Negative never flushes early.
+func (r *ParticipantRepo) pipelineChunkSize() int {
+	if r.config.PipelineChunkSize != 0 {
+		return r.config.PipelineChunkSize
+	}
+
+	return DefaultPipelineChunkSize
+}
+
+// pipelineChunkFull reports whether a chunk's worth of commands is queued
+// and should be flushed, keeping the pipeline's memory and the size of each
+// round trip bounded
+func (r *ParticipantRepo) pipelineChunkFull(pipe redis.Pipeliner) bool {
+	chunkSize := r.pipelineChunkSize()
+
+	return chunkSize >= 0 && pipe.Len() >= chunkSize
+}
+
+// execPipeline executes the queued commands and joins the errors of every
+// failed command, rather than only the first one Exec reports
+func execPipeline(ctx context.Context, pipe redis.Pipeliner) error {
+	cmds, err := pipe.Exec(ctx)
+	if err == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && cmdErr != redis.Nil {
+			errs = append(errs, fmt.Errorf("%s: %w", cmd.Name(), cmdErr))
+		}
+	}
+	if len(errs) == 0 {
+		errs = append(errs, err)
+	}
+
+	return fmt.Errorf(
+		"failed to execute Redis pipeline: %w",
+		errors.Join(errs...),
+	)
+}
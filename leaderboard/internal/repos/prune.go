@@ -0,0 +1,227 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// pruneBatchItems bounds the items deleted in one prune transaction
+const pruneBatchItems = 25
+
+// PruneParticipants removes the participants of a leaderboard whose items
+// were all last written before inactiveSince, from DynamoDB and the cache,
+// and returns how many were removed. Frozen participants are kept. Each
+// delete is conditioned on the item still being inactive, so a participant
+// scoring while the prune runs is kept. Batches are paced at the bulk write
+// rate, which backs off when DynamoDB throttles.
+func (r *ParticipantRepo) PruneParticipants(
+	ctx context.Context,
+	leaderboardID string,
+	inactiveSince time.Time,
+) (int64, error) {
+	cutoff := &types.AttributeValueMemberN{
+		Value: strconv.FormatInt(inactiveSince.Unix(), 10),
+	}
+
+	var pruned int64
+	var batch []string
+	seen := make(map[string]bool)
+	flush := func() error {
+		removed, err := r.pruneMembers(ctx, leaderboardID, batch, cutoff)
+		pruned += int64(len(removed))
+		batch = batch[:0]
+		return err
+	}
+
+	membersPerBatch := pruneBatchItems / len(r.basePartitionKeys(leaderboardID))
+	if membersPerBatch < 1 {
+		membersPerBatch = 1
+	}
+	for _, partitionKey := range r.readPartitionKeys(leaderboardID) {
+		paginator := dynamodb.NewQueryPaginator(r.dynamoClient, r.inactiveQueryInput(partitionKey, cutoff))
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return pruned, fmt.Errorf(
+					"failed to query inactive participants: %w",
+					err,
+				)
+			}
+
+			for _, item := range page.Items {
+				member, ok := r.config.KeySchema.memberFromItem(item)
+				if !ok || seen[member] {
+					continue
+				}
+				seen[member] = true
+
+				batch = append(batch, member)
+				if len(batch) >= membersPerBatch {
+					if err := flush(); err != nil {
+						return pruned, err
+					}
+				}
+			}
+		}
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return pruned, err
+		}
+	}
+
+	return pruned, nil
+}
+
+// inactiveQueryInput builds the query for a partition's items last written
+// before cutoff, through the updated_at index when one is configured
+func (r *ParticipantRepo) inactiveQueryInput(
+	partitionKey string,
+	cutoff types.AttributeValue,
+) *dynamodb.QueryInput {
+	keySchema := r.config.KeySchema
+	keyCondition, values := keySchema.PartitionQuery(partitionKey)
+	names := keySchema.KeyNames()
+	names["#frozenAt"] = frozenAtAttribute
+	values[":cutoff"] = cutoff
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		KeyConditionExpression:    aws.String(keyCondition),
+		FilterExpression:          aws.String("updated_at < :cutoff AND attribute_not_exists(#frozenAt)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ProjectionExpression:      aws.String("#sk"),
+	}
+
+	// The index sorts on updated_at, so other entities sharing the
+	// partition are filtered out instead
+	if r.config.UpdatedAtIndexName != "" {
+		input.IndexName = aws.String(r.config.UpdatedAtIndexName)
+		input.KeyConditionExpression = aws.String("#pk = :lid AND updated_at < :cutoff")
+		filter := "attribute_not_exists(#frozenAt)"
+		if _, ok := values[":skPrefix"]; ok {
+			filter += " AND begins_with(#sk, :skPrefix)"
+		}
+		input.FilterExpression = aws.String(filter)
+	}
+
+	return input
+}
+
+// pruneMembers deletes every item of the members that is still inactive in
+// one transaction and removes them from the cache, returning the members
+// removed. Members that became active or were frozen since they were
+// found are dropped from the batch and kept.
+func (r *ParticipantRepo) pruneMembers(
+	ctx context.Context,
+	leaderboardID string,
+	members []string,
+	cutoff types.AttributeValue,
+) ([]string, error) {
+	for len(members) > 0 {
+		items := make([]types.TransactWriteItem, 0, len(members))
+		owners := make([]string, 0, len(members))
+		for _, member := range members {
+			for _, partitionKey := range r.memberPartitionKeys(leaderboardID, member) {
+				items = append(items, types.TransactWriteItem{
+					Delete: &types.Delete{
+						TableName: aws.String(r.tableName),
+						Key:       r.config.KeySchema.ItemKey(partitionKey, member),
+						ConditionExpression: aws.String(
+							"(attribute_not_exists(updated_at) OR updated_at < :cutoff) AND attribute_not_exists(#frozenAt)",
+						),
+						ExpressionAttributeNames: map[string]string{
+							"#frozenAt": frozenAtAttribute,
+						},
+						ExpressionAttributeValues: map[string]types.AttributeValue{
+							":cutoff": cutoff,
+						},
+					},
+				})
+				owners = append(owners, member)
+			}
+		}
+
+		r.paceBulkWrite(ctx, len(items))
+		err := r.retryConflicts(ctx, func() error {
+			_, err := r.dynamoClient.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+				TransactItems: items,
+			})
+			return err
+		})
+		if err == nil {
+			break
+		}
+
+		// Keep the members whose condition failed and retry the rest
+		var cancelledErr *types.TransactionCanceledException
+		if !errors.As(err, &cancelledErr) {
+			return nil, fmt.Errorf(
+				"failed to delete inactive participants: %w",
+				err,
+			)
+		}
+		keep := make(map[string]bool)
+		for i, reason := range cancelledErr.CancellationReasons {
+			if reason.Code != nil && *reason.Code == "ConditionalCheckFailed" && i < len(owners) {
+				keep[owners[i]] = true
+			}
+		}
+		if len(keep) == 0 {
+			return nil, fmt.Errorf(
+				"failed to delete inactive participants: %w",
+				err,
+			)
+		}
+		remaining := members[:0:0]
+		for _, member := range members {
+			if !keep[member] {
+				remaining = append(remaining, member)
+			}
+		}
+		members = remaining
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	// Drop the members from the cache. DynamoDB no longer holds them, so a
+	// failure is repaired later rather than returned.
+	pipe := r.redisClient.Pipeline()
+	removed := make([]interface{}, len(members))
+	for i, member := range members {
+		removed[i] = member
+		r.unindexMember(ctx, leaderboardID, member, pipe)
+	}
+	pipe.ZRem(ctx, r.getRedisKey(leaderboardID), removed...)
+	pipe.ZRem(ctx, r.getExpiriesKey(leaderboardID), removed...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.queueRepair(leaderboardID, err, members...)
+	}
+
+	return append([]string(nil), members...), nil
+}
+
+// paceBulkWrite waits long enough for items writes to fit the bulk write
+// rate
+func (r *ParticipantRepo) paceBulkWrite(ctx context.Context, items int) {
+	rate := r.batchWriter.Rate()
+	if rate <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(time.Duration(float64(items) / rate * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
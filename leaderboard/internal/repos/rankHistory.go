@@ -0,0 +1,282 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// DefaultRankHistoryTableName is the DynamoDB table holding participants'
+// rank history
+const DefaultRankHistoryTableName = "PlatformLeaderboardRankHistory"
+
+// rankHistoryBucket is the span of rank history compacted into one item
+const rankHistoryBucket = 24 * time.Hour
+
+// rankHistoryPageSize is how many members are read from the copied
+// standings at a time while recording rank history
+const rankHistoryPageSize = 1000
+
+// rankHistoryTableName returns the table holding rank history
+func (r *ParticipantRepo) rankHistoryTableName() string {
+	if r.config.RankHistoryTableName != "" {
+		return r.config.RankHistoryTableName
+	}
+
+	return DefaultRankHistoryTableName
+}
+
+// getRankHistoryFreshKey returns the key that exists while the last rank
+// history point is younger than the history interval
+func (r *ParticipantRepo) getRankHistoryFreshKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":rankHistoryFresh"
+}
+
+// rankHistoryKey returns the DynamoDB key of the bucket of a participant's
+// rank history starting at bucketStart
+func rankHistoryKey(
+	leaderboardID string,
+	namespacedUserID string,
+	bucketStart time.Time,
+) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"historyID":   &types.AttributeValueMemberS{Value: leaderboardID + "#" + namespacedUserID},
+		"bucketStart": &types.AttributeValueMemberN{Value: strconv.FormatInt(bucketStart.Unix(), 10)},
+	}
+}
+
+// RecordRankHistory appends every participant's current rank and score to
+// their rank history and returns how many participants were recorded. With
+// a history interval configured, only the first call per interval records
+// and the others return zero, so every instance may call it on a schedule.
+// Points are compacted into one item per participant and day.
+func (r *ParticipantRepo) RecordRankHistory(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+) (int64, error) {
+	now := utils.GetCurrTimeStamp()
+
+	// Only one caller records per interval
+	if r.config.RankHistoryInterval > 0 {
+		claimed, err := r.redisClient.SetNX(
+			ctx,
+			r.getRankHistoryFreshKey(leaderboardID),
+			now.Unix(),
+			r.config.RankHistoryInterval,
+		).Result()
+		if err != nil {
+			return 0, fmt.Errorf(
+				"failed to check rank history age: %w",
+				err,
+			)
+		}
+		if !claimed {
+			return 0, nil
+		}
+	}
+
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return 0, err
+	}
+
+	// Copy the standings so every point of this run comes from the same
+	// moment, even while scores keep changing
+	copyKey, err := r.copyStandings(ctx, leaderboardID)
+	if err != nil {
+		return 0, err
+	}
+	defer r.redisClient.Del(context.WithoutCancel(ctx), copyKey)
+
+	bucketStart := now.Truncate(rankHistoryBucket)
+	var recorded int64
+	for start := int64(0); ; start += rankHistoryPageSize {
+		results, err := r.readStandings(ctx, copyKey, start, rankHistoryPageSize)
+		if err != nil {
+			return recorded, err
+		}
+
+		r.paceBulkWrite(ctx, len(results))
+		for i, result := range results {
+			point := models.RankHistoryPointModel{
+				At:    now.Unix(),
+				Rank:  start + int64(i) + 1,
+				Score: result.Score,
+			}
+			err := r.appendRankHistory(ctx, leaderboardID, result.Member.(string), bucketStart, point, leaderboardEndTime)
+			if err != nil {
+				return recorded, err
+			}
+			recorded++
+		}
+
+		if len(results) < rankHistoryPageSize {
+			break
+		}
+	}
+
+	return recorded, nil
+}
+
+// appendRankHistory appends a point to the participant's rank history
+// bucket, creating the bucket if needed
+func (r *ParticipantRepo) appendRankHistory(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	bucketStart time.Time,
+	point models.RankHistoryPointModel,
+	leaderboardEndTime time.Time,
+) error {
+	pointValue, err := attributevalue.Marshal(point)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to marshal rank history point: %w",
+			err,
+		)
+	}
+
+	updateExpression := "SET points = list_append(if_not_exists(points, :empty), :point)"
+	expressionAttributeNames := make(map[string]string)
+	expressionAttributeValues := map[string]types.AttributeValue{
+		":empty": &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+		":point": &types.AttributeValueMemberL{Value: []types.AttributeValue{pointValue}},
+	}
+
+	// History is cleaned up with the leaderboard's other items
+	if r.config.RetentionPeriod > 0 && !leaderboardEndTime.IsZero() {
+		updateExpression += ", #ttl = :expiresAt"
+		expressionAttributeNames["#ttl"] = r.ttlAttributeName()
+		expressionAttributeValues[":expiresAt"] = &types.AttributeValueMemberN{
+			Value: fmt.Sprintf("%d", leaderboardEndTime.Add(r.config.RetentionPeriod).Unix()),
+		}
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.rankHistoryTableName()),
+		Key:                       rankHistoryKey(leaderboardID, namespacedUserID, bucketStart),
+		UpdateExpression:          aws.String(updateExpression),
+		ExpressionAttributeValues: expressionAttributeValues,
+	}
+	if len(expressionAttributeNames) > 0 {
+		input.ExpressionAttributeNames = expressionAttributeNames
+	}
+	if _, err := r.dynamoClient.UpdateItem(ctx, input); err != nil {
+		return fmt.Errorf(
+			"failed to record rank history in DynamoDB: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// GetRankHistory returns the participant's recorded ranks between from and
+// to, inclusive, oldest first
+func (r *ParticipantRepo) GetRankHistory(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	from time.Time,
+	to time.Time,
+) ([]customTypes.RankHistoryPoint, error) {
+	firstBucket := rankHistoryKey(leaderboardID, namespacedUserID, from.Truncate(rankHistoryBucket))
+	lastBucket := rankHistoryKey(leaderboardID, namespacedUserID, to.Truncate(rankHistoryBucket))
+
+	paginator := dynamodb.NewQueryPaginator(r.dynamoClient, &dynamodb.QueryInput{
+		TableName:              aws.String(r.rankHistoryTableName()),
+		KeyConditionExpression: aws.String("historyID = :historyID AND bucketStart BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":historyID": firstBucket["historyID"],
+			":from":      firstBucket["bucketStart"],
+			":to":        lastBucket["bucketStart"],
+		},
+	})
+
+	var history []customTypes.RankHistoryPoint
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to query rank history: %w",
+				err,
+			)
+		}
+
+		var buckets []models.RankHistoryBucketModel
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &buckets); err != nil {
+			return nil, fmt.Errorf(
+				"failed to unmarshal rank history: %w",
+				err,
+			)
+		}
+		for _, bucket := range buckets {
+			for _, point := range bucket.Points {
+				at := time.Unix(point.At, 0).UTC()
+				if at.Before(from) || at.After(to) {
+					continue
+				}
+				history = append(history, customTypes.RankHistoryPoint{
+					At:    at,
+					Rank:  point.Rank,
+					Score: r.displayScore(point.Score),
+				})
+			}
+		}
+	}
+
+	return history, nil
+}
+
+// deleteRankHistory deletes the rank history of every participant of a
+// leaderboard, including those who left it, and returns how many items it
+// held. History is keyed by participant, so the table is scanned for the
+// leaderboard's items. With dryRun set the items are only counted.
+func (r *ParticipantRepo) deleteRankHistory(
+	ctx context.Context,
+	leaderboardID string,
+	dryRun bool,
+) (int64, error) {
+	paginator := dynamodb.NewScanPaginator(r.dynamoClient, &dynamodb.ScanInput{
+		TableName:            aws.String(r.rankHistoryTableName()),
+		FilterExpression:     aws.String("begins_with(historyID, :prefix)"),
+		ProjectionExpression: aws.String("historyID, bucketStart"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":prefix": &types.AttributeValueMemberS{Value: leaderboardID + "#"},
+		},
+	})
+
+	var items int64
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if isMissingTable(err) {
+			// Leaderboards without rank history have no table to clean
+			return 0, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf(
+				"failed to scan rank history: %w",
+				err,
+			)
+		}
+
+		items += int64(len(page.Items))
+		if dryRun || len(page.Items) == 0 {
+			continue
+		}
+		if err := r.deleteItemKeys(ctx, r.rankHistoryTableName(), page.Items); err != nil {
+			return 0, err
+		}
+	}
+
+	return items, nil
+}
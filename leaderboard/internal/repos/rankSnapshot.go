@@ -0,0 +1,128 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// getRankSnapshotKey returns the key holding a copy of the leaderboard as it
+// was when the last rank snapshot was taken
+func (r *ParticipantRepo) getRankSnapshotKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":rankSnapshot"
+}
+
+// getRankSnapshotFreshKey returns the key that exists while the current rank
+// snapshot is younger than the snapshot interval
+func (r *ParticipantRepo) getRankSnapshotFreshKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":rankSnapshotFresh"
+}
+
+// TakeRankSnapshot copies the current standings so later reads can report
+// how each participant's rank moved since
+func (r *ParticipantRepo) TakeRankSnapshot(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+) error {
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return err
+	}
+
+	snapshotKey := r.getRankSnapshotKey(leaderboardID)
+	pipe := r.redisClient.TxPipeline()
+	pipe.ZUnionStore(ctx, snapshotKey, &redis.ZStore{
+		Keys: []string{r.getRedisKey(leaderboardID)},
+	})
+	r.setupLeaderboardExpiry(ctx, snapshotKey, leaderboardEndTime, pipe)
+	if r.config.RankSnapshotInterval > 0 {
+		pipe.Set(
+			ctx,
+			r.getRankSnapshotFreshKey(leaderboardID),
+			utils.GetCurrTimeStamp().Unix(),
+			r.config.RankSnapshotInterval,
+		)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf(
+			"failed to take rank snapshot: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// rotateRankSnapshot takes a new snapshot if the current one is older than
+// the snapshot interval. The freshness key is claimed with SET NX, so only
+// one caller rotates per interval.
+func (r *ParticipantRepo) rotateRankSnapshot(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+) error {
+	claimed, err := r.redisClient.SetNX(
+		ctx,
+		r.getRankSnapshotFreshKey(leaderboardID),
+		utils.GetCurrTimeStamp().Unix(),
+		r.config.RankSnapshotInterval,
+	).Result()
+	if err != nil {
+		return fmt.Errorf(
+			"failed to check rank snapshot age: %w",
+			err,
+		)
+	}
+	if !claimed {
+		return nil
+	}
+
+	return r.TakeRankSnapshot(ctx, leaderboardID, leaderboardEndTime)
+}
+
+// annotateRankDeltas fills PreviousRank and RankDelta of each entry from the
+// rank snapshot. Entries absent from the snapshot are left at zero.
+func (r *ParticipantRepo) annotateRankDeltas(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+	entries []customTypes.MemberScore,
+) error {
+	if !r.config.RankDeltas || len(entries) == 0 {
+		return nil
+	}
+
+	if r.config.RankSnapshotInterval > 0 {
+		if err := r.rotateRankSnapshot(ctx, leaderboardID, leaderboardEndTime); err != nil {
+			return err
+		}
+	}
+
+	snapshotKey := r.getRankSnapshotKey(leaderboardID)
+	pipe := r.redisClient.Pipeline()
+	cmds := make([]*redis.IntCmd, len(entries))
+	for i, entry := range entries {
+		cmds[i] = pipe.ZRevRank(ctx, snapshotKey, entry.Member)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return fmt.Errorf(
+			"failed to read previous ranks: %w",
+			err,
+		)
+	}
+
+	for i, cmd := range cmds {
+		previous, err := cmd.Result()
+		if err != nil {
+			continue
+		}
+		entries[i].PreviousRank = previous + 1
+		entries[i].RankDelta = entries[i].PreviousRank - entries[i].Rank
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package repos
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+const (
+	// DefaultRebuildLockTTL bounds how long a crashed rebuilder blocks others
+	DefaultRebuildLockTTL = 60 * time.Second
+	// DefaultRebuildWaitTimeout is how long an instance waits for another
+	// instance's rebuild before giving up
+	DefaultRebuildWaitTimeout = 5 * time.Second
+	// rebuildPollInterval is how often waiting instances check for the key
+	rebuildPollInterval = 50 * time.Millisecond
+)
+
+// releaseLockScript deletes the lock only if it still holds our token, so an
+// instance whose lock expired never releases someone else's
+var releaseLockScript = newScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// renewLockScript extends the lock's TTL, given in ARGV[2] milliseconds,
+// only if it still holds our token
+var renewLockScript = newScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// getRebuildLockKey returns the Redis key guarding a leaderboard's rebuild
+func (r *ParticipantRepo) getRebuildLockKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":lock"
+}
+
+// acquireRebuildLock tries once to take the rebuild lock, returning the
+// token to release it with and whether it was acquired
+func (r *ParticipantRepo) acquireRebuildLock(
+	ctx context.Context,
+	leaderboardID string,
+) (string, bool, error) {
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", false, fmt.Errorf(
+			"failed to generate lock token: %w",
+			err,
+		)
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	acquired, err := r.redisClient.SetNX(ctx, r.getRebuildLockKey(leaderboardID), token, r.rebuildLockTTL()).Result()
+	if err != nil {
+		return "", false, fmt.Errorf(
+			"failed to acquire rebuild lock: %w",
+			err,
+		)
+	}
+
+	return token, acquired, nil
+}
+
+// rebuildLockTTL returns how long the rebuild lock lasts without renewal
+func (r *ParticipantRepo) rebuildLockTTL() time.Duration {
+	if r.config.RebuildLockTTL > 0 {
+		return r.config.RebuildLockTTL
+	}
+
+	return DefaultRebuildLockTTL
+}
+
+// keepRebuildLock renews the rebuild lock every third of its TTL while a
+// rebuild runs, so a sync outlasting the TTL never lets another instance
+// start a second rebuild. The returned function stops the renewal.
+func (r *ParticipantRepo) keepRebuildLock(
+	ctx context.Context,
+	leaderboardID string,
+	token string,
+) func() {
+	ttl := r.rebuildLockTTL()
+	keys := []string{r.getRebuildLockKey(leaderboardID)}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			renewed, err := renewLockScript.Run(ctx, r.redisClient, keys, token, ttl.Milliseconds()).Int()
+			if err != nil {
+				// The next tick tries again before the lock expires
+				fmt.Printf("Error renewing rebuild lock: %v\n", err)
+				continue
+			}
+			if renewed == 0 {
+				fmt.Printf("Error renewing rebuild lock: lock on %s was lost\n", leaderboardID)
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// releaseRebuildLock releases the rebuild lock if it is still ours
+func (r *ParticipantRepo) releaseRebuildLock(
+	ctx context.Context,
+	leaderboardID string,
+	token string,
+) {
+	err := releaseLockScript.Run(ctx, r.redisClient, []string{r.getRebuildLockKey(leaderboardID)}, token).Err()
+	if err != nil {
+		// The lock expires on its own, so only log
+		fmt.Printf("Error releasing rebuild lock: %v\n", err)
+	}
+}
+
+// waitForRebuild polls until another instance's rebuild has produced the
+// leaderboard key, returning ErrLeaderboardRebuilding if it does not appear
+// within the configured wait timeout, or if the rebuilder released the lock
+// without caching the leaderboard, e.g. after a cancelled sync
+func (r *ParticipantRepo) waitForRebuild(
+	ctx context.Context,
+	leaderboardID string,
+) error {
+	timeout := r.config.RebuildWaitTimeout
+	if timeout == 0 {
+		timeout = DefaultRebuildWaitTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	lockKey := r.getRebuildLockKey(leaderboardID)
+
+	for {
+		// Done once the leaderboard is cached; a released lock without it
+		// means the rebuild gave up, and writing now would start a partial
+		// sorted set
+		cached, err := r.isCached(ctx, leaderboardID)
+		if err != nil {
+			return err
+		}
+		locked, err := r.redisClient.Exists(ctx, lockKey).Result()
+		if err != nil {
+			return fmt.Errorf(
+				"failed to check rebuild progress: %w",
+				err,
+			)
+		}
+		if cached {
+			return nil
+		}
+
+		if locked == 0 || timeout < 0 || time.Now().After(deadline) {
+			return customTypes.ErrLeaderboardRebuilding
+		}
+		if err := sleepContext(ctx, rebuildPollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// prepareCacheForWrite ensures the cached leaderboard exists ahead of a
+// write. It reports false when another instance is still rebuilding, in
+// which case the write should only go to DynamoDB.
+func (r *ParticipantRepo) prepareCacheForWrite(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+) (bool, error) {
+	err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime)
+	if errors.Is(err, customTypes.ErrLeaderboardRebuilding) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
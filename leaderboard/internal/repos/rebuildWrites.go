@@ -0,0 +1,152 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/redis/go-redis/v9"
+)
+
+// noteRebuildWritesScript records members written while a rebuild holds the
+// leaderboard's lock, so the rebuild syncs them again once its keys are
+// swapped in; the rebuild may have read them from DynamoDB before the
+// write. It replies 1 if the members were noted and 0 when no rebuild is
+// running. KEYS[1] is the rebuild lock and KEYS[2] the noted members;
+// ARGV[1] is the set's TTL in seconds and the rest the members.
+var noteRebuildWritesScript = newScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+for i = 2, #ARGV do
+	redis.call("SADD", KEYS[2], ARGV[i])
+end
+redis.call("EXPIRE", KEYS[2], ARGV[1])
+return 1
+`)
+
+// drainRebuildWritesScript returns and removes the members noted during a
+// rebuild. Once none are left it releases the rebuild lock, if it still
+// holds our token, in the same step, so no write can be noted after the
+// last drain. KEYS[1] is the rebuild lock and KEYS[2] the noted members;
+// ARGV[1] is the lock's token.
+var drainRebuildWritesScript = newScript(`
+local members = redis.call("SMEMBERS", KEYS[2])
+if #members > 0 then
+	redis.call("DEL", KEYS[2])
+elseif redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("DEL", KEYS[1])
+end
+return members
+`)
+
+// getRebuildWritesKey returns the Redis set of members written while a
+// leaderboard was being rebuilt
+func (r *ParticipantRepo) getRebuildWritesKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":rebuildWrites"
+}
+
+// noteRebuildWrites queues the noting of members written to the cache in
+// pipe. Queue it in the same transaction as the write, so a rebuild's
+// final drain either sees the member or comes after the write.
+func (r *ParticipantRepo) noteRebuildWrites(
+	ctx context.Context,
+	leaderboardID string,
+	pipe redis.Pipeliner,
+	namespacedUserIDs ...string,
+) {
+	if len(namespacedUserIDs) == 0 {
+		return
+	}
+
+	args := make([]any, 0, len(namespacedUserIDs)+1)
+	args = append(args, int64(shadowKeyTTL.Seconds()))
+	for _, namespacedUserID := range namespacedUserIDs {
+		args = append(args, namespacedUserID)
+	}
+	noteRebuildWritesScript.Eval(
+		ctx,
+		pipe,
+		[]string{r.getRebuildLockKey(leaderboardID), r.getRebuildWritesKey(leaderboardID)},
+		args...,
+	)
+}
+
+// syncSkippedWrites makes writes that only reached DynamoDB because another
+// instance was rebuilding the cache visible to reads. A rebuild still
+// running syncs the members once it swaps its keys in; if it finished in
+// the meantime, they are synced here.
+func (r *ParticipantRepo) syncSkippedWrites(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+	namespacedUserIDs ...string,
+) {
+	if len(namespacedUserIDs) == 0 {
+		return
+	}
+
+	pipe := r.redisClient.Pipeline()
+	r.noteRebuildWrites(ctx, leaderboardID, pipe, namespacedUserIDs...)
+	cmds, err := pipe.Exec(ctx)
+	if err != nil {
+		r.queueRepair(leaderboardID, err, namespacedUserIDs...)
+		return
+	}
+	if noted, _ := cmds[0].(*redis.Cmd).Int(); noted == 1 {
+		return
+	}
+
+	// A rebuild that gave up leaves the leaderboard uncached, and the next
+	// one reads the writes from DynamoDB
+	cached, err := r.isCached(ctx, leaderboardID)
+	if err != nil {
+		r.queueRepair(leaderboardID, err, namespacedUserIDs...)
+		return
+	}
+	if !cached {
+		return
+	}
+	for _, namespacedUserID := range namespacedUserIDs {
+		_, err := r.syncMember(ctx, leaderboardID, namespacedUserID, leaderboardEndTime)
+		if err != nil && !errors.Is(err, customTypes.ErrParticipantNotFound) {
+			r.queueRepair(leaderboardID, err, namespacedUserID)
+		}
+	}
+}
+
+// replayRebuildWrites syncs the members written while a rebuild ran, and
+// those queued for repair, from DynamoDB once the rebuilt keys are swapped
+// in, then releases the rebuild lock. The swap replaced the cache with what
+// the rebuild read, which may predate those writes.
+func (r *ParticipantRepo) replayRebuildWrites(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+	token string,
+) {
+	keys := []string{r.getRebuildLockKey(leaderboardID), r.getRebuildWritesKey(leaderboardID)}
+	members := r.repairs.take(leaderboardID)
+	for {
+		for _, namespacedUserID := range members {
+			_, err := r.syncMember(ctx, leaderboardID, namespacedUserID, leaderboardEndTime)
+			if err != nil && !errors.Is(err, customTypes.ErrParticipantNotFound) {
+				r.queueRepair(leaderboardID, err, namespacedUserID)
+			}
+		}
+
+		var err error
+		members, err = drainRebuildWritesScript.Run(ctx, r.redisClient, keys, token).StringSlice()
+		if err != nil {
+			// Members still noted are left for the next rebuild to sync,
+			// and the caller releases the lock
+			fmt.Printf("Error replaying writes made during rebuild: %v\n", err)
+			return
+		}
+		if len(members) == 0 {
+			return
+		}
+	}
+}
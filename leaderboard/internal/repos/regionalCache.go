@@ -0,0 +1,87 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// applyIfExistsScript increments a member only while the leaderboard's
+// cached marker exists, so a remote update never creates a partial sorted
+// set. KEYS[1] is the leaderboard and KEYS[2] its marker.
+var applyIfExistsScript = newScript(`
+if redis.call("EXISTS", KEYS[2]) == 1 then
+	return redis.call("ZINCRBY", KEYS[1], ARGV[1], ARGV[2])
+end
+return false
+`)
+
+// ApplyRemoteScoreDelta applies a score delta written in another region to
+// the local Redis cache. Leaderboards that are not cached locally are left
+// alone; they pick up the delta from DynamoDB on their next sync.
+func (r *ParticipantRepo) ApplyRemoteScoreDelta(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	scoreDelta float64,
+) error {
+	err := applyIfExistsScript.Run(
+		ctx,
+		r.redisClient,
+		[]string{r.getRedisKey(leaderboardID), r.getCachedMarkerKey(leaderboardID)},
+		scoreDelta,
+		namespacedUserID,
+	).Err()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf(
+			"failed to apply remote score delta: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// RemoveRemoteMember removes a participant removed in another region from
+// the local Redis cache
+func (r *ParticipantRepo) RemoveRemoteMember(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+) error {
+	pipe := r.redisClient.Pipeline()
+	pipe.ZRem(ctx, r.getRedisKey(leaderboardID), namespacedUserID)
+	pipe.ZRem(ctx, r.getExpiriesKey(leaderboardID), namespacedUserID)
+	r.unindexMember(ctx, leaderboardID, namespacedUserID, pipe)
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return fmt.Errorf(
+			"failed to remove remote participant: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// InvalidateCache drops the locally cached leaderboard so the next access
+// rebuilds it from DynamoDB
+func (r *ParticipantRepo) InvalidateCache(
+	ctx context.Context,
+	leaderboardID string,
+) error {
+	err := r.redisClient.Del(
+		ctx,
+		r.getRedisKey(leaderboardID),
+		r.getCachedMarkerKey(leaderboardID),
+	).Err()
+	if err != nil {
+		return fmt.Errorf(
+			"failed to invalidate cached leaderboard: %w",
+			err,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// repairQueue remembers members whose cached score may disagree with
+// DynamoDB because a write reached only one of the stores. It lives in
+// memory, so repairs queued by an instance that dies are left to the next
+// rebuild of the cache.
+type repairQueue struct {
+	mu      sync.Mutex
+	pending map[string]map[string]struct{}
+}
+
+// add queues members of a leaderboard for repair
+func (q *repairQueue) add(leaderboardID string, namespacedUserIDs ...string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.pending == nil {
+		q.pending = make(map[string]map[string]struct{})
+	}
+	members, ok := q.pending[leaderboardID]
+	if !ok {
+		members = make(map[string]struct{})
+		q.pending[leaderboardID] = members
+	}
+	for _, namespacedUserID := range namespacedUserIDs {
+		members[namespacedUserID] = struct{}{}
+	}
+}
+
+// take removes and returns the members of a leaderboard queued for repair
+func (q *repairQueue) take(leaderboardID string) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	members := make([]string, 0, len(q.pending[leaderboardID]))
+	for namespacedUserID := range q.pending[leaderboardID] {
+		members = append(members, namespacedUserID)
+	}
+	delete(q.pending, leaderboardID)
+
+	return members
+}
+
+// leaderboards returns the leaderboards with repairs queued
+func (q *repairQueue) leaderboards() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	leaderboardIDs := make([]string, 0, len(q.pending))
+	for leaderboardID := range q.pending {
+		leaderboardIDs = append(leaderboardIDs, leaderboardID)
+	}
+
+	return leaderboardIDs
+}
+
+// has reports whether a leaderboard has repairs queued
+func (q *repairQueue) has(leaderboardID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.pending[leaderboardID]) > 0
+}
+
+// queueRepair records that a write reached DynamoDB but not the cache, or
+// the other way round, so the members are repaired from DynamoDB later
+func (r *ParticipantRepo) queueRepair(
+	leaderboardID string,
+	cause error,
+	namespacedUserIDs ...string,
+) {
+	fmt.Printf("Error updating cache, queued %d members for repair: %v\n", len(namespacedUserIDs), cause)
+	r.repairs.add(leaderboardID, namespacedUserIDs...)
+}
+
+// RepairCache sets the cached score of every member queued for repair on a
+// leaderboard to the one stored in DynamoDB, removing members DynamoDB no
+// longer holds. A member whose cached score changes while it is read is
+// left queued rather than overwritten, as is a member that could not be
+// repaired. It returns how many members were repaired.
+func (r *ParticipantRepo) RepairCache(
+	ctx context.Context,
+	leaderboardID string,
+) (int, error) {
+	members := r.repairs.take(leaderboardID)
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	repaired := 0
+	var errs []error
+	for _, namespacedUserID := range members {
+		// The end time is not known here; views the repair creates get
+		// their expiry when the cache's expiry is next reconciled
+		synced, err := r.syncMember(ctx, leaderboardID, namespacedUserID, time.Time{})
+		if errors.Is(err, customTypes.ErrParticipantNotFound) {
+			repaired++
+			continue
+		}
+		if err != nil {
+			// Members that fail to repair are queued again
+			r.repairs.add(leaderboardID, namespacedUserID)
+			errs = append(errs, fmt.Errorf(
+				"failed to repair cached participant: %w",
+				err,
+			))
+			continue
+		}
+		// A zero rank means the member was queued again, or the leaderboard
+		// is no longer cached and its next rebuild loads the member
+		if synced.Rank > 0 {
+			repaired++
+		}
+	}
+
+	return repaired, errors.Join(errs...)
+}
+
+// RepairAllCaches repairs every leaderboard with members queued for repair
+func (r *ParticipantRepo) RepairAllCaches(ctx context.Context) (int, error) {
+	repaired := 0
+	var errs []error
+	for _, leaderboardID := range r.repairs.leaderboards() {
+		n, err := r.RepairCache(ctx, leaderboardID)
+		repaired += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return repaired, errors.Join(errs...)
+}
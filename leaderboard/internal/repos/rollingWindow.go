@@ -0,0 +1,227 @@
+package repos
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// getRollingKey returns the Redis key of the rolling window view
+func (r *ParticipantRepo) getRollingKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":rolling"
+}
+
+// getRollingBucketKey returns the Redis key of the bucket starting at start
+func (r *ParticipantRepo) getRollingBucketKey(leaderboardID string, start time.Time) string {
+	return r.getRollingKey(leaderboardID) + ":" + strconv.FormatInt(start.Unix(), 10)
+}
+
+// rollingBucketStart returns the start of the bucket containing t
+func (r *ParticipantRepo) rollingBucketStart(t time.Time) time.Time {
+	return t.Truncate(r.config.RollingBucket)
+}
+
+// recordRollingScore adds a write to the bucket of the time it was made. The
+// bucket expires once it has left the window.
+func (r *ParticipantRepo) recordRollingScore(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	storedDelta float64,
+	now time.Time,
+	pipe redis.Pipeliner,
+) {
+	if r.config.RollingWindow <= 0 {
+		return
+	}
+
+	bucketKey := r.getRollingBucketKey(leaderboardID, r.rollingBucketStart(now))
+	pipe.ZIncrBy(ctx, bucketKey, storedDelta, namespacedUserID)
+	pipe.Expire(ctx, bucketKey, r.config.RollingWindow+r.config.RollingBucket)
+}
+
+// rollingBucketStarts returns the starts of every bucket that may still
+// hold writes at now, oldest first
+func (r *ParticipantRepo) rollingBucketStarts(now time.Time) []time.Time {
+	var starts []time.Time
+	oldest := r.rollingBucketStart(now.Add(-r.config.RollingWindow))
+	for start := oldest; !start.After(now); start = start.Add(r.config.RollingBucket) {
+		starts = append(starts, start)
+	}
+
+	return starts
+}
+
+// rollingKeys returns the rolling window view and its live buckets
+func (r *ParticipantRepo) rollingKeys(leaderboardID string) []string {
+	if r.config.RollingWindow <= 0 {
+		return nil
+	}
+
+	keys := []string{r.getRollingKey(leaderboardID)}
+	for _, start := range r.rollingBucketStarts(utils.GetCurrTimeStamp()) {
+		keys = append(keys, r.getRollingBucketKey(leaderboardID, start))
+	}
+
+	return keys
+}
+
+// RefreshRollingWindow rebuilds the rolling window view as the union of the
+// buckets still inside the window. It is meant to be run on a schedule, at
+// least once per bucket.
+func (r *ParticipantRepo) RefreshRollingWindow(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+) error {
+	if r.config.RollingWindow <= 0 {
+		return customTypes.ErrRollingWindowDisabled
+	}
+
+	// The oldest bucket only counts once it lies wholly inside the window
+	now := utils.GetCurrTimeStamp()
+	var bucketKeys []string
+	for _, start := range r.rollingBucketStarts(now) {
+		if !start.Before(now.Add(-r.config.RollingWindow)) {
+			bucketKeys = append(bucketKeys, r.getRollingBucketKey(leaderboardID, start))
+		}
+	}
+
+	rollingKey := r.getRollingKey(leaderboardID)
+	pipe := r.redisClient.TxPipeline()
+	pipe.ZUnionStore(ctx, rollingKey, &redis.ZStore{
+		Keys:      bucketKeys,
+		Aggregate: "SUM",
+	})
+	r.setupLeaderboardExpiry(ctx, rollingKey, leaderboardEndTime, pipe)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf(
+			"failed to refresh rolling window: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// GetTopNRollingParticipants retrieves the top N participants of the rolling
+// window view, refreshing it first if it does not exist yet
+func (r *ParticipantRepo) GetTopNRollingParticipants(
+	ctx context.Context,
+	leaderboardID string,
+	n int64,
+	leaderboardEndTime time.Time,
+) ([]customTypes.MemberScore, error) {
+	if r.config.RollingWindow <= 0 {
+		return nil, customTypes.ErrRollingWindowDisabled
+	}
+
+	rollingKey := r.getRollingKey(leaderboardID)
+	exists, err := r.redisClient.Exists(ctx, rollingKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to check if Redis key exists: %w",
+			err,
+		)
+	}
+	if exists == 0 {
+		if err := r.RefreshRollingWindow(ctx, leaderboardID, leaderboardEndTime); err != nil {
+			return nil, err
+		}
+	}
+
+	results, err := r.redisClient.ZRevRangeWithScores(ctx, rollingKey, 0, n-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to get top N rolling participants from Redis: %w",
+			err,
+		)
+	}
+
+	participants := make([]customTypes.MemberScore, len(results))
+	for i, result := range results {
+		participants[i] = customTypes.MemberScore{
+			Member:     result.Member.(string),
+			Score:      r.displayScore(result.Score),
+			ComputedAt: utils.GetCurrTimeStamp(),
+			Rank:       int64(i + 1),
+		}
+	}
+
+	return participants, nil
+}
+
+// GetTopVelocityParticipants retrieves the N participants who gained the
+// most score over the trailing window, summed from the rolling window's
+// buckets. The window is rounded to whole buckets and may not exceed the
+// rolling window.
+func (r *ParticipantRepo) GetTopVelocityParticipants(
+	ctx context.Context,
+	leaderboardID string,
+	window time.Duration,
+	n int64,
+) ([]customTypes.MemberScore, error) {
+	if r.config.RollingWindow <= 0 {
+		return nil, customTypes.ErrRollingWindowDisabled
+	}
+	if window <= 0 || window > r.config.RollingWindow {
+		return nil, fmt.Errorf(
+			"velocity window must be between zero and the rolling window of %v",
+			r.config.RollingWindow,
+		)
+	}
+
+	// Like the rolling view, only count buckets wholly inside the window,
+	// but always the current one
+	now := utils.GetCurrTimeStamp()
+	var bucketKeys []string
+	for _, start := range r.rollingBucketStarts(now) {
+		if !start.Before(now.Add(-window)) || start.Equal(r.rollingBucketStart(now)) {
+			bucketKeys = append(bucketKeys, r.getRollingBucketKey(leaderboardID, start))
+		}
+	}
+
+	// Sum the buckets into a short-lived key
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf(
+			"failed to generate temporary key: %w",
+			err,
+		)
+	}
+	tmpKey := r.getRollingKey(leaderboardID) + ":velocityTmp:" + hex.EncodeToString(suffix)
+
+	pipe := r.redisClient.Pipeline()
+	pipe.ZUnionStore(ctx, tmpKey, &redis.ZStore{
+		Keys:      bucketKeys,
+		Aggregate: "SUM",
+	})
+	pipe.Expire(ctx, tmpKey, 10*time.Second)
+	rangeCmd := pipe.ZRevRangeWithScores(ctx, tmpKey, 0, n-1)
+	pipe.Del(ctx, tmpKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf(
+			"failed to get top velocity participants from Redis: %w",
+			err,
+		)
+	}
+
+	participants := make([]customTypes.MemberScore, len(rangeCmd.Val()))
+	for i, result := range rangeCmd.Val() {
+		participants[i] = customTypes.MemberScore{
+			Member:     result.Member.(string),
+			Score:      r.displayScore(result.Score),
+			ComputedAt: now,
+			Rank:       int64(i + 1),
+		}
+	}
+
+	return participants, nil
+}
@@ -0,0 +1,212 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// schemaVersionAttribute is the participant item attribute recording which
+// schema migrations the item has been through. Items without it are at
+// version zero.
+const schemaVersionAttribute = "schemaVersion"
+
+// SchemaMigration upgrades a participant item by one schema version in
+// place, e.g. adding a field or renaming an attribute. Score updates do not
+// stamp the version on existing items, so items created by one may already
+// have the new shape; migrations must leave such items unchanged.
+type SchemaMigration func(item map[string]types.AttributeValue) error
+
+// currentSchemaVersion returns the version items are at once every
+// registered migration has been applied
+func (r *ParticipantRepo) currentSchemaVersion() int {
+	return len(r.config.SchemaMigrations)
+}
+
+// schemaVersionValue returns the attribute value stamping the current
+// schema version on a new item
+func (r *ParticipantRepo) schemaVersionValue() types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.Itoa(r.currentSchemaVersion())}
+}
+
+// itemSchemaVersion returns the schema version of a participant item
+func itemSchemaVersion(item map[string]types.AttributeValue) int {
+	n, ok := item[schemaVersionAttribute].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0
+	}
+	version, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return 0
+	}
+
+	return version
+}
+
+// migrateItem applies the migrations the item has not been through yet, in
+// place, and returns the version it was at. Items written by a newer schema
+// are left alone.
+func (r *ParticipantRepo) migrateItem(item map[string]types.AttributeValue) (int, error) {
+	from := itemSchemaVersion(item)
+	current := r.currentSchemaVersion()
+	if from >= current {
+		return from, nil
+	}
+
+	for version := from; version < current; version++ {
+		if err := r.config.SchemaMigrations[version](item); err != nil {
+			return from, fmt.Errorf(
+				"failed to migrate item from schema version %d: %w",
+				version,
+				err,
+			)
+		}
+	}
+	item[schemaVersionAttribute] = r.schemaVersionValue()
+
+	return from, nil
+}
+
+// storeMigratedItem writes back an item migrated from schema version from.
+// The write only goes through if the item is unchanged since it was read,
+// reporting false otherwise; it is then migrated again on its next read.
+func (r *ParticipantRepo) storeMigratedItem(
+	ctx context.Context,
+	item map[string]types.AttributeValue,
+	from int,
+) (bool, error) {
+	condition := "attribute_not_exists(#version)"
+	names := map[string]string{
+		"#version":   schemaVersionAttribute,
+		"#updatedAt": "updated_at",
+	}
+	values := make(map[string]types.AttributeValue)
+	if from > 0 {
+		condition = "#version = :from"
+		values[":from"] = &types.AttributeValueMemberN{Value: strconv.Itoa(from)}
+	}
+
+	// Every write touches updated_at, so an unchanged value means no write
+	// landed since the read
+	if updatedAt, ok := item["updated_at"]; ok {
+		condition += " AND #updatedAt = :updatedAt"
+		values[":updatedAt"] = updatedAt
+	} else {
+		condition += " AND attribute_not_exists(#updatedAt)"
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:                aws.String(r.tableName),
+		Item:                     item,
+		ConditionExpression:      aws.String(condition),
+		ExpressionAttributeNames: names,
+	}
+	if len(values) > 0 {
+		input.ExpressionAttributeValues = values
+	}
+	if _, err := r.dynamoClient.PutItem(ctx, input); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return false, nil
+		}
+		return false, fmt.Errorf(
+			"failed to store migrated item in DynamoDB: %w",
+			err,
+		)
+	}
+
+	return true, nil
+}
+
+// migrateOnRead migrates an item just read from DynamoDB and writes it
+// back. Failing migrations fail the read, while
+// failed write-backs are only logged since the item is migrated again on
+// its next read.
+func (r *ParticipantRepo) migrateOnRead(
+	ctx context.Context,
+	item map[string]types.AttributeValue,
+) error {
+	from, err := r.migrateItem(item)
+	if err != nil {
+		return err
+	}
+	if from >= r.currentSchemaVersion() {
+		return nil
+	}
+
+	if _, err := r.storeMigratedItem(ctx, item, from); err != nil {
+		fmt.Printf("Error storing migrated participant: %v\n", err)
+	}
+
+	return nil
+}
+
+// MigrateSchema migrates every participant item of the leaderboard still at
+// an older schema version and returns how many were migrated. Items written
+// to while they are migrated are skipped and picked up by a later run or
+// their next read. Writes are paced at the bulk write rate.
+func (r *ParticipantRepo) MigrateSchema(
+	ctx context.Context,
+	leaderboardID string,
+) (int64, error) {
+	current := r.currentSchemaVersion()
+	if current == 0 {
+		return 0, nil
+	}
+
+	var (
+		mu       sync.Mutex
+		migrated int64
+	)
+	err := r.queryPartitions(
+		ctx,
+		r.readPartitionKeys(leaderboardID),
+		func(partitionKey string) *dynamodb.QueryInput {
+			keyCondition, keyValues := r.config.KeySchema.PartitionQuery(partitionKey)
+			keyValues[":current"] = r.schemaVersionValue()
+			keyNames := r.config.KeySchema.conditionNames(keyCondition)
+			keyNames["#version"] = schemaVersionAttribute
+
+			return &dynamodb.QueryInput{
+				TableName:                 aws.String(r.tableName),
+				KeyConditionExpression:    aws.String(keyCondition),
+				FilterExpression:          aws.String("attribute_not_exists(#version) OR #version < :current"),
+				ExpressionAttributeNames:  keyNames,
+				ExpressionAttributeValues: keyValues,
+			}
+		},
+		func(items []map[string]types.AttributeValue) error {
+			for _, item := range items {
+				if _, ok := r.config.KeySchema.memberFromItem(item); !ok {
+					continue
+				}
+				from, err := r.migrateItem(item)
+				if err != nil {
+					return err
+				}
+
+				r.paceBulkWrite(ctx, 1)
+				stored, err := r.storeMigratedItem(ctx, item, from)
+				if err != nil {
+					return err
+				}
+				if stored {
+					mu.Lock()
+					migrated++
+					mu.Unlock()
+				}
+			}
+			return nil
+		},
+	)
+
+	mu.Lock()
+	defer mu.Unlock()
+	return migrated, err
+}
@@ -0,0 +1,93 @@
+package repos
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// scoreLimit returns the largest score magnitude accepted in one write
+func (r *ParticipantRepo) scoreLimit() float64 {
+	if r.config.MaxScoreDelta > 0 {
+		return r.config.MaxScoreDelta
+	}
+	if r.config.ScaledScores {
+		return models.ScoreLimit(r.config.ScorePrecision)
+	}
+
+	return models.MaxExactScore
+}
+
+// storedScore validates a score and converts it into the units stored in
+// DynamoDB and Redis
+func (r *ParticipantRepo) storedScore(score float64) (float64, error) {
+	if err := models.ValidateScore(score, r.scoreLimit()); err != nil {
+		return 0, err
+	}
+	if r.config.IntegerScores && score != math.Trunc(score) {
+		return 0, customTypes.ErrFractionalScore
+	}
+	if !r.config.ScaledScores {
+		return score, nil
+	}
+
+	units, err := models.ScaleScore(score, r.config.ScorePrecision)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(units), nil
+}
+
+// displayScore converts stored units back into the caller's score, rounded
+// to the display precision when one is configured
+func (r *ParticipantRepo) displayScore(units float64) float64 {
+	score := r.unscaleScore(units)
+	if !r.config.RoundDisplayScores {
+		return score
+	}
+
+	return models.RoundScore(score, r.config.DisplayPrecision, r.config.DisplayRounding)
+}
+
+// unscaleScore converts stored units back into the caller's score exactly
+func (r *ParticipantRepo) unscaleScore(units float64) float64 {
+	if !r.config.ScaledScores {
+		return units
+	}
+
+	return models.UnscaleScore(units, r.config.ScorePrecision)
+}
+
+// formatStoredScore formats stored units as an exact DynamoDB number
+func formatStoredScore(units float64) string {
+	return strconv.FormatFloat(units, 'f', -1, 64)
+}
+
+// CacheIncrement returns the increment applied to the cached sorted set for
+// a score delta, so it can be replayed against other caches
+func (r *ParticipantRepo) CacheIncrement(scoreDelta float64) (float64, error) {
+	return r.storedScore(scoreDelta)
+}
+
+// totalBound returns the stored total a participant may have before an
+// increment of delta would leave the exactly representable range
+func totalBound(delta float64) float64 {
+	if delta >= 0 {
+		return models.MaxExactScore - delta
+	}
+
+	return -models.MaxExactScore - delta
+}
+
+// totalBoundCondition returns the DynamoDB condition keeping a participant's
+// total within the exactly representable range after adding delta
+func (r *ParticipantRepo) totalBoundCondition(delta float64) string {
+	if delta >= 0 {
+		return "attribute_not_exists(score) OR score <= :bound"
+	}
+
+	return "attribute_not_exists(score) OR score >= :bound"
+}
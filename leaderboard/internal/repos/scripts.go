@@ -0,0 +1,50 @@
+package repos
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scriptInfo describes a Lua script the repo runs
+type scriptInfo struct {
+	source   string
+	readOnly bool
+}
+
+// scripts holds every script the repo runs, keyed by SHA1, so that
+// commands running them by hash can be replayed elsewhere. It is only
+// written during package initialisation.
+var scripts = make(map[string]scriptInfo)
+
+// newScript registers a script that may write
+func newScript(source string) *redis.Script {
+	return registerScript(source, false)
+}
+
+// newReadOnlyScript registers a script that only reads
+func newReadOnlyScript(source string) *redis.Script {
+	return registerScript(source, true)
+}
+
+// registerScript records a script's source under its hash
+func registerScript(source string, readOnly bool) *redis.Script {
+	script := redis.NewScript(source)
+	scripts[script.Hash()] = scriptInfo{source: source, readOnly: readOnly}
+
+	return script
+}
+
+// ScriptSource returns the source of one of the repo's scripts by SHA1 and
+// whether it only reads
+func ScriptSource(sha string) (string, bool, bool) {
+	info, ok := scripts[sha]
+	return info.source, info.readOnly, ok
+}
+
+// ScriptHash returns the SHA1 Redis identifies a script source by
+func ScriptHash(source string) string {
+	sum := sha1.Sum([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,100 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// SeedParticipants writes system-controlled participants with fixed scores,
+// replacing any earlier seed of the same participant
+func (r *ParticipantRepo) SeedParticipants(
+	ctx context.Context,
+	leaderboardID string,
+	participants []*models.ParticipantModel,
+	leaderboardEndTime time.Time,
+) error {
+	if len(participants) == 0 {
+		return nil
+	}
+
+	// Seeds after the end are subject to the same policy as score updates
+	if _, err := r.checkWriteDeadline(
+		ctx,
+		leaderboardID,
+		leaderboardEndTime,
+		utils.GetCurrTimeStamp(),
+	); err != nil {
+		return err
+	}
+
+	// Reject scores that cannot be stored exactly before writing anything
+	storedScores := make([]float64, len(participants))
+	for i, participant := range participants {
+		storedScore, err := r.storedScore(participant.Score)
+		if err != nil {
+			return err
+		}
+		storedScores[i] = storedScore
+	}
+
+	// Ensure Redis key exists before writing
+	cacheReady, err := r.prepareCacheForWrite(ctx, leaderboardID, leaderboardEndTime)
+	if err != nil {
+		return err
+	}
+
+	requests := make([]types.WriteRequest, len(participants))
+	expiries := make([]time.Time, len(participants))
+	ttlEnabled := false
+	for i, participant := range participants {
+		var item map[string]types.AttributeValue
+		item, expiries[i], ttlEnabled, err = r.participantItem(participant, storedScores[i], leaderboardEndTime)
+		if err != nil {
+			return err
+		}
+		requests[i] = types.WriteRequest{
+			PutRequest: &types.PutRequest{Item: item},
+		}
+	}
+	if err := r.batchWrite(ctx, requests); err != nil {
+		return err
+	}
+
+	members := make([]string, len(participants))
+	for i, participant := range participants {
+		members[i] = participant.NamespacedUserID
+	}
+
+	// A rebuild still in progress picks the seeds up from DynamoDB
+	if !cacheReady {
+		r.syncSkippedWrites(ctx, leaderboardID, leaderboardEndTime, members...)
+		return nil
+	}
+
+	// Add the seeds unless the cache was dropped since the check
+	pipe := r.redisClient.TxPipeline()
+	r.noteRebuildWrites(ctx, leaderboardID, pipe, members...)
+	seeded := make([]redis.Z, len(participants))
+	for i, participant := range participants {
+		seeded[i] = redis.Z{
+			Score:  storedScores[i],
+			Member: participant.NamespacedUserID,
+		}
+		if ttlEnabled {
+			r.trackParticipantExpiry(ctx, leaderboardID, participant.NamespacedUserID, expiries[i], leaderboardEndTime, pipe)
+		}
+	}
+	r.addIfCached(ctx, leaderboardID, seeded, pipe)
+
+	// The seeds are stored, so a cache failure is repaired later
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		r.queueRepair(leaderboardID, err, members...)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package repos
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// copyStandings copies the cached leaderboard into a short-lived key and
+// returns it, so long reads see the standings of a single moment. The
+// caller deletes the key when done; it expires on its own otherwise.
+func (r *ParticipantRepo) copyStandings(
+	ctx context.Context,
+	leaderboardID string,
+) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf(
+			"failed to generate temporary key: %w",
+			err,
+		)
+	}
+	copyKey := r.getRedisKey(leaderboardID) + ":standingsTmp:" + hex.EncodeToString(suffix)
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.ZUnionStore(ctx, copyKey, &redis.ZStore{
+		Keys: []string{r.getRedisKey(leaderboardID)},
+	})
+	pipe.Expire(ctx, copyKey, shadowKeyTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf(
+			"failed to copy standings: %w",
+			err,
+		)
+	}
+
+	return copyKey, nil
+}
+
+// readStandings reads up to count members of copied standings in rank
+// order, starting at the 0-based rank start
+func (r *ParticipantRepo) readStandings(
+	ctx context.Context,
+	copyKey string,
+	start int64,
+	count int64,
+) ([]redis.Z, error) {
+	results, err := r.redisClient.ZRevRangeWithScores(ctx, copyKey, start, start+count-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read standings: %w",
+			err,
+		)
+	}
+
+	return results, nil
+}
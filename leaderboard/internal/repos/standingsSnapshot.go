@@ -0,0 +1,345 @@
+package repos
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// DefaultSnapshotTableName is the DynamoDB table holding standings snapshots
+const DefaultSnapshotTableName = "PlatformLeaderboardSnapshots"
+
+// snapshotChunkSize is how many members are compressed into one snapshot
+// item, keeping each well under DynamoDB's item size limit
+const snapshotChunkSize = 5000
+
+// snapshotTableName returns the table holding standings snapshots
+func (r *ParticipantRepo) snapshotTableName() string {
+	if r.config.SnapshotTableName != "" {
+		return r.config.SnapshotTableName
+	}
+
+	return DefaultSnapshotTableName
+}
+
+// getSnapshotFreshKey returns the key that exists while the last standings
+// snapshot is younger than the snapshot interval
+func (r *ParticipantRepo) getSnapshotFreshKey(leaderboardID string) string {
+	return r.getRedisKey(leaderboardID) + ":snapshotFresh"
+}
+
+// snapshotPrefix returns the snapshotID prefix shared by the chunks of the
+// snapshot taken at takenAt. IDs are zero padded so they sort by time.
+func snapshotPrefix(takenAt int64) string {
+	return fmt.Sprintf("%012d#", takenAt)
+}
+
+// ClaimStandingsSnapshot reports whether the caller should take the next
+// scheduled snapshot. Only the first caller per interval is told to.
+func (r *ParticipantRepo) ClaimStandingsSnapshot(
+	ctx context.Context,
+	leaderboardID string,
+	interval time.Duration,
+) (bool, error) {
+	claimed, err := r.redisClient.SetNX(
+		ctx,
+		r.getSnapshotFreshKey(leaderboardID),
+		utils.GetCurrTimeStamp().Unix(),
+		interval,
+	).Result()
+	if err != nil {
+		return false, fmt.Errorf(
+			"failed to check snapshot age: %w",
+			err,
+		)
+	}
+
+	return claimed, nil
+}
+
+// TakeStandingsSnapshot stores the full standings, gzip compressed in
+// chunks, and returns when they were taken. The chunks are written in
+// order and each records how many there are, so a snapshot cut short is
+// detected when it is read.
+func (r *ParticipantRepo) TakeStandingsSnapshot(
+	ctx context.Context,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+) (time.Time, error) {
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return time.Time{}, err
+	}
+
+	// Copy the standings so the snapshot comes from a single moment
+	takenAt := utils.GetCurrTimeStamp()
+	copyKey, err := r.copyStandings(ctx, leaderboardID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer r.redisClient.Del(context.WithoutCancel(ctx), copyKey)
+
+	total, err := r.redisClient.ZCard(ctx, copyKey).Result()
+	if err != nil {
+		return time.Time{}, fmt.Errorf(
+			"failed to count standings: %w",
+			err,
+		)
+	}
+	chunks := (total + snapshotChunkSize - 1) / snapshotChunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+
+	for chunk := int64(0); chunk < chunks; chunk++ {
+		results, err := r.readStandings(ctx, copyKey, chunk*snapshotChunkSize, snapshotChunkSize)
+		if err != nil {
+			return time.Time{}, err
+		}
+		entries := make([]models.SnapshotEntryModel, len(results))
+		for i, result := range results {
+			entries[i] = models.SnapshotEntryModel{
+				Member: result.Member.(string),
+				Score:  result.Score,
+			}
+		}
+		standings, err := compressSnapshot(entries)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		item := map[string]types.AttributeValue{
+			"leaderboardID": &types.AttributeValueMemberS{Value: leaderboardID},
+			"snapshotID": &types.AttributeValueMemberS{
+				Value: snapshotPrefix(takenAt.Unix()) + fmt.Sprintf("%06d", chunk),
+			},
+			"chunks":    &types.AttributeValueMemberN{Value: strconv.FormatInt(chunks, 10)},
+			"standings": &types.AttributeValueMemberB{Value: standings},
+		}
+
+		// Snapshots are cleaned up with the leaderboard's other items
+		if r.config.RetentionPeriod > 0 && !leaderboardEndTime.IsZero() {
+			item[r.ttlAttributeName()] = &types.AttributeValueMemberN{
+				Value: fmt.Sprintf("%d", leaderboardEndTime.Add(r.config.RetentionPeriod).Unix()),
+			}
+		}
+
+		r.paceBulkWrite(ctx, 1)
+		_, err = r.dynamoClient.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(r.snapshotTableName()),
+			Item:      item,
+		})
+		if err != nil {
+			return time.Time{}, fmt.Errorf(
+				"failed to store snapshot in DynamoDB: %w",
+				err,
+			)
+		}
+	}
+
+	return takenAt, nil
+}
+
+// compressSnapshot gzips the JSON encoding of snapshot entries
+func compressSnapshot(entries []models.SnapshotEntryModel) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(writer).Encode(entries); err != nil {
+		return nil, fmt.Errorf(
+			"failed to encode snapshot: %w",
+			err,
+		)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf(
+			"failed to compress snapshot: %w",
+			err,
+		)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompressSnapshot reverses compressSnapshot
+func decompressSnapshot(data []byte) ([]models.SnapshotEntryModel, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to decompress snapshot: %w",
+			err,
+		)
+	}
+	defer reader.Close()
+
+	var entries []models.SnapshotEntryModel
+	if err := json.NewDecoder(reader).Decode(&entries); err != nil {
+		return nil, fmt.Errorf(
+			"failed to decode snapshot: %w",
+			err,
+		)
+	}
+
+	return entries, nil
+}
+
+// loadStandingsSnapshot returns the latest snapshot taken at or before at,
+// in rank order, along with when it was taken
+func (r *ParticipantRepo) loadStandingsSnapshot(
+	ctx context.Context,
+	leaderboardID string,
+	at time.Time,
+) (time.Time, []models.SnapshotEntryModel, error) {
+	// The last chunk at or before the time belongs to the snapshot sought
+	latest, err := r.dynamoClient.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.snapshotTableName()),
+		KeyConditionExpression: aws.String("leaderboardID = :lid AND snapshotID < :before"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":lid":    &types.AttributeValueMemberS{Value: leaderboardID},
+			":before": &types.AttributeValueMemberS{Value: snapshotPrefix(at.Unix() + 1)},
+		},
+		ProjectionExpression: aws.String("snapshotID"),
+		ScanIndexForward:     aws.Bool(false),
+		Limit:                aws.Int32(1),
+	})
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf(
+			"failed to find snapshot: %w",
+			err,
+		)
+	}
+	if len(latest.Items) == 0 {
+		return time.Time{}, nil, customTypes.ErrSnapshotNotFound
+	}
+	snapshotID, _ := latest.Items[0]["snapshotID"].(*types.AttributeValueMemberS)
+	if snapshotID == nil {
+		return time.Time{}, nil, customTypes.ErrSnapshotNotFound
+	}
+	prefix, _, _ := strings.Cut(snapshotID.Value, "#")
+	takenAtUnix, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf(
+			"failed to parse snapshot ID %q: %w",
+			snapshotID.Value,
+			err,
+		)
+	}
+
+	// Read every chunk of that snapshot in order
+	paginator := dynamodb.NewQueryPaginator(r.dynamoClient, &dynamodb.QueryInput{
+		TableName:              aws.String(r.snapshotTableName()),
+		KeyConditionExpression: aws.String("leaderboardID = :lid AND begins_with(snapshotID, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":lid":    &types.AttributeValueMemberS{Value: leaderboardID},
+			":prefix": &types.AttributeValueMemberS{Value: snapshotPrefix(takenAtUnix)},
+		},
+	})
+	var entries []models.SnapshotEntryModel
+	var chunks, expected int64
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf(
+				"failed to read snapshot: %w",
+				err,
+			)
+		}
+		for _, item := range page.Items {
+			if n, ok := item["chunks"].(*types.AttributeValueMemberN); ok {
+				expected, _ = strconv.ParseInt(n.Value, 10, 64)
+			}
+			data, _ := item["standings"].(*types.AttributeValueMemberB)
+			if data == nil {
+				continue
+			}
+			chunkEntries, err := decompressSnapshot(data.Value)
+			if err != nil {
+				return time.Time{}, nil, err
+			}
+			entries = append(entries, chunkEntries...)
+			chunks++
+		}
+	}
+	if chunks != expected {
+		return time.Time{}, nil, fmt.Errorf(
+			"snapshot taken at %d is incomplete: %d of %d chunks stored",
+			takenAtUnix,
+			chunks,
+			expected,
+		)
+	}
+
+	return time.Unix(takenAtUnix, 0).UTC(), entries, nil
+}
+
+// DiffSnapshots compares the latest snapshots taken at or before from and
+// to and returns the participants whose rank changed between them: the
+// climbers and fallers ordered by how far they moved, and the entrants
+// ordered by rank
+func (r *ParticipantRepo) DiffSnapshots(
+	ctx context.Context,
+	leaderboardID string,
+	from time.Time,
+	to time.Time,
+) (*customTypes.SnapshotDiff, error) {
+	fromTakenAt, before, err := r.loadStandingsSnapshot(ctx, leaderboardID, from)
+	if err != nil {
+		return nil, err
+	}
+	toTakenAt, after, err := r.loadStandingsSnapshot(ctx, leaderboardID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := make(map[string]int, len(before))
+	for i, entry := range before {
+		previous[entry.Member] = i
+	}
+
+	diff := &customTypes.SnapshotDiff{
+		From: fromTakenAt,
+		To:   toTakenAt,
+	}
+	for i, entry := range after {
+		mover := customTypes.Mover{
+			Member: entry.Member,
+			Rank:   int64(i + 1),
+			Score:  r.displayScore(entry.Score),
+		}
+		index, ok := previous[entry.Member]
+		if !ok {
+			diff.Entered = append(diff.Entered, mover)
+			continue
+		}
+		mover.PreviousRank = int64(index + 1)
+		mover.PreviousScore = r.displayScore(before[index].Score)
+		mover.RankDelta = mover.PreviousRank - mover.Rank
+		switch {
+		case mover.RankDelta > 0:
+			diff.Climbers = append(diff.Climbers, mover)
+		case mover.RankDelta < 0:
+			diff.Fallers = append(diff.Fallers, mover)
+		}
+	}
+
+	// Biggest moves first, ties by current rank
+	sort.SliceStable(diff.Climbers, func(i, j int) bool {
+		return diff.Climbers[i].RankDelta > diff.Climbers[j].RankDelta
+	})
+	sort.SliceStable(diff.Fallers, func(i, j int) bool {
+		return diff.Fallers[i].RankDelta < diff.Fallers[j].RankDelta
+	})
+
+	return diff, nil
+}
@@ -0,0 +1,149 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/redis/go-redis/v9"
+)
+
+// statsScanBatch is how many members are read per round trip when summing
+// scores for the mean
+const statsScanBatch = 1000
+
+// GetLeaderboardStats computes count, mean, median, min, max and a score
+// histogram with the given number of equal-width buckets. The mean reads
+// every score in batches; the other figures come from rank lookups and
+// ZCOUNT.
+func (r *ParticipantRepo) GetLeaderboardStats(
+	ctx context.Context,
+	leaderboardID string,
+	buckets int,
+	leaderboardEndTime time.Time,
+) (*customTypes.LeaderboardStats, error) {
+	redisKey := r.getRedisKey(leaderboardID)
+
+	// Ensure the leaderboard exists in Redis
+	if err := r.ensureLeaderboardExists(ctx, leaderboardID, leaderboardEndTime); err != nil {
+		return nil, err
+	}
+
+	count, err := r.redisClient.ZCard(ctx, redisKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to count participants: %w",
+			err,
+		)
+	}
+
+	stats := &customTypes.LeaderboardStats{Count: count}
+	if count == 0 {
+		return stats, nil
+	}
+
+	// Read min, max and the one or two middle entries in one round trip
+	pipe := r.redisClient.Pipeline()
+	minCmd := pipe.ZRangeWithScores(ctx, redisKey, 0, 0)
+	maxCmd := pipe.ZRevRangeWithScores(ctx, redisKey, 0, 0)
+	medianCmd := pipe.ZRangeWithScores(ctx, redisKey, (count-1)/2, count/2)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf(
+			"failed to read score bounds: %w",
+			err,
+		)
+	}
+
+	// The leaderboard may have been emptied since ZCARD, e.g. by a prune
+	if len(minCmd.Val()) == 0 || len(maxCmd.Val()) == 0 || len(medianCmd.Val()) == 0 {
+		return &customTypes.LeaderboardStats{}, nil
+	}
+
+	minScore := minCmd.Val()[0].Score
+	maxScore := maxCmd.Val()[0].Score
+	var medianSum float64
+	for _, z := range medianCmd.Val() {
+		medianSum += z.Score
+	}
+
+	stats.Min = r.displayScore(minScore)
+	stats.Max = r.displayScore(maxScore)
+	stats.Median = r.displayScore(medianSum / float64(len(medianCmd.Val())))
+
+	// Sum every score in batches for the mean
+	var total float64
+	for start := int64(0); start < count; start += statsScanBatch {
+		page, err := r.redisClient.ZRangeWithScores(ctx, redisKey, start, start+statsScanBatch-1).Result()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to read scores: %w",
+				err,
+			)
+		}
+		for _, z := range page {
+			total += z.Score
+		}
+	}
+	stats.Mean = r.displayScore(total / float64(count))
+
+	histogram, err := r.scoreHistogram(ctx, redisKey, minScore, maxScore, buckets)
+	if err != nil {
+		return nil, err
+	}
+	stats.Histogram = histogram
+
+	return stats, nil
+}
+
+// scoreHistogram buckets the scores between minScore and maxScore into equal
+// width ranges counted with ZCOUNT
+func (r *ParticipantRepo) scoreHistogram(
+	ctx context.Context,
+	redisKey string,
+	minScore float64,
+	maxScore float64,
+	buckets int,
+) ([]customTypes.HistogramBucket, error) {
+	if buckets <= 0 {
+		return nil, nil
+	}
+	if minScore == maxScore {
+		buckets = 1
+	}
+
+	width := (maxScore - minScore) / float64(buckets)
+	pipe := r.redisClient.Pipeline()
+	cmds := make([]*redis.IntCmd, buckets)
+	bounds := make([][2]float64, buckets)
+	for i := 0; i < buckets; i++ {
+		lower := minScore + float64(i)*width
+		upper := minScore + float64(i+1)*width
+
+		// Buckets are half-open except the last, which includes the max
+		upperBound := "(" + formatStoredScore(upper)
+		if i == buckets-1 {
+			upper = maxScore
+			upperBound = formatStoredScore(maxScore)
+		}
+		bounds[i] = [2]float64{lower, upper}
+		cmds[i] = pipe.ZCount(ctx, redisKey, formatStoredScore(lower), upperBound)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf(
+			"failed to count score buckets: %w",
+			err,
+		)
+	}
+
+	histogram := make([]customTypes.HistogramBucket, buckets)
+	for i, cmd := range cmds {
+		histogram[i] = customTypes.HistogramBucket{
+			Min:   r.displayScore(bounds[i][0]),
+			Max:   r.displayScore(bounds[i][1]),
+			Count: cmd.Val(),
+		}
+	}
+
+	return histogram, nil
+}
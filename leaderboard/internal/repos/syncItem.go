@@ -0,0 +1,102 @@
+package repos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// syncItem is a participant item read while syncing a leaderboard
+type syncItem struct {
+	Member string
+	Score  float64
+	// ExpiresAt is the item's TTL in Unix seconds, zero if it has none
+	ExpiresAt  float64
+	Attributes map[string]interface{}
+	Stats      map[string]float64
+}
+
+// decodeSyncItem decodes an item read while syncing a leaderboard. It
+// reports false for items that are not participants. Items whose
+// attributes cannot be used, e.g. after a manual fix left the score a
+// string that is not a number, return an error naming the item so the sync
+// can skip them.
+func (r *ParticipantRepo) decodeSyncItem(
+	item map[string]types.AttributeValue,
+	ttlAttribute string,
+) (syncItem, bool, error) {
+	keySchema := r.config.KeySchema
+	member, ok := keySchema.memberFromItem(item)
+	if !ok {
+		return syncItem{}, false, nil
+	}
+
+	// Older items are read in the current schema; MigrateSchema stores them
+	if _, err := r.migrateItem(item); err != nil {
+		return syncItem{}, true, fmt.Errorf(
+			"failed to migrate %s: %w",
+			member,
+			err,
+		)
+	}
+
+	decoded := syncItem{
+		Member: member,
+		Stats:  itemStats(item),
+	}
+
+	var err error
+	decoded.Score, err = decodeNumber(item["score"])
+	if err != nil {
+		return syncItem{}, true, fmt.Errorf(
+			"invalid score of %s: %w",
+			member,
+			err,
+		)
+	}
+
+	if ttlAttribute != "" {
+		if value, ok := item[ttlAttribute]; ok {
+			decoded.ExpiresAt, err = decodeNumber(value)
+			if err != nil {
+				return syncItem{}, true, fmt.Errorf(
+					"invalid %s of %s: %w",
+					ttlAttribute,
+					member,
+					err,
+				)
+			}
+		}
+	}
+
+	if value, ok := item[filterAttributesName]; ok {
+		if err := attributevalue.Unmarshal(value, &decoded.Attributes); err != nil {
+			return syncItem{}, true, fmt.Errorf(
+				"invalid %s of %s: %w",
+				filterAttributesName,
+				member,
+				err,
+			)
+		}
+	}
+
+	return decoded, true, nil
+}
+
+// decodeNumber decodes a numeric attribute, accepting numbers stored as
+// strings
+func decodeNumber(value types.AttributeValue) (float64, error) {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberN:
+		return strconv.ParseFloat(v.Value, 64)
+	case *types.AttributeValueMemberS:
+		return strconv.ParseFloat(strings.TrimSpace(v.Value), 64)
+	case nil:
+		return 0, fmt.Errorf("attribute missing")
+	default:
+		return 0, fmt.Errorf("unexpected attribute type %T", value)
+	}
+}
@@ -0,0 +1,52 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// annotateTiers labels each entry with its tier, measured against the live
+// size of the sorted set at key
+func (r *ParticipantRepo) annotateTiers(
+	ctx context.Context,
+	key string,
+	entries []customTypes.MemberScore,
+) error {
+	if len(r.config.Tiers) == 0 || len(entries) == 0 {
+		return nil
+	}
+
+	count, err := r.redisClient.ZCard(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf(
+			"failed to count participants: %w",
+			err,
+		)
+	}
+
+	r.assignTiers(entries, count)
+	return nil
+}
+
+// assignTiers labels each entry with the narrowest tier its rank falls in
+// out of count members. Tiers are kept sorted by TopPercent ascending.
+func (r *ParticipantRepo) assignTiers(entries []customTypes.MemberScore, count int64) {
+	if count == 0 {
+		return
+	}
+
+	for i := range entries {
+		if entries[i].Rank <= 0 {
+			continue
+		}
+		percentile := float64(entries[i].Rank) * 100 / float64(count)
+		for _, tier := range r.config.Tiers {
+			if percentile <= tier.TopPercent {
+				entries[i].Tier = tier.Label
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// storeDynamoDB names DynamoDB in timeout errors
+	storeDynamoDB = "dynamodb"
+	// storeRedis names Redis in timeout errors
+	storeRedis = "redis"
+)
+
+// withDynamoTimeout returns a copy of the client that bounds every operation,
+// retries included, by timeout. The caller's client is left untouched.
+func withDynamoTimeout(
+	client *dynamodb.Client,
+	timeout time.Duration,
+) *dynamodb.Client {
+	timeoutMiddleware := middleware.InitializeMiddlewareFunc(
+		"LeaderboardOperationTimeout",
+		func(
+			ctx context.Context,
+			in middleware.InitializeInput,
+			next middleware.InitializeHandler,
+		) (middleware.InitializeOutput, middleware.Metadata, error) {
+			opCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			out, metadata, err := next.HandleInitialize(opCtx, in)
+			if isStoreTimeout(ctx, opCtx, err) {
+				err = &customTypes.StoreTimeoutError{
+					Store:     storeDynamoDB,
+					Operation: awsmiddleware.GetOperationName(ctx),
+					Timeout:   timeout,
+					Err:       err,
+				}
+			}
+			return out, metadata, err
+		},
+	)
+
+	return dynamodb.New(client.Options(), func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(timeoutMiddleware, middleware.After)
+		})
+	})
+}
+
+// withRedisTimeout returns a client sharing the caller's connection pool
+// whose commands and pipelines are bounded by timeout, both on the socket
+// and through the context
+func withRedisTimeout(
+	client *redis.Client,
+	timeout time.Duration,
+) *redis.Client {
+	bounded := client.WithTimeout(timeout)
+	bounded.AddHook(redisTimeoutHook{timeout: timeout})
+	return bounded
+}
+
+// redisTimeoutHook applies the operation timeout to each command and
+// pipeline, reporting expiries as StoreTimeoutError
+type redisTimeoutHook struct {
+	timeout time.Duration
+}
+
+// DialHook leaves dialing to the client's own dial timeout
+func (h redisTimeoutHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook bounds a single command
+func (h redisTimeoutHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		opCtx, cancel := context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+
+		err := next(opCtx, cmd)
+		if isStoreTimeout(ctx, opCtx, err) {
+			err = h.timeoutError(cmd.Name(), err)
+			cmd.SetErr(err)
+		}
+		return err
+	}
+}
+
+// ProcessPipelineHook bounds a whole pipeline or transaction
+func (h redisTimeoutHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		opCtx, cancel := context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+
+		err := next(opCtx, cmds)
+		if isStoreTimeout(ctx, opCtx, err) {
+			err = h.timeoutError("pipeline", err)
+			for _, cmd := range cmds {
+				if cmd.Err() != nil {
+					cmd.SetErr(err)
+				}
+			}
+		}
+		return err
+	}
+}
+
+// timeoutError wraps err as a Redis StoreTimeoutError
+func (h redisTimeoutHook) timeoutError(operation string, err error) error {
+	return &customTypes.StoreTimeoutError{
+		Store:     storeRedis,
+		Operation: operation,
+		Timeout:   h.timeout,
+		Err:       err,
+	}
+}
+
+// isStoreTimeout reports whether err was caused by the operation timeout
+// rather than by the caller's own context
+func isStoreTimeout(ctx context.Context, opCtx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(opCtx.Err(), context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,228 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxTransactItems is the DynamoDB limit for a single TransactWriteItems call
+const maxTransactItems = 100
+
+// ScoreWrite is one participant's score change in an atomic update
+type ScoreWrite struct {
+	LeaderboardID      string
+	NamespacedUserID   string
+	ScoreDelta         float64
+	LeaderboardEndTime time.Time
+	// Attributes feed the leaderboard's filtered views, as with UpdateScore
+	Attributes map[string]string
+}
+
+// UpdateScoresAtomically applies every write in a single DynamoDB
+// transaction, so either all writes are applied or none is, and then
+// applies them to the cached leaderboards in one MULTI/EXEC block. Results
+// follow the order of writes; a leaderboard whose cache was being rebuilt
+// reports a zero score and rank, and picks the write up from DynamoDB.
+func (r *ParticipantRepo) UpdateScoresAtomically(
+	ctx context.Context,
+	writes []ScoreWrite,
+) ([]customTypes.MemberScore, error) {
+	return r.updateScoresAtomically(ctx, writes, nil)
+}
+
+// updateScoresAtomically applies the writes in one transaction together
+// with extraItems, which must all succeed for any write to be applied
+func (r *ParticipantRepo) updateScoresAtomically(
+	ctx context.Context,
+	writes []ScoreWrite,
+	extraItems []types.TransactWriteItem,
+) ([]customTypes.MemberScore, error) {
+	if len(writes) == 0 && len(extraItems) == 0 {
+		return nil, nil
+	}
+	if len(writes)+len(extraItems) > maxTransactItems {
+		return nil, fmt.Errorf(
+			"%w: cannot write more than %d items in one transaction",
+			customTypes.ErrBatchTooLarge,
+			maxTransactItems,
+		)
+	}
+
+	// A write retried under the same token must send the same request
+	now := utils.GetCurrTimeStamp()
+	token, stampedAt, idempotent := utils.WriteToken(ctx)
+	if idempotent {
+		now = stampedAt
+	}
+	storedDeltas := make([]float64, len(writes))
+	cacheReady := make([]bool, len(writes))
+	expiries := make([]time.Time, len(writes))
+	attributes := make([]map[string]string, len(writes))
+	ttlEnabled := false
+	items := make([]types.TransactWriteItem, len(writes))
+	seen := make(map[string]bool, len(writes))
+
+	for i, write := range writes {
+		// A transaction may touch each item only once
+		itemID := write.LeaderboardID + "\x00" + write.NamespacedUserID
+		if seen[itemID] {
+			return nil, fmt.Errorf(
+				"%w: participant %s is updated more than once on leaderboard %s",
+				customTypes.ErrDuplicateBatchEntry,
+				write.NamespacedUserID,
+				write.LeaderboardID,
+			)
+		}
+		seen[itemID] = true
+
+		// Reject deltas that cannot be stored exactly
+		storedDelta, err := r.storedScore(write.ScoreDelta)
+		if err != nil {
+			return nil, err
+		}
+		storedDeltas[i] = storedDelta
+
+		// Keep post-deadline writes from changing final standings
+		late, err := r.checkWriteDeadline(
+			ctx,
+			write.LeaderboardID,
+			write.LeaderboardEndTime,
+			now,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Ensure Redis key exists before writing
+		cacheReady[i], err = r.prepareCacheForWrite(ctx, write.LeaderboardID, write.LeaderboardEndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		attributes[i] = r.filterAttributes(write.Attributes)
+		var input *dynamodb.UpdateItemInput
+		input, expiries[i], ttlEnabled, err = r.buildScoreUpdate(
+			write.LeaderboardID,
+			write.NamespacedUserID,
+			storedDelta,
+			attributes[i],
+			late,
+			now,
+			write.LeaderboardEndTime,
+			utils.RequestID(ctx),
+		)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = types.TransactWriteItem{Update: transactUpdate(input)}
+	}
+
+	// Credit every leaderboard durably, or none, retrying when concurrent
+	// writes to the same participants cancel the transaction
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: append(items, extraItems...),
+	}
+	if idempotent {
+		input.ClientRequestToken = aws.String(token)
+	}
+	err := r.retryConflicts(ctx, func() error {
+		_, err := r.dynamoClient.TransactWriteItems(ctx, input)
+		return err
+	})
+	if err != nil {
+		var cancelledErr *types.TransactionCanceledException
+		if errors.As(err, &cancelledErr) {
+			for i, reason := range cancelledErr.CancellationReasons {
+				if reason.Code == nil || *reason.Code != "ConditionalCheckFailed" {
+					continue
+				}
+				if i >= len(writes) {
+					return nil, customTypes.ErrPendingUpdateNotFound
+				}
+				if frozenErr := frozenError(writes[i].NamespacedUserID, reason.Item); frozenErr != nil {
+					return nil, frozenErr
+				}
+				return nil, &customTypes.ScoreOutOfRangeError{
+					Score: writes[i].ScoreDelta,
+					Limit: models.MaxExactScore,
+				}
+			}
+		}
+		return nil, fmt.Errorf(
+			"failed to update scores in DynamoDB: %w",
+			err,
+		)
+	}
+
+	// Apply the writes to the cached leaderboards together
+	pipe := r.redisClient.TxPipeline()
+	incrementCmds := make([]*redis.Cmd, len(writes))
+	for i, write := range writes {
+		r.recordRollingScore(ctx, write.LeaderboardID, write.NamespacedUserID, storedDeltas[i], now, pipe)
+		if !cacheReady[i] {
+			continue
+		}
+		incrementCmds[i] = r.incrementIfCached(ctx, write.LeaderboardID, write.NamespacedUserID, storedDeltas[i], pipe)
+		if ttlEnabled {
+			r.trackParticipantExpiry(ctx, write.LeaderboardID, write.NamespacedUserID, expiries[i], write.LeaderboardEndTime, pipe)
+		}
+		if len(attributes[i]) > 0 {
+			r.indexMemberAttributes(ctx, write.LeaderboardID, write.NamespacedUserID, attributes[i], write.LeaderboardEndTime, pipe)
+		}
+	}
+	results := make([]customTypes.MemberScore, len(writes))
+	for i, write := range writes {
+		results[i].Member = write.NamespacedUserID
+	}
+
+	// The writes are durable, so a cache failure is repaired later rather
+	// than returned; a retry would apply them twice
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		for i, write := range writes {
+			if cacheReady[i] {
+				r.queueRepair(write.LeaderboardID, err, write.NamespacedUserID)
+			}
+		}
+		return results, nil
+	}
+
+	for i, write := range writes {
+		if !cacheReady[i] {
+			// A rebuild still in progress picks the write up from DynamoDB
+			r.syncSkippedWrites(ctx, write.LeaderboardID, write.LeaderboardEndTime, write.NamespacedUserID)
+			continue
+		}
+		if incrementCmds[i] == nil {
+			continue
+		}
+		if units, rank, ok := cachedIncrement(incrementCmds[i]); ok {
+			results[i].Score = r.displayScore(units)
+			results[i].Rank = rank + 1
+		}
+	}
+
+	return results, nil
+}
+
+// transactUpdate converts an update into a transaction item
+func transactUpdate(input *dynamodb.UpdateItemInput) *types.Update {
+	return &types.Update{
+		TableName:                           input.TableName,
+		Key:                                 input.Key,
+		UpdateExpression:                    input.UpdateExpression,
+		ConditionExpression:                 input.ConditionExpression,
+		ExpressionAttributeNames:            input.ExpressionAttributeNames,
+		ExpressionAttributeValues:           input.ExpressionAttributeValues,
+		ReturnValuesOnConditionCheckFailure: input.ReturnValuesOnConditionCheckFailure,
+	}
+}
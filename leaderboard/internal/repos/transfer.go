@@ -0,0 +1,222 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// transferScoreScript moves an amount from one member's cached score to
+// another's, unless the sender's cached score is below the amount, and
+// refreshes both in the filtered views they are indexed in. It replies with
+// each member's new score and zero-based rank, or nil if the sender's
+// cached score falls short.
+// KEYS[1] is the leaderboard, followed by each filter attribute's
+// member->value hash and view key prefix. ARGV[1] is the sender, ARGV[2]
+// the recipient and ARGV[3] the amount.
+var transferScoreScript = newScript(`
+local fromScore = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if not fromScore or tonumber(fromScore) < tonumber(ARGV[3]) then
+	return false
+end
+local members = {ARGV[1], ARGV[2]}
+local increments = {"-" .. ARGV[3], ARGV[3]}
+local reply = {}
+for i = 1, 2 do
+	local score = redis.call("ZINCRBY", KEYS[1], increments[i], members[i])
+	for j = 2, #KEYS, 2 do
+		local value = redis.call("HGET", KEYS[j], members[i])
+		if value then
+			redis.call("ZADD", KEYS[j + 1] .. value, score, members[i])
+		end
+	end
+	reply[#reply + 1] = score
+	reply[#reply + 1] = redis.call("ZREVRANK", KEYS[1], members[i])
+end
+return reply
+`)
+
+// TransferScore moves amount from one participant's score to another's, in
+// a single DynamoDB transaction that fails with ErrInsufficientScore unless
+// the sender has at least amount, so concurrent transfers can never spend
+// the same score twice. The cached leaderboard is then updated by one Lua
+// script. It returns the sender's and the recipient's new standings; ranks
+// are zero while the cached leaderboard is being rebuilt. Under the
+// additive merge strategy only the sender's score in this region counts.
+func (r *ParticipantRepo) TransferScore(
+	ctx context.Context,
+	leaderboardID string,
+	fromUserID string,
+	toUserID string,
+	amount float64,
+	leaderboardEndTime time.Time,
+) (customTypes.MemberScore, customTypes.MemberScore, error) {
+	from := customTypes.MemberScore{Member: fromUserID}
+	to := customTypes.MemberScore{Member: toUserID}
+	if fromUserID == toUserID {
+		return from, to, fmt.Errorf(
+			"cannot transfer score from participant %s to itself",
+			fromUserID,
+		)
+	}
+
+	// Reject amounts that cannot be stored exactly
+	storedAmount, err := r.storedScore(amount)
+	if err != nil {
+		return from, to, err
+	}
+	if storedAmount <= 0 {
+		return from, to, fmt.Errorf(
+			"%w: transfer amount %v must be positive",
+			customTypes.ErrInvalidScore,
+			amount,
+		)
+	}
+
+	// Keep post-deadline transfers from changing final standings
+	now := utils.GetCurrTimeStamp()
+	late, err := r.checkWriteDeadline(ctx, leaderboardID, leaderboardEndTime, now)
+	if err != nil {
+		return from, to, err
+	}
+
+	// Ensure Redis key exists before writing
+	cacheReady, err := r.prepareCacheForWrite(ctx, leaderboardID, leaderboardEndTime)
+	if err != nil {
+		return from, to, err
+	}
+
+	requestID := utils.RequestID(ctx)
+	debit, expiresAt, ttlEnabled, err := r.buildScoreUpdate(
+		leaderboardID,
+		fromUserID,
+		-storedAmount,
+		nil,
+		late,
+		now,
+		leaderboardEndTime,
+		requestID,
+	)
+	if err != nil {
+		return from, to, err
+	}
+	credit, _, _, err := r.buildScoreUpdate(
+		leaderboardID,
+		toUserID,
+		storedAmount,
+		nil,
+		late,
+		now,
+		leaderboardEndTime,
+		requestID,
+	)
+	if err != nil {
+		return from, to, err
+	}
+
+	// The sender must hold the whole amount; a missing score fails too
+	debit.ConditionExpression = aws.String(*debit.ConditionExpression + " AND score >= :transferAmount")
+	debit.ExpressionAttributeValues[":transferAmount"] = &types.AttributeValueMemberN{
+		Value: formatStoredScore(storedAmount),
+	}
+
+	// Debit and credit together, retrying when concurrent writes to the
+	// same participants cancel the transaction
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Update: transactUpdate(debit)},
+			{Update: transactUpdate(credit)},
+		},
+	}
+	err = r.retryConflicts(ctx, func() error {
+		_, err := r.dynamoClient.TransactWriteItems(ctx, input)
+		return err
+	})
+	if err != nil {
+		var cancelledErr *types.TransactionCanceledException
+		if errors.As(err, &cancelledErr) {
+			for i, reason := range cancelledErr.CancellationReasons {
+				if reason.Code == nil || *reason.Code != "ConditionalCheckFailed" {
+					continue
+				}
+				member := []string{fromUserID, toUserID}[min(i, 1)]
+				if frozenErr := frozenError(member, reason.Item); frozenErr != nil {
+					return from, to, frozenErr
+				}
+				if i == 0 {
+					return from, to, customTypes.ErrInsufficientScore
+				}
+				return from, to, &customTypes.ScoreOutOfRangeError{
+					Score: amount,
+					Limit: models.MaxExactScore,
+				}
+			}
+		}
+		return from, to, fmt.Errorf(
+			"failed to transfer score in DynamoDB: %w",
+			err,
+		)
+	}
+
+	// A rebuild still in progress picks the transfer up from DynamoDB
+	if !cacheReady {
+		r.syncSkippedWrites(ctx, leaderboardID, leaderboardEndTime, fromUserID, toUserID)
+		return from, to, nil
+	}
+
+	keys := []string{r.getRedisKey(leaderboardID)}
+	args := []any{fromUserID, toUserID, formatStoredScore(storedAmount)}
+	for _, attribute := range r.config.FilterAttributes {
+		keys = append(
+			keys,
+			r.getFilterValuesKey(leaderboardID, attribute),
+			r.getFilterKeyPrefix(leaderboardID, attribute),
+		)
+	}
+
+	pipe := r.redisClient.TxPipeline()
+	r.noteRebuildWrites(ctx, leaderboardID, pipe, fromUserID, toUserID)
+	transferCmd := transferScoreScript.Eval(ctx, pipe, keys, args...)
+	if ttlEnabled {
+		r.trackParticipantExpiry(ctx, leaderboardID, fromUserID, expiresAt, leaderboardEndTime, pipe)
+		r.trackParticipantExpiry(ctx, leaderboardID, toUserID, expiresAt, leaderboardEndTime, pipe)
+	}
+
+	// The transfer is durable, so a cache that failed or disagrees with
+	// DynamoDB is repaired later; a retry would apply it twice
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		r.queueRepair(leaderboardID, err, fromUserID, toUserID)
+		return from, to, nil
+	}
+	reply, err := transferCmd.Slice()
+	if err != nil || len(reply) != 4 {
+		if err == nil || err == redis.Nil {
+			err = errors.New("cached sender score is below the transferred amount")
+		}
+		r.queueRepair(leaderboardID, err, fromUserID, toUserID)
+		return from, to, nil
+	}
+
+	fromUnits, fromErr := strconv.ParseFloat(fmt.Sprint(reply[0]), 64)
+	toUnits, toErr := strconv.ParseFloat(fmt.Sprint(reply[2]), 64)
+	if err := errors.Join(fromErr, toErr); err != nil {
+		r.queueRepair(leaderboardID, err, fromUserID, toUserID)
+		return from, to, nil
+	}
+	fromRank, _ := reply[1].(int64)
+	toRank, _ := reply[3].(int64)
+	from.Score, from.Rank = r.displayScore(fromUnits), fromRank+1
+	to.Score, to.Rank = r.displayScore(toUnits), toRank+1
+
+	return from, to, nil
+}
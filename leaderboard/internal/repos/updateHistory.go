@@ -0,0 +1,87 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// updateHistoryTTL is how long a participant's update history is kept after
+// their last update
+const updateHistoryTTL = 7 * 24 * time.Hour
+
+// getUpdateHistoryKey returns the Redis hash summarising a participant's
+// recent score deltas
+func (r *ParticipantRepo) getUpdateHistoryKey(leaderboardID string, namespacedUserID string) string {
+	return r.getRedisKey(leaderboardID) + ":history:" + namespacedUserID
+}
+
+// GetUpdateHistory returns the statistics of a participant's recent score
+// deltas
+func (r *ParticipantRepo) GetUpdateHistory(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+) (customTypes.UpdateHistory, error) {
+	var history customTypes.UpdateHistory
+
+	values, err := r.redisClient.HMGet(
+		ctx,
+		r.getUpdateHistoryKey(leaderboardID, namespacedUserID),
+		"count",
+		"sum",
+		"sumSquares",
+	).Result()
+	if err != nil {
+		return history, fmt.Errorf(
+			"failed to get update history: %w",
+			err,
+		)
+	}
+
+	parsed := make([]float64, len(values))
+	for i, value := range values {
+		if text, ok := value.(string); ok {
+			parsed[i], _ = strconv.ParseFloat(text, 64)
+		}
+	}
+	count, sum, sumSquares := parsed[0], parsed[1], parsed[2]
+	if count == 0 {
+		return history, nil
+	}
+
+	history.Count = int64(count)
+	history.Mean = sum / count
+	history.StdDev = math.Sqrt(math.Max(sumSquares/count-history.Mean*history.Mean, 0))
+
+	return history, nil
+}
+
+// RecordUpdateHistory adds an applied score delta to the participant's
+// update history
+func (r *ParticipantRepo) RecordUpdateHistory(
+	ctx context.Context,
+	leaderboardID string,
+	namespacedUserID string,
+	scoreDelta float64,
+) error {
+	key := r.getUpdateHistoryKey(leaderboardID, namespacedUserID)
+
+	pipe := r.redisClient.Pipeline()
+	pipe.HIncrByFloat(ctx, key, "count", 1)
+	pipe.HIncrByFloat(ctx, key, "sum", scoreDelta)
+	pipe.HIncrByFloat(ctx, key, "sumSquares", scoreDelta*scoreDelta)
+	pipe.Expire(ctx, key, updateHistoryTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf(
+			"failed to record update history: %w",
+			err,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// writeRateBucket is the granularity of write rate counters
+	writeRateBucket = time.Minute
+	// MaxWriteRateWindow is how far back write rate counters are kept
+	MaxWriteRateWindow = time.Hour
+)
+
+// getWriteRateKey returns the counter of score writes made in the minute
+// starting at bucketStart
+func (r *ParticipantRepo) getWriteRateKey(leaderboardID string, bucketStart time.Time) string {
+	return r.getRedisKey(leaderboardID) + ":writes:" + strconv.FormatInt(bucketStart.Unix(), 10)
+}
+
+// writeRateKeys returns the write rate counters that may still exist at now
+func (r *ParticipantRepo) writeRateKeys(leaderboardID string, now time.Time) []string {
+	buckets := int((MaxWriteRateWindow + writeRateBucket) / writeRateBucket)
+	keys := make([]string, 0, buckets+1)
+	currentStart := now.Truncate(writeRateBucket)
+	for i := 0; i <= buckets; i++ {
+		keys = append(keys, r.getWriteRateKey(leaderboardID, currentStart.Add(-time.Duration(i)*writeRateBucket)))
+	}
+
+	return keys
+}
+
+// RecordWrites counts score writes made at now. It reports whether they
+// were the first of a new minute, which exactly one caller observes per
+// minute.
+func (r *ParticipantRepo) RecordWrites(
+	ctx context.Context,
+	leaderboardID string,
+	writes int64,
+	now time.Time,
+) (bool, error) {
+	key := r.getWriteRateKey(leaderboardID, now.Truncate(writeRateBucket))
+
+	pipe := r.redisClient.Pipeline()
+	countCmd := pipe.IncrBy(ctx, key, writes)
+	pipe.Expire(ctx, key, MaxWriteRateWindow+writeRateBucket)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf(
+			"failed to record write rate: %w",
+			err,
+		)
+	}
+
+	return countCmd.Val() == writes, nil
+}
+
+// GetWriteRate returns the write rate over the complete minutes of the
+// window before now, capped at MaxWriteRateWindow
+func (r *ParticipantRepo) GetWriteRate(
+	ctx context.Context,
+	leaderboardID string,
+	window time.Duration,
+	now time.Time,
+) (customTypes.WriteRate, error) {
+	if window > MaxWriteRateWindow {
+		window = MaxWriteRateWindow
+	}
+	buckets := int(window / writeRateBucket)
+	if buckets < 1 {
+		buckets = 1
+	}
+	rate := customTypes.WriteRate{Window: time.Duration(buckets) * writeRateBucket}
+
+	// Read the complete minutes, most recent first
+	currentStart := now.Truncate(writeRateBucket)
+	keys := make([]string, buckets)
+	for i := range keys {
+		keys[i] = r.getWriteRateKey(leaderboardID, currentStart.Add(-time.Duration(i+1)*writeRateBucket))
+	}
+	values, err := r.redisClient.MGet(ctx, keys...).Result()
+	if err != nil && err != redis.Nil {
+		return rate, fmt.Errorf(
+			"failed to get write rate: %w",
+			err,
+		)
+	}
+
+	perSecond := writeRateBucket.Seconds()
+	var total float64
+	for i, value := range values {
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+		count, _ := strconv.ParseFloat(text, 64)
+		total += count
+		if i == 0 {
+			rate.Current = count / perSecond
+		}
+		if count/perSecond > rate.Peak {
+			rate.Peak = count / perSecond
+		}
+	}
+	rate.Mean = total / (float64(buckets) * perSecond)
+
+	return rate, nil
+}
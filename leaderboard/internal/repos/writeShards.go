@@ -0,0 +1,51 @@
+package repos
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// shardPartitionKey returns the write shard of partitionKey holding a
+// participant's item. Each participant always maps to the same shard.
+func (r *ParticipantRepo) shardPartitionKey(partitionKey string, namespacedUserID string) string {
+	return ShardPartitionKey(partitionKey, namespacedUserID, r.config.WriteShards)
+}
+
+// shardPartitionKeys returns every write shard of partitionKey
+func (r *ParticipantRepo) shardPartitionKeys(partitionKey string) []string {
+	return ShardPartitionKeys(partitionKey, r.config.WriteShards)
+}
+
+// ShardPartitionKey returns the write shard of partitionKey holding a
+// participant's item when it is spread over writeShards partitions. Each
+// participant always maps to the same shard.
+func ShardPartitionKey(partitionKey string, namespacedUserID string, writeShards int) string {
+	if writeShards <= 1 {
+		return partitionKey
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(namespacedUserID))
+
+	return shardKey(partitionKey, int(hash.Sum32()%uint32(writeShards)))
+}
+
+// ShardPartitionKeys returns every write shard of partitionKey when it is
+// spread over writeShards partitions
+func ShardPartitionKeys(partitionKey string, writeShards int) []string {
+	if writeShards <= 1 {
+		return []string{partitionKey}
+	}
+
+	partitionKeys := make([]string, writeShards)
+	for shard := range partitionKeys {
+		partitionKeys[shard] = shardKey(partitionKey, shard)
+	}
+
+	return partitionKeys
+}
+
+// shardKey returns the partition key of one write shard
+func shardKey(partitionKey string, shard int) string {
+	return fmt.Sprintf("%s#shard%d", partitionKey, shard)
+}
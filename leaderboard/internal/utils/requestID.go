@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"context"
+)
+
+// requestIDKey carries the request ID of a call in its context
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying requestID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID carried by ctx, empty if none
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// LogPrefix returns the prefix tagging log lines with the request ID
+// carried by ctx, empty if none
+func LogPrefix(ctx context.Context) string {
+	if requestID := RequestID(ctx); requestID != "" {
+		return "[request " + requestID + "] "
+	}
+
+	return ""
+}
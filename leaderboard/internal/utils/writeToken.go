@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"context"
+	"time"
+)
+
+// writeTokenKey carries the idempotency token of a transactional write in
+// its context
+type writeTokenKey struct{}
+
+// writeToken is an idempotency token and the time writes made under it
+// are stamped with
+type writeToken struct {
+	token     string
+	stampedAt time.Time
+}
+
+// WithWriteToken returns a context making the transactional score writes
+// made with it idempotent under token. The writes are stamped with
+// stampedAt rather than the current time, so a retry sends DynamoDB the
+// same request.
+func WithWriteToken(ctx context.Context, token string, stampedAt time.Time) context.Context {
+	return context.WithValue(ctx, writeTokenKey{}, writeToken{token: token, stampedAt: stampedAt})
+}
+
+// WriteToken returns the token carried by ctx and the time writes made
+// under it are stamped with, and false if there is none
+func WriteToken(ctx context.Context) (string, time.Time, bool) {
+	token, ok := ctx.Value(writeTokenKey{}).(writeToken)
+	return token.token, token.stampedAt, ok
+}
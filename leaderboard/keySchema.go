@@ -0,0 +1,25 @@
+package leaderboard
+
+import (
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+)
+
+// KeySchema describes how participant items are keyed in DynamoDB: the
+// partition and sort key attribute names, and templates building their
+// values from the leaderboard and member IDs (for example
+// "LB#{leaderboardID}" and "USER#{namespacedUserID}"). Unset fields keep
+// their defaults.
+type KeySchema = repos.KeySchema
+
+// DefaultKeySchema keys items by their plain leaderboardID and
+// namespacedUserID attributes
+func DefaultKeySchema() KeySchema {
+	return repos.DefaultKeySchema()
+}
+
+// SingleTableKeySchema keys items by generic PK and SK attributes with
+// entity-type prefixes ("LB#{leaderboardID}", "PARTICIPANT#{namespacedUserID}")
+// and tags them with an entityType attribute, following single-table design
+func SingleTableKeySchema() KeySchema {
+	return repos.SingleTableKeySchema()
+}
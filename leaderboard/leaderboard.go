@@ -0,0 +1,42 @@
+package leaderboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// MemberScore is a participant's score and standing as returned by reads and
+// writes
+type MemberScore = customTypes.MemberScore
+
+// Leaderboard is the read side of a single leaderboard. Consumers that only
+// serve standings can depend on it to swap implementations, decorate reads
+// with middleware, e.g. metrics or authorization, or substitute the fakes in
+// the mocks package in unit tests. IndividualLeaderboardHelper implements it.
+type Leaderboard interface {
+	GetTopNParticipants(ctx context.Context, n int64) ([]customTypes.MemberScore, error)
+	GetParticipantScoreAndRank(ctx context.Context, namespacedUserID string) (*customTypes.MemberScore, error)
+	GetTopNFilteredParticipants(ctx context.Context, n int64, filter Filter) ([]customTypes.MemberScore, error)
+	GetLeaderboardStats(ctx context.Context) (*customTypes.LeaderboardStats, error)
+	GetPage(ctx context.Context, cursor string, limit int64) (*Page, error)
+	GetParticipant(ctx context.Context, userID string) (*ParticipantModel, error)
+}
+
+// LeaderboardWriter is the write side of a single leaderboard, for consumers
+// that sign participants up and submit scores. IndividualLeaderboardHelper
+// implements it.
+type LeaderboardWriter interface {
+	JoinLeaderboard(ctx context.Context, userID string, initialScore float64) error
+	JoinLeaderboardBatch(ctx context.Context, participants []*ParticipantModel) ([]error, error)
+	UpdateScore(ctx context.Context, namespacedUserID string, scoreDelta float64) (*customTypes.MemberScore, error)
+	UpdateScoreInt(ctx context.Context, namespacedUserID string, scoreDelta int64) (*customTypes.MemberScore, error)
+	UpdateScoreAt(ctx context.Context, namespacedUserID string, scoreDelta float64, eventTime time.Time) (*customTypes.MemberScore, error)
+	UpdateScores(ctx context.Context, deltas map[string]float64) (map[string]customTypes.MemberScore, error)
+}
+
+var (
+	_ Leaderboard       = (*IndividualLeaderboardHelper)(nil)
+	_ LeaderboardWriter = (*IndividualLeaderboardHelper)(nil)
+)
@@ -0,0 +1,58 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// LeaderboardView is the top of a leaderboard together with one
+// participant's entry and neighborhood
+type LeaderboardView = customTypes.LeaderboardView
+
+// GetLeaderboardView returns, in one Redis round trip, the top n entries,
+// the standing of one of the helper's client's users and, when aroundWindow
+// is positive, the aroundWindow entries ranked on either side of them. A
+// user who has not scored yet gets a view with a nil Entry rather than
+// ErrParticipantNotFound.
+func (l *IndividualLeaderboardHelper) GetLeaderboardView(
+	ctx context.Context,
+	userID string,
+	n int64,
+	aroundWindow int64,
+) (*LeaderboardView, error) {
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	namespacedUserID, err := l.memberID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	view, err := l.repo.GetLeaderboardView(
+		ctx,
+		l.storageID,
+		namespacedUserID,
+		n,
+		aroundWindow,
+		l.endTime(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	l.markGhosts(view.Top)
+	l.markGhosts(view.Around)
+	if view.Entry != nil {
+		entry := []customTypes.MemberScore{*view.Entry}
+		l.markGhosts(entry)
+		view.Entry = &entry[0]
+		l.rankCache.put(namespacedUserID, view.Entry)
+		l.transformResult(ctx, view.Entry)
+	}
+	l.transformResults(ctx, view.Top)
+	l.transformResults(ctx, view.Around)
+
+	return view, nil
+}
@@ -0,0 +1,125 @@
+package leaderboardtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+)
+
+// startEnv starts an Env for the test, skipping it when the containers
+// cannot be started, e.g. without Docker
+func startEnv(t *testing.T) *Env {
+	t.Helper()
+
+	ctx := context.Background()
+	env, err := Start(ctx)
+	if err != nil {
+		t.Skipf("leaderboardtest: %v", err)
+	}
+	t.Cleanup(func() {
+		env.Close(ctx)
+	})
+
+	return env
+}
+
+func TestEmptyLeaderboardReturnsEmptyResults(t *testing.T) {
+	ctx := context.Background()
+	env := startEnv(t)
+	helper := env.Helper("client", "empty", farFuture)
+
+	// Read twice, so both the cold load and the cached board are covered
+	for i := 0; i < 2; i++ {
+		top, err := helper.GetTopNParticipants(ctx, 10)
+		if err != nil {
+			t.Fatalf("GetTopNParticipants: %v", err)
+		}
+		if len(top) != 0 {
+			t.Fatalf("GetTopNParticipants returned %d entries, want 0", len(top))
+		}
+
+		page, err := helper.GetPage(ctx, "", 10)
+		if err != nil {
+			t.Fatalf("GetPage: %v", err)
+		}
+		if len(page.Entries) != 0 || page.NextCursor != "" {
+			t.Fatalf("GetPage returned %+v, want an empty last page", page)
+		}
+
+		stats, err := helper.GetLeaderboardStats(ctx)
+		if err != nil {
+			t.Fatalf("GetLeaderboardStats: %v", err)
+		}
+		if stats.Count != 0 {
+			t.Fatalf("GetLeaderboardStats counted %d participants, want 0", stats.Count)
+		}
+	}
+}
+
+func TestEmptyLeaderboardAfterPrune(t *testing.T) {
+	ctx := context.Background()
+	env := startEnv(t)
+	helper, err := env.SeedLeaderboard(ctx, "client", "drained", RankedParticipants(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, err := helper.PruneParticipants(ctx, farFuture)
+	if err != nil {
+		t.Fatalf("PruneParticipants: %v", err)
+	}
+	if pruned != 2 {
+		t.Fatalf("PruneParticipants removed %d participants, want 2", pruned)
+	}
+
+	top, err := helper.GetTopNParticipants(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetTopNParticipants: %v", err)
+	}
+	if len(top) != 0 {
+		t.Fatalf("GetTopNParticipants returned %d entries, want 0", len(top))
+	}
+
+	stats, err := helper.GetLeaderboardStats(ctx)
+	if err != nil {
+		t.Fatalf("GetLeaderboardStats: %v", err)
+	}
+	if stats.Count != 0 {
+		t.Fatalf("GetLeaderboardStats counted %d participants, want 0", stats.Count)
+	}
+}
+
+func TestFailedSyncIsRetried(t *testing.T) {
+	ctx := context.Background()
+	env := startEnv(t)
+
+	// The table does not exist yet, so the first load fails and the
+	// leaderboard is served empty
+	const tableName = "LeaderboardTestLateTable"
+	helper := env.Helper("client", "late", farFuture, leaderboard.WithTableName(tableName))
+	top, err := helper.GetTopNParticipants(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetTopNParticipants: %v", err)
+	}
+	if len(top) != 0 {
+		t.Fatalf("GetTopNParticipants returned %d entries, want 0", len(top))
+	}
+
+	// Once the table exists, the next access loads the leaderboard rather
+	// than serving the failed load's empty board
+	if err := env.CreateTable(ctx, tableName, env.KeySchema, DefaultUpdatedAtIndex); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewSeeder(helper, "client", nil).Seed(ctx, RankedParticipants(3)...); err != nil {
+		t.Fatal(err)
+	}
+
+	top, err = helper.GetTopNParticipants(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetTopNParticipants: %v", err)
+	}
+	if len(top) != 3 {
+		t.Fatalf("GetTopNParticipants returned %d entries, want 3", len(top))
+	}
+}
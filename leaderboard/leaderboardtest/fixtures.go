@@ -0,0 +1,109 @@
+package leaderboardtest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+)
+
+// Participant is a participant to seed with a starting score
+type Participant struct {
+	UserID string
+	Score  float64
+}
+
+// RankedParticipants returns n participants "user-1" to "user-n" whose
+// scores put them in that rank order, from n points down to 1
+func RankedParticipants(n int) []Participant {
+	participants := make([]Participant, n)
+	for i := range participants {
+		participants[i] = Participant{
+			UserID: fmt.Sprintf("user-%d", i+1),
+			Score:  float64(n - i),
+		}
+	}
+
+	return participants
+}
+
+// TiedParticipants returns n participants "user-1" to "user-n" all holding
+// the same score
+func TiedParticipants(n int, score float64) []Participant {
+	participants := make([]Participant, n)
+	for i := range participants {
+		participants[i] = Participant{
+			UserID: fmt.Sprintf("user-%d", i+1),
+			Score:  score,
+		}
+	}
+
+	return participants
+}
+
+// Seeder writes fixtures through a helper, so they go through the same
+// write path as production traffic
+type Seeder struct {
+	helper     *leaderboard.IndividualLeaderboardHelper
+	clientID   string
+	namespacer leaderboard.Namespacer
+}
+
+// NewSeeder creates a seeder for the helper's leaderboard. namespacer must
+// match the helper's; nil uses the default scheme.
+func NewSeeder(
+	helper *leaderboard.IndividualLeaderboardHelper,
+	clientID string,
+	namespacer leaderboard.Namespacer,
+) *Seeder {
+	if namespacer == nil {
+		namespacer = leaderboard.DefaultNamespacer()
+	}
+
+	return &Seeder{
+		helper:     helper,
+		clientID:   clientID,
+		namespacer: namespacer,
+	}
+}
+
+// MemberID returns the namespaced user ID a seeded participant is stored as
+func (s *Seeder) MemberID(userID string) (string, error) {
+	return s.namespacer.Join(s.clientID, userID)
+}
+
+// Seed adds each participant's score to the leaderboard
+func (s *Seeder) Seed(ctx context.Context, participants ...Participant) error {
+	for _, participant := range participants {
+		member, err := s.MemberID(participant.UserID)
+		if err != nil {
+			return err
+		}
+		if _, err := s.helper.UpdateScore(ctx, member, participant.Score); err != nil {
+			return fmt.Errorf(
+				"failed to seed participant %s: %w",
+				participant.UserID,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// SeedLeaderboard creates a helper on the environment and seeds it with the
+// participants under the default namespace scheme
+func (e *Env) SeedLeaderboard(
+	ctx context.Context,
+	clientID string,
+	leaderboardID string,
+	participants []Participant,
+	opts ...leaderboard.Option,
+) (*leaderboard.IndividualLeaderboardHelper, error) {
+	helper := e.Helper(clientID, leaderboardID, farFuture, opts...)
+	if err := NewSeeder(helper, clientID, nil).Seed(ctx, participants...); err != nil {
+		return nil, err
+	}
+
+	return helper, nil
+}
@@ -0,0 +1,273 @@
+// Package leaderboardtest runs Redis and DynamoDB Local in containers for
+// integration tests of services built on the leaderboard package, and
+// provides fixtures for seeding leaderboards. Docker must be available. It
+// is a module of its own, so services importing the leaderboard package do
+// not pull in testcontainers.
+package leaderboardtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+	"github.com/redis/go-redis/v9"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// DefaultRedisImage is the Redis image started by Start
+	DefaultRedisImage = "redis:7-alpine"
+	// DefaultDynamoImage is the DynamoDB Local image started by Start
+	DefaultDynamoImage = "amazon/dynamodb-local:2.4.0"
+	// DefaultTableName is the participant table created by Start
+	DefaultTableName = "LeaderboardTestScores"
+	// DefaultUpdatedAtIndex is the updated_at GSI created with the table
+	DefaultUpdatedAtIndex = "updated_at-index"
+)
+
+// farFuture is the end time of seeded leaderboards, keeping them cached for
+// the whole test
+var farFuture = time.Now().AddDate(1, 0, 0)
+
+// Env is a running pair of Redis and DynamoDB Local containers with the
+// participant table created
+type Env struct {
+	Redis     *redis.Client
+	Dynamo    *dynamodb.Client
+	TableName string
+	KeySchema leaderboard.KeySchema
+
+	containers []testcontainers.Container
+}
+
+// envOptions collects the settings of Start
+type envOptions struct {
+	redisImage     string
+	dynamoImage    string
+	tableName      string
+	keySchema      leaderboard.KeySchema
+	updatedAtIndex string
+}
+
+// Option configures Start
+type Option func(*envOptions)
+
+// WithRedisImage overrides the Redis image
+func WithRedisImage(image string) Option {
+	return func(o *envOptions) {
+		o.redisImage = image
+	}
+}
+
+// WithDynamoImage overrides the DynamoDB Local image
+func WithDynamoImage(image string) Option {
+	return func(o *envOptions) {
+		o.dynamoImage = image
+	}
+}
+
+// WithTable creates the participant table under a custom name and key schema
+func WithTable(tableName string, keySchema leaderboard.KeySchema) Option {
+	return func(o *envOptions) {
+		o.tableName = tableName
+		o.keySchema = keySchema
+	}
+}
+
+// Start launches the containers and creates the participant table with its
+// updated_at index. Call Close to remove the containers.
+func Start(ctx context.Context, opts ...Option) (*Env, error) {
+	options := &envOptions{
+		redisImage:     DefaultRedisImage,
+		dynamoImage:    DefaultDynamoImage,
+		tableName:      DefaultTableName,
+		keySchema:      leaderboard.DefaultKeySchema(),
+		updatedAtIndex: DefaultUpdatedAtIndex,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	env := &Env{
+		TableName: options.tableName,
+		KeySchema: options.keySchema.WithDefaults(),
+	}
+
+	redisEndpoint, err := env.startContainer(ctx, testcontainers.ContainerRequest{
+		Image:        options.redisImage,
+		ExposedPorts: []string{"6379/tcp"},
+		WaitingFor:   wait.ForLog("Ready to accept connections"),
+	})
+	if err != nil {
+		env.Close(ctx)
+		return nil, fmt.Errorf("failed to start Redis: %w", err)
+	}
+	env.Redis = redis.NewClient(&redis.Options{Addr: redisEndpoint})
+
+	dynamoEndpoint, err := env.startContainer(ctx, testcontainers.ContainerRequest{
+		Image:        options.dynamoImage,
+		ExposedPorts: []string{"8000/tcp"},
+		Cmd:          []string{"-jar", "DynamoDBLocal.jar", "-inMemory", "-sharedDb"},
+		WaitingFor:   wait.ForListeningPort("8000/tcp"),
+	})
+	if err != nil {
+		env.Close(ctx)
+		return nil, fmt.Errorf("failed to start DynamoDB Local: %w", err)
+	}
+	env.Dynamo = dynamodb.New(dynamodb.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String("http://" + dynamoEndpoint),
+		Credentials:  credentials.NewStaticCredentialsProvider("local", "local", ""),
+	})
+
+	if err := env.CreateTable(ctx, env.TableName, env.KeySchema, options.updatedAtIndex); err != nil {
+		env.Close(ctx)
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// New starts an Env for a test and removes it when the test finishes,
+// failing the test if the containers cannot be started
+func New(t testing.TB, opts ...Option) *Env {
+	t.Helper()
+
+	ctx := context.Background()
+	env, err := Start(ctx, opts...)
+	if err != nil {
+		t.Fatalf("leaderboardtest: %v", err)
+	}
+	t.Cleanup(func() {
+		env.Close(ctx)
+	})
+
+	return env
+}
+
+// startContainer starts a container and returns the host:port of its
+// exposed port
+func (e *Env) startContainer(
+	ctx context.Context,
+	request testcontainers.ContainerRequest,
+) (string, error) {
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: request,
+		Started:          true,
+	})
+	if container != nil {
+		e.containers = append(e.containers, container)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return container.Endpoint(ctx, "")
+}
+
+// CreateTable creates a participant table keyed by the schema, with an
+// updated_at GSI for incremental syncs when updatedAtIndex is set, and waits
+// until it is active
+func (e *Env) CreateTable(
+	ctx context.Context,
+	tableName string,
+	keySchema leaderboard.KeySchema,
+	updatedAtIndex string,
+) error {
+	keySchema = keySchema.WithDefaults()
+	input := &dynamodb.CreateTableInput{
+		TableName:   aws.String(tableName),
+		BillingMode: types.BillingModePayPerRequest,
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(keySchema.PartitionKey), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String(keySchema.SortKey), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String(keySchema.PartitionKey), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String(keySchema.SortKey), KeyType: types.KeyTypeRange},
+		},
+	}
+	if updatedAtIndex != "" {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String("updated_at"),
+			AttributeType: types.ScalarAttributeTypeN,
+		})
+		input.GlobalSecondaryIndexes = []types.GlobalSecondaryIndex{{
+			IndexName: aws.String(updatedAtIndex),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String(keySchema.PartitionKey), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("updated_at"), KeyType: types.KeyTypeRange},
+			},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}}
+	}
+
+	if _, err := e.Dynamo.CreateTable(ctx, input); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", tableName, err)
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(e.Dynamo)
+	err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	}, time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed waiting for table %s: %w", tableName, err)
+	}
+
+	return nil
+}
+
+// Helper creates a helper backed by the environment's stores and table
+func (e *Env) Helper(
+	clientID string,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+	opts ...leaderboard.Option,
+) *leaderboard.IndividualLeaderboardHelper {
+	opts = append([]leaderboard.Option{
+		leaderboard.WithTableName(e.TableName),
+		leaderboard.WithKeySchema(e.KeySchema),
+	}, opts...)
+
+	return leaderboard.NewIndividualLeaderboardHelper(
+		e.Dynamo,
+		e.Redis,
+		clientID,
+		leaderboardID,
+		leaderboardEndTime,
+		opts...,
+	)
+}
+
+// Reset empties Redis so each test starts from a cold cache
+func (e *Env) Reset(ctx context.Context) error {
+	if err := e.Redis.FlushAll(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to flush Redis: %w", err)
+	}
+
+	return nil
+}
+
+// Close removes the containers
+func (e *Env) Close(ctx context.Context) error {
+	if e.Redis != nil {
+		e.Redis.Close()
+	}
+
+	var firstErr error
+	for _, container := range e.containers {
+		if err := container.Terminate(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	e.containers = nil
+
+	return firstErr
+}
@@ -0,0 +1,187 @@
+package leaderboardtest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+)
+
+func TestWritesDuringForcedWarmAreKept(t *testing.T) {
+	ctx := context.Background()
+	env := startEnv(t)
+	participants := RankedParticipants(5)
+	helper, err := env.SeedLeaderboard(ctx, "client", "rewarmed", participants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeder := NewSeeder(helper, "client", nil)
+
+	// Write to every participant once the rebuild has read DynamoDB but
+	// before it swaps its keys in, which used to drop the writes
+	var writeErr error
+	wrote := false
+	err = helper.WarmCache(ctx, true, func(progress leaderboard.WarmProgress) {
+		if wrote || progress.Done {
+			return
+		}
+		wrote = true
+		for _, participant := range participants {
+			member, err := seeder.MemberID(participant.UserID)
+			if err != nil {
+				writeErr = err
+				return
+			}
+			if _, err := helper.UpdateScore(ctx, member, 10); err != nil {
+				writeErr = err
+				return
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("WarmCache: %v", err)
+	}
+	if writeErr != nil {
+		t.Fatalf("UpdateScore: %v", writeErr)
+	}
+	if !wrote {
+		t.Fatal("WarmCache reported no progress before finishing")
+	}
+
+	top, err := helper.GetTopNParticipants(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetTopNParticipants: %v", err)
+	}
+	if len(top) != len(participants) {
+		t.Fatalf("GetTopNParticipants returned %d entries, want %d", len(top), len(participants))
+	}
+	for i, entry := range top {
+		want := participants[i].Score + 10
+		if entry.Score != want {
+			t.Fatalf("entry %d (%s) has score %v, want %v", i, entry.Member, entry.Score, want)
+		}
+	}
+}
+
+func TestReadYourWritesSyncKeepsConcurrentIncrements(t *testing.T) {
+	ctx := context.Background()
+	env := startEnv(t)
+	participants := RankedParticipants(5)
+	helper, err := env.SeedLeaderboard(ctx, "client", "synced", participants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeder := NewSeeder(helper, "client", nil)
+
+	// Start writers once the rebuild is under way. Writes landing during the
+	// rebuild are synced from DynamoDB by the ones reading their own writes,
+	// racing the plain increments, which the sync used to overwrite.
+	const writers, writes = 4, 10
+	var wg sync.WaitGroup
+	writeErrs := make(chan error, writers*writes*len(participants))
+	started := false
+	err = helper.WarmCache(ctx, true, func(progress leaderboard.WarmProgress) {
+		if started || progress.Done {
+			return
+		}
+		started = true
+		for w := 0; w < writers; w++ {
+			writeCtx := ctx
+			if w%2 == 0 {
+				writeCtx = leaderboard.ReadYourWrites(ctx)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < writes; i++ {
+					for _, participant := range participants {
+						member, err := seeder.MemberID(participant.UserID)
+						if err != nil {
+							writeErrs <- err
+							return
+						}
+						if _, err := helper.UpdateScore(writeCtx, member, 1); err != nil {
+							writeErrs <- err
+							return
+						}
+					}
+				}
+			}()
+		}
+	})
+	if err != nil {
+		t.Fatalf("WarmCache: %v", err)
+	}
+	wg.Wait()
+	close(writeErrs)
+	for err := range writeErrs {
+		t.Fatalf("UpdateScore: %v", err)
+	}
+	if !started {
+		t.Fatal("WarmCache reported no progress before finishing")
+	}
+
+	top, err := helper.GetTopNParticipants(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetTopNParticipants: %v", err)
+	}
+	if len(top) != len(participants) {
+		t.Fatalf("GetTopNParticipants returned %d entries, want %d", len(top), len(participants))
+	}
+	for i, entry := range top {
+		want := participants[i].Score + writers*writes
+		if entry.Score != want {
+			t.Fatalf("entry %d (%s) has score %v, want %v", i, entry.Member, entry.Score, want)
+		}
+	}
+}
+
+func TestRebuildLockIsRenewedWhileRebuilding(t *testing.T) {
+	ctx := context.Background()
+	env := startEnv(t)
+	participants := RankedParticipants(5)
+	const ttl = 200 * time.Millisecond
+	lock := leaderboard.WithRebuildLock(ttl, -1)
+	helper, err := env.SeedLeaderboard(ctx, "client", "locked", participants, lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := env.Helper("client", "locked", farFuture, lock)
+
+	// Outlast the lock's TTL mid-rebuild; another instance must still find
+	// it held rather than start a second rebuild
+	var otherErr error
+	checked := false
+	err = helper.WarmCache(ctx, true, func(progress leaderboard.WarmProgress) {
+		if checked || progress.Done {
+			return
+		}
+		checked = true
+		time.Sleep(5 * ttl)
+		otherErr = other.WarmCache(ctx, true, nil)
+	})
+	if err != nil {
+		t.Fatalf("WarmCache: %v", err)
+	}
+	if !checked {
+		t.Fatal("WarmCache reported no progress before finishing")
+	}
+	if !errors.Is(otherErr, leaderboard.ErrLeaderboardRebuilding) {
+		t.Fatalf("concurrent WarmCache returned %v, want %v", otherErr, leaderboard.ErrLeaderboardRebuilding)
+	}
+
+	// The lock is released once the rebuild is done
+	if err := other.WarmCache(ctx, true, nil); err != nil {
+		t.Fatalf("WarmCache after rebuild: %v", err)
+	}
+	top, err := other.GetTopNParticipants(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetTopNParticipants: %v", err)
+	}
+	if len(top) != len(participants) {
+		t.Fatalf("GetTopNParticipants returned %d entries, want %d", len(top), len(participants))
+	}
+}
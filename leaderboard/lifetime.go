@@ -0,0 +1,77 @@
+package leaderboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+)
+
+// LifetimeLeaderboardID is the ID of each client's lifetime leaderboard
+// maintained by WithLifetimeLeaderboard
+const LifetimeLeaderboardID = "__lifetime"
+
+// lifetimeStorageID returns the storage ID of a client's lifetime
+// leaderboard, kept apart from the client's event leaderboards
+func lifetimeStorageID(clientID string) string {
+	return tenantScopedID(clientID, LifetimeLeaderboardID)
+}
+
+// LifetimeLeaderboard returns a helper for the client's lifetime
+// leaderboard, which never ends and accepts writes at any time. It shares
+// the helper's stores and options.
+func (l *IndividualLeaderboardHelper) LifetimeLeaderboard() *IndividualLeaderboardHelper {
+	lifetime := *l
+	lifetime.leaderboardID = LifetimeLeaderboardID
+	lifetime.storageID = lifetimeStorageID(l.clientID)
+	lifetime.end = &leaderboardEnd{}
+	lifetime.startTime = time.Time{}
+	lifetime.scheduledStart = false
+	lifetime.freezeStart = time.Time{}
+	lifetime.freezeEnd = time.Time{}
+	lifetime.lifetimeLeaderboard = false
+	lifetime.payoutTable = nil
+	lifetime.escrow = nil
+	lifetime.brackets = nil
+
+	// Start from the helper's visibility without sharing later changes
+	lifetime.visibility = &leaderboardVisibility{
+		visibility: l.Visibility(),
+		shadow:     l.ShadowMode(),
+	}
+
+	// Its standings change with every event leaderboard's writes, which
+	// this helper never sees
+	lifetime.rankCache = nil
+
+	return &lifetime
+}
+
+// applyWithLifetime credits a score update to the leaderboard and the
+// client's lifetime leaderboard in one transaction, returning the
+// leaderboard's result
+func (l *IndividualLeaderboardHelper) applyWithLifetime(
+	ctx context.Context,
+	update *ScoreUpdate,
+) (*customTypes.MemberScore, error) {
+	results, err := l.repo.UpdateScoresAtomically(ctx, []repos.ScoreWrite{
+		{
+			LeaderboardID:      l.storageID,
+			NamespacedUserID:   update.NamespacedUserID,
+			ScoreDelta:         update.ScoreDelta,
+			LeaderboardEndTime: l.endTime(),
+			Attributes:         update.Attributes,
+		},
+		{
+			LeaderboardID:    lifetimeStorageID(l.clientID),
+			NamespacedUserID: update.NamespacedUserID,
+			ScoreDelta:       update.ScoreDelta,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &results[0], nil
+}
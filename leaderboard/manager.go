@@ -0,0 +1,159 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+	"github.com/redis/go-redis/v9"
+)
+
+// EndTimeResolver looks up when a leaderboard ends, typically from the
+// leaderboard's metadata
+type EndTimeResolver interface {
+	ResolveEndTime(ctx context.Context, leaderboardID string) (time.Time, error)
+}
+
+// EndTimeResolverFunc adapts a function to the EndTimeResolver interface
+type EndTimeResolverFunc func(ctx context.Context, leaderboardID string) (time.Time, error)
+
+// ResolveEndTime calls f
+func (f EndTimeResolverFunc) ResolveEndTime(
+	ctx context.Context,
+	leaderboardID string,
+) (time.Time, error) {
+	return f(ctx, leaderboardID)
+}
+
+// Manager hands out one cached helper per leaderboard for a client. Helpers
+// share the manager's store clients and options. It is safe for concurrent
+// use.
+type Manager struct {
+	repo     *repos.ParticipantRepo
+	options  *helperOptions
+	clientID string
+	endTimes EndTimeResolver
+
+	mu      sync.Mutex
+	entries map[string]*managerEntry
+}
+
+// managerEntry holds a helper while its end time is being resolved, so
+// concurrent callers for the same leaderboard share one lookup
+type managerEntry struct {
+	ready  chan struct{}
+	helper *IndividualLeaderboardHelper
+	err    error
+}
+
+// NewManager creates a manager building helpers with the given options.
+// endTimes is consulted once per leaderboard, when its helper is first
+// requested.
+func NewManager(
+	dynamoClient *dynamodb.Client,
+	redisClient *redis.Client,
+	clientID string,
+	endTimes EndTimeResolver,
+	opts ...Option,
+) *Manager {
+	options := applyOptions(opts)
+
+	repo := repos.NewParticipantRepo(dynamoClient, redisClient, options.repoConfig)
+	return newManager(repo, options, clientID, endTimes)
+}
+
+// newManager creates a manager for a client on top of a shared repo
+func newManager(
+	repo *repos.ParticipantRepo,
+	options *helperOptions,
+	clientID string,
+	endTimes EndTimeResolver,
+) *Manager {
+	return &Manager{
+		repo:     repo,
+		options:  options,
+		clientID: clientID,
+		endTimes: endTimes,
+		entries:  make(map[string]*managerEntry),
+	}
+}
+
+// Helper returns the helper for a leaderboard, creating it on first use.
+// Failed end time lookups are not cached.
+func (m *Manager) Helper(
+	ctx context.Context,
+	leaderboardID string,
+) (*IndividualLeaderboardHelper, error) {
+	m.mu.Lock()
+	entry, ok := m.entries[leaderboardID]
+	if !ok {
+		entry = &managerEntry{ready: make(chan struct{})}
+		m.entries[leaderboardID] = entry
+	}
+	m.mu.Unlock()
+
+	// Another caller is already building this helper
+	if ok {
+		select {
+		case <-entry.ready:
+			return entry.helper, entry.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	endTime, err := m.endTimes.ResolveEndTime(ctx, leaderboardID)
+	if err != nil {
+		entry.err = fmt.Errorf(
+			"failed to resolve leaderboard end time: %w",
+			err,
+		)
+
+		// Let the next caller retry the lookup
+		m.mu.Lock()
+		delete(m.entries, leaderboardID)
+		m.mu.Unlock()
+	} else {
+		entry.helper = newHelper(m.repo, m.options, m.clientID, leaderboardID, endTime)
+	}
+	close(entry.ready)
+
+	return entry.helper, entry.err
+}
+
+// Evict drops the cached helper for a leaderboard, for example once it has
+// ended or its end time changed. The next Helper call builds a new one.
+func (m *Manager) Evict(leaderboardID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, leaderboardID)
+}
+
+// Len returns the number of cached helpers
+func (m *Manager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.entries)
+}
+
+// ExtendLeaderboard moves one of the client's leaderboards to a later end
+// time, as IndividualLeaderboardHelper.ExtendLeaderboard. The end time
+// resolver must report the new end time too, so that helpers built later
+// agree.
+func (m *Manager) ExtendLeaderboard(
+	ctx context.Context,
+	leaderboardID string,
+	newEndTime time.Time,
+) error {
+	helper, err := m.Helper(ctx, leaderboardID)
+	if err != nil {
+		return err
+	}
+
+	return helper.ExtendLeaderboard(ctx, newEndTime)
+}
@@ -0,0 +1,177 @@
+package leaderboard
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+)
+
+// FieldCipher encrypts single display metadata values (see
+// WithEncryptedMetadata). associatedData binds a ciphertext to the member
+// and attribute it was written for.
+type FieldCipher = repos.FieldCipher
+
+// errCiphertextTooShort is returned for ciphertexts cut short
+var errCiphertextTooShort = errors.New("ciphertext too short")
+
+// aeadFieldCipher encrypts with AES-GCM under a fixed key
+type aeadFieldCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAEADFieldCipher creates a FieldCipher encrypting with AES-GCM under the
+// given 16, 24 or 32 byte key. Each value gets a random nonce.
+func NewAEADFieldCipher(key []byte) (FieldCipher, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aeadFieldCipher{aead: aead}, nil
+}
+
+// newGCM creates an AES-GCM AEAD for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to create cipher: %w",
+			err,
+		)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// sealValue encrypts plaintext under aead, prefixing a random nonce
+func sealValue(aead cipher.AEAD, plaintext []byte, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf(
+			"failed to generate nonce: %w",
+			err,
+		)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+// openValue reverses sealValue
+func openValue(aead cipher.AEAD, ciphertext []byte, associatedData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errCiphertextTooShort
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, sealed, associatedData)
+}
+
+// Encrypt implements FieldCipher
+func (c *aeadFieldCipher) Encrypt(
+	ctx context.Context,
+	plaintext []byte,
+	associatedData []byte,
+) ([]byte, error) {
+	return sealValue(c.aead, plaintext, associatedData)
+}
+
+// Decrypt implements FieldCipher
+func (c *aeadFieldCipher) Decrypt(
+	ctx context.Context,
+	ciphertext []byte,
+	associatedData []byte,
+) ([]byte, error) {
+	return openValue(c.aead, ciphertext, associatedData)
+}
+
+// DataKeyProvider issues and unwraps data keys for envelope encryption,
+// typically backed by KMS GenerateDataKey (with a 256-bit AES key spec) and
+// Decrypt
+type DataKeyProvider interface {
+	// GenerateDataKey returns a new data key in the clear and wrapped
+	GenerateDataKey(ctx context.Context) (plaintext []byte, wrapped []byte, err error)
+	// DecryptDataKey unwraps a data key returned by GenerateDataKey
+	DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// envelopeFieldCipher encrypts each value under a fresh data key stored,
+// wrapped, with the ciphertext
+type envelopeFieldCipher struct {
+	keys DataKeyProvider
+}
+
+// NewEnvelopeFieldCipher creates a FieldCipher encrypting each value with
+// AES-GCM under a new data key from keys, stored wrapped next to the value,
+// so the master key never leaves the key service. Every encryption and
+// decryption calls keys once.
+func NewEnvelopeFieldCipher(keys DataKeyProvider) FieldCipher {
+	return &envelopeFieldCipher{keys: keys}
+}
+
+// Encrypt implements FieldCipher. The ciphertext is the wrapped key's
+// length as two bytes, the wrapped key, then the sealed value.
+func (c *envelopeFieldCipher) Encrypt(
+	ctx context.Context,
+	plaintext []byte,
+	associatedData []byte,
+) ([]byte, error) {
+	key, wrapped, err := c.keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to generate data key: %w",
+			err,
+		)
+	}
+	if len(wrapped) > 0xFFFF {
+		return nil, fmt.Errorf("wrapped data key of %d bytes is too long", len(wrapped))
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := sealValue(aead, plaintext, associatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := make([]byte, 2, 2+len(wrapped)+len(sealed))
+	binary.BigEndian.PutUint16(ciphertext, uint16(len(wrapped)))
+	ciphertext = append(ciphertext, wrapped...)
+	return append(ciphertext, sealed...), nil
+}
+
+// Decrypt implements FieldCipher
+func (c *envelopeFieldCipher) Decrypt(
+	ctx context.Context,
+	ciphertext []byte,
+	associatedData []byte,
+) ([]byte, error) {
+	if len(ciphertext) < 2 {
+		return nil, errCiphertextTooShort
+	}
+	wrappedLen := int(binary.BigEndian.Uint16(ciphertext))
+	if len(ciphertext) < 2+wrappedLen {
+		return nil, errCiphertextTooShort
+	}
+	wrapped, sealed := ciphertext[2:2+wrappedLen], ciphertext[2+wrappedLen:]
+
+	key, err := c.keys.DecryptDataKey(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to decrypt data key: %w",
+			err,
+		)
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return openValue(aead, sealed, associatedData)
+}
@@ -0,0 +1,189 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Checkpoint records how far a leaderboard migration has progressed so an
+// interrupted run can resume from the last copied participant. Partition is
+// the source partition being copied; checkpoints without one were taken in
+// the leaderboard's own partition.
+type Checkpoint struct {
+	LeaderboardID        string    `json:"leaderboardID"`
+	Partition            string    `json:"partition,omitempty"`
+	LastNamespacedUserID string    `json:"lastNamespacedUserID"`
+	ItemsCopied          int64     `json:"itemsCopied"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+}
+
+// CheckpointStore persists migration checkpoints between runs
+type CheckpointStore interface {
+	// Load returns the checkpoint for a leaderboard, or nil if none exists
+	Load(ctx context.Context, leaderboardID string) (*Checkpoint, error)
+	// Save stores the checkpoint, replacing any previous one
+	Save(ctx context.Context, checkpoint *Checkpoint) error
+	// Clear removes the checkpoint once a migration has completed
+	Clear(ctx context.Context, leaderboardID string) error
+}
+
+// MemoryCheckpointStore keeps checkpoints in process memory. It only allows
+// resuming within the same process and is mainly useful for tests and
+// one-shot runs.
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewMemoryCheckpointStore creates an empty in-memory checkpoint store
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{
+		checkpoints: make(map[string]Checkpoint),
+	}
+}
+
+// Load returns the stored checkpoint for the leaderboard
+func (s *MemoryCheckpointStore) Load(
+	ctx context.Context,
+	leaderboardID string,
+) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoint, ok := s.checkpoints[leaderboardID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &checkpoint, nil
+}
+
+// Save stores a copy of the checkpoint
+func (s *MemoryCheckpointStore) Save(
+	ctx context.Context,
+	checkpoint *Checkpoint,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[checkpoint.LeaderboardID] = *checkpoint
+	return nil
+}
+
+// Clear removes the checkpoint for the leaderboard
+func (s *MemoryCheckpointStore) Clear(
+	ctx context.Context,
+	leaderboardID string,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.checkpoints, leaderboardID)
+	return nil
+}
+
+// FileCheckpointStore keeps one JSON checkpoint file per leaderboard in a
+// directory, so a migration can be resumed after the process restarts
+type FileCheckpointStore struct {
+	dir string
+}
+
+// NewFileCheckpointStore creates a checkpoint store rooted at dir, creating
+// the directory if needed
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf(
+			"failed to create checkpoint directory: %w",
+			err,
+		)
+	}
+
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+// path returns the checkpoint file path for a leaderboard
+func (s *FileCheckpointStore) path(leaderboardID string) string {
+	return filepath.Join(s.dir, leaderboardID+".checkpoint.json")
+}
+
+// Load reads the checkpoint file for the leaderboard
+func (s *FileCheckpointStore) Load(
+	ctx context.Context,
+	leaderboardID string,
+) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path(leaderboardID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(
+			"failed to read checkpoint: %w",
+			err,
+		)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf(
+			"failed to decode checkpoint: %w",
+			err,
+		)
+	}
+
+	return &checkpoint, nil
+}
+
+// Save atomically replaces the checkpoint file for the leaderboard
+func (s *FileCheckpointStore) Save(
+	ctx context.Context,
+	checkpoint *Checkpoint,
+) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to encode checkpoint: %w",
+			err,
+		)
+	}
+
+	// Write to a temporary file first so a crash never leaves a torn checkpoint
+	target := s.path(checkpoint.LeaderboardID)
+	tmp := target + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf(
+			"failed to write checkpoint: %w",
+			err,
+		)
+	}
+
+	if err := os.Rename(tmp, target); err != nil {
+		return fmt.Errorf(
+			"failed to replace checkpoint: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// Clear deletes the checkpoint file for the leaderboard
+func (s *FileCheckpointStore) Clear(
+	ctx context.Context,
+	leaderboardID string,
+) error {
+	err := os.Remove(s.path(leaderboardID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf(
+			"failed to remove checkpoint: %w",
+			err,
+		)
+	}
+
+	return nil
+}
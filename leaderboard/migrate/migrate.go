@@ -0,0 +1,407 @@
+// Package migrate copies leaderboard data between DynamoDB tables or AWS
+// regions with resumable checkpoints and throughput throttles.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// Endpoint identifies one side of a migration. The DynamoDB client decides
+// the region; the Redis client is optional and only used on the destination
+// to drop a stale cached leaderboard once the copy has finished.
+type Endpoint struct {
+	DynamoClient *dynamodb.Client
+	TableName    string
+	RedisClient  *redis.Client
+	// RedisKeyPrefix and RedisDB locate the cached leaderboards of a tenant
+	// on RedisClient, as the tenant's TenantStorage and any
+	// WithRedisKeyPrefix do. A non-zero RedisDB is selected on a connection
+	// of its own.
+	RedisKeyPrefix string
+	RedisDB        int
+	// Partitioning is how the table spreads the leaderboards' items
+	Partitioning Partitioning
+}
+
+// Partitioning describes how a table spreads each leaderboard's items over
+// DynamoDB partitions. It matches the leaderboards' WithWriteShards and,
+// under the additive merge strategy, the regions given to
+// WithMergeStrategy. The zero value keeps every item in one partition.
+type Partitioning struct {
+	// WriteShards is the number of write shards per partition
+	WriteShards int
+	// Regions lists the regions whose score deltas are kept in partitions
+	// of their own under the additive merge strategy
+	Regions []string
+}
+
+// partition is a source partition holding part of a leaderboard's items
+// and the unsharded destination partition they are copied to
+type partition struct {
+	source      string
+	destination string
+}
+
+// Result summarises a completed leaderboard migration
+type Result struct {
+	LeaderboardID string
+	ItemsCopied   int64
+	Resumed       bool
+	Duration      time.Duration
+}
+
+// Migrator copies leaderboards from a source endpoint to a destination
+type Migrator struct {
+	source      Endpoint
+	destination Endpoint
+	checkpoints CheckpointStore
+	keySchema   repos.KeySchema
+	pageSize    int32
+	readLimit   int
+	writeLimit  int
+	writeUnits  float64
+	writer      *repos.BatchWriter
+}
+
+// Option configures a Migrator
+type Option func(*Migrator)
+
+// WithCheckpointStore sets where progress is recorded between pages
+func WithCheckpointStore(store CheckpointStore) Option {
+	return func(m *Migrator) {
+		m.checkpoints = store
+	}
+}
+
+// WithKeySchema sets the key schema shared by the source and destination
+// tables
+func WithKeySchema(schema repos.KeySchema) Option {
+	return func(m *Migrator) {
+		m.keySchema = schema.WithDefaults()
+	}
+}
+
+// WithPageSize sets how many items are read from the source per query page
+func WithPageSize(pageSize int32) Option {
+	return func(m *Migrator) {
+		m.pageSize = pageSize
+	}
+}
+
+// WithReadRateLimit caps the number of items read from the source per second
+func WithReadRateLimit(itemsPerSecond int) Option {
+	return func(m *Migrator) {
+		m.readLimit = itemsPerSecond
+	}
+}
+
+// WithWriteRateLimit caps the number of items written to the destination per
+// second. Writes slow down below the cap whenever the destination throttles.
+func WithWriteRateLimit(itemsPerSecond int) Option {
+	return func(m *Migrator) {
+		m.writeLimit = itemsPerSecond
+	}
+}
+
+// WithWriteCapacityLimit caps the write capacity units consumed at the
+// destination per second, as reported by DynamoDB
+func WithWriteCapacityLimit(unitsPerSecond float64) Option {
+	return func(m *Migrator) {
+		m.writeUnits = unitsPerSecond
+	}
+}
+
+// NewMigrator creates a new migrator between the two endpoints. Empty table
+// names default to the standard participant scores table.
+func NewMigrator(
+	source Endpoint,
+	destination Endpoint,
+	opts ...Option,
+) *Migrator {
+	if source.TableName == "" {
+		source.TableName = repos.DefaultTableName
+	}
+	if destination.TableName == "" {
+		destination.TableName = repos.DefaultTableName
+	}
+
+	m := &Migrator{
+		source:      source,
+		destination: destination,
+		checkpoints: NewMemoryCheckpointStore(),
+		keySchema:   repos.DefaultKeySchema(),
+		pageSize:    100,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	// Adapt the write rate to the destination's spare capacity
+	limits := repos.DefaultBatchWriteLimits()
+	if m.writeLimit > 0 {
+		limits.MaxRate = float64(m.writeLimit)
+	}
+	limits.CapacityPerSecond = m.writeUnits
+	m.writer = repos.NewBatchWriter(destination.DynamoClient, limits)
+
+	return m
+}
+
+// MigrateLeaderboard copies every participant item of a leaderboard from the
+// source to the destination table, across all of the source's write shards
+// and regional partitions. Items are copied verbatim apart from their keys,
+// which place each item in the partition the destination's partitioning
+// expects, so all stored participant attributes (score, timestamps,
+// identifiers) are preserved. Every source region needs a partition at the
+// destination. Progress is checkpointed after each page; calling it again
+// after a failure resumes from the last checkpoint. Writes overwrite
+// existing destination items, so re-copying a page is harmless.
+func (m *Migrator) MigrateLeaderboard(
+	ctx context.Context,
+	leaderboardID string,
+) (*Result, error) {
+	startedAt := time.Now()
+	readThrottle := newThrottle(m.readLimit)
+
+	partitions, err := m.partitions(leaderboardID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resume from an earlier run if one was interrupted
+	checkpoint, err := m.checkpoints.Load(ctx, leaderboardID)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to load migration checkpoint: %w",
+			err,
+		)
+	}
+
+	result := &Result{LeaderboardID: leaderboardID}
+	if checkpoint != nil {
+		result.Resumed = true
+		result.ItemsCopied = checkpoint.ItemsCopied
+	} else {
+		checkpoint = &Checkpoint{LeaderboardID: leaderboardID}
+	}
+
+	// Resume in the partition the checkpoint was taken in
+	resumeAt := 0
+	if checkpoint.Partition != "" {
+		resumeAt = -1
+		for i, partition := range partitions {
+			if partition.source == checkpoint.Partition {
+				resumeAt = i
+			}
+		}
+		if resumeAt < 0 {
+			return nil, fmt.Errorf(
+				"checkpointed partition %s is not one of the source's partitions",
+				checkpoint.Partition,
+			)
+		}
+	}
+
+	for _, partition := range partitions[resumeAt:] {
+		if checkpoint.Partition != "" && checkpoint.Partition != partition.source {
+			checkpoint.LastNamespacedUserID = ""
+		}
+		checkpoint.Partition = partition.source
+
+		err := m.copyPartition(ctx, partition, checkpoint, readThrottle, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Drop any cached copy at the destination so it is rebuilt from the new data
+	if err := m.invalidateDestination(ctx, leaderboardID); err != nil {
+		return nil, err
+	}
+
+	if err := m.checkpoints.Clear(ctx, leaderboardID); err != nil {
+		return nil, fmt.Errorf(
+			"failed to clear migration checkpoint: %w",
+			err,
+		)
+	}
+
+	result.Duration = time.Since(startedAt)
+	return result, nil
+}
+
+// invalidateDestination drops the destination's cached copy of a
+// leaderboard, if it has a Redis client
+func (m *Migrator) invalidateDestination(ctx context.Context, leaderboardID string) error {
+	client := m.destination.RedisClient
+	if client == nil {
+		return nil
+	}
+	if m.destination.RedisDB != 0 {
+		options := *client.Options()
+		options.DB = m.destination.RedisDB
+		client = redis.NewClient(&options)
+		defer client.Close()
+	}
+
+	prefix := m.destination.RedisKeyPrefix
+	err := client.Del(
+		ctx,
+		prefix+repos.RedisKey(leaderboardID),
+		prefix+repos.CachedMarkerKey(leaderboardID),
+	).Err()
+	if err != nil {
+		return fmt.Errorf(
+			"failed to invalidate destination cache: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// partitions returns the source partitions holding a leaderboard's items,
+// in the order they are copied, with their destination partitions
+func (m *Migrator) partitions(leaderboardID string) ([]partition, error) {
+	destinationRegions := make(map[string]bool, len(m.destination.Partitioning.Regions))
+	for _, region := range m.destination.Partitioning.Regions {
+		destinationRegions[region] = true
+	}
+
+	bases := []partition{{source: leaderboardID, destination: leaderboardID}}
+	for _, region := range m.source.Partitioning.Regions {
+		if !destinationRegions[region] {
+			return nil, fmt.Errorf(
+				"source region %s has no partition at the destination",
+				region,
+			)
+		}
+		regional := repos.RegionalPartitionKey(leaderboardID, region)
+		bases = append(bases, partition{source: regional, destination: regional})
+	}
+
+	var partitions []partition
+	for _, base := range bases {
+		for _, source := range repos.ShardPartitionKeys(base.source, m.source.Partitioning.WriteShards) {
+			partitions = append(partitions, partition{source: source, destination: base.destination})
+		}
+	}
+
+	return partitions, nil
+}
+
+// copyPartition copies the items of one source partition, after the
+// checkpoint's last participant, to their destination partitions
+func (m *Migrator) copyPartition(
+	ctx context.Context,
+	partition partition,
+	checkpoint *Checkpoint,
+	readThrottle *throttle,
+	result *Result,
+) error {
+	keyCondition, keyValues := m.keySchema.PartitionQuery(partition.source)
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(m.source.TableName),
+		KeyConditionExpression:    aws.String(keyCondition),
+		ExpressionAttributeNames:  m.keySchema.KeyNames(),
+		ExpressionAttributeValues: keyValues,
+		Limit:                     aws.Int32(m.pageSize),
+		ConsistentRead:            aws.Bool(true),
+	}
+	if checkpoint.LastNamespacedUserID != "" {
+		input.ExclusiveStartKey = m.keySchema.ItemKey(
+			partition.source,
+			checkpoint.LastNamespacedUserID,
+		)
+	}
+
+	paginator := dynamodb.NewQueryPaginator(m.source.DynamoClient, input)
+	for paginator.HasMorePages() {
+		if err := readThrottle.wait(ctx, int(m.pageSize)); err != nil {
+			return err
+		}
+
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to query source table: %w",
+				err,
+			)
+		}
+		if len(page.Items) == 0 {
+			continue
+		}
+
+		// Copy the page into the destination's partitions; the writer
+		// batches it and adapts to throttling
+		requests := make([]types.WriteRequest, len(page.Items))
+		var lastUser string
+		for i, item := range page.Items {
+			namespacedUserID, err := m.itemMember(item)
+			if err != nil {
+				return err
+			}
+			destinationKey := m.keySchema.ItemKey(
+				repos.ShardPartitionKey(partition.destination, namespacedUserID, m.destination.Partitioning.WriteShards),
+				namespacedUserID,
+			)
+			for name, value := range destinationKey {
+				item[name] = value
+			}
+			requests[i] = types.WriteRequest{
+				PutRequest: &types.PutRequest{Item: item},
+			}
+			lastUser = namespacedUserID
+		}
+		err = m.writer.Write(ctx, m.destination.TableName, requests)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to write batch to destination table: %w",
+				err,
+			)
+		}
+
+		// Record progress only once the whole page is durable
+		result.ItemsCopied += int64(len(page.Items))
+		checkpoint.LastNamespacedUserID = lastUser
+		checkpoint.ItemsCopied = result.ItemsCopied
+		checkpoint.UpdatedAt = utils.GetCurrTimeStamp()
+		if err := m.checkpoints.Save(ctx, checkpoint); err != nil {
+			return fmt.Errorf(
+				"failed to save migration checkpoint: %w",
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// itemMember returns the participant a source item belongs to
+func (m *Migrator) itemMember(item map[string]types.AttributeValue) (string, error) {
+	sortValue, ok := item[m.keySchema.SortKey].(*types.AttributeValueMemberS)
+	if !ok {
+		return "", fmt.Errorf(
+			"source item is missing its %s sort key",
+			m.keySchema.SortKey,
+		)
+	}
+	namespacedUserID, ok := m.keySchema.MemberFromSortValue(sortValue.Value)
+	if !ok {
+		return "", fmt.Errorf(
+			"source sort key %q does not match the key schema",
+			sortValue.Value,
+		)
+	}
+
+	return namespacedUserID, nil
+}
@@ -0,0 +1,59 @@
+package migrate
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// throttle spaces out work so that no more than a fixed number of items are
+// processed per second
+type throttle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newThrottle creates a throttle allowing perSecond items per second. A
+// non-positive rate disables throttling.
+func newThrottle(perSecond int) *throttle {
+	if perSecond <= 0 {
+		return nil
+	}
+
+	return &throttle{
+		interval: time.Second / time.Duration(perSecond),
+	}
+}
+
+// wait blocks until n more items may be processed or ctx is done
+func (t *throttle) wait(ctx context.Context, n int) error {
+	if t == nil || n <= 0 {
+		return nil
+	}
+
+	// Reserve a slot for the n items and work out when it starts
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	start := t.next
+	t.next = t.next.Add(time.Duration(n) * t.interval)
+	t.mu.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// Milestone is a score threshold a participant reached and when it was
+// first reached (see WithMilestones)
+type Milestone = customTypes.Milestone
+
+// GetMilestones lists the milestones one of the helper's client's users has
+// reached, by threshold. It returns ErrParticipantNotFound for users that
+// are not on the leaderboard. It is a standings read, so the leaderboard's
+// visibility and access policy apply, and callers not shown
+// ResultFieldMilestones get none.
+func (l *IndividualLeaderboardHelper) GetMilestones(
+	ctx context.Context,
+	userID string,
+) ([]Milestone, error) {
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	namespacedUserID, err := l.memberID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := l.repo.GetParticipant(ctx, l.storageID, namespacedUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if visible := l.visibleFields(ctx); visible != nil && !visible[ResultFieldMilestones] {
+		return nil, nil
+	}
+	return participant.Milestones, nil
+}
+
+// recordMilestones records the milestones an applied score update crossed
+// and reports the newly reached ones on its result
+func (l *IndividualLeaderboardHelper) recordMilestones(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta float64,
+	result *customTypes.MemberScore,
+) {
+	// Writes made during a rebuild do not know the new total
+	if result.Rank == 0 {
+		return
+	}
+
+	reached, err := l.repo.RecordMilestones(
+		ctx,
+		l.storageID,
+		namespacedUserID,
+		result.Score-scoreDelta,
+		result.Score,
+	)
+	if err != nil {
+		// The score write is durable, so only log
+		fmt.Printf("%sError recording milestones: %v\n", utils.LogPrefix(ctx), err)
+		return
+	}
+
+	result.Milestones = reached
+}
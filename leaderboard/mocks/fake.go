@@ -0,0 +1,598 @@
+// Package mocks provides test doubles for the leaderboard.Leaderboard and
+// leaderboard.LeaderboardWriter interfaces: Fake, an in-memory leaderboard
+// with deterministic ordering and controllable ranks, and Mock, whose
+// behaviour is set per method.
+package mocks
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// Fake is an in-memory leaderboard. Members are ordered like the Redis
+// backed leaderboard: by score descending, ties broken by member in reverse
+// lexicographic order. Ranks can be pinned to force a standing regardless of
+// score. It is safe for concurrent use.
+type Fake struct {
+	mu         sync.Mutex
+	namespacer leaderboard.Namespacer
+	clientID   string
+	scores     map[string]float64
+	attributes map[string]map[string]string
+	pinned     map[string]int64
+	errs       map[string]error
+}
+
+var (
+	_ leaderboard.Leaderboard       = (*Fake)(nil)
+	_ leaderboard.LeaderboardWriter = (*Fake)(nil)
+)
+
+// NewFake creates an empty fake leaderboard validating member IDs with the
+// default namespace scheme
+func NewFake() *Fake {
+	return &Fake{
+		namespacer: leaderboard.DefaultNamespacer(),
+		scores:     make(map[string]float64),
+		attributes: make(map[string]map[string]string),
+		pinned:     make(map[string]int64),
+		errs:       make(map[string]error),
+	}
+}
+
+// WithNamespacer validates member IDs with a custom namespace scheme
+func (f *Fake) WithNamespacer(namespacer leaderboard.Namespacer) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.namespacer = namespacer
+	return f
+}
+
+// WithClient sets the client whose users the methods taking a userID, such
+// as JoinLeaderboard and UpdateScores, refer to. Without one they fail with
+// ErrClientRequired, like a helper of a global leaderboard.
+func (f *Fake) WithClient(clientID string) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.clientID = clientID
+	return f
+}
+
+// SetScore sets a member's score, adding the member if needed
+func (f *Fake) SetScore(namespacedUserID string, score float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.scores[namespacedUserID] = score
+}
+
+// SetAttributes sets the attributes a member is matched on by filtered reads
+func (f *Fake) SetAttributes(namespacedUserID string, attributes map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.attributes[namespacedUserID] = attributes
+}
+
+// PinRank forces a member to the given 1-based rank in every read, with the
+// other members shifting around it. The member must have a score.
+func (f *Fake) PinRank(namespacedUserID string, rank int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.pinned[namespacedUserID] = rank
+}
+
+// UnpinRank returns a member to its score-based rank
+func (f *Fake) UnpinRank(namespacedUserID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.pinned, namespacedUserID)
+}
+
+// FailWith makes the named method (e.g. "UpdateScore") return err until it
+// is cleared with a nil error
+func (f *Fake) FailWith(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.errs, method)
+		return
+	}
+	f.errs[method] = err
+}
+
+// Reset removes every member, pinned rank and injected error
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.scores = make(map[string]float64)
+	f.attributes = make(map[string]map[string]string)
+	f.pinned = make(map[string]int64)
+	f.errs = make(map[string]error)
+}
+
+// UpdateScore adds scoreDelta to the member's score and returns the new
+// score and rank
+func (f *Fake) UpdateScore(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta float64,
+) (*customTypes.MemberScore, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["UpdateScore"]; err != nil {
+		return nil, err
+	}
+
+	return f.updateScore(namespacedUserID, scoreDelta)
+}
+
+// UpdateScoreInt adds a whole-number delta to the member's score
+func (f *Fake) UpdateScoreInt(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta int64,
+) (*customTypes.MemberScore, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["UpdateScoreInt"]; err != nil {
+		return nil, err
+	}
+
+	return f.updateScore(namespacedUserID, float64(scoreDelta))
+}
+
+// UpdateScoreAt adds scoreDelta to the member's score. The fake has no
+// active window, so every event time is accepted.
+func (f *Fake) UpdateScoreAt(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta float64,
+	eventTime time.Time,
+) (*customTypes.MemberScore, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["UpdateScoreAt"]; err != nil {
+		return nil, err
+	}
+
+	return f.updateScore(namespacedUserID, scoreDelta)
+}
+
+// UpdateScores adds each delta to the score of one of the client's users,
+// keyed by userID, and returns their standings keyed the same way. Every
+// user is validated before any score changes.
+func (f *Fake) UpdateScores(
+	ctx context.Context,
+	deltas map[string]float64,
+) (map[string]customTypes.MemberScore, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["UpdateScores"]; err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]string, len(deltas))
+	for userID, scoreDelta := range deltas {
+		member, err := f.memberID(userID)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(scoreDelta) || math.IsInf(scoreDelta, 0) {
+			return nil, leaderboard.ErrNonFiniteScore
+		}
+		members[userID] = member
+	}
+
+	for userID, scoreDelta := range deltas {
+		f.scores[members[userID]] += scoreDelta
+	}
+	results := make(map[string]customTypes.MemberScore, len(deltas))
+	for userID, member := range members {
+		entry, err := f.standing(member)
+		if err != nil {
+			return nil, err
+		}
+		results[userID] = *entry
+	}
+
+	return results, nil
+}
+
+// JoinLeaderboard adds one of the client's users with an initial score, or
+// fails with ErrAlreadyJoined if the user has a score
+func (f *Fake) JoinLeaderboard(
+	ctx context.Context,
+	userID string,
+	initialScore float64,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["JoinLeaderboard"]; err != nil {
+		return err
+	}
+
+	member, err := f.memberID(userID)
+	if err != nil {
+		return err
+	}
+	if _, ok := f.scores[member]; ok {
+		return leaderboard.ErrAlreadyJoined
+	}
+	f.scores[member] = initialScore
+
+	return nil
+}
+
+// JoinLeaderboardBatch adds a party as a whole: if any participant already
+// has a score, none joins and the others fail with ErrBatchCancelled
+func (f *Fake) JoinLeaderboardBatch(
+	ctx context.Context,
+	participants []*leaderboard.ParticipantModel,
+) ([]error, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["JoinLeaderboardBatch"]; err != nil {
+		return nil, err
+	}
+
+	members := make([]string, len(participants))
+	for i, participant := range participants {
+		if participant == nil {
+			return nil, fmt.Errorf("participant %d is nil", i)
+		}
+		clientID := f.clientID
+		if clientID == "" {
+			clientID = participant.ClientID
+		}
+		if participant.ClientID != "" && participant.ClientID != clientID {
+			return nil, &leaderboard.ClientMismatchError{
+				Expected: clientID,
+				Actual:   participant.ClientID,
+			}
+		}
+		if clientID == "" {
+			return nil, leaderboard.ErrClientRequired
+		}
+		member, err := f.namespacer.Join(clientID, participant.UserID)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = member
+	}
+
+	errs := make([]error, len(participants))
+	joined := true
+	for i, member := range members {
+		if _, ok := f.scores[member]; ok {
+			errs[i] = leaderboard.ErrAlreadyJoined
+			joined = false
+		}
+	}
+	if !joined {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = leaderboard.ErrBatchCancelled
+			}
+		}
+		return errs, nil
+	}
+
+	for i, member := range members {
+		f.scores[member] = participants[i].Score
+		if participants[i].Attributes != nil {
+			f.attributes[member] = participants[i].Attributes
+		}
+	}
+
+	return errs, nil
+}
+
+// memberID returns the member of one of the client's users. The caller
+// must hold f.mu.
+func (f *Fake) memberID(userID string) (string, error) {
+	if f.clientID == "" {
+		return "", leaderboard.ErrClientRequired
+	}
+
+	return f.namespacer.Join(f.clientID, userID)
+}
+
+// updateScore applies a delta and returns the member's standing. The caller
+// must hold f.mu.
+func (f *Fake) updateScore(
+	namespacedUserID string,
+	scoreDelta float64,
+) (*customTypes.MemberScore, error) {
+	if _, _, err := f.namespacer.Split(namespacedUserID); err != nil {
+		return nil, err
+	}
+	if math.IsNaN(scoreDelta) || math.IsInf(scoreDelta, 0) {
+		return nil, leaderboard.ErrNonFiniteScore
+	}
+
+	f.scores[namespacedUserID] += scoreDelta
+	return f.standing(namespacedUserID)
+}
+
+// GetTopNParticipants returns the first n members of the standings
+func (f *Fake) GetTopNParticipants(
+	ctx context.Context,
+	n int64,
+) ([]customTypes.MemberScore, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["GetTopNParticipants"]; err != nil {
+		return nil, err
+	}
+
+	return topN(f.standings(nil), n), nil
+}
+
+// GetParticipantScoreAndRank returns a member's score and rank
+func (f *Fake) GetParticipantScoreAndRank(
+	ctx context.Context,
+	namespacedUserID string,
+) (*customTypes.MemberScore, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["GetParticipantScoreAndRank"]; err != nil {
+		return nil, err
+	}
+	if _, _, err := f.namespacer.Split(namespacedUserID); err != nil {
+		return nil, err
+	}
+
+	return f.standing(namespacedUserID)
+}
+
+// standing returns a member's score and rank. The caller must hold f.mu.
+func (f *Fake) standing(namespacedUserID string) (*customTypes.MemberScore, error) {
+	for _, entry := range f.standings(nil) {
+		if entry.Member == namespacedUserID {
+			return &entry, nil
+		}
+	}
+
+	return nil, leaderboard.ErrParticipantNotFound
+}
+
+// GetPage returns up to limit members after cursor, starting from the top
+// when cursor is empty. The fake's cursors are offsets; anything else fails
+// with ErrInvalidCursor.
+func (f *Fake) GetPage(
+	ctx context.Context,
+	cursor string,
+	limit int64,
+) (*leaderboard.Page, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["GetPage"]; err != nil {
+		return nil, err
+	}
+
+	var offset int64
+	if cursor != "" {
+		var err error
+		offset, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || offset < 0 {
+			return nil, leaderboard.ErrInvalidCursor
+		}
+	}
+
+	standings := f.standings(nil)
+	if offset > int64(len(standings)) {
+		offset = int64(len(standings))
+	}
+	page := &leaderboard.Page{Entries: topN(standings[offset:], limit)}
+	if next := offset + int64(len(page.Entries)); next < int64(len(standings)) && len(page.Entries) > 0 {
+		page.NextCursor = strconv.FormatInt(next, 10)
+	}
+
+	return page, nil
+}
+
+// GetParticipant returns one of the client's users with their score and
+// attributes
+func (f *Fake) GetParticipant(
+	ctx context.Context,
+	userID string,
+) (*leaderboard.ParticipantModel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["GetParticipant"]; err != nil {
+		return nil, err
+	}
+
+	member, err := f.memberID(userID)
+	if err != nil {
+		return nil, err
+	}
+	score, ok := f.scores[member]
+	if !ok {
+		return nil, leaderboard.ErrParticipantNotFound
+	}
+
+	return &leaderboard.ParticipantModel{
+		NamespacedUserID: member,
+		ClientID:         f.clientID,
+		UserID:           userID,
+		Score:            score,
+		Attributes:       f.attributes[member],
+	}, nil
+}
+
+// GetTopNFilteredParticipants returns the first n members whose attributes
+// match the filter, ranked among themselves
+func (f *Fake) GetTopNFilteredParticipants(
+	ctx context.Context,
+	n int64,
+	filter leaderboard.Filter,
+) ([]customTypes.MemberScore, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["GetTopNFilteredParticipants"]; err != nil {
+		return nil, err
+	}
+
+	return topN(f.standings(filter), n), nil
+}
+
+// GetLeaderboardStats summarises the scores with a 10-bucket histogram
+func (f *Fake) GetLeaderboardStats(ctx context.Context) (*customTypes.LeaderboardStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.errs["GetLeaderboardStats"]; err != nil {
+		return nil, err
+	}
+
+	scores := make([]float64, 0, len(f.scores))
+	for _, score := range f.scores {
+		scores = append(scores, score)
+	}
+
+	return computeStats(scores, 10), nil
+}
+
+// standings returns the members matching filter in rank order, with pinned
+// members moved to their pinned ranks. The caller must hold f.mu.
+func (f *Fake) standings(filter leaderboard.Filter) []customTypes.MemberScore {
+	var ranked, pinned []customTypes.MemberScore
+	for member, score := range f.scores {
+		if !matches(f.attributes[member], filter) {
+			continue
+		}
+		entry := customTypes.MemberScore{Member: member, Score: score}
+		if _, ok := f.pinned[member]; ok {
+			pinned = append(pinned, entry)
+			continue
+		}
+		ranked = append(ranked, entry)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Member > ranked[j].Member
+	})
+
+	// Insert pinned members in ascending rank order, so each lands at its
+	// pinned position
+	sort.Slice(pinned, func(i, j int) bool {
+		ri, rj := f.pinned[pinned[i].Member], f.pinned[pinned[j].Member]
+		if ri != rj {
+			return ri < rj
+		}
+		return pinned[i].Member < pinned[j].Member
+	})
+	for _, entry := range pinned {
+		index := int(f.pinned[entry.Member]) - 1
+		if index < 0 {
+			index = 0
+		}
+		if index > len(ranked) {
+			index = len(ranked)
+		}
+		ranked = append(ranked, customTypes.MemberScore{})
+		copy(ranked[index+1:], ranked[index:])
+		ranked[index] = entry
+	}
+
+	for i := range ranked {
+		ranked[i].Rank = int64(i + 1)
+	}
+
+	return ranked
+}
+
+// matches reports whether attributes satisfy every entry of the filter
+func matches(attributes map[string]string, filter leaderboard.Filter) bool {
+	for name, value := range filter {
+		if attributes[name] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// topN truncates standings to n entries
+func topN(standings []customTypes.MemberScore, n int64) []customTypes.MemberScore {
+	if n < 0 {
+		n = 0
+	}
+	if int64(len(standings)) > n {
+		standings = standings[:n]
+	}
+
+	return standings
+}
+
+// computeStats summarises scores the way the Redis backed leaderboard does
+func computeStats(scores []float64, buckets int) *customTypes.LeaderboardStats {
+	stats := &customTypes.LeaderboardStats{Count: int64(len(scores))}
+	if len(scores) == 0 {
+		return stats
+	}
+
+	sort.Float64s(scores)
+	var total float64
+	for _, score := range scores {
+		total += score
+	}
+	count := len(scores)
+	stats.Min = scores[0]
+	stats.Max = scores[count-1]
+	stats.Mean = total / float64(count)
+	stats.Median = (scores[(count-1)/2] + scores[count/2]) / 2
+
+	if stats.Min == stats.Max {
+		buckets = 1
+	}
+	width := (stats.Max - stats.Min) / float64(buckets)
+	stats.Histogram = make([]customTypes.HistogramBucket, buckets)
+	for i := range stats.Histogram {
+		stats.Histogram[i].Min = stats.Min + float64(i)*width
+		stats.Histogram[i].Max = stats.Min + float64(i+1)*width
+	}
+	stats.Histogram[buckets-1].Max = stats.Max
+	for _, score := range scores {
+		index := buckets - 1
+		if width > 0 {
+			index = int((score - stats.Min) / width)
+			if index >= buckets {
+				index = buckets - 1
+			}
+		}
+		stats.Histogram[index].Count++
+	}
+
+	return stats
+}
@@ -0,0 +1,240 @@
+package mocks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// Call records one method invocation on a Mock
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Mock implements leaderboard.Leaderboard and leaderboard.LeaderboardWriter
+// by delegating each method to the matching Func field and records every
+// call. Calling a method whose Func is unset returns an error, so tests only
+// stub what they exercise.
+type Mock struct {
+	UpdateScoreFunc                 func(ctx context.Context, namespacedUserID string, scoreDelta float64) (*customTypes.MemberScore, error)
+	UpdateScoreIntFunc              func(ctx context.Context, namespacedUserID string, scoreDelta int64) (*customTypes.MemberScore, error)
+	GetTopNParticipantsFunc         func(ctx context.Context, n int64) ([]customTypes.MemberScore, error)
+	GetParticipantScoreAndRankFunc  func(ctx context.Context, namespacedUserID string) (*customTypes.MemberScore, error)
+	GetTopNFilteredParticipantsFunc func(ctx context.Context, n int64, filter leaderboard.Filter) ([]customTypes.MemberScore, error)
+	GetLeaderboardStatsFunc         func(ctx context.Context) (*customTypes.LeaderboardStats, error)
+	GetPageFunc                     func(ctx context.Context, cursor string, limit int64) (*leaderboard.Page, error)
+	GetParticipantFunc              func(ctx context.Context, userID string) (*leaderboard.ParticipantModel, error)
+	JoinLeaderboardFunc             func(ctx context.Context, userID string, initialScore float64) error
+	JoinLeaderboardBatchFunc        func(ctx context.Context, participants []*leaderboard.ParticipantModel) ([]error, error)
+	UpdateScoreAtFunc               func(ctx context.Context, namespacedUserID string, scoreDelta float64, eventTime time.Time) (*customTypes.MemberScore, error)
+	UpdateScoresFunc                func(ctx context.Context, deltas map[string]float64) (map[string]customTypes.MemberScore, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var (
+	_ leaderboard.Leaderboard       = (*Mock)(nil)
+	_ leaderboard.LeaderboardWriter = (*Mock)(nil)
+)
+
+// record appends a call
+func (m *Mock) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns every recorded call in order
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount returns how often the named method was called
+func (m *Mock) CallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, call := range m.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+
+	return count
+}
+
+// notStubbed is returned by methods without a Func
+func notStubbed(method string) error {
+	return fmt.Errorf("mocks: %s called but not stubbed", method)
+}
+
+// UpdateScore calls UpdateScoreFunc
+func (m *Mock) UpdateScore(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta float64,
+) (*customTypes.MemberScore, error) {
+	m.record("UpdateScore", namespacedUserID, scoreDelta)
+	if m.UpdateScoreFunc == nil {
+		return nil, notStubbed("UpdateScore")
+	}
+
+	return m.UpdateScoreFunc(ctx, namespacedUserID, scoreDelta)
+}
+
+// UpdateScoreInt calls UpdateScoreIntFunc
+func (m *Mock) UpdateScoreInt(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta int64,
+) (*customTypes.MemberScore, error) {
+	m.record("UpdateScoreInt", namespacedUserID, scoreDelta)
+	if m.UpdateScoreIntFunc == nil {
+		return nil, notStubbed("UpdateScoreInt")
+	}
+
+	return m.UpdateScoreIntFunc(ctx, namespacedUserID, scoreDelta)
+}
+
+// GetTopNParticipants calls GetTopNParticipantsFunc
+func (m *Mock) GetTopNParticipants(
+	ctx context.Context,
+	n int64,
+) ([]customTypes.MemberScore, error) {
+	m.record("GetTopNParticipants", n)
+	if m.GetTopNParticipantsFunc == nil {
+		return nil, notStubbed("GetTopNParticipants")
+	}
+
+	return m.GetTopNParticipantsFunc(ctx, n)
+}
+
+// GetParticipantScoreAndRank calls GetParticipantScoreAndRankFunc
+func (m *Mock) GetParticipantScoreAndRank(
+	ctx context.Context,
+	namespacedUserID string,
+) (*customTypes.MemberScore, error) {
+	m.record("GetParticipantScoreAndRank", namespacedUserID)
+	if m.GetParticipantScoreAndRankFunc == nil {
+		return nil, notStubbed("GetParticipantScoreAndRank")
+	}
+
+	return m.GetParticipantScoreAndRankFunc(ctx, namespacedUserID)
+}
+
+// GetTopNFilteredParticipants calls GetTopNFilteredParticipantsFunc
+func (m *Mock) GetTopNFilteredParticipants(
+	ctx context.Context,
+	n int64,
+	filter leaderboard.Filter,
+) ([]customTypes.MemberScore, error) {
+	m.record("GetTopNFilteredParticipants", n, filter)
+	if m.GetTopNFilteredParticipantsFunc == nil {
+		return nil, notStubbed("GetTopNFilteredParticipants")
+	}
+
+	return m.GetTopNFilteredParticipantsFunc(ctx, n, filter)
+}
+
+// GetLeaderboardStats calls GetLeaderboardStatsFunc
+func (m *Mock) GetLeaderboardStats(ctx context.Context) (*customTypes.LeaderboardStats, error) {
+	m.record("GetLeaderboardStats")
+	if m.GetLeaderboardStatsFunc == nil {
+		return nil, notStubbed("GetLeaderboardStats")
+	}
+
+	return m.GetLeaderboardStatsFunc(ctx)
+}
+
+// GetPage calls GetPageFunc
+func (m *Mock) GetPage(
+	ctx context.Context,
+	cursor string,
+	limit int64,
+) (*leaderboard.Page, error) {
+	m.record("GetPage", cursor, limit)
+	if m.GetPageFunc == nil {
+		return nil, notStubbed("GetPage")
+	}
+
+	return m.GetPageFunc(ctx, cursor, limit)
+}
+
+// GetParticipant calls GetParticipantFunc
+func (m *Mock) GetParticipant(
+	ctx context.Context,
+	userID string,
+) (*leaderboard.ParticipantModel, error) {
+	m.record("GetParticipant", userID)
+	if m.GetParticipantFunc == nil {
+		return nil, notStubbed("GetParticipant")
+	}
+
+	return m.GetParticipantFunc(ctx, userID)
+}
+
+// JoinLeaderboard calls JoinLeaderboardFunc
+func (m *Mock) JoinLeaderboard(
+	ctx context.Context,
+	userID string,
+	initialScore float64,
+) error {
+	m.record("JoinLeaderboard", userID, initialScore)
+	if m.JoinLeaderboardFunc == nil {
+		return notStubbed("JoinLeaderboard")
+	}
+
+	return m.JoinLeaderboardFunc(ctx, userID, initialScore)
+}
+
+// JoinLeaderboardBatch calls JoinLeaderboardBatchFunc
+func (m *Mock) JoinLeaderboardBatch(
+	ctx context.Context,
+	participants []*leaderboard.ParticipantModel,
+) ([]error, error) {
+	m.record("JoinLeaderboardBatch", participants)
+	if m.JoinLeaderboardBatchFunc == nil {
+		return nil, notStubbed("JoinLeaderboardBatch")
+	}
+
+	return m.JoinLeaderboardBatchFunc(ctx, participants)
+}
+
+// UpdateScoreAt calls UpdateScoreAtFunc
+func (m *Mock) UpdateScoreAt(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta float64,
+	eventTime time.Time,
+) (*customTypes.MemberScore, error) {
+	m.record("UpdateScoreAt", namespacedUserID, scoreDelta, eventTime)
+	if m.UpdateScoreAtFunc == nil {
+		return nil, notStubbed("UpdateScoreAt")
+	}
+
+	return m.UpdateScoreAtFunc(ctx, namespacedUserID, scoreDelta, eventTime)
+}
+
+// UpdateScores calls UpdateScoresFunc
+func (m *Mock) UpdateScores(
+	ctx context.Context,
+	deltas map[string]float64,
+) (map[string]customTypes.MemberScore, error) {
+	m.record("UpdateScores", deltas)
+	if m.UpdateScoresFunc == nil {
+		return nil, notStubbed("UpdateScores")
+	}
+
+	return m.UpdateScoresFunc(ctx, deltas)
+}
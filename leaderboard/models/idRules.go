@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// DefaultIDMaxLength is the longest clientID or userID, in bytes, accepted
+// under DefaultIDRules
+const DefaultIDMaxLength = 128
+
+// defaultIDChars are the characters besides ASCII letters and digits
+// accepted under DefaultIDRules
+const defaultIDChars = "-_.:@"
+
+// IDRules constrain the clientIDs and userIDs accepted by a RuleNamespacer
+type IDRules struct {
+	// MaxLength bounds each ID in bytes; zero allows any length
+	MaxLength int
+	// AllowedRune reports whether a character may appear in an ID; nil
+	// allows any character
+	AllowedRune func(r rune) bool
+}
+
+// DefaultIDRules accepts IDs of up to DefaultIDMaxLength bytes made of
+// ASCII letters, digits and "-_.:@"
+func DefaultIDRules() IDRules {
+	return IDRules{
+		MaxLength: DefaultIDMaxLength,
+		AllowedRune: func(r rune) bool {
+			return r < utf8.RuneSelf &&
+				('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' ||
+					strings.ContainsRune(defaultIDChars, r))
+		},
+	}
+}
+
+// Validate checks one ID against the rules. field names the ID in the
+// returned InvalidIDError.
+func (rules IDRules) Validate(field, id string) error {
+	invalid := func(reason string, args ...any) error {
+		return &customTypes.InvalidIDError{
+			Field:  field,
+			ID:     id,
+			Reason: fmt.Sprintf(reason, args...),
+		}
+	}
+
+	if id == "" {
+		return invalid("must not be empty")
+	}
+	if !utf8.ValidString(id) {
+		return invalid("must be valid UTF-8")
+	}
+	if rules.MaxLength > 0 && len(id) > rules.MaxLength {
+		return invalid("must be at most %d bytes long", rules.MaxLength)
+	}
+	if rules.AllowedRune != nil {
+		for _, r := range id {
+			if !rules.AllowedRune(r) {
+				return invalid("must not contain %q", r)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RuleNamespacer applies IDRules to the IDs joined by another namespacer,
+// and rejects pairs that would not split back into the same IDs, such as a
+// userID starting with part of the separator. Split is left to the wrapped
+// namespacer, so members stored before the rules stay readable.
+type RuleNamespacer struct {
+	Namespacer
+	Rules IDRules
+}
+
+// Join validates both IDs and combines them
+func (n RuleNamespacer) Join(clientID, userID string) (string, error) {
+	if err := n.Rules.Validate("clientID", clientID); err != nil {
+		return "", err
+	}
+	if err := n.Rules.Validate("userID", userID); err != nil {
+		return "", err
+	}
+
+	joined, err := n.Namespacer.Join(clientID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	// The member must split back into the same pair
+	splitClientID, splitUserID, err := n.Namespacer.Split(joined)
+	if err != nil || splitClientID != clientID || splitUserID != userID {
+		return "", &customTypes.InvalidIDError{
+			Field:  "userID",
+			ID:     userID,
+			Reason: fmt.Sprintf("cannot be told apart from clientID %q once joined", clientID),
+		}
+	}
+
+	return joined, nil
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// DefaultSeparator joins clientID and userID in the default namespace scheme
+const DefaultSeparator = "___"
+
+// Namespacer combines a clientID and userID into the member stored in the
+// leaderboard, and splits it back
+type Namespacer interface {
+	// Join returns the namespaced user ID for the pair
+	Join(clientID, userID string) (string, error)
+	// Split returns the clientID and userID encoded in a namespaced user ID
+	Split(namespacedUserID string) (clientID, userID string, err error)
+}
+
+// SeparatorNamespacer joins IDs with a fixed separator ("clientID___userID"
+// by default). IDs containing the separator are rejected, since they could
+// not be split back unambiguously.
+type SeparatorNamespacer struct {
+	Separator string
+}
+
+// NewSeparatorNamespacer creates a namespacer using the given separator,
+// or the default "___" when empty
+func NewSeparatorNamespacer(separator string) *SeparatorNamespacer {
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+
+	return &SeparatorNamespacer{Separator: separator}
+}
+
+// Join combines the IDs with the separator
+func (n *SeparatorNamespacer) Join(clientID, userID string) (string, error) {
+	if clientID == "" || userID == "" {
+		return "", fmt.Errorf("%w: clientID and userID are required", customTypes.ErrInvalidUserID)
+	}
+	if strings.Contains(clientID, n.Separator) || strings.Contains(userID, n.Separator) {
+		return "", fmt.Errorf(
+			"%w: IDs must not contain the separator %q",
+			customTypes.ErrInvalidUserID,
+			n.Separator,
+		)
+	}
+
+	return clientID + n.Separator + userID, nil
+}
+
+// Split separates the IDs, requiring exactly one separator
+func (n *SeparatorNamespacer) Split(namespacedUserID string) (string, string, error) {
+	parts := strings.Split(namespacedUserID, n.Separator)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", customTypes.ErrInvalidUserID
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// URLSafeNamespacer base64url-encodes both IDs and joins them with a dot,
+// so any characters are allowed in either ID
+type URLSafeNamespacer struct{}
+
+// Join encodes and combines the IDs
+func (URLSafeNamespacer) Join(clientID, userID string) (string, error) {
+	if clientID == "" || userID == "" {
+		return "", fmt.Errorf("%w: clientID and userID are required", customTypes.ErrInvalidUserID)
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(clientID)) +
+		"." +
+		base64.RawURLEncoding.EncodeToString([]byte(userID)), nil
+}
+
+// Split separates and decodes the IDs
+func (URLSafeNamespacer) Split(namespacedUserID string) (string, string, error) {
+	parts := strings.Split(namespacedUserID, ".")
+	if len(parts) != 2 {
+		return "", "", customTypes.ErrInvalidUserID
+	}
+
+	clientID, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(clientID) == 0 {
+		return "", "", customTypes.ErrInvalidUserID
+	}
+	userID, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(userID) == 0 {
+		return "", "", customTypes.ErrInvalidUserID
+	}
+
+	return string(clientID), string(userID), nil
+}
+
+// StructuredNamespacer prefixes the clientID with its length
+// ("<len>:<clientID><userID>"), keeping both IDs readable while allowing
+// any characters in them
+type StructuredNamespacer struct{}
+
+// Join combines the IDs behind a length prefix
+func (StructuredNamespacer) Join(clientID, userID string) (string, error) {
+	if clientID == "" || userID == "" {
+		return "", fmt.Errorf("%w: clientID and userID are required", customTypes.ErrInvalidUserID)
+	}
+
+	return strconv.Itoa(len(clientID)) + ":" + clientID + userID, nil
+}
+
+// Split reads the length prefix and separates the IDs
+func (StructuredNamespacer) Split(namespacedUserID string) (string, string, error) {
+	prefix, rest, found := strings.Cut(namespacedUserID, ":")
+	if !found {
+		return "", "", customTypes.ErrInvalidUserID
+	}
+
+	clientLen, err := strconv.Atoi(prefix)
+	if err != nil || clientLen <= 0 || clientLen >= len(rest) {
+		return "", "", customTypes.ErrInvalidUserID
+	}
+
+	return rest[:clientLen], rest[clientLen:], nil
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// OutboxEventModel is a leaderboard event written in the same transaction
+// as the score update it describes, awaiting dispatch
+type OutboxEventModel struct {
+	LeaderboardID    string    `json:"leaderboardID" dynamodbav:"leaderboardID"`
+	EventID          string    `json:"eventID" dynamodbav:"eventID"`
+	Type             string    `json:"type" dynamodbav:"type"`
+	NamespacedUserID string    `json:"namespacedUserID" dynamodbav:"namespacedUserID"`
+	ScoreDelta       float64   `json:"scoreDelta" dynamodbav:"scoreDelta"`
+	CreatedAt        time.Time `json:"createdAt" dynamodbav:"createdAt"`
+	RequestID        string    `json:"requestID,omitempty" dynamodbav:"requestID,omitempty"`
+}
@@ -0,0 +1,117 @@
+// Package models holds the participant record stored in DynamoDB and the
+// namespacing and score conversion rules applied to it.
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// Participant represents a user's score in a leaderboard.
+type ParticipantModel struct {
+	LeaderboardID    string    `json:"leaderboardID" dynamodbav:"leaderboardID"`
+	NamespacedUserID string    `json:"namespacedUserID" dynamodbav:"namespacedUserID"`
+	ClientID         string    `json:"clientID" dynamodbav:"clientID"`
+	UserID           string    `json:"userID" dynamodbav:"userID"`
+	Score            float64   `json:"score" dynamodbav:"score"`
+	UpdatedAt        time.Time `json:"updatedAt" dynamodbav:"updatedAt"`
+	// Attributes are the filterable attributes stored with the participant
+	Attributes map[string]string `json:"attributes,omitempty" dynamodbav:"filterAttrs,omitempty"`
+	// Ghost marks system-controlled participants, which are excluded from
+	// rewards
+	Ghost bool `json:"ghost,omitempty" dynamodbav:"ghost,omitempty"`
+	// Metadata holds display attributes such as the name and avatar, set
+	// with UpdateParticipantMetadata
+	Metadata map[string]any `json:"metadata,omitempty" dynamodbav:"metadata,omitempty"`
+	// LastRequestID is the request ID of the last score write made with
+	// one, for tracing
+	LastRequestID string `json:"lastRequestID,omitempty" dynamodbav:"last_request_id,omitempty"`
+	// Stats holds the participant's named stat totals. They are written
+	// with UpdateStats only, as top-level stat_<name> attributes.
+	Stats map[string]float64 `json:"stats,omitempty" dynamodbav:"-"`
+	// FrozenAt is when the participant's score was frozen with
+	// FreezeParticipant, zero if it is not frozen. It is only read back, as
+	// written items must never carry the freeze.
+	FrozenAt time.Time `json:"frozenAt,omitempty" dynamodbav:"-"`
+	// FrozenReason is the reason given when the score was frozen
+	FrozenReason string `json:"frozenReason,omitempty" dynamodbav:"-"`
+	// Milestones are the configured score thresholds the participant has
+	// reached, by threshold. They are written as top-level milestone:<n>
+	// attributes.
+	Milestones []customTypes.Milestone `json:"milestones,omitempty" dynamodbav:"-"`
+	// SkillBracket is the skill bracket sub-board the participant was
+	// assigned to on join
+	SkillBracket string `json:"skillBracket,omitempty" dynamodbav:"skillBracket,omitempty"`
+	// EscrowHoldID is the escrow provider's ID for the entry fee held when
+	// the participant joined a paid leaderboard
+	EscrowHoldID string `json:"escrowHoldID,omitempty" dynamodbav:"escrowHoldID,omitempty"`
+	// EscrowState is where the held entry fee stands
+	EscrowState customTypes.EscrowState `json:"escrowState,omitempty" dynamodbav:"escrowState,omitempty"`
+}
+
+// NewParticipant creates a new participant with the given parameters
+func NewParticipantModel(leaderboardID, clientID, userID string, score float64) *ParticipantModel {
+	namespacedUserID := CreateNamespacedUserID(clientID, userID)
+	return &ParticipantModel{
+		LeaderboardID:    leaderboardID,
+		NamespacedUserID: namespacedUserID,
+		ClientID:         clientID,
+		UserID:           userID,
+		Score:            score,
+		UpdatedAt:        utils.GetCurrTimeStamp(),
+	}
+}
+
+// NewParticipantFromNamespacedID creates a new participant from a namespaced user ID
+func NewParticipantFromNamespacedID(leaderboardID, namespacedUserID string, score float64) *ParticipantModel {
+	clientID, userID := SplitNamespacedUserID(namespacedUserID)
+	return &ParticipantModel{
+		LeaderboardID:    leaderboardID,
+		NamespacedUserID: namespacedUserID,
+		ClientID:         clientID,
+		UserID:           userID,
+		Score:            score,
+		UpdatedAt:        utils.GetCurrTimeStamp(),
+	}
+}
+
+// NewNamespacedParticipantModel creates a new participant whose namespaced
+// user ID is produced by the given namespacer
+func NewNamespacedParticipantModel(
+	namespacer Namespacer,
+	leaderboardID, clientID, userID string,
+	score float64,
+) (*ParticipantModel, error) {
+	namespacedUserID, err := namespacer.Join(clientID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParticipantModel{
+		LeaderboardID:    leaderboardID,
+		NamespacedUserID: namespacedUserID,
+		ClientID:         clientID,
+		UserID:           userID,
+		Score:            score,
+		UpdatedAt:        utils.GetCurrTimeStamp(),
+	}, nil
+}
+
+// CreateNamespacedUserID combines clientID and userID into the expected format
+func CreateNamespacedUserID(clientID, userID string) string {
+	return clientID + DefaultSeparator + userID
+}
+
+// SplitNamespacedUserID splits a combined user ID into clientID and userID
+// The format is expected to be "clientID___userID"
+func SplitNamespacedUserID(namespacedUserID string) (clientID, userID string) {
+	parts := strings.Split(namespacedUserID, DefaultSeparator)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+
+	return "", ""
+}
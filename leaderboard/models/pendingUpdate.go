@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PendingUpdateModel is a quarantined score update awaiting review
+type PendingUpdateModel struct {
+	LeaderboardID    string  `json:"leaderboardID" dynamodbav:"leaderboardID"`
+	UpdateID         string  `json:"updateID" dynamodbav:"updateID"`
+	NamespacedUserID string  `json:"namespacedUserID" dynamodbav:"namespacedUserID"`
+	ScoreDelta       float64 `json:"scoreDelta" dynamodbav:"scoreDelta"`
+	// Attributes are the filterable attributes resolved for the update
+	Attributes    map[string]string `json:"attributes,omitempty" dynamodbav:"filterAttrs,omitempty"`
+	QuarantinedAt time.Time         `json:"quarantinedAt" dynamodbav:"quarantinedAt"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// RankHistoryPointModel is a participant's rank and score, in stored units,
+// at one point in time
+type RankHistoryPointModel struct {
+	At    int64   `json:"at" dynamodbav:"at"`
+	Rank  int64   `json:"rank" dynamodbav:"rank"`
+	Score float64 `json:"score" dynamodbav:"score"`
+}
+
+// RankHistoryBucketModel holds a participant's rank history points for one
+// day, compacted into a single item
+type RankHistoryBucketModel struct {
+	HistoryID   string                  `json:"historyID" dynamodbav:"historyID"`
+	BucketStart int64                   `json:"bucketStart" dynamodbav:"bucketStart"`
+	Points      []RankHistoryPointModel `json:"points" dynamodbav:"points"`
+}
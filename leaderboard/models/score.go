@@ -0,0 +1,68 @@
+package models
+
+import (
+	"math"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// MaxExactScore is the largest magnitude below which float64 represents
+// every integer exactly, which is what Redis sorted set scores are
+const MaxExactScore = 1 << 53
+
+// ScoreLimit returns the largest score magnitude that can be stored exactly
+// with the given number of decimal places
+func ScoreLimit(precision int) float64 {
+	return MaxExactScore / math.Pow10(precision)
+}
+
+// ValidateScore rejects NaN, infinite and out-of-range scores
+func ValidateScore(score float64, limit float64) error {
+	if math.IsNaN(score) || math.IsInf(score, 0) {
+		return customTypes.ErrNonFiniteScore
+	}
+	if limit > 0 && math.Abs(score) > limit {
+		return &customTypes.ScoreOutOfRangeError{
+			Score: score,
+			Limit: limit,
+		}
+	}
+
+	return nil
+}
+
+// ScaleScore converts a score into integer units with the given number of
+// decimal places, rounding half away from zero
+func ScaleScore(score float64, precision int) (int64, error) {
+	if err := ValidateScore(score, ScoreLimit(precision)); err != nil {
+		return 0, err
+	}
+
+	return int64(math.Round(score * math.Pow10(precision))), nil
+}
+
+// UnscaleScore converts integer units back into a score
+func UnscaleScore(units float64, precision int) float64 {
+	return units / math.Pow10(precision)
+}
+
+// RoundScore rounds a score to the given number of decimal places
+func RoundScore(score float64, precision int, mode customTypes.RoundingMode) float64 {
+	factor := math.Pow10(precision)
+	scaled := score * factor
+
+	switch mode {
+	case customTypes.RoundHalfEven:
+		scaled = math.RoundToEven(scaled)
+	case customTypes.RoundTowardZero:
+		scaled = math.Trunc(scaled)
+	case customTypes.RoundFloor:
+		scaled = math.Floor(scaled)
+	case customTypes.RoundCeil:
+		scaled = math.Ceil(scaled)
+	default:
+		scaled = math.Round(scaled)
+	}
+
+	return scaled / factor
+}
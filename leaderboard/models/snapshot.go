@@ -0,0 +1,8 @@
+package models
+
+// SnapshotEntryModel is one member of a standings snapshot, with the score
+// in stored units. Entries are kept in rank order, so ranks are not stored.
+type SnapshotEntryModel struct {
+	Member string  `json:"m"`
+	Score  float64 `json:"s"`
+}
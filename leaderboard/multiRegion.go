@@ -0,0 +1,213 @@
+package leaderboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+	"github.com/redis/go-redis/v9"
+)
+
+// MergeStrategy decides how concurrent score updates from different regions
+// are reconciled
+type MergeStrategy = customTypes.MergeStrategy
+
+const (
+	// MergeLastWriterWins keeps one item per participant and relies on
+	// DynamoDB Global Tables replication to pick the latest write
+	MergeLastWriterWins = customTypes.MergeLastWriterWins
+	// MergeAdditive keeps per-region delta items that are summed on read, so
+	// concurrent increments from different regions are never lost
+	MergeAdditive = customTypes.MergeAdditive
+)
+
+// defaultInvalidationChannel is the pub/sub channel used for cache updates
+const defaultInvalidationChannel = "leaderboard:cache-updates"
+
+// CacheUpdate describes a change that regional caches must apply
+type CacheUpdate struct {
+	Region string `json:"region"`
+	// ClientID is the client of the helper that made the write, used to find
+	// the client's storage when tenants are routed (see TenantRouter)
+	ClientID         string `json:"clientID,omitempty"`
+	LeaderboardID    string `json:"leaderboardID"`
+	NamespacedUserID string `json:"namespacedUserID,omitempty"`
+	// ScoreDelta is the increment applied to the cached sorted set, in the
+	// leaderboard's stored units
+	ScoreDelta float64 `json:"scoreDelta,omitempty"`
+	// Invalidate drops the whole cached leaderboard instead of applying a delta
+	Invalidate bool `json:"invalidate,omitempty"`
+	// Removed drops NamespacedUserID from the cached leaderboard instead of
+	// applying a delta
+	Removed bool `json:"removed,omitempty"`
+	// RequestID is the request ID of the write, set with WithRequestID
+	RequestID string `json:"requestID,omitempty"`
+}
+
+// InvalidationBus carries cache updates between regions
+type InvalidationBus interface {
+	// Publish sends an update to every subscribed region
+	Publish(ctx context.Context, update CacheUpdate) error
+	// Subscribe streams updates until ctx is done
+	Subscribe(ctx context.Context) (<-chan CacheUpdate, error)
+}
+
+// RedisInvalidationBus is an InvalidationBus backed by Redis pub/sub. The
+// client must point at a Redis deployment reachable from every region.
+type RedisInvalidationBus struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisInvalidationBus creates a new pub/sub backed invalidation bus
+func NewRedisInvalidationBus(client *redis.Client) *RedisInvalidationBus {
+	return &RedisInvalidationBus{
+		client:  client,
+		channel: defaultInvalidationChannel,
+	}
+}
+
+// Publish sends the update on the pub/sub channel
+func (b *RedisInvalidationBus) Publish(
+	ctx context.Context,
+	update CacheUpdate,
+) error {
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache update: %w", err)
+	}
+
+	if err := b.client.Publish(ctx, b.channel, payload).Err(); err != nil {
+		return fmt.Errorf(
+			"failed to publish cache update: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// Subscribe streams decoded updates from the pub/sub channel until ctx is
+// done
+func (b *RedisInvalidationBus) Subscribe(
+	ctx context.Context,
+) (<-chan CacheUpdate, error) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+
+	// Wait for the subscription to be confirmed before returning
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf(
+			"failed to subscribe to cache updates: %w",
+			err,
+		)
+	}
+
+	updates := make(chan CacheUpdate)
+	go func() {
+		defer close(updates)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var update CacheUpdate
+				if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+					// Log the error but keep consuming
+					fmt.Printf("Error decoding cache update: %v\n", err)
+					continue
+				}
+
+				select {
+				case updates <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// ListenForCacheUpdates applies updates published by other regions to the
+// local Redis cache until ctx is done. Updates originating in localRegion are
+// skipped since they were already applied on the write path. opts must match
+// the options of the publishing helpers, so the same Redis keys are updated;
+// use TenantRouter.ListenForCacheUpdates when storage is routed per client.
+func ListenForCacheUpdates(
+	ctx context.Context,
+	bus InvalidationBus,
+	redisClient *redis.Client,
+	localRegion string,
+	opts ...Option,
+) error {
+	repo := repos.NewParticipantRepo(nil, redisClient, applyOptions(opts).repoConfig)
+
+	return applyCacheUpdates(
+		ctx,
+		bus,
+		localRegion,
+		func(context.Context, string) (*repos.ParticipantRepo, error) {
+			return repo, nil
+		},
+	)
+}
+
+// applyCacheUpdates applies remote updates to the repo repoFor returns for
+// each update's client until ctx is done
+func applyCacheUpdates(
+	ctx context.Context,
+	bus InvalidationBus,
+	localRegion string,
+	repoFor func(ctx context.Context, clientID string) (*repos.ParticipantRepo, error),
+) error {
+	updates, err := bus.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+
+	for update := range updates {
+		if update.Region == localRegion {
+			continue
+		}
+
+		repo, err := repoFor(ctx, update.ClientID)
+		if err != nil {
+			// Nothing can be applied without the client's storage; its
+			// caches catch up on rebuild
+			fmt.Printf("Error applying cache update: %v\n", err)
+			continue
+		}
+
+		switch {
+		case update.Invalidate:
+			err = repo.InvalidateCache(ctx, update.LeaderboardID)
+		case update.Removed:
+			err = repo.RemoveRemoteMember(ctx, update.LeaderboardID, update.NamespacedUserID)
+		default:
+			err = repo.ApplyRemoteScoreDelta(
+				ctx,
+				update.LeaderboardID,
+				update.NamespacedUserID,
+				update.ScoreDelta,
+			)
+		}
+		if err != nil {
+			// Fall back to dropping the board so it is rebuilt from DynamoDB
+			fmt.Printf("Error applying cache update: %v\n", err)
+			_ = repo.InvalidateCache(ctx, update.LeaderboardID)
+		}
+	}
+
+	return ctx.Err()
+}
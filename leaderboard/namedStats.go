@@ -0,0 +1,85 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// UpdateStats adds each delta to the participant's named stat, e.g.
+// {"kills": 3, "coins": 120}, and returns the participant's new total and
+// rank for each stat. Stats must be configured with WithStats; unknown ones
+// fail with ErrUnknownStat before anything is written. The participant's
+// score is left unchanged. Ranks are zero while the cached leaderboard is
+// being rebuilt.
+func (l *IndividualLeaderboardHelper) UpdateStats(
+	ctx context.Context,
+	namespacedUserID string,
+	deltas map[string]float64,
+) (map[string]customTypes.MemberScore, error) {
+	// Scheduled leaderboards only take joins until they open
+	if l.stateAt(utils.GetCurrTimeStamp()) == LeaderboardScheduled {
+		return nil, ErrLeaderboardNotStarted
+	}
+
+	if _, _, err := l.validateNamespacedUserID(namespacedUserID); err != nil {
+		return nil, err
+	}
+
+	results, err := l.repo.UpdateStats(ctx, l.storageID, namespacedUserID, deltas, l.endTime())
+	if err != nil {
+		return nil, err
+	}
+
+	l.recordWrites(ctx, 1)
+	l.publishChanges(ctx, namespacedUserID)
+	return results, nil
+}
+
+// GetTopNByStat retrieves the top N participants ranked by a named stat
+// configured with WithStats
+func (l *IndividualLeaderboardHelper) GetTopNByStat(
+	ctx context.Context,
+	stat string,
+	n int64,
+) ([]customTypes.MemberScore, error) {
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	participants, err := l.repo.GetTopNByStat(ctx, l.storageID, stat, n, l.endTime())
+	if err != nil {
+		return nil, err
+	}
+
+	l.markGhosts(participants)
+	l.transformResults(ctx, participants)
+	return participants, nil
+}
+
+// GetParticipantRankByStat retrieves a participant's total and rank for a
+// named stat configured with WithStats. It returns ErrParticipantNotFound
+// for participants that never recorded the stat.
+func (l *IndividualLeaderboardHelper) GetParticipantRankByStat(
+	ctx context.Context,
+	stat string,
+	namespacedUserID string,
+) (*customTypes.MemberScore, error) {
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	clientID, _, err := l.validateNamespacedUserID(namespacedUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := l.repo.GetParticipantRankByStat(ctx, l.storageID, stat, namespacedUserID, l.endTime())
+	if err != nil {
+		return nil, err
+	}
+
+	participant.Ghost = clientID == GhostClientID
+	return l.transformResult(ctx, participant), nil
+}
@@ -0,0 +1,33 @@
+package leaderboard
+
+import (
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// Namespacer combines a clientID and userID into the member stored in the
+// leaderboard, and splits it back
+type Namespacer = models.Namespacer
+
+// DefaultNamespacer returns the "clientID___userID" scheme. IDs containing
+// the separator are rejected with ErrInvalidUserID.
+func DefaultNamespacer() Namespacer {
+	return models.NewSeparatorNamespacer(models.DefaultSeparator)
+}
+
+// NewSeparatorNamespacer returns a scheme joining IDs with a custom
+// separator. IDs containing the separator are rejected with ErrInvalidUserID.
+func NewSeparatorNamespacer(separator string) Namespacer {
+	return models.NewSeparatorNamespacer(separator)
+}
+
+// NewURLSafeNamespacer returns a scheme that base64url-encodes both IDs, so
+// any characters are allowed in them
+func NewURLSafeNamespacer() Namespacer {
+	return models.URLSafeNamespacer{}
+}
+
+// NewStructuredNamespacer returns a readable length-prefixed scheme
+// ("<len>:<clientID><userID>") that allows any characters in either ID
+func NewStructuredNamespacer() Namespacer {
+	return models.StructuredNamespacer{}
+}
@@ -0,0 +1,739 @@
+package leaderboard
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// Option configures an IndividualLeaderboardHelper
+type Option func(*helperOptions)
+
+// helperOptions collects the settings applied by Option values
+type helperOptions struct {
+	repoConfig           repos.Config
+	invalidationBus      InvalidationBus
+	namespacer           Namespacer
+	beforeUpdateHooks    []BeforeUpdateHook
+	afterUpdateHooks     []AfterUpdateHook
+	metadataResolver     MetadataResolver
+	anomalyDetector      AnomalyDetector
+	quarantine           bool
+	outbox               bool
+	scalingHints         bool
+	changeNotifications  bool
+	scalingThresholds    ScalingThresholds
+	scalingHintFunc      ScalingHintFunc
+	startTime            time.Time
+	scheduledStart       bool
+	freezeStart          time.Time
+	freezeEnd            time.Time
+	tenantIsolation      bool
+	lifetimeLeaderboard  bool
+	rankCacheTTL         time.Duration
+	rankCacheMaxEntries  int
+	staleRankWindow      time.Duration
+	staleRankMaxInFlight int
+	idRules              *IDRules
+	visibility           Visibility
+	shadowMode           bool
+	payoutTable          PayoutTable
+	clientViews          bool
+	escrow               EscrowProvider
+	entryFee             float64
+	resultTransformers   []ResultTransformer
+	skillBrackets        []SkillBracket
+	accessPolicy         AccessPolicy
+}
+
+// defaultHelperOptions returns the settings used when no options are given
+func defaultHelperOptions() *helperOptions {
+	return &helperOptions{
+		repoConfig:        repos.DefaultConfig(),
+		namespacer:        DefaultNamespacer(),
+		scalingThresholds: DefaultScalingThresholds(),
+	}
+}
+
+// applyOptions returns the default settings with opts applied
+func applyOptions(opts []Option) *helperOptions {
+	options := defaultHelperOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Index clients alongside any attributes set by WithFilterAttributes
+	if options.clientViews && !slices.Contains(options.repoConfig.FilterAttributes, ClientFilterAttribute) {
+		options.repoConfig.FilterAttributes = append(
+			append([]string(nil), options.repoConfig.FilterAttributes...),
+			ClientFilterAttribute,
+		)
+	}
+
+	// Wrap whichever namespacer was chosen, regardless of option order
+	if options.idRules != nil {
+		options.namespacer = models.RuleNamespacer{
+			Namespacer: options.namespacer,
+			Rules:      *options.idRules,
+		}
+	}
+
+	return options
+}
+
+// WithRegion tags every write with the region this instance runs in
+func WithRegion(region string) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.Region = region
+	}
+}
+
+// WithTableName sets the DynamoDB table holding participant items
+func WithTableName(tableName string) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.TableName = tableName
+	}
+}
+
+// WithKeySchema keys participant items with custom attribute names and value
+// templates, for tables following a single-table design
+func WithKeySchema(schema KeySchema) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.KeySchema = schema
+	}
+}
+
+// WithSingleTableDesign stores participants in a table shared with other
+// entities, keyed by SingleTableKeySchema. updatedAtIndex names the GSI keyed
+// on PK and updated_at; when set, cache refreshes read through it.
+func WithSingleTableDesign(tableName string, updatedAtIndex string) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.TableName = tableName
+		o.repoConfig.KeySchema = SingleTableKeySchema()
+		o.repoConfig.UpdatedAtIndexName = updatedAtIndex
+	}
+}
+
+// WithTimeouts bounds every DynamoDB operation and every Redis command or
+// pipeline made by the helper, failing them with ErrStoreTimeout instead of
+// waiting on a hung store. Zero leaves that store unbounded.
+func WithTimeouts(dynamo time.Duration, redis time.Duration) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.DynamoTimeout = dynamo
+		o.repoConfig.RedisTimeout = redis
+	}
+}
+
+// WithMergeStrategy sets how writes from different regions are reconciled.
+// regions must list every region writing to the leaderboard when using
+// MergeAdditive.
+func WithMergeStrategy(strategy MergeStrategy, regions ...string) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.MergeStrategy = strategy
+		o.repoConfig.Regions = regions
+	}
+}
+
+// WithInvalidationBus publishes every local score update on the bus so that
+// caches in other regions stay current
+func WithInvalidationBus(bus InvalidationBus) Option {
+	return func(o *helperOptions) {
+		o.invalidationBus = bus
+	}
+}
+
+// WithParticipantTTL expires participants ttl after their last write. Each
+// write sets the DynamoDB TTL attribute (expiresAt by default) and records
+// the expiry in Redis for SweepExpiredParticipants.
+func WithParticipantTTL(ttl time.Duration) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.ParticipantTTL = ttl
+	}
+}
+
+// WithRetentionPeriod expires participant and pending update items
+// retention after the leaderboard's end time, so DynamoDB deletes old
+// leaderboards on its own. The TTL attribute is set on every write; with
+// WithParticipantTTL too, whichever expiry comes first applies.
+func WithRetentionPeriod(retention time.Duration) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.RetentionPeriod = retention
+	}
+}
+
+// WithTTLAttributeName overrides the DynamoDB TTL attribute name used by
+// WithParticipantTTL and WithRetentionPeriod
+func WithTTLAttributeName(name string) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.TTLAttributeName = name
+	}
+}
+
+// WithScorePrecision stores scores as integers scaled by 10^decimals in both
+// DynamoDB and Redis, so repeated increments never drift apart. It must be
+// set from the leaderboard's first write onwards.
+func WithScorePrecision(decimals int) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.ScaledScores = true
+		o.repoConfig.ScorePrecision = decimals
+	}
+}
+
+// WithDisplayPrecision rounds every score the helper returns, from reads and
+// writes alike, to decimals places using mode, so all services show the same
+// figures. Stored scores are not rounded; combine it with WithScorePrecision
+// to also store them exactly.
+func WithDisplayPrecision(decimals int, mode RoundingMode) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.RoundDisplayScores = true
+		o.repoConfig.DisplayPrecision = decimals
+		o.repoConfig.DisplayRounding = mode
+	}
+}
+
+// WithMaxScoreDelta rejects score writes whose magnitude exceeds max with a
+// ScoreOutOfRangeError
+func WithMaxScoreDelta(max float64) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.MaxScoreDelta = max
+	}
+}
+
+// WithIntegerScores switches the leaderboard to exact integer arithmetic:
+// scores are stored as whole numbers in DynamoDB and Redis, fractional
+// writes fail with ErrFractionalScore, and totals are kept within the range
+// Redis represents exactly (±2^53)
+func WithIntegerScores() Option {
+	return func(o *helperOptions) {
+		o.repoConfig.IntegerScores = true
+		o.repoConfig.ScaledScores = true
+		o.repoConfig.ScorePrecision = 0
+	}
+}
+
+// WithIncrementalSync makes RefreshCache read only items changed since the
+// last sync through indexName, a GSI with leaderboardID as partition key and
+// updated_at (epoch seconds) as sort key
+func WithIncrementalSync(indexName string) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.UpdatedAtIndexName = indexName
+	}
+}
+
+// WithRebuildLock tunes the lock that lets a single instance rebuild a cold
+// leaderboard: ttl bounds how long a crashed rebuilder blocks others, and
+// wait is how long other instances wait before failing with
+// ErrLeaderboardRebuilding (negative fails immediately)
+func WithRebuildLock(ttl time.Duration, wait time.Duration) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.RebuildLockTTL = ttl
+		o.repoConfig.RebuildWaitTimeout = wait
+	}
+}
+
+// WithRedisKeyPrefix prepends prefix to every Redis key of the leaderboard,
+// keeping leaderboards apart from other users of a shared Redis
+func WithRedisKeyPrefix(prefix string) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.RedisKeyPrefix = prefix
+	}
+}
+
+// WithLegacyRedisKeys keeps the leaderboard's Redis keys in the format of
+// releases before the Redis Cluster hash tag, so instances of both releases
+// share one cache during a rolling upgrade. Remove it once every instance
+// runs this release; the leaderboard is then rebuilt from DynamoDB under
+// the new keys. It does not work against Redis Cluster.
+func WithLegacyRedisKeys() Option {
+	return func(o *helperOptions) {
+		o.repoConfig.LegacyRedisKeys = true
+	}
+}
+
+// WithSyncConcurrency tunes loading a leaderboard from DynamoDB: workers
+// partitions are read and pages processed at once, and members are added to
+// Redis batchSize per ZADD. Zero keeps the defaults of 4 and 500.
+func WithSyncConcurrency(workers int, batchSize int) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.SyncConcurrency = workers
+		o.repoConfig.SyncBatchSize = batchSize
+	}
+}
+
+// WithPipelineChunkSize flushes the Redis pipeline every chunkSize commands
+// while loading a leaderboard, bounding memory use and command size. Zero
+// keeps the default of 10,000; negative sends everything at once.
+func WithPipelineChunkSize(chunkSize int) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.PipelineChunkSize = chunkSize
+	}
+}
+
+// WithWriteShards spreads each leaderboard's DynamoDB items over shards
+// partitions so busy leaderboards are not throttled on a single hot
+// partition. Reads and cache rebuilds cover every shard. It must be set
+// before a leaderboard has participants and never changed afterwards.
+func WithWriteShards(shards int) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.WriteShards = shards
+	}
+}
+
+// WithBatchWriteLimits bounds how fast bulk writes like seeding and
+// deleting leaderboards write to DynamoDB, so they leave capacity for live
+// traffic. Zero fields keep DefaultBatchWriteLimits.
+func WithBatchWriteLimits(limits BatchWriteLimits) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.BatchWriteLimits = limits
+	}
+}
+
+// WithNamespacer replaces the scheme combining clientID and userID into the
+// leaderboard member. It must not change once a leaderboard has members.
+func WithNamespacer(namespacer Namespacer) Option {
+	return func(o *helperOptions) {
+		o.namespacer = namespacer
+	}
+}
+
+// WithBeforeUpdate adds hooks run in order before every score update. They
+// may change the update (for example apply a multiplier) or reject it by
+// returning an error, which is passed back to the caller unchanged.
+func WithBeforeUpdate(hooks ...BeforeUpdateHook) Option {
+	return func(o *helperOptions) {
+		o.beforeUpdateHooks = append(o.beforeUpdateHooks, hooks...)
+	}
+}
+
+// WithAfterUpdate adds hooks run in order after every score update that got
+// past the before hooks, with the outcome of the write
+func WithAfterUpdate(hooks ...AfterUpdateHook) Option {
+	return func(o *helperOptions) {
+		o.afterUpdateHooks = append(o.afterUpdateHooks, hooks...)
+	}
+}
+
+// WithFilterAttributes maintains a filtered view of the leaderboard for each
+// value of the named attributes (for example "region" or "platform"), fed
+// by resolver on every score update. The attributes are also stored on the
+// participant item so cache rebuilds restore the views.
+func WithFilterAttributes(resolver MetadataResolver, attributes ...string) Option {
+	return func(o *helperOptions) {
+		o.metadataResolver = resolver
+		o.repoConfig.FilterAttributes = attributes
+	}
+}
+
+// WithClientViews keeps a view of the leaderboard per client, maintained
+// on every score update, for GetTopNParticipantsForClient and
+// GetParticipantRankForClient. It is meant for leaderboards spanning
+// several clients (see NewGlobalLeaderboardHelper). Participants appear in
+// their client's view from their next score update.
+func WithClientViews() Option {
+	return func(o *helperOptions) {
+		o.clientViews = true
+	}
+}
+
+// WithStats tracks named stats such as "kills", "wins" or "coins" per
+// participant alongside the score, written with UpdateStats and each ranked
+// in its own leaderboard. Stats are stored on the participant item so cache
+// rebuilds restore their leaderboards.
+func WithStats(names ...string) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.Stats = names
+	}
+}
+
+// WithRankDeltas fills MemberScore.PreviousRank and RankDelta on reads,
+// compared against a snapshot of the standings that reads refresh every
+// interval. A zero interval leaves snapshots to TakeRankSnapshot.
+func WithRankDeltas(interval time.Duration) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.RankDeltas = true
+		o.repoConfig.RankSnapshotInterval = interval
+	}
+}
+
+// WithStartTime rejects score events timestamped before the leaderboard
+// opens with ErrLeaderboardNotStarted
+func WithStartTime(startTime time.Time) Option {
+	return func(o *helperOptions) {
+		o.startTime = startTime
+	}
+}
+
+// WithScheduledStart creates the leaderboard in the SCHEDULED state: joins
+// are accepted straight away, while score updates fail with
+// ErrLeaderboardNotStarted until startTime, when the leaderboard becomes
+// ACTIVE on its own. Score events are also validated as with WithStartTime.
+func WithScheduledStart(startTime time.Time) Option {
+	return func(o *helperOptions) {
+		o.startTime = startTime
+		o.scheduledStart = true
+	}
+}
+
+// WithFreezeWindow rejects score events timestamped in [start, end) with
+// ErrLeaderboardFrozen, e.g. to freeze standings ahead of the finish. A zero
+// end keeps the leaderboard frozen from start onwards.
+func WithFreezeWindow(start time.Time, end time.Time) Option {
+	return func(o *helperOptions) {
+		o.freezeStart = start
+		o.freezeEnd = end
+	}
+}
+
+// WithCacheExpiry decides when the leaderboard's cached Redis keys expire.
+// The default, CacheExpireAfterEnd, expires them retention after the end
+// time; CacheExpireAfterAccess renews them on every read and write, and
+// CacheNeverExpire suits all-time leaderboards. Zero retention uses 24 hours.
+func WithCacheExpiry(policy CacheExpiryPolicy, retention time.Duration) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.CacheExpiryPolicy = policy
+		o.repoConfig.CacheRetention = retention
+	}
+}
+
+// WithEndTimePolicy decides what happens to writes arriving after the
+// leaderboard's end time. The default, EndTimeReject, fails them with
+// ErrLeaderboardEnded; gracePeriod only applies to EndTimeGrace.
+func WithEndTimePolicy(policy EndTimePolicy, gracePeriod time.Duration) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.EndTimePolicy = policy
+		o.repoConfig.EndTimeGracePeriod = gracePeriod
+	}
+}
+
+// WithIdempotentJoin makes JoinLeaderboard a no-op for participants that
+// already joined, instead of failing with ErrAlreadyJoined. The existing
+// score is kept either way.
+func WithIdempotentJoin() Option {
+	return func(o *helperOptions) {
+		o.repoConfig.IdempotentJoin = true
+	}
+}
+
+// WithTenantIsolation stores the leaderboard under Redis keys and DynamoDB
+// partitions scoped by the helper's clientID, so helpers of different
+// clients never share data even for the same leaderboardID. Enabling it on
+// an existing leaderboard leaves its current data behind.
+func WithTenantIsolation() Option {
+	return func(o *helperOptions) {
+		o.tenantIsolation = true
+	}
+}
+
+// WithTiers labels query results with the narrowest tier each participant's
+// rank falls in, against the live participant count (filtered queries use
+// the size of the filtered view), e.g. Tier{Label: "top 1%", TopPercent: 1}
+// and Tier{Label: "top 10%", TopPercent: 10}
+func WithTiers(tiers ...Tier) Option {
+	return func(o *helperOptions) {
+		sorted := append([]Tier(nil), tiers...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].TopPercent < sorted[j].TopPercent
+		})
+		o.repoConfig.Tiers = sorted
+	}
+}
+
+// WithMilestones records when each participant first reaches each score
+// threshold, e.g. 10000, 50000 and 100000 points. Score updates report the
+// milestones they reached in MemberScore.Milestones, and GetMilestones
+// lists a participant's milestones.
+func WithMilestones(thresholds ...float64) Option {
+	return func(o *helperOptions) {
+		sorted := append([]float64(nil), thresholds...)
+		sort.Float64s(sorted)
+		o.repoConfig.Milestones = sorted
+	}
+}
+
+// WithPayoutTable sets the prizes paid by rank, used by ProjectReward to
+// show participants what they would win if the leaderboard ended now
+func WithPayoutTable(table PayoutTable) Option {
+	return func(o *helperOptions) {
+		o.payoutTable = append(PayoutTable(nil), table...)
+	}
+}
+
+// WithResultTransformer applies transformers, in order, to every standing
+// returned by the read APIs, so display rules such as AbbreviateScores or
+// masking members live in one place. Responses built with NewTopNResponse
+// and NewRankResponse carry the result, including DisplayScore. Score
+// updates return their standings untransformed.
+func WithResultTransformer(transformers ...ResultTransformer) Option {
+	return func(o *helperOptions) {
+		o.resultTransformers = append(o.resultTransformers, transformers...)
+	}
+}
+
+// WithSkillBrackets splits the leaderboard into sub-boards by skill, so
+// casual players do not compete directly against the very best.
+// JoinLeaderboardWithRating assigns each joiner to a bracket by rating,
+// score updates are credited to the user's bracket as well, and
+// GetMyBracketTopN reads a user's bracket. Users joined without a rating
+// stay on the main board only.
+func WithSkillBrackets(brackets ...SkillBracket) Option {
+	return func(o *helperOptions) {
+		o.skillBrackets = brackets
+	}
+}
+
+// WithEntryFee makes the leaderboard a paid one: joining holds amount with
+// provider, recorded on the participant's item, and SettleEscrow or
+// RefundEscrow resolve the held fees once the leaderboard ends or is
+// cancelled. Joins that fail release their hold again.
+func WithEntryFee(provider EscrowProvider, amount float64) Option {
+	return func(o *helperOptions) {
+		o.escrow = provider
+		o.entryFee = amount
+	}
+}
+
+// WithLifetimeLeaderboard credits every score update to the client's
+// lifetime leaderboard as well, in the same DynamoDB transaction, so
+// all-time points need no second update call. The lifetime leaderboard is
+// stored under its own partition and Redis key and read through
+// LifetimeLeaderboard. It requires a clientID. While the leaderboard's cache
+// is being rebuilt, updates report a zero score and rank.
+func WithLifetimeLeaderboard() Option {
+	return func(o *helperOptions) {
+		o.lifetimeLeaderboard = true
+	}
+}
+
+// WithRollingWindow keeps a sliding view of the leaderboard in which only
+// scores written in the trailing window count, e.g. the last 7 days. Writes
+// are collected in Redis buckets of the given size, which also sets the
+// granularity of the window, and RefreshRollingWindow recombines them. The
+// buckets live in Redis only, so they are not restored by cache rebuilds.
+func WithRollingWindow(window time.Duration, bucket time.Duration) Option {
+	return func(o *helperOptions) {
+		if bucket <= 0 || bucket > window {
+			bucket = window
+		}
+		o.repoConfig.RollingWindow = window
+		o.repoConfig.RollingBucket = bucket
+	}
+}
+
+// WithAnomalyDetector screens every score update with detector after the
+// before hooks. Its verdict decides whether the update is applied, flagged,
+// quarantined or rejected; DefaultZScoreDetector judges deltas against the
+// participant's recent history, which is kept in Redis.
+func WithAnomalyDetector(detector AnomalyDetector) Option {
+	return func(o *helperOptions) {
+		o.anomalyDetector = detector
+	}
+}
+
+// WithQuarantine stores updates the anomaly detector quarantines in the
+// pending table, keyed by leaderboardID and updateID, for review with
+// ReviewPendingUpdates. An empty table name uses DefaultPendingTableName.
+func WithQuarantine(pendingTableName string) Option {
+	return func(o *helperOptions) {
+		o.quarantine = true
+		o.repoConfig.PendingTableName = pendingTableName
+	}
+}
+
+// WithConflictRetries retries DynamoDB transactions cancelled by concurrent
+// writes to the same participants up to retries times, waiting a random
+// delay of up to backoff, doubled with each retry. Zero keeps the defaults of
+// 3 retries and 20ms; negative retries disable retrying.
+func WithConflictRetries(retries int, backoff time.Duration) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.ConflictRetries = retries
+		o.repoConfig.ConflictBackoff = backoff
+	}
+}
+
+// WithOutbox records a ScoreUpdated event in the outbox table, keyed by
+// leaderboardID and eventID, in the same DynamoDB transaction as each score
+// update, for delivery with DispatchOutbox. An empty table name uses
+// DefaultOutboxTableName.
+func WithOutbox(outboxTableName string) Option {
+	return func(o *helperOptions) {
+		o.outbox = true
+		o.repoConfig.OutboxTableName = outboxTableName
+	}
+}
+
+// WithVisibility restricts who may read the leaderboard's standings, so
+// internal test events do not leak through shared read endpoints. Reads
+// identify their caller with WithCaller, or skip the check with
+// WithTrustedCaller, and fail with ErrLeaderboardNotVisible otherwise.
+// Writes are not affected.
+func WithVisibility(visibility Visibility) Option {
+	return func(o *helperOptions) {
+		o.visibility = visibility
+	}
+}
+
+// WithAccessPolicy consults policy on every standings read, as with
+// WithVisibility, to decide whether the caller identified with WithCaller
+// may read the leaderboard and which fields of the standings it may see.
+// Refused reads fail with ErrAccessDenied. Writes are not affected.
+func WithAccessPolicy(policy AccessPolicy) Option {
+	return func(o *helperOptions) {
+		o.accessPolicy = policy
+	}
+}
+
+// WithShadowMode runs the leaderboard as a shadow: writes are accepted and
+// ranked as usual, but standings reads fail with ErrShadowLeaderboard unless
+// made with ShadowReads. Pair it with MirrorToShadow to dual-run a new
+// scoring formula next to the live leaderboard, and CompareStandings to
+// check the results before switching.
+func WithShadowMode() Option {
+	return func(o *helperOptions) {
+		o.shadowMode = true
+	}
+}
+
+// WithRankHistory records participants' rank history in the rank history
+// table, keyed by historyID and bucketStart, when RecordRankHistory is
+// called, at most once per interval. An empty table name uses
+// DefaultRankHistoryTableName.
+func WithRankHistory(rankHistoryTableName string, interval time.Duration) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.RankHistoryTableName = rankHistoryTableName
+		o.repoConfig.RankHistoryInterval = interval
+	}
+}
+
+// WithSnapshots stores the standings snapshots taken by
+// TakeStandingsSnapshot and RunSnapshots in the snapshot table, keyed by
+// leaderboardID and snapshotID. An empty table name uses
+// DefaultSnapshotTableName.
+func WithSnapshots(snapshotTableName string) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.SnapshotTableName = snapshotTableName
+	}
+}
+
+// WithMetadataCache writes participants' display metadata to a Redis hash
+// next to the leaderboard whenever it is updated, so it can be read with
+// GetCachedMetadata without going to DynamoDB
+func WithMetadataCache() Option {
+	return func(o *helperOptions) {
+		o.repoConfig.CacheMetadata = true
+	}
+}
+
+// WithEncryptedMetadata encrypts the named display metadata attributes,
+// such as usernames or emails, with fieldCipher before they are written to
+// DynamoDB or the Redis metadata hash. Reads decrypt them again, and values
+// stored before an attribute was listed are read as they are.
+func WithEncryptedMetadata(fieldCipher FieldCipher, fields ...string) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.MetadataCipher = fieldCipher
+		o.repoConfig.EncryptedMetadataFields = fields
+	}
+}
+
+// WithScalingHints counts each leaderboard's score writes per minute in
+// Redis, for GetWriteRate and GetScalingHint; zero thresholds take their
+// defaults from DefaultScalingThresholds. If callback is set it receives
+// the leaderboard's scaling hint once a minute while writes arrive, from
+// whichever instance makes the minute's first write.
+func WithScalingHints(thresholds ScalingThresholds, callback ScalingHintFunc) Option {
+	defaults := DefaultScalingThresholds()
+	if thresholds.ShardWritesPerSecond <= 0 {
+		thresholds.ShardWritesPerSecond = defaults.ShardWritesPerSecond
+	}
+	if thresholds.Headroom <= 0 {
+		thresholds.Headroom = defaults.Headroom
+	}
+	if thresholds.Window <= 0 {
+		thresholds.Window = defaults.Window
+	}
+
+	return func(o *helperOptions) {
+		o.scalingHints = true
+		o.scalingThresholds = thresholds
+		o.scalingHintFunc = callback
+	}
+}
+
+// WithChangeNotifications announces every score write, join and seed on a
+// Redis pub/sub channel per leaderboard, which SubscribeTopN listens to
+func WithChangeNotifications() Option {
+	return func(o *helperOptions) {
+		o.changeNotifications = true
+	}
+}
+
+// WithRankCache keeps GetParticipantScoreAndRank results in process for ttl,
+// holding at most maxEntries members (zero for no limit), so repeated reads
+// of the same member skip Redis. A member's entry is dropped when this
+// helper writes their score; writes by other members or instances, which
+// also move ranks, are only seen once the entry expires, so keep ttl short.
+func WithRankCache(ttl time.Duration, maxEntries int) Option {
+	return func(o *helperOptions) {
+		o.rankCacheTTL = ttl
+		o.rankCacheMaxEntries = maxEntries
+	}
+}
+
+// WithStaleRanks lets GetParticipantScoreAndRank answer from a standing
+// that expired from the rank cache up to maxStaleness ago when Redis is
+// overloaded: when maxInFlight reads are already waiting on it (zero for no
+// limit), or when the read times out. Served standings keep the ComputedAt
+// of their original read, so callers can show how fresh a rank is. Without
+// WithRankCache every read still goes to Redis and is only kept for this
+// fallback.
+func WithStaleRanks(maxStaleness time.Duration, maxInFlight int) Option {
+	return func(o *helperOptions) {
+		o.staleRankWindow = maxStaleness
+		o.staleRankMaxInFlight = maxInFlight
+	}
+}
+
+// WithIDRules validates clientIDs and userIDs against rules wherever the
+// helper builds or accepts a member, failing with an InvalidIDError before
+// anything reaches the stores. Pairs that would not split back into the
+// same IDs, such as a userID starting with part of the separator, are
+// rejected too. Members already stored are still read as before.
+func WithIDRules(rules IDRules) Option {
+	return func(o *helperOptions) {
+		o.idRules = &rules
+	}
+}
+
+// WithSchemaMigrations registers the migrations evolving participant items,
+// the i-th upgrading items from schema version i to i+1; append new ones and
+// never reorder or remove them. New items are stamped with the latest
+// version in a schemaVersion attribute, older items are migrated when read
+// and written back, and MigrateSchema migrates the rest in bulk.
+func WithSchemaMigrations(migrations ...SchemaMigration) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.SchemaMigrations = migrations
+	}
+}
+
+// WithAssumeRole makes every DynamoDB request as roleARN, assumed with the
+// DynamoDB client's credentials and refreshed before they expire.
+// externalID may be empty. Scoping the role's policy to one tenant's tables
+// keeps the helper off every other table; TenantStorage.RoleARN does the
+// same per client.
+func WithAssumeRole(roleARN string, externalID string) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.AssumeRoleARN = roleARN
+		o.repoConfig.AssumeRoleExternalID = externalID
+	}
+}
+
+// WithRedisStandby mirrors every Redis write to standby and sends all
+// commands to it once standby.Failover is called. Share one standby
+// between helpers so a single call fails all of them over.
+func WithRedisStandby(standby *RedisStandby) Option {
+	return func(o *helperOptions) {
+		o.repoConfig.RedisHooks = append(o.repoConfig.RedisHooks, standby.hook())
+	}
+}
@@ -0,0 +1,118 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+)
+
+// DefaultOutboxTableName is the DynamoDB table holding undispatched events
+const DefaultOutboxTableName = repos.DefaultOutboxTableName
+
+// OutboxEventScoreUpdated is the type of the event recorded for each
+// applied score update
+const OutboxEventScoreUpdated = "ScoreUpdated"
+
+// defaultOutboxBatch is how many events DispatchOutbox reads at a time
+const defaultOutboxBatch = 100
+
+// OutboxEvent is a leaderboard event recorded in the outbox together with
+// the write it describes
+type OutboxEvent = customTypes.OutboxEvent
+
+// OutboxPublisher delivers an outbox event, e.g. to a message broker. An
+// event is removed from the outbox only once it returns nil, so it may see
+// an event again after a failure and should deduplicate by EventID.
+type OutboxPublisher func(ctx context.Context, event OutboxEvent) error
+
+// applyWithOutbox applies a score update, together with its lifetime
+// leaderboard credit if any, and records its event in one transaction
+func (l *IndividualLeaderboardHelper) applyWithOutbox(
+	ctx context.Context,
+	update *ScoreUpdate,
+) (*customTypes.MemberScore, error) {
+	writes := []repos.ScoreWrite{{
+		LeaderboardID:      l.storageID,
+		NamespacedUserID:   update.NamespacedUserID,
+		ScoreDelta:         update.ScoreDelta,
+		LeaderboardEndTime: l.endTime(),
+		Attributes:         update.Attributes,
+	}}
+	if l.lifetimeLeaderboard {
+		writes = append(writes, repos.ScoreWrite{
+			LeaderboardID:    lifetimeStorageID(l.clientID),
+			NamespacedUserID: update.NamespacedUserID,
+			ScoreDelta:       update.ScoreDelta,
+		})
+	}
+
+	results, err := l.repo.UpdateScoresWithEvents(ctx, l.storageID, writes, OutboxEventScoreUpdated)
+	if err != nil {
+		return nil, err
+	}
+
+	return &results[0], nil
+}
+
+// DispatchOutbox publishes the leaderboard's undispatched events oldest
+// first, removing each once published, and returns how many were
+// dispatched. It stops at the first failure so events keep their order;
+// the failed event is retried on the next call.
+func (l *IndividualLeaderboardHelper) DispatchOutbox(
+	ctx context.Context,
+	publish OutboxPublisher,
+) (int, error) {
+	dispatched := 0
+	for {
+		events, err := l.repo.ListOutboxEvents(ctx, l.storageID, defaultOutboxBatch)
+		if err != nil {
+			return dispatched, err
+		}
+
+		for _, event := range events {
+			if err := publish(ctx, event); err != nil {
+				return dispatched, fmt.Errorf(
+					"failed to publish outbox event %s: %w",
+					event.EventID,
+					err,
+				)
+			}
+			if err := l.repo.DeleteOutboxEvent(ctx, l.storageID, event.EventID); err != nil {
+				return dispatched, err
+			}
+			dispatched++
+		}
+
+		if len(events) < defaultOutboxBatch {
+			return dispatched, nil
+		}
+	}
+}
+
+// RunOutboxDispatcher calls DispatchOutbox once per interval until ctx is
+// cancelled. Running it on several instances is safe but delivers events
+// more than once.
+func (l *IndividualLeaderboardHelper) RunOutboxDispatcher(
+	ctx context.Context,
+	interval time.Duration,
+	publish OutboxPublisher,
+) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if _, err := l.DispatchOutbox(ctx, publish); err != nil {
+			// Undispatched events stay in the outbox, so only log
+			fmt.Printf("Error dispatching outbox events: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// Page is one page of GetPage results with the cursor of the next page
+type Page = customTypes.Page
+
+// GetPage returns up to limit participants after cursor, starting from the
+// top when cursor is empty. Pass the returned NextCursor to read the next
+// page; it is empty once the end is reached. Unlike offsets, cursors stay
+// stable while scores change between pages. Invalid cursors fail with
+// ErrInvalidCursor.
+func (l *IndividualLeaderboardHelper) GetPage(
+	ctx context.Context,
+	cursor string,
+	limit int64,
+) (*Page, error) {
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	page, err := l.repo.GetPage(ctx, l.storageID, cursor, limit, l.endTime())
+	if err != nil {
+		return nil, err
+	}
+
+	l.markGhosts(page.Entries)
+	l.transformResults(ctx, page.Entries)
+	return page, nil
+}
@@ -0,0 +1,138 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/models"
+)
+
+// ParticipantModel is a participant's stored record
+type ParticipantModel = models.ParticipantModel
+
+// JoinLeaderboard adds one of the helper's client's users to the leaderboard
+// with an initial score. Joining again keeps the accumulated score and fails
+// with ErrAlreadyJoined unless WithIdempotentJoin is set. Joins are accepted
+// while the leaderboard is still SCHEDULED. Paid leaderboards (see
+// WithEntryFee) hold the entry fee first.
+func (l *IndividualLeaderboardHelper) JoinLeaderboard(
+	ctx context.Context,
+	userID string,
+	initialScore float64,
+) error {
+	_, err := l.join(ctx, userID, initialScore, "")
+	return err
+}
+
+// join adds a user to the leaderboard, recording the skill bracket if any
+func (l *IndividualLeaderboardHelper) join(
+	ctx context.Context,
+	userID string,
+	initialScore float64,
+	skillBracket string,
+) (*ParticipantModel, error) {
+	if l.clientID == "" {
+		return nil, ErrClientRequired
+	}
+
+	participant, err := models.NewNamespacedParticipantModel(
+		l.namespacer,
+		l.storageID,
+		l.clientID,
+		userID,
+		initialScore,
+	)
+	if err != nil {
+		return nil, err
+	}
+	participant.SkillBracket = skillBracket
+
+	if l.escrow != nil {
+		err = l.joinWithEscrow(ctx, participant)
+	} else {
+		err = l.repo.JoinLeaderboard(ctx, participant, l.endTime())
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.rankCache.invalidate(participant.NamespacedUserID)
+	l.publishChanges(ctx, participant.NamespacedUserID)
+
+	return participant, nil
+}
+
+// IsParticipant reports whether the participant is on the leaderboard
+func (l *IndividualLeaderboardHelper) IsParticipant(
+	ctx context.Context,
+	namespacedUserID string,
+) (bool, error) {
+	_, _, err := l.validateNamespacedUserID(namespacedUserID)
+	if err != nil {
+		return false, err
+	}
+
+	return l.repo.IsParticipant(ctx, l.storageID, namespacedUserID, l.endTime())
+}
+
+// GetParticipant returns the stored record of one of the helper's client's
+// users, or ErrParticipantNotFound
+func (l *IndividualLeaderboardHelper) GetParticipant(
+	ctx context.Context,
+	userID string,
+) (*ParticipantModel, error) {
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	namespacedUserID, err := l.memberID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := l.repo.GetParticipant(ctx, l.storageID, namespacedUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Items written only by score updates do not store the split IDs
+	participant.LeaderboardID = l.leaderboardID
+	participant.ClientID = l.clientID
+	participant.UserID = userID
+
+	return participant, nil
+}
+
+// UpdateParticipantMetadata changes the display attributes, such as the
+// name or avatar, stored with one of the helper's client's users without
+// touching the score. Attributes set to nil are removed; the others are
+// merged into the stored metadata, which is also written to the Redis
+// metadata hash when WithMetadataCache is set.
+func (l *IndividualLeaderboardHelper) UpdateParticipantMetadata(
+	ctx context.Context,
+	userID string,
+	attrs map[string]any,
+) error {
+	namespacedUserID, err := l.memberID(userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = l.repo.UpdateParticipantMetadata(
+		ctx,
+		l.storageID,
+		namespacedUserID,
+		attrs,
+		l.endTime(),
+	)
+
+	return err
+}
+
+// GetCachedMetadata returns the display metadata held in the Redis metadata
+// hash for the given members, keyed by member. Members without cached
+// metadata, or whose metadata cannot be read, are left out.
+func (l *IndividualLeaderboardHelper) GetCachedMetadata(
+	ctx context.Context,
+	namespacedUserIDs ...string,
+) (map[string]map[string]any, error) {
+	return l.repo.GetCachedMetadata(ctx, l.storageID, namespacedUserIDs)
+}
@@ -0,0 +1,37 @@
+package leaderboard
+
+import (
+	"context"
+)
+
+// FreezeParticipant locks the score of one of the helper's client's users,
+// e.g. while a dispute is investigated. The user stays ranked with their
+// current score, but score updates fail with a ParticipantFrozenError
+// carrying reason until UnfreezeParticipant is called. It returns
+// ErrParticipantNotFound for users that are not on the leaderboard.
+func (l *IndividualLeaderboardHelper) FreezeParticipant(
+	ctx context.Context,
+	userID string,
+	reason string,
+) error {
+	namespacedUserID, err := l.memberID(userID)
+	if err != nil {
+		return err
+	}
+
+	return l.repo.FreezeParticipant(ctx, l.storageID, namespacedUserID, reason)
+}
+
+// UnfreezeParticipant lets score updates reach a user frozen with
+// FreezeParticipant again. Updates rejected while frozen are not replayed.
+func (l *IndividualLeaderboardHelper) UnfreezeParticipant(
+	ctx context.Context,
+	userID string,
+) error {
+	namespacedUserID, err := l.memberID(userID)
+	if err != nil {
+		return err
+	}
+
+	return l.repo.UnfreezeParticipant(ctx, l.storageID, namespacedUserID)
+}
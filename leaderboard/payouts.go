@@ -0,0 +1,94 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// PayoutTier pays Amount, and the optional Reward such as an item or badge,
+// to each participant ranked from FromRank to ToRank inclusive
+type PayoutTier struct {
+	FromRank int64
+	ToRank   int64
+	Amount   float64
+	Reward   string
+}
+
+// PayoutTable lists the prizes of a leaderboard by rank range, e.g.
+// PayoutTable{{1, 1, 500, ""}, {2, 10, 50, ""}}. Ranks outside every tier
+// win nothing.
+type PayoutTable []PayoutTier
+
+// For returns the tier paying a 1-based rank
+func (t PayoutTable) For(rank int64) (PayoutTier, bool) {
+	for _, tier := range t {
+		if rank >= tier.FromRank && rank <= tier.ToRank {
+			return tier, true
+		}
+	}
+
+	return PayoutTier{}, false
+}
+
+// next returns the closest tier ranked entirely above rank
+func (t PayoutTable) next(rank int64) (PayoutTier, bool) {
+	var best PayoutTier
+	found := false
+	for _, tier := range t {
+		if tier.ToRank < rank && (!found || tier.ToRank > best.ToRank) {
+			best = tier
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// RewardProjection is what a participant would win if the leaderboard
+// ended now
+type RewardProjection struct {
+	// Standing is the participant's current score and rank
+	Standing customTypes.MemberScore
+	// Payout is the tier the rank falls in, nil when it wins nothing
+	Payout *PayoutTier
+	// NextPayout is the closest better-ranked tier, nil at the top
+	NextPayout *PayoutTier
+}
+
+// ProjectReward returns what one of the helper's client's users would win
+// with their current rank under the payout table set with WithPayoutTable.
+// Ghost participants never win anything. It returns ErrNoPayoutTable
+// without a payout table, and ErrParticipantNotFound for users that are
+// not on the leaderboard.
+func (l *IndividualLeaderboardHelper) ProjectReward(
+	ctx context.Context,
+	userID string,
+) (*RewardProjection, error) {
+	if len(l.payoutTable) == 0 {
+		return nil, ErrNoPayoutTable
+	}
+
+	namespacedUserID, err := l.memberID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	standing, err := l.GetParticipantScoreAndRank(ctx, namespacedUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	projection := &RewardProjection{Standing: *standing}
+	if standing.Ghost || standing.Rank == 0 {
+		return projection, nil
+	}
+	if tier, ok := l.payoutTable.For(standing.Rank); ok {
+		projection.Payout = &tier
+	}
+	if tier, ok := l.payoutTable.next(standing.Rank); ok {
+		projection.NextPayout = &tier
+	}
+
+	return projection, nil
+}
@@ -0,0 +1,93 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+)
+
+// PlacementPoints awards points by final placement: the first entry goes to
+// 1st place, the second to 2nd, and so on. Placements past the end earn
+// nothing.
+type PlacementPoints []float64
+
+// F1Points is the Formula 1 points table, 25 points for 1st down to 1 for
+// 10th
+var F1Points = PlacementPoints{25, 18, 15, 12, 10, 8, 6, 4, 2, 1}
+
+// For returns the points earned by a 1-based rank
+func (p PlacementPoints) For(rank int64) float64 {
+	if rank < 1 || rank > int64(len(p)) {
+		return 0
+	}
+
+	return p[rank-1]
+}
+
+// AwardPlacementPoints converts the standings of source into placement
+// points and adds them to the same participants on target, for example to
+// score a championship across events. Every award is applied in a single
+// transaction, so a failure awards nothing. It returns the awards made,
+// with each participant's rank on source and the points as score. Ghost
+// participants earn no points. Run it once per finished event; running it
+// again awards the points again.
+func AwardPlacementPoints(
+	ctx context.Context,
+	source *IndividualLeaderboardHelper,
+	target *IndividualLeaderboardHelper,
+	points PlacementPoints,
+) ([]customTypes.MemberScore, error) {
+	if target.State() == LeaderboardScheduled {
+		return nil, ErrLeaderboardNotStarted
+	}
+
+	// Only placements that earn points matter. Awards are made by the
+	// service, whatever the source's visibility.
+	entries, err := source.GetTopNParticipants(WithTrustedCaller(ctx), int64(len(points)))
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read final standings: %w",
+			err,
+		)
+	}
+
+	var awards []customTypes.MemberScore
+	var writes []repos.ScoreWrite
+	for _, entry := range entries {
+		awarded := points.For(entry.Rank)
+		if entry.Ghost || awarded == 0 {
+			continue
+		}
+		if _, _, err := target.validateNamespacedUserID(entry.Member); err != nil {
+			return nil, err
+		}
+		awards = append(awards, customTypes.MemberScore{
+			Member: entry.Member,
+			Score:  awarded,
+			Rank:   entry.Rank,
+		})
+		writes = append(writes, repos.ScoreWrite{
+			LeaderboardID:      target.storageID,
+			NamespacedUserID:   entry.Member,
+			ScoreDelta:         awarded,
+			LeaderboardEndTime: target.endTime(),
+		})
+	}
+
+	if _, err := target.repo.UpdateScoresAtomically(ctx, writes); err != nil {
+		return nil, fmt.Errorf(
+			"failed to award placement points: %w",
+			err,
+		)
+	}
+
+	// Let caches in other regions apply the same awards
+	for _, award := range awards {
+		target.publishCacheUpdate(ctx, award.Member, award.Score)
+		target.creditBracket(ctx, award.Member, award.Score)
+	}
+
+	return awards, nil
+}
@@ -0,0 +1,25 @@
+package leaderboard
+
+import (
+	"context"
+	"time"
+)
+
+// PruneParticipants removes participants that have not scored since
+// inactiveSince from DynamoDB and the cached leaderboard, keeping long
+// running leaderboards relevant and their Redis memory bounded, and returns
+// how many were removed. Frozen participants and participants scoring while
+// the prune runs are kept. Deletes are batched and paced at the bulk write
+// rate (see WithBatchWriteLimits).
+func (l *IndividualLeaderboardHelper) PruneParticipants(
+	ctx context.Context,
+	inactiveSince time.Time,
+) (int64, error) {
+	pruned, err := l.repo.PruneParticipants(ctx, l.storageID, inactiveSince)
+	if pruned > 0 {
+		l.publishInvalidation(ctx)
+		l.publishChanges(ctx)
+	}
+
+	return pruned, err
+}
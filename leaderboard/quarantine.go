@@ -0,0 +1,102 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+)
+
+// DefaultPendingTableName is the DynamoDB table holding quarantined score
+// updates unless WithQuarantine names another
+const DefaultPendingTableName = repos.DefaultPendingTableName
+
+// PendingUpdate is a quarantined score update awaiting review
+type PendingUpdate = customTypes.PendingUpdate
+
+// quarantineUpdate stores an update the anomaly detector quarantined
+func (l *IndividualLeaderboardHelper) quarantineUpdate(
+	ctx context.Context,
+	update *ScoreUpdate,
+) error {
+	_, err := l.repo.QuarantineUpdate(
+		ctx,
+		l.storageID,
+		update.NamespacedUserID,
+		update.ScoreDelta,
+		update.Attributes,
+		l.endTime(),
+	)
+
+	return err
+}
+
+// ListPendingUpdates returns up to limit quarantined updates of the
+// leaderboard, oldest first
+func (l *IndividualLeaderboardHelper) ListPendingUpdates(
+	ctx context.Context,
+	limit int32,
+) ([]PendingUpdate, error) {
+	return l.repo.ListPendingUpdates(ctx, l.storageID, limit)
+}
+
+// ReviewPendingUpdates settles quarantined updates in one batch: the
+// approved updates are credited, together with the lifetime leaderboard if
+// enabled, and both approved and rejected updates leave the queue, all in a
+// single DynamoDB transaction. It fails with ErrPendingUpdateNotFound if any
+// update was already reviewed, in which case nothing is applied. It returns
+// the standings of the credited participants; update hooks are not run.
+func (l *IndividualLeaderboardHelper) ReviewPendingUpdates(
+	ctx context.Context,
+	approved []string,
+	rejected []string,
+) ([]customTypes.MemberScore, error) {
+	review := repos.PendingReview{
+		LeaderboardID:      l.storageID,
+		LeaderboardEndTime: l.endTime(),
+		Approved:           approved,
+		Rejected:           rejected,
+	}
+	if l.lifetimeLeaderboard {
+		review.ExtraWrites = func(member string, scoreDelta float64) []repos.ScoreWrite {
+			return []repos.ScoreWrite{{
+				LeaderboardID:    lifetimeStorageID(l.clientID),
+				NamespacedUserID: member,
+				ScoreDelta:       scoreDelta,
+			}}
+		}
+	}
+
+	results, updates, err := l.repo.ReviewPendingUpdates(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+
+	// Let caches in other regions apply the same deltas
+	credited := make(map[string]float64, len(results))
+	for _, update := range updates {
+		credited[update.NamespacedUserID] += update.ScoreDelta
+		l.publishCacheUpdate(ctx, update.NamespacedUserID, update.ScoreDelta)
+		if l.anomalyDetector != nil {
+			l.recordUpdateHistory(ctx, &ScoreUpdate{
+				NamespacedUserID: update.NamespacedUserID,
+				ScoreDelta:       update.ScoreDelta,
+			})
+		}
+	}
+
+	// Each participant's approved updates were credited as one write
+	members := make([]string, len(results))
+	for i, result := range results {
+		l.recordMilestones(ctx, result.Member, credited[result.Member], &results[i])
+		l.creditBracket(ctx, result.Member, credited[result.Member])
+		members[i] = result.Member
+	}
+
+	l.recordWrites(ctx, int64(len(results)))
+	if len(results) > 0 {
+		l.publishChanges(ctx, members...)
+	}
+
+	return results, nil
+}
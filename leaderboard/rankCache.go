@@ -0,0 +1,181 @@
+package leaderboard
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// rankCache holds recent GetParticipantScoreAndRank results in process (see
+// WithRankCache). Entries are dropped when the helper writes the member's
+// score; changes made by other members or instances show up once the entry
+// expires. With WithStaleRanks, expired entries are kept a while longer to
+// be served when the stores are overloaded.
+type rankCache struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	maxEntries  int
+	staleFor    time.Duration
+	maxInFlight int
+	inFlight    int
+	entries     map[string]rankCacheEntry
+}
+
+// rankCacheEntry is one cached standing
+type rankCacheEntry struct {
+	score     customTypes.MemberScore
+	expiresAt time.Time
+}
+
+// newRankCache creates a cache, or returns nil when neither ttl nor
+// staleFor is positive
+func newRankCache(
+	ttl time.Duration,
+	maxEntries int,
+	staleFor time.Duration,
+	maxInFlight int,
+) *rankCache {
+	if ttl <= 0 && staleFor <= 0 {
+		return nil
+	}
+
+	return &rankCache{
+		ttl:         max(ttl, 0),
+		maxEntries:  maxEntries,
+		staleFor:    max(staleFor, 0),
+		maxInFlight: maxInFlight,
+		entries:     make(map[string]rankCacheEntry),
+	}
+}
+
+// get returns a copy of the member's cached standing if it has not expired
+func (c *rankCache) get(member string) (*customTypes.MemberScore, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[member]
+	if !ok {
+		return nil, false
+	}
+	now := time.Now()
+	if now.After(entry.expiresAt) {
+		if now.After(entry.expiresAt.Add(c.staleFor)) {
+			delete(c.entries, member)
+		}
+		return nil, false
+	}
+
+	score := entry.score
+	return &score, true
+}
+
+// getStale returns a copy of the member's cached standing if it expired no
+// longer than staleFor ago
+func (c *rankCache) getStale(member string) (*customTypes.MemberScore, bool) {
+	if c == nil || c.staleFor <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[member]
+	if !ok || time.Now().After(entry.expiresAt.Add(c.staleFor)) {
+		return nil, false
+	}
+
+	score := entry.score
+	return &score, true
+}
+
+// overloaded reports whether maxInFlight reads are already waiting on the
+// stores
+func (c *rankCache) overloaded() bool {
+	if c == nil || c.maxInFlight <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.inFlight >= c.maxInFlight
+}
+
+// begin counts a read waiting on the stores; the returned func ends it
+func (c *rankCache) begin() func() {
+	if c == nil {
+		return func() {}
+	}
+
+	c.mu.Lock()
+	c.inFlight++
+	c.mu.Unlock()
+
+	return func() {
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}
+}
+
+// put caches a copy of the member's standing
+func (c *rankCache) put(member string, score *customTypes.MemberScore) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		// Make room by dropping expired entries first, then arbitrary ones
+		for cached, entry := range c.entries {
+			if now.After(entry.expiresAt.Add(c.staleFor)) {
+				delete(c.entries, cached)
+			}
+		}
+		for cached := range c.entries {
+			if len(c.entries) < c.maxEntries {
+				break
+			}
+			delete(c.entries, cached)
+		}
+	}
+
+	c.entries[member] = rankCacheEntry{
+		score:     *score,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// invalidate drops the members' cached standings
+func (c *rankCache) invalidate(members ...string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, member := range members {
+		delete(c.entries, member)
+	}
+}
+
+// clear drops every cached standing
+func (c *rankCache) clear() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]rankCacheEntry)
+}
@@ -0,0 +1,47 @@
+package leaderboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+)
+
+// DefaultRankHistoryTableName is the DynamoDB table holding rank history
+// unless WithRankHistory names another
+const DefaultRankHistoryTableName = repos.DefaultRankHistoryTableName
+
+// RankHistoryPoint is a participant's recorded rank and score at one point
+// in time
+type RankHistoryPoint = customTypes.RankHistoryPoint
+
+// RecordRankHistory appends every participant's current rank and score to
+// their rank history and returns how many were recorded. It is meant to be
+// run on a schedule, e.g. hourly; with the interval of WithRankHistory set,
+// calls made before it has passed record nothing, so every instance may run
+// the schedule.
+func (l *IndividualLeaderboardHelper) RecordRankHistory(ctx context.Context) (int64, error) {
+	return l.repo.RecordRankHistory(ctx, l.storageID, l.endTime())
+}
+
+// GetRankHistory returns the recorded ranks of one of the helper's client's
+// users between from and to, oldest first, e.g. to chart their rank over
+// the event
+func (l *IndividualLeaderboardHelper) GetRankHistory(
+	ctx context.Context,
+	userID string,
+	from time.Time,
+	to time.Time,
+) ([]RankHistoryPoint, error) {
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	namespacedUserID, err := l.memberID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.repo.GetRankHistory(ctx, l.storageID, namespacedUserID, from, to)
+}
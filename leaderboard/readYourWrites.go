@@ -0,0 +1,25 @@
+package leaderboard
+
+import (
+	"context"
+)
+
+// readYourWritesKey marks contexts of score updates made with ReadYourWrites
+type readYourWritesKey struct{}
+
+// ReadYourWrites returns a context that makes a score update made with it
+// visible to every read that follows it, for "submit score, then show rank"
+// flows. When the update lands while the cached leaderboard is being
+// rebuilt, it waits for the rebuild and writes the participant's durable
+// score into the cache before returning, instead of leaving the write to a
+// later sync. The returned rank is then set unless that sync fails, which
+// is logged since the write itself is already durable.
+func ReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, true)
+}
+
+// readYourWrites reports whether ctx was made by ReadYourWrites
+func readYourWrites(ctx context.Context) bool {
+	enabled, _ := ctx.Value(readYourWritesKey{}).(bool)
+	return enabled
+}
@@ -0,0 +1,55 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RepairCache makes the cached scores of members whose earlier writes
+// reached DynamoDB but not Redis match DynamoDB again, and returns how many
+// were repaired. Such members are also repaired lazily on the leaderboard's
+// next access; this repairs them without waiting for one.
+func (l *IndividualLeaderboardHelper) RepairCache(ctx context.Context) (int, error) {
+	return l.repo.RepairCache(ctx, l.storageID)
+}
+
+// RunRepairs repairs the cache of every leaderboard sharing the helper's
+// stores once per interval, as RepairCache, until ctx is cancelled. Repairs
+// are queued in memory by the instance whose write failed, so each
+// instance runs its own worker.
+func (l *IndividualLeaderboardHelper) RunRepairs(
+	ctx context.Context,
+	interval time.Duration,
+) error {
+	return runRepairs(ctx, interval, l.repo.RepairAllCaches)
+}
+
+// RunRepairs repairs the caches of the client's leaderboards once per
+// interval until ctx is cancelled, as IndividualLeaderboardHelper.RunRepairs
+func (m *Manager) RunRepairs(ctx context.Context, interval time.Duration) error {
+	return runRepairs(ctx, interval, m.repo.RepairAllCaches)
+}
+
+// runRepairs calls repair once per interval until ctx is cancelled
+func runRepairs(
+	ctx context.Context,
+	interval time.Duration,
+	repair func(ctx context.Context) (int, error),
+) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if _, err := repair(ctx); err != nil {
+			// The members stay queued for the next round, so only log
+			fmt.Printf("Error repairing cached participants: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// WithRequestID returns a context tagging the calls made with it with a
+// request or correlation ID, for tracing one score change from the game
+// server to downstream consumers. Score writes record it on the
+// participant's item as last_request_id, and it is passed on in
+// ScoreUpdate.RequestID to update hooks, in cache updates sent to other
+// regions, in outbox events, and at the start of the library's log lines.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return utils.WithRequestID(ctx, requestID)
+}
+
+// RequestIDFromContext returns the request ID set with WithRequestID, empty
+// if none
+func RequestIDFromContext(ctx context.Context) string {
+	return utils.RequestID(ctx)
+}
@@ -0,0 +1,85 @@
+package leaderboard
+
+import (
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// ResponseVersion is the version of the response types' JSON shape. It only
+// changes when a field is removed or changes meaning.
+const ResponseVersion = "1"
+
+// RankedEntryV1 is a participant's standing in a response
+type RankedEntryV1 struct {
+	ClientID     string  `json:"clientId"`
+	UserID       string  `json:"userId"`
+	Score        float64 `json:"score"`
+	Rank         int64   `json:"rank"`
+	PreviousRank int64   `json:"previousRank,omitempty"`
+	RankDelta    int64   `json:"rankDelta,omitempty"`
+	Tier         string  `json:"tier,omitempty"`
+	Ghost        bool    `json:"ghost,omitempty"`
+	DisplayScore string  `json:"displayScore,omitempty"`
+}
+
+// TopNResponse is the versioned response of a top-N read, with stable JSON
+// field names that can be passed through to external clients
+type TopNResponse struct {
+	Version       string          `json:"version"`
+	LeaderboardID string          `json:"leaderboardId"`
+	Entries       []RankedEntryV1 `json:"entries"`
+}
+
+// RankResponse is the versioned response of a single participant's score
+// and rank
+type RankResponse struct {
+	Version       string        `json:"version"`
+	LeaderboardID string        `json:"leaderboardId"`
+	Entry         RankedEntryV1 `json:"entry"`
+}
+
+// NewTopNResponse builds the response for entries read from the helper's
+// leaderboard
+func (l *IndividualLeaderboardHelper) NewTopNResponse(entries []customTypes.MemberScore) *TopNResponse {
+	response := &TopNResponse{
+		Version:       ResponseVersion,
+		LeaderboardID: l.leaderboardID,
+		Entries:       make([]RankedEntryV1, len(entries)),
+	}
+	for i, entry := range entries {
+		response.Entries[i] = l.rankedEntry(entry)
+	}
+
+	return response
+}
+
+// NewRankResponse builds the response for a participant read from the
+// helper's leaderboard
+func (l *IndividualLeaderboardHelper) NewRankResponse(entry *customTypes.MemberScore) *RankResponse {
+	return &RankResponse{
+		Version:       ResponseVersion,
+		LeaderboardID: l.leaderboardID,
+		Entry:         l.rankedEntry(*entry),
+	}
+}
+
+// rankedEntry converts a member's standing, splitting the member into its
+// client and user IDs
+func (l *IndividualLeaderboardHelper) rankedEntry(entry customTypes.MemberScore) RankedEntryV1 {
+	clientID, userID, err := l.namespacer.Split(entry.Member)
+	if err != nil {
+		// Members written outside the namespace scheme are passed as is
+		userID = entry.Member
+	}
+
+	return RankedEntryV1{
+		ClientID:     clientID,
+		UserID:       userID,
+		Score:        entry.Score,
+		Rank:         entry.Rank,
+		PreviousRank: entry.PreviousRank,
+		RankDelta:    entry.RankDelta,
+		Tier:         entry.Tier,
+		Ghost:        entry.Ghost,
+		DisplayScore: entry.DisplayScore,
+	}
+}
@@ -0,0 +1,77 @@
+package leaderboard
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// ResultTransformer rewrites each standing returned by the read APIs, and
+// so by the response types built from them, e.g. to fill DisplayScore or to
+// mask members. It runs after ghosts are marked and must not block.
+type ResultTransformer func(ctx context.Context, entry *customTypes.MemberScore)
+
+// scoreSuffixes are the abbreviations AbbreviateScores uses by power of
+// a thousand
+var scoreSuffixes = []string{"", "K", "M", "B", "T"}
+
+// AbbreviateScores returns a transformer setting DisplayScore to the score
+// abbreviated with K, M, B or T and at most decimals decimal places, e.g.
+// 1234567890 as "1.2B" with one decimal
+func AbbreviateScores(decimals int) ResultTransformer {
+	return func(ctx context.Context, entry *customTypes.MemberScore) {
+		entry.DisplayScore = abbreviateScore(entry.Score, decimals)
+	}
+}
+
+// abbreviateScore formats a score with the largest suffix keeping it at or
+// above one
+func abbreviateScore(score float64, decimals int) string {
+	scaled := score
+	suffix := 0
+	for math.Abs(scaled) >= 1000 && suffix < len(scoreSuffixes)-1 {
+		scaled /= 1000
+		suffix++
+	}
+
+	// Round down so 999.96K is not shown as 1000.0K
+	factor := math.Pow(10, float64(decimals))
+	scaled = math.Trunc(scaled*factor) / factor
+
+	return strconv.FormatFloat(scaled, 'f', -1, 64) + scoreSuffixes[suffix]
+}
+
+// transformResults applies the result transformers to entries in place,
+// then hides the fields the access policy keeps from the caller
+func (l *IndividualLeaderboardHelper) transformResults(
+	ctx context.Context,
+	entries []customTypes.MemberScore,
+) {
+	visible := l.visibleFields(ctx)
+	for i := range entries {
+		for _, transform := range l.resultTransformers {
+			transform(ctx, &entries[i])
+		}
+		if visible != nil {
+			hideFields(&entries[i], visible)
+		}
+	}
+}
+
+// transformResult applies the result transformers and the access policy to
+// one entry
+func (l *IndividualLeaderboardHelper) transformResult(
+	ctx context.Context,
+	entry *customTypes.MemberScore,
+) *customTypes.MemberScore {
+	for _, transform := range l.resultTransformers {
+		transform(ctx, entry)
+	}
+	if visible := l.visibleFields(ctx); visible != nil {
+		hideFields(entry, visible)
+	}
+
+	return entry
+}
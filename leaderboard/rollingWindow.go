@@ -0,0 +1,56 @@
+package leaderboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// RefreshRollingWindow recomputes the rolling window view from the buckets
+// still inside the window. Run it on a schedule, at least once per bucket;
+// reads serve the view as of the last refresh. It fails with
+// ErrRollingWindowDisabled unless WithRollingWindow is set.
+func (l *IndividualLeaderboardHelper) RefreshRollingWindow(ctx context.Context) error {
+	return l.repo.RefreshRollingWindow(ctx, l.storageID, l.endTime())
+}
+
+// GetTopNRollingParticipants retrieves the top N participants by the scores
+// written in the rolling window
+func (l *IndividualLeaderboardHelper) GetTopNRollingParticipants(
+	ctx context.Context,
+	n int64,
+) ([]customTypes.MemberScore, error) {
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	participants, err := l.repo.GetTopNRollingParticipants(ctx, l.storageID, n, l.endTime())
+	if err != nil {
+		return nil, err
+	}
+
+	l.markGhosts(participants)
+	l.transformResults(ctx, participants)
+	return participants, nil
+}
+
+// GetTopVelocityParticipants retrieves the N participants whose score grew
+// the most over the trailing window, e.g. for daily trust and safety
+// review, with each gain as the score. It reads the rolling window's
+// buckets, so window is rounded to whole buckets, may not exceed the
+// rolling window and fails with ErrRollingWindowDisabled unless
+// WithRollingWindow is set. Standings visibility does not apply.
+func (l *IndividualLeaderboardHelper) GetTopVelocityParticipants(
+	ctx context.Context,
+	window time.Duration,
+	n int64,
+) ([]customTypes.MemberScore, error) {
+	participants, err := l.repo.GetTopVelocityParticipants(ctx, l.storageID, window, n)
+	if err != nil {
+		return nil, err
+	}
+
+	l.markGhosts(participants)
+	return participants, nil
+}
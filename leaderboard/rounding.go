@@ -0,0 +1,22 @@
+package leaderboard
+
+import (
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// RoundingMode decides how returned scores are rounded (see
+// WithDisplayPrecision)
+type RoundingMode = customTypes.RoundingMode
+
+const (
+	// RoundHalfAwayFromZero rounds to the nearest value, ties away from zero
+	RoundHalfAwayFromZero = customTypes.RoundHalfAwayFromZero
+	// RoundHalfEven rounds to the nearest value, ties to the even neighbour
+	RoundHalfEven = customTypes.RoundHalfEven
+	// RoundTowardZero truncates extra decimal places
+	RoundTowardZero = customTypes.RoundTowardZero
+	// RoundFloor rounds toward negative infinity
+	RoundFloor = customTypes.RoundFloor
+	// RoundCeil rounds toward positive infinity
+	RoundCeil = customTypes.RoundCeil
+)
@@ -0,0 +1,109 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// WriteRate summarises a leaderboard's recent score write rate
+type WriteRate = customTypes.WriteRate
+
+// ScalingThresholds decide when scaling hints recommend action
+type ScalingThresholds struct {
+	// ShardWritesPerSecond is the peak write rate above which a single
+	// sorted set becomes a hotspot and the leaderboard should be sharded
+	ShardWritesPerSecond float64
+	// Headroom multiplies the peak write rate when suggesting DynamoDB
+	// write capacity
+	Headroom float64
+	// Window is the period the peak write rate is taken over
+	Window time.Duration
+}
+
+// DefaultScalingThresholds recommends sharding above 5,000 writes per
+// second and suggests DynamoDB capacity with 50% headroom over the peak of
+// the last 15 minutes
+func DefaultScalingThresholds() ScalingThresholds {
+	return ScalingThresholds{
+		ShardWritesPerSecond: 5000,
+		Headroom:             1.5,
+		Window:               15 * time.Minute,
+	}
+}
+
+// ScalingHint is a capacity recommendation for a leaderboard
+type ScalingHint struct {
+	LeaderboardID string
+	Rate          WriteRate
+	// ShardRecommended is set once the peak write rate passes
+	// ScalingThresholds.ShardWritesPerSecond
+	ShardRecommended bool
+	// SuggestedWriteCapacity is the DynamoDB write capacity units the
+	// leaderboard's peak write rate needs, headroom included
+	SuggestedWriteCapacity int64
+}
+
+// ScalingHintFunc receives a scaling hint for a leaderboard
+type ScalingHintFunc func(ctx context.Context, hint ScalingHint)
+
+// GetWriteRate returns the leaderboard's score write rate over the complete
+// minutes of the window, which is capped at one hour. Writes are only
+// counted with WithScalingHints.
+func (l *IndividualLeaderboardHelper) GetWriteRate(
+	ctx context.Context,
+	window time.Duration,
+) (WriteRate, error) {
+	return l.repo.GetWriteRate(ctx, l.storageID, window, utils.GetCurrTimeStamp())
+}
+
+// GetScalingHint returns the leaderboard's current capacity recommendation
+func (l *IndividualLeaderboardHelper) GetScalingHint(ctx context.Context) (*ScalingHint, error) {
+	rate, err := l.GetWriteRate(ctx, l.scalingThresholds.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transactional writes to the lifetime leaderboard cost two items at
+	// twice the capacity
+	capacityPerWrite := 1.0
+	if l.lifetimeLeaderboard {
+		capacityPerWrite = 4
+	}
+
+	return &ScalingHint{
+		LeaderboardID:          l.leaderboardID,
+		Rate:                   rate,
+		ShardRecommended:       rate.Peak > l.scalingThresholds.ShardWritesPerSecond,
+		SuggestedWriteCapacity: int64(math.Ceil(rate.Peak * capacityPerWrite * l.scalingThresholds.Headroom)),
+	}, nil
+}
+
+// recordWrites counts applied score writes for the write rate, and hands
+// the scaling hint to the callback once per minute across all instances
+func (l *IndividualLeaderboardHelper) recordWrites(ctx context.Context, writes int64) {
+	if !l.scalingHints {
+		return
+	}
+
+	newMinute, err := l.repo.RecordWrites(ctx, l.storageID, writes, utils.GetCurrTimeStamp())
+	if err != nil {
+		// The write is durable, so only log; the rate is advisory
+		fmt.Printf("%sError recording write rate: %v\n", utils.LogPrefix(ctx), err)
+		return
+	}
+	if !newMinute || l.scalingHintFunc == nil {
+		return
+	}
+
+	hint, err := l.GetScalingHint(ctx)
+	if err != nil {
+		fmt.Printf("Error computing scaling hint: %v\n", err)
+		return
+	}
+	l.scalingHintFunc(ctx, *hint)
+}
@@ -0,0 +1,23 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+)
+
+// SchemaMigration upgrades a participant item by one schema version in
+// place, e.g. adding a field or renaming an attribute. Items created by
+// score updates may already have the new shape, so a migration must leave
+// such items unchanged.
+type SchemaMigration = repos.SchemaMigration
+
+// MigrateSchema migrates every participant item still at an older schema
+// version (see WithSchemaMigrations) and returns how many were migrated.
+// Items written to while the migration runs are left to their next read or
+// a later run. Writes are paced at the bulk write rate. Migrations that
+// change scores are only seen by the cached leaderboard once it is rebuilt,
+// e.g. with WarmCache.
+func (l *IndividualLeaderboardHelper) MigrateSchema(ctx context.Context) (int64, error) {
+	return l.repo.MigrateSchema(ctx, l.storageID)
+}
@@ -0,0 +1,279 @@
+package leaderboard
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+const (
+	// DefaultBatchSize is how many distinct users a ScoreBatcher collects
+	// before flushing
+	DefaultBatchSize = 50
+	// DefaultBatchFlushInterval is how often a ScoreBatcher flushes
+	// whatever it has collected
+	DefaultBatchFlushInterval = time.Second
+	// DefaultBatchRetries is how often a ScoreBatcher retries a failed batch
+	DefaultBatchRetries = 3
+	// DefaultBatchRetryBackoff is the delay before a ScoreBatcher's first
+	// retry, doubling with each further one
+	DefaultBatchRetryBackoff = 100 * time.Millisecond
+)
+
+// ErrBatcherFull is returned by ScoreBatcher.Add when MaxPending users
+// already wait to be flushed
+var ErrBatcherFull = errors.New("score batcher is full")
+
+// BatchFailurePolicy decides what a ScoreBatcher does with a batch that
+// still fails once its retries are used up
+type BatchFailurePolicy int
+
+const (
+	// BatchRequeue keeps the batch's deltas to be flushed again, merged with
+	// anything added meanwhile
+	BatchRequeue BatchFailurePolicy = iota
+	// BatchDrop discards the batch's deltas after reporting them to OnDrop
+	BatchDrop
+)
+
+// ScoreBatchWriter applies coalesced score deltas keyed by userID, as
+// IndividualLeaderboardHelper.UpdateScores does. Every attempt at a batch
+// is made with a context carrying the same idempotency token, which
+// IndividualLeaderboardHelper passes on to DynamoDB.
+type ScoreBatchWriter interface {
+	UpdateScores(ctx context.Context, deltas map[string]float64) (map[string]customTypes.MemberScore, error)
+}
+
+var _ ScoreBatchWriter = (*IndividualLeaderboardHelper)(nil)
+
+// ScoreBatcherOptions tune when a ScoreBatcher flushes and how it handles
+// failures. Zero fields use the defaults.
+type ScoreBatcherOptions struct {
+	// BatchSize is how many distinct users trigger a flush and the most
+	// written in one batch, at most MaxBatchUpdateSize
+	BatchSize int
+	// FlushInterval is how often Run flushes whatever has been collected
+	FlushInterval time.Duration
+	// Retries is how often a failed batch is retried. Negative never
+	// retries. Rejections such as invalid scores are never retried.
+	Retries int
+	// RetryBackoff is the delay before the first retry, doubling with each
+	// further one
+	RetryBackoff time.Duration
+	// FailurePolicy decides what happens to a batch that keeps failing
+	FailurePolicy BatchFailurePolicy
+	// MaxPending caps how many distinct users wait to be flushed, beyond
+	// which Add fails with ErrBatcherFull. Zero never caps.
+	MaxPending int
+	// OnDrop is told about every batch discarded under BatchDrop, and about
+	// rejected batches under either policy
+	OnDrop func(deltas map[string]float64, err error)
+}
+
+// ScoreBatcher coalesces the score deltas game servers submit at high
+// frequency, summing each user's deltas, and writes them in batches with
+// UpdateScores once BatchSize users are waiting or FlushInterval passes.
+// Added deltas are not visible on the leaderboard before then. It is safe
+// for concurrent use.
+type ScoreBatcher struct {
+	writer  ScoreBatchWriter
+	options ScoreBatcherOptions
+
+	mu      sync.Mutex
+	pending map[string]float64
+	full    chan struct{}
+
+	// flushMu keeps flushes from running concurrently
+	flushMu sync.Mutex
+}
+
+// NewScoreBatcher creates a batcher writing to writer, usually a
+// leaderboard helper. Call Run to flush it in the background.
+func NewScoreBatcher(writer ScoreBatchWriter, options ScoreBatcherOptions) *ScoreBatcher {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+	options.BatchSize = min(options.BatchSize, MaxBatchUpdateSize)
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = DefaultBatchFlushInterval
+	}
+	if options.Retries == 0 {
+		options.Retries = DefaultBatchRetries
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = DefaultBatchRetryBackoff
+	}
+
+	return &ScoreBatcher{
+		writer:  writer,
+		options: options,
+		pending: make(map[string]float64),
+		full:    make(chan struct{}, 1),
+	}
+}
+
+// Add queues a score delta for the user, summed with the user's other
+// pending deltas. It never waits on the stores.
+func (b *ScoreBatcher) Add(userID string, delta float64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.pending[userID]; !ok {
+		if b.options.MaxPending > 0 && len(b.pending) >= b.options.MaxPending {
+			return ErrBatcherFull
+		}
+	}
+	b.pending[userID] += delta
+
+	// Wake Run without waiting for it
+	if len(b.pending) >= b.options.BatchSize {
+		select {
+		case b.full <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Pending returns how many distinct users wait to be flushed
+func (b *ScoreBatcher) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.pending)
+}
+
+// Flush writes everything collected so far in batches of BatchSize,
+// retrying failed batches and then applying the failure policy. The
+// returned error joins the failures of batches that were not written.
+func (b *ScoreBatcher) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	// Take the pending deltas so writes do not hold up Add
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = make(map[string]float64)
+	b.mu.Unlock()
+
+	userIDs := make([]string, 0, len(pending))
+	for userID := range pending {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	var errs []error
+	for start := 0; start < len(userIDs); start += b.options.BatchSize {
+		end := min(start+b.options.BatchSize, len(userIDs))
+		batch := make(map[string]float64, end-start)
+		for _, userID := range userIDs[start:end] {
+			batch[userID] = pending[userID]
+		}
+
+		if err := b.writeBatch(ctx, batch); err != nil {
+			errs = append(errs, fmt.Errorf(
+				"failed to flush scores of %d users: %w",
+				len(batch),
+				err,
+			))
+			b.handleFailure(batch, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// writeBatch writes one batch, retrying failures that may pass on retry.
+// Every attempt carries the same idempotency token, so a retry of a write
+// DynamoDB applied but whose reply was lost does not credit it twice.
+func (b *ScoreBatcher) writeBatch(ctx context.Context, batch map[string]float64) error {
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return fmt.Errorf(
+			"failed to generate batch token: %w",
+			err,
+		)
+	}
+	ctx = utils.WithWriteToken(ctx, hex.EncodeToString(tokenBytes), utils.GetCurrTimeStamp())
+
+	backoff := b.options.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		_, err := b.writer.UpdateScores(ctx, batch)
+		if err == nil || rejectedBatch(err) || attempt >= b.options.Retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// handleFailure requeues or drops a batch that could not be written.
+// Rejected batches would fail again, so they are always dropped.
+func (b *ScoreBatcher) handleFailure(batch map[string]float64, err error) {
+	if b.options.FailurePolicy == BatchRequeue && !rejectedBatch(err) {
+		b.mu.Lock()
+		for userID, delta := range batch {
+			b.pending[userID] += delta
+		}
+		b.mu.Unlock()
+		return
+	}
+
+	if b.options.OnDrop != nil {
+		b.options.OnDrop(batch, err)
+	}
+}
+
+// rejectedBatch reports whether a batch failed for a reason retrying
+// cannot fix
+func rejectedBatch(err error) bool {
+	var invalidID *InvalidIDError
+	return errors.Is(err, ErrInvalidScore) ||
+		errors.Is(err, ErrInvalidUserID) ||
+		errors.Is(err, ErrClientMismatch) ||
+		errors.Is(err, ErrClientRequired) ||
+		errors.Is(err, ErrEventOutsideWindow) ||
+		errors.Is(err, ErrParticipantFrozen) ||
+		errors.Is(err, ErrAnomalousUpdate) ||
+		errors.Is(err, ErrBatchTooLarge) ||
+		errors.Is(err, ErrDuplicateBatchEntry) ||
+		errors.As(err, &invalidID)
+}
+
+// Run flushes once per FlushInterval, or as soon as BatchSize users are
+// waiting, until ctx is cancelled, then flushes a last time
+func (b *ScoreBatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(b.options.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := b.Flush(context.WithoutCancel(ctx)); err != nil {
+				fmt.Printf("Error flushing score batch: %v\n", err)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		case <-b.full:
+		}
+
+		if err := b.Flush(ctx); err != nil {
+			// Failed batches were requeued or dropped, so only log
+			fmt.Printf("Error flushing score batch: %v\n", err)
+		}
+	}
+}
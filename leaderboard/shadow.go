@@ -0,0 +1,129 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// StandingsComparison compares the top of a live leaderboard with a shadow
+// leaderboard
+type StandingsComparison = customTypes.StandingsComparison
+
+// ComparedStanding is a participant's standing on the live and shadow
+// leaderboards
+type ComparedStanding = customTypes.ComparedStanding
+
+// ShadowFormula computes the score delta a shadow leaderboard takes for an
+// update applied to the live leaderboard
+type ShadowFormula func(update ScoreUpdate) float64
+
+// shadowReadsKey marks contexts of reads allowed on shadow leaderboards
+type shadowReadsKey struct{}
+
+// ShadowReads returns a context whose reads are served by leaderboards in
+// shadow mode (see WithShadowMode)
+func ShadowReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, shadowReadsKey{}, true)
+}
+
+// shadowReads reports whether ctx was made by ShadowReads
+func shadowReads(ctx context.Context) bool {
+	enabled, _ := ctx.Value(shadowReadsKey{}).(bool)
+	return enabled
+}
+
+// ShadowMode reports whether the leaderboard only serves reads made with
+// ShadowReads
+func (l *IndividualLeaderboardHelper) ShadowMode() bool {
+	l.visibility.mu.RLock()
+	defer l.visibility.mu.RUnlock()
+
+	return l.visibility.shadow
+}
+
+// SetShadowMode switches shadow mode on or off for this helper, e.g. to
+// promote a shadow leaderboard once its results have been compared. Other
+// instances keep the mode they were created with.
+func (l *IndividualLeaderboardHelper) SetShadowMode(shadow bool) {
+	l.visibility.mu.Lock()
+	defer l.visibility.mu.Unlock()
+
+	l.visibility.shadow = shadow
+}
+
+// MirrorToShadow returns an after update hook that applies every successful
+// update of the leaderboard it is added to onto shadow as well, with the
+// delta computed by formula, so a new scoring formula can run alongside the
+// live one. Shadow writes that fail are logged and do not affect the live
+// update.
+func MirrorToShadow(shadow *IndividualLeaderboardHelper, formula ShadowFormula) AfterUpdateHook {
+	return func(ctx context.Context, update ScoreUpdate, err error) {
+		if err != nil {
+			return
+		}
+
+		if _, err := shadow.UpdateScore(ctx, update.NamespacedUserID, formula(update)); err != nil {
+			fmt.Printf("%sError mirroring score update to shadow leaderboard: %v\n", utils.LogPrefix(ctx), err)
+		}
+	}
+}
+
+// CompareStandings compares the top n participants of a live leaderboard
+// with those of a shadow leaderboard, for evaluating a scoring change
+// before switching to it. The shadow is read with ShadowReads.
+func CompareStandings(
+	ctx context.Context,
+	live *IndividualLeaderboardHelper,
+	shadow *IndividualLeaderboardHelper,
+	n int64,
+) (*StandingsComparison, error) {
+	liveEntries, err := live.GetTopNParticipants(ctx, n)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read live standings: %w",
+			err,
+		)
+	}
+	shadowEntries, err := shadow.GetTopNParticipants(ShadowReads(ctx), n)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read shadow standings: %w",
+			err,
+		)
+	}
+
+	comparison := &StandingsComparison{}
+	index := make(map[string]int, len(liveEntries))
+	for _, entry := range liveEntries {
+		index[entry.Member] = len(comparison.Standings)
+		comparison.Standings = append(comparison.Standings, ComparedStanding{
+			Member:    entry.Member,
+			LiveRank:  entry.Rank,
+			LiveScore: entry.Score,
+		})
+	}
+	for _, entry := range shadowEntries {
+		i, ok := index[entry.Member]
+		if !ok {
+			comparison.Standings = append(comparison.Standings, ComparedStanding{
+				Member:      entry.Member,
+				ShadowRank:  entry.Rank,
+				ShadowScore: entry.Score,
+			})
+			continue
+		}
+
+		standing := &comparison.Standings[i]
+		standing.ShadowRank = entry.Rank
+		standing.ShadowScore = entry.Score
+		comparison.Overlap++
+		if standing.LiveRank == standing.ShadowRank {
+			comparison.Unchanged++
+		}
+	}
+
+	return comparison, nil
+}
@@ -0,0 +1,229 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// SkillBracket groups joiners rated at least MinRating, up to the next
+// bracket's MinRating, into a sub-board of their own
+type SkillBracket struct {
+	Name      string
+	MinRating float64
+}
+
+// skillBrackets assigns joiners to brackets and remembers the bracket of
+// each member seen, shared by every copy of a helper
+type skillBrackets struct {
+	brackets []SkillBracket
+
+	mu      sync.RWMutex
+	members map[string]string
+}
+
+// newSkillBrackets sorts the brackets by MinRating, or returns nil when
+// there are none
+func newSkillBrackets(brackets []SkillBracket) *skillBrackets {
+	if len(brackets) == 0 {
+		return nil
+	}
+
+	sorted := append([]SkillBracket(nil), brackets...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].MinRating < sorted[j].MinRating
+	})
+
+	return &skillBrackets{
+		brackets: sorted,
+		members:  make(map[string]string),
+	}
+}
+
+// forRating returns the highest bracket whose MinRating the rating meets,
+// or the lowest bracket for ratings below all of them
+func (b *skillBrackets) forRating(rating float64) SkillBracket {
+	bracket := b.brackets[0]
+	for _, candidate := range b.brackets[1:] {
+		if rating >= candidate.MinRating {
+			bracket = candidate
+		}
+	}
+
+	return bracket
+}
+
+// bracketLeaderboard returns the helper of a skill bracket's sub-board.
+// Updates reach it already screened and hooked by the main board, so it
+// runs none of its own; it shares the main board's visibility.
+func (l *IndividualLeaderboardHelper) bracketLeaderboard(name string) *IndividualLeaderboardHelper {
+	bracket := *l
+	bracket.leaderboardID = l.leaderboardID + ":bracket:" + name
+	bracket.parentLeaderboardID = l.leaderboardID
+	bracket.storageID = l.storageID + ":bracket:" + name
+	bracket.beforeUpdateHooks = nil
+	bracket.afterUpdateHooks = nil
+	bracket.metadataResolver = nil
+	bracket.anomalyDetector = nil
+	bracket.quarantine = false
+	bracket.outbox = false
+	bracket.changeNotifications = false
+	bracket.lifetimeLeaderboard = false
+	bracket.rankCache = nil
+	bracket.escrow = nil
+	bracket.brackets = nil
+
+	return &bracket
+}
+
+// JoinLeaderboardWithRating adds one of the helper's client's users to the
+// leaderboard, as JoinLeaderboard does, and to the sub-board of the skill
+// bracket their rating falls in (see WithSkillBrackets). It returns the
+// bracket joined.
+func (l *IndividualLeaderboardHelper) JoinLeaderboardWithRating(
+	ctx context.Context,
+	userID string,
+	initialScore float64,
+	rating float64,
+) (string, error) {
+	if l.brackets == nil {
+		return "", ErrSkillBracketsDisabled
+	}
+
+	participant, err := l.join(ctx, userID, initialScore, l.brackets.forRating(rating).Name)
+	if err != nil {
+		return "", err
+	}
+
+	// An idempotent rejoin keeps the bracket the user first joined
+	l.brackets.mu.Lock()
+	delete(l.brackets.members, participant.NamespacedUserID)
+	l.brackets.mu.Unlock()
+	bracket, err := l.memberBracket(ctx, participant.NamespacedUserID)
+	if err != nil {
+		return "", err
+	}
+	if bracket == "" {
+		return "", ErrAlreadyJoined
+	}
+
+	// A retried join finds the sub-board already joined
+	bracketHelper := l.bracketLeaderboard(bracket)
+	bracketParticipant := *participant
+	bracketParticipant.LeaderboardID = bracketHelper.storageID
+	bracketParticipant.SkillBracket = ""
+	bracketParticipant.EscrowHoldID = ""
+	bracketParticipant.EscrowState = ""
+	err = l.repo.JoinLeaderboard(ctx, &bracketParticipant, l.endTime())
+	if err != nil && !errors.Is(err, ErrAlreadyJoined) {
+		return "", fmt.Errorf(
+			"failed to join skill bracket %s: %w",
+			bracket,
+			err,
+		)
+	}
+
+	return bracket, nil
+}
+
+// SkillBracket returns the skill bracket one of the helper's client's users
+// joined, or ErrParticipantNotFound for users without one
+func (l *IndividualLeaderboardHelper) SkillBracket(
+	ctx context.Context,
+	userID string,
+) (string, error) {
+	if l.brackets == nil {
+		return "", ErrSkillBracketsDisabled
+	}
+
+	namespacedUserID, err := l.memberID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	bracket, err := l.memberBracket(ctx, namespacedUserID)
+	if err != nil {
+		return "", err
+	}
+	if bracket == "" {
+		return "", ErrParticipantNotFound
+	}
+
+	return bracket, nil
+}
+
+// GetMyBracketTopN retrieves the top N participants of the skill bracket
+// one of the helper's client's users joined, ranked within the bracket
+func (l *IndividualLeaderboardHelper) GetMyBracketTopN(
+	ctx context.Context,
+	userID string,
+	n int64,
+) ([]customTypes.MemberScore, error) {
+	bracket, err := l.SkillBracket(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.bracketLeaderboard(bracket).GetTopNParticipants(ctx, n)
+}
+
+// memberBracket returns a member's skill bracket, reading it from the
+// member's item the first time, or "" if the member has none
+func (l *IndividualLeaderboardHelper) memberBracket(
+	ctx context.Context,
+	namespacedUserID string,
+) (string, error) {
+	l.brackets.mu.RLock()
+	bracket, ok := l.brackets.members[namespacedUserID]
+	l.brackets.mu.RUnlock()
+	if ok {
+		return bracket, nil
+	}
+
+	participant, err := l.repo.GetParticipant(ctx, l.storageID, namespacedUserID)
+	if errors.Is(err, ErrParticipantNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	l.brackets.mu.Lock()
+	l.brackets.members[namespacedUserID] = participant.SkillBracket
+	l.brackets.mu.Unlock()
+
+	return participant.SkillBracket, nil
+}
+
+// creditBracket applies a score update of the main board to the member's
+// skill bracket sub-board. Failures are logged and do not affect the main
+// board's update.
+func (l *IndividualLeaderboardHelper) creditBracket(
+	ctx context.Context,
+	namespacedUserID string,
+	scoreDelta float64,
+) {
+	if l.brackets == nil {
+		return
+	}
+
+	bracket, err := l.memberBracket(ctx, namespacedUserID)
+	if err == nil && bracket != "" {
+		_, err = l.repo.UpdateScore(
+			ctx,
+			l.bracketLeaderboard(bracket).storageID,
+			namespacedUserID,
+			scoreDelta,
+			nil,
+			l.endTime(),
+		)
+	}
+	if err != nil {
+		fmt.Printf("%sError crediting skill bracket: %v\n", utils.LogPrefix(ctx), err)
+	}
+}
@@ -0,0 +1,87 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+)
+
+// DefaultSnapshotTableName is the DynamoDB table holding standings
+// snapshots unless WithSnapshots names another
+const DefaultSnapshotTableName = repos.DefaultSnapshotTableName
+
+// SnapshotDiff compares two standings snapshots
+type SnapshotDiff = customTypes.SnapshotDiff
+
+// Mover is a participant's standing in two snapshots
+type Mover = customTypes.Mover
+
+// TakeStandingsSnapshot stores the leaderboard's full standings, compressed,
+// and returns when they were taken
+func (l *IndividualLeaderboardHelper) TakeStandingsSnapshot(ctx context.Context) (time.Time, error) {
+	return l.repo.TakeStandingsSnapshot(ctx, l.storageID, l.endTime())
+}
+
+// RunSnapshots takes a standings snapshot once per interval, e.g. daily,
+// until ctx is cancelled. Only one instance snapshots per interval, so every
+// instance may run it.
+func (l *IndividualLeaderboardHelper) RunSnapshots(
+	ctx context.Context,
+	interval time.Duration,
+) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		claimed, err := l.repo.ClaimStandingsSnapshot(ctx, l.storageID, interval)
+		if err != nil {
+			// The next round tries again, so only log
+			fmt.Printf("Error claiming standings snapshot: %v\n", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if _, err := l.TakeStandingsSnapshot(ctx); err != nil {
+			// The next round takes a fresh snapshot, so only log
+			fmt.Printf("Error taking standings snapshot: %v\n", err)
+		}
+	}
+}
+
+// DiffSnapshots compares the latest snapshots taken at or before t1 and t2
+// and returns who climbed, fell or entered the standings in between, e.g.
+// for a "top movers today" feature. It returns ErrSnapshotNotFound when no
+// snapshot was taken at or before either time.
+func (l *IndividualLeaderboardHelper) DiffSnapshots(
+	ctx context.Context,
+	t1 time.Time,
+	t2 time.Time,
+) (*SnapshotDiff, error) {
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	diff, err := l.repo.DiffSnapshots(ctx, l.storageID, t1, t2)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, movers := range [][]Mover{diff.Climbers, diff.Fallers, diff.Entered} {
+		for i := range movers {
+			clientID, _, err := l.namespacer.Split(movers[i].Member)
+			movers[i].Ghost = err == nil && clientID == GhostClientID
+		}
+	}
+
+	return diff, nil
+}
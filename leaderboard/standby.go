@@ -0,0 +1,239 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+	"github.com/redis/go-redis/v9"
+)
+
+// standbyWrites are the commands mirrored to the standby. Lua scripts are
+// handled separately.
+var standbyWrites = map[string]bool{
+	"set": true, "setnx": true, "setex": true, "psetex": true, "getset": true, "getdel": true,
+	"del": true, "unlink": true, "rename": true, "renamenx": true, "copy": true,
+	"expire": true, "pexpire": true, "expireat": true, "pexpireat": true, "persist": true,
+	"incr": true, "incrby": true, "incrbyfloat": true, "decr": true, "decrby": true,
+	"hset": true, "hsetnx": true, "hmset": true, "hdel": true, "hincrby": true, "hincrbyfloat": true,
+	"zadd": true, "zincrby": true, "zrem": true, "zremrangebyrank": true, "zremrangebyscore": true,
+	"zremrangebylex": true, "zunionstore": true, "zinterstore": true, "zdiffstore": true,
+	"zrangestore": true, "zpopmin": true, "zpopmax": true,
+	"sadd": true, "srem": true, "spop": true,
+	"lpush": true, "rpush": true, "lpop": true, "rpop": true, "ltrim": true, "lrem": true,
+}
+
+// RedisStandby keeps a second Redis cluster warm by mirroring every write
+// to it, so losing the primary cache does not force a rebuild from
+// DynamoDB under full load: call Failover and every helper configured with
+// WithRedisStandby switches to the standby at once. Mirrored writes add a
+// standby round trip to each write. Writes the standby misses while it is
+// unreachable are counted by MirrorErrors; rebuild it (e.g. with WarmCache
+// after Failover) before relying on it. Change notifications keep their
+// Redis connection across a failover. Tenants a TenantRouter selects a
+// RedisDB for are mirrored to the same database of the standby. It is safe
+// for concurrent use.
+type RedisStandby struct {
+	client       *redis.Client
+	failedOver   atomic.Bool
+	mirrorErrors atomic.Int64
+
+	mu        sync.Mutex
+	dbClients map[int]*redis.Client
+}
+
+// NewRedisStandby creates a standby on client, initially receiving
+// mirrored writes
+func NewRedisStandby(client *redis.Client) *RedisStandby {
+	return &RedisStandby{client: client}
+}
+
+// Failover sends every command to the standby instead of the primary
+func (s *RedisStandby) Failover() {
+	s.failedOver.Store(true)
+}
+
+// Failback returns to the primary, mirroring writes to the standby again.
+// Writes made while failed over are not copied back.
+func (s *RedisStandby) Failback() {
+	s.failedOver.Store(false)
+}
+
+// FailedOver reports whether commands go to the standby
+func (s *RedisStandby) FailedOver() bool {
+	return s.failedOver.Load()
+}
+
+// MirrorErrors returns how many mirrored writes or pipelines failed
+func (s *RedisStandby) MirrorErrors() int64 {
+	return s.mirrorErrors.Load()
+}
+
+// hook returns the Redis hook mirroring writes and redirecting commands
+func (s *RedisStandby) hook() redis.Hook {
+	return standbyHook{standby: s}
+}
+
+// clientFor returns the standby client for database db of the primary.
+// Zero is the database the standby was created on; the others get a
+// connection pool of their own on the same server.
+func (s *RedisStandby) clientFor(db int) *redis.Client {
+	if db == 0 {
+		return s.client
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if client, ok := s.dbClients[db]; ok {
+		return client
+	}
+	options := *s.client.Options()
+	options.DB = db
+	client := redis.NewClient(&options)
+	if s.dbClients == nil {
+		s.dbClients = make(map[int]*redis.Client)
+	}
+	s.dbClients[db] = client
+
+	return client
+}
+
+// dbHook is a Redis hook depending on the database its client selects
+type dbHook interface {
+	redis.Hook
+	// forDB returns the hook for a client on database db
+	forDB(db int) redis.Hook
+}
+
+// hooksForDB returns hooks for a client on database db
+func hooksForDB(hooks []redis.Hook, db int) []redis.Hook {
+	selected := make([]redis.Hook, len(hooks))
+	for i, hook := range hooks {
+		selected[i] = hook
+		if hook, ok := hook.(dbHook); ok {
+			selected[i] = hook.forDB(db)
+		}
+	}
+
+	return selected
+}
+
+// mirrorArgs returns the arguments replaying a command on the standby,
+// reporting false for commands that are not mirrored. Scripts run by hash
+// are replayed from source, since the standby may not have them cached.
+func mirrorArgs(cmd redis.Cmder) ([]interface{}, bool) {
+	args := cmd.Args()
+	switch name := cmd.Name(); name {
+	case "evalsha":
+		sha, _ := args[1].(string)
+		source, readOnly, ok := repos.ScriptSource(sha)
+		if !ok || readOnly {
+			return nil, false
+		}
+		return append([]interface{}{"eval", source}, args[2:]...), true
+	case "eval":
+		source, _ := args[1].(string)
+		if _, readOnly, ok := repos.ScriptSource(repos.ScriptHash(source)); ok && readOnly {
+			return nil, false
+		}
+		return args, true
+	default:
+		return args, standbyWrites[name]
+	}
+}
+
+// applied reports whether a command ran on the primary
+func applied(cmd redis.Cmder) bool {
+	return cmd.Err() == nil || cmd.Err() == redis.Nil
+}
+
+// standbyHook mirrors writes to the standby, or redirects every command to
+// it once failed over, on the database db of its client
+type standbyHook struct {
+	standby *RedisStandby
+	db      int
+}
+
+var _ dbHook = standbyHook{}
+
+// forDB implements dbHook
+func (h standbyHook) forDB(db int) redis.Hook {
+	return standbyHook{standby: h.standby, db: db}
+}
+
+// DialHook implements redis.Hook
+func (h standbyHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook implements redis.Hook
+func (h standbyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		client := h.standby.clientFor(h.db)
+		if h.standby.FailedOver() {
+			return client.Process(ctx, cmd)
+		}
+
+		err := next(ctx, cmd)
+		if !applied(cmd) {
+			return err
+		}
+		if args, ok := mirrorArgs(cmd); ok {
+			if mirrorErr := client.Do(ctx, args...).Err(); mirrorErr != nil && mirrorErr != redis.Nil {
+				h.standby.mirrorErrors.Add(1)
+				fmt.Printf("Error mirroring %s to Redis standby: %v\n", cmd.Name(), mirrorErr)
+			}
+		}
+
+		return err
+	}
+}
+
+// ProcessPipelineHook implements redis.Hook. It serves both pipelines and
+// transactions, whose commands arrive wrapped in MULTI and EXEC.
+func (h standbyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		commands := cmds
+		tx := len(cmds) >= 2 && cmds[0].Name() == "multi" && cmds[len(cmds)-1].Name() == "exec"
+		if tx {
+			commands = cmds[1 : len(cmds)-1]
+		}
+		client := h.standby.clientFor(h.db)
+		newPipeline := client.Pipeline
+		if tx {
+			newPipeline = client.TxPipeline
+		}
+
+		if h.standby.FailedOver() {
+			pipe := newPipeline()
+			for _, cmd := range commands {
+				_ = pipe.Process(ctx, cmd)
+			}
+			_, err := pipe.Exec(ctx)
+			return err
+		}
+
+		err := next(ctx, cmds)
+
+		pipe := newPipeline()
+		for _, cmd := range commands {
+			if !applied(cmd) {
+				continue
+			}
+			if args, ok := mirrorArgs(cmd); ok {
+				pipe.Do(ctx, args...)
+			}
+		}
+		if pipe.Len() > 0 {
+			if _, mirrorErr := pipe.Exec(ctx); mirrorErr != nil && mirrorErr != redis.Nil {
+				h.standby.mirrorErrors.Add(1)
+				fmt.Printf("Error mirroring pipeline to Redis standby: %v\n", mirrorErr)
+			}
+		}
+
+		return err
+	}
+}
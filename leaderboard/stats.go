@@ -0,0 +1,30 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// defaultHistogramBuckets is the histogram resolution of GetLeaderboardStats
+const defaultHistogramBuckets = 10
+
+// GetLeaderboardStats returns the participant count, mean, median, min and
+// max score, and a 10-bucket score histogram. It reads every score to
+// compute the mean, so it is meant for dashboards rather than hot paths.
+func (l *IndividualLeaderboardHelper) GetLeaderboardStats(ctx context.Context) (*customTypes.LeaderboardStats, error) {
+	return l.GetLeaderboardStatsWithBuckets(ctx, defaultHistogramBuckets)
+}
+
+// GetLeaderboardStatsWithBuckets is GetLeaderboardStats with a custom
+// number of histogram buckets; zero omits the histogram
+func (l *IndividualLeaderboardHelper) GetLeaderboardStatsWithBuckets(
+	ctx context.Context,
+	buckets int,
+) (*customTypes.LeaderboardStats, error) {
+	if err := l.checkVisibility(ctx); err != nil {
+		return nil, err
+	}
+
+	return l.repo.GetLeaderboardStats(ctx, l.storageID, buckets, l.endTime())
+}
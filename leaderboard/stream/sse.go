@@ -0,0 +1,206 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+)
+
+// MessageTopNDelta carries the top N entries that changed since the
+// previous push to a server-sent events client
+const MessageTopNDelta = "topNDelta"
+
+// DefaultHeartbeat is how often SSEHandler writes a comment to keep idle
+// connections open through proxies
+const DefaultHeartbeat = 15 * time.Second
+
+// EntryID identifies a participant in a top N delta
+type EntryID struct {
+	ClientID string `json:"clientId"`
+	UserID   string `json:"userId"`
+}
+
+// TopNDelta lists the entries of the top N that were added or changed and
+// the participants that left it since the previous push
+type TopNDelta struct {
+	Version       string                      `json:"version"`
+	LeaderboardID string                      `json:"leaderboardId"`
+	Changed       []leaderboard.RankedEntryV1 `json:"changed,omitempty"`
+	Removed       []EntryID                   `json:"removed,omitempty"`
+}
+
+// SSEHandler streams a hub's leaderboard as server-sent events, a lighter
+// alternative to websockets for web clients behind restrictive proxies.
+// Each client receives a "topN" event with the full standings, then
+// "topNDelta" events with the changes, and "rank" events with its own score
+// and rank.
+type SSEHandler struct {
+	Hub *Hub
+	// Identify returns the namespaced user ID of the request's participant,
+	// or an empty ID to stream the standings alone to an anonymous caller.
+	// An error rejects the request with 401. Callers the leaderboard's
+	// visibility or access policy refuse are rejected with 403.
+	Identify func(r *http.Request) (string, error)
+	// Heartbeat is how often a comment is written to idle connections.
+	// Zero uses DefaultHeartbeat.
+	Heartbeat time.Duration
+}
+
+// ServeHTTP streams events until the client disconnects
+func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var member string
+	if h.Identify != nil {
+		var err error
+		member, err = h.Identify(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	conn := &sseConn{w: w, flusher: flusher, failed: cancel}
+
+	// Hold the connection's pushes until the headers are written
+	conn.mu.Lock()
+	detach, err := h.Hub.Attach(ctx, conn, member)
+	if err != nil {
+		conn.mu.Unlock()
+		status := http.StatusInternalServerError
+		if errors.Is(err, leaderboard.ErrAccessDenied) ||
+			errors.Is(err, leaderboard.ErrLeaderboardNotVisible) ||
+			errors.Is(err, leaderboard.ErrShadowLeaderboard) {
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	defer detach()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	conn.mu.Unlock()
+
+	heartbeat := h.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.writeRaw(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sseConn writes hub messages as server-sent events, turning top N pushes
+// after the first into deltas
+type sseConn struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	failed  context.CancelFunc
+
+	mu       sync.Mutex
+	lastTopN map[EntryID]leaderboard.RankedEntryV1
+}
+
+// WriteJSON writes a hub message as an event
+func (c *sseConn) WriteJSON(ctx context.Context, v interface{}) error {
+	message, ok := v.(Message)
+	if !ok {
+		return c.writeEvent("message", v)
+	}
+
+	switch message.Type {
+	case MessageTopN:
+		delta, first := c.topNDelta(message.TopN)
+		if first {
+			return c.writeEvent(MessageTopN, message.TopN)
+		}
+		if len(delta.Changed) == 0 && len(delta.Removed) == 0 {
+			return nil
+		}
+		return c.writeEvent(MessageTopNDelta, delta)
+	case MessageRank:
+		return c.writeEvent(MessageRank, message.Rank)
+	}
+
+	return c.writeEvent(message.Type, message)
+}
+
+// topNDelta diffs standings against the previous push and remembers them.
+// It reports whether these are the first standings pushed.
+func (c *sseConn) topNDelta(topN *leaderboard.TopNResponse) (*TopNDelta, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delta := &TopNDelta{
+		Version:       topN.Version,
+		LeaderboardID: topN.LeaderboardID,
+	}
+	current := make(map[EntryID]leaderboard.RankedEntryV1, len(topN.Entries))
+	for _, entry := range topN.Entries {
+		id := EntryID{ClientID: entry.ClientID, UserID: entry.UserID}
+		current[id] = entry
+		if previous, ok := c.lastTopN[id]; !ok || previous != entry {
+			delta.Changed = append(delta.Changed, entry)
+		}
+	}
+	for id := range c.lastTopN {
+		if _, ok := current[id]; !ok {
+			delta.Removed = append(delta.Removed, id)
+		}
+	}
+
+	first := c.lastTopN == nil
+	c.lastTopN = current
+	return delta, first
+}
+
+// writeEvent writes one event with a JSON payload
+func (c *sseConn) writeEvent(event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event, err)
+	}
+
+	return c.writeRaw(fmt.Sprintf("event: %s\ndata: %s\n\n", event, data))
+}
+
+// writeRaw writes to the response and flushes it, ending the stream on
+// failure
+func (c *sseConn) writeRaw(text string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := fmt.Fprint(c.w, text); err != nil {
+		c.failed()
+		return err
+	}
+	c.flusher.Flush()
+
+	return nil
+}
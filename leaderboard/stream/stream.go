@@ -0,0 +1,413 @@
+// Package stream fans live leaderboard updates out to client connections,
+// typically websockets. A Hub follows one leaderboard's top N through
+// SubscribeTopN and pushes it, along with each connection's personal rank,
+// to every attached connection, throttled per connection so slow or chatty
+// clients only ever receive the latest standings. Personal ranks are taken
+// from the top N where they appear there, and otherwise read again only when
+// a write changes the connection's own participant, as announced through
+// SubscribeParticipantChanges.
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard"
+)
+
+const (
+	// MessageTopN carries the leaderboard's top N
+	MessageTopN = "topN"
+	// MessageRank carries the connection's own score and rank
+	MessageRank = "rank"
+)
+
+// Message is written to connections as JSON
+type Message struct {
+	Type string                    `json:"type"`
+	TopN *leaderboard.TopNResponse `json:"topN,omitempty"`
+	Rank *leaderboard.RankResponse `json:"rank,omitempty"`
+}
+
+// Conn is a client connection messages are written to. Websocket libraries
+// are adapted with a small wrapper around their JSON writer.
+type Conn interface {
+	WriteJSON(ctx context.Context, v interface{}) error
+}
+
+// Config describes how a hub streams a leaderboard
+type Config struct {
+	// TopN is the number of leading participants streamed
+	TopN int64
+	// MinInterval is the shortest time between two pushes to a connection;
+	// changes in between are coalesced into the next push. Zero pushes
+	// every change.
+	MinInterval time.Duration
+	// WriteTimeout bounds each write to a connection. Connections whose
+	// writes fail are detached.
+	WriteTimeout time.Duration
+}
+
+// DefaultConfig streams the top 10 at most once a second per connection
+func DefaultConfig() Config {
+	return Config{
+		TopN:         10,
+		MinInterval:  time.Second,
+		WriteTimeout: 5 * time.Second,
+	}
+}
+
+// Hub streams one leaderboard to its attached connections. It is safe for
+// concurrent use.
+type Hub struct {
+	helper *leaderboard.IndividualLeaderboardHelper
+	cfg    Config
+
+	mu      sync.Mutex
+	entries []leaderboard.MemberScore
+	read    bool
+	conns   map[*connection]struct{}
+	closed  bool
+}
+
+// NewHub creates a hub for the helper's leaderboard. Writers to the
+// leaderboard must use leaderboard.WithChangeNotifications.
+func NewHub(helper *leaderboard.IndividualLeaderboardHelper, cfg Config) *Hub {
+	defaults := DefaultConfig()
+	if cfg.TopN <= 0 {
+		cfg.TopN = defaults.TopN
+	}
+	if cfg.MinInterval < 0 {
+		cfg.MinInterval = 0
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaults.WriteTimeout
+	}
+
+	return &Hub{
+		helper: helper,
+		cfg:    cfg,
+		conns:  make(map[*connection]struct{}),
+	}
+}
+
+// Run follows the leaderboard and pushes every change to the attached
+// connections until ctx is done, after which every connection is detached.
+// The standings are read once for every connection as a trusted caller;
+// each connection is authorized when attached and only receives the fields
+// the access policy shows its caller.
+func (h *Hub) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(leaderboard.WithTrustedCaller(ctx))
+	defer cancel()
+
+	changes, err := h.helper.SubscribeParticipantChanges(ctx)
+	if err != nil {
+		return err
+	}
+	standings, err := h.helper.SubscribeTopN(ctx, h.cfg.TopN)
+	if err != nil {
+		return err
+	}
+	defer h.close()
+
+	for {
+		select {
+		case entries, ok := <-standings:
+			if !ok {
+				return ctx.Err()
+			}
+			h.mu.Lock()
+			h.entries = entries
+			h.read = true
+			for conn := range h.conns {
+				conn.notify(true, false)
+			}
+			h.mu.Unlock()
+		case changed, ok := <-changes:
+			if !ok {
+				// Ranks outside the top N are no longer followed; keep
+				// streaming the standings
+				changes = nil
+				continue
+			}
+			h.changed(changed.Members)
+		}
+	}
+}
+
+// changed marks the connections of the given participants, or of all of
+// them when none are given, as having a rank to read again
+func (h *Hub) changed(members []string) {
+	changed := make(map[string]bool, len(members))
+	for _, member := range members {
+		changed[member] = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for conn := range h.conns {
+		if conn.member != "" && (len(members) == 0 || changed[conn.member]) {
+			conn.notify(false, true)
+		}
+	}
+}
+
+// Attach streams to conn until ctx is done, a write fails or the returned
+// function is called. namespacedUserID, if not empty, identifies the
+// connection's caller, as WithCaller, and also streams that participant's
+// own score and rank. Callers the leaderboard's visibility or access policy
+// refuse are not attached and get the error a read would fail with. The
+// current standings are pushed right away.
+func (h *Hub) Attach(
+	ctx context.Context,
+	conn Conn,
+	namespacedUserID string,
+) (func(), error) {
+	if namespacedUserID != "" {
+		ctx = leaderboard.WithCaller(ctx, namespacedUserID)
+	}
+	if err := h.helper.AuthorizeRead(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	c := &connection{
+		hub:    h,
+		conn:   conn,
+		member: namespacedUserID,
+		wake:   make(chan struct{}, 1),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		cancel()
+		return func() {}, nil
+	}
+	h.conns[c] = struct{}{}
+	c.notify(h.read, namespacedUserID != "")
+	h.mu.Unlock()
+
+	go func() {
+		defer h.detach(c)
+		c.run(ctx)
+	}()
+
+	return cancel, nil
+}
+
+// Connections returns the number of attached connections
+func (h *Hub) Connections() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.conns)
+}
+
+// latest returns the standings last read, and false if there are none yet
+func (h *Hub) latest() ([]leaderboard.MemberScore, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.entries, h.read
+}
+
+// detach removes a connection
+func (h *Hub) detach(c *connection) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.conns, c)
+}
+
+// close stops accepting connections and wakes the attached ones to stop
+func (h *Hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for conn := range h.conns {
+		conn.stop()
+	}
+}
+
+// connection is one attached client
+type connection struct {
+	hub      *Hub
+	conn     Conn
+	member   string
+	wake     chan struct{}
+	lastRank *leaderboard.RankedEntryV1
+	inTopN   bool
+
+	mu        sync.Mutex
+	stopped   bool
+	topNStale bool
+	rankStale bool
+}
+
+// notify marks the connection as having new standings, its participant's
+// rank to read again, or both, to push
+func (c *connection) notify(topN, rank bool) {
+	c.mu.Lock()
+	c.topNStale = c.topNStale || topN
+	c.rankStale = c.rankStale || rank
+	c.mu.Unlock()
+	c.wakeUp()
+}
+
+// wakeUp wakes the connection's stream without marking anything to push
+func (c *connection) wakeUp() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// stop ends the connection's stream after its current push
+func (c *connection) stop() {
+	c.mu.Lock()
+	c.stopped = true
+	c.mu.Unlock()
+	c.wakeUp()
+}
+
+// run pushes the latest standings whenever they change, at most once per
+// MinInterval
+func (c *connection) run(ctx context.Context) {
+	var lastPush time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.wake:
+		}
+
+		c.mu.Lock()
+		stopped := c.stopped
+		c.mu.Unlock()
+		if stopped {
+			return
+		}
+
+		// Hold back pushes that come too soon; changes meanwhile coalesce
+		if wait := c.hub.cfg.MinInterval - time.Since(lastPush); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		lastPush = time.Now()
+
+		if err := c.push(ctx); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				fmt.Printf("Error streaming leaderboard: %v\n", err)
+			}
+			return
+		}
+	}
+}
+
+// push writes the latest standings, if they changed, as the connection's
+// caller may see them and, if it changed, the connection's own rank
+func (c *connection) push(ctx context.Context) error {
+	entries, ok := c.hub.latest()
+	if !ok {
+		return nil
+	}
+
+	c.mu.Lock()
+	topNStale, rankStale := c.topNStale, c.rankStale
+	c.topNStale, c.rankStale = false, false
+	c.mu.Unlock()
+
+	topN := c.hub.helper.NewVisibleTopNResponse(ctx, entries)
+	if topNStale {
+		if err := c.write(ctx, Message{Type: MessageTopN, TopN: topN}); err != nil {
+			return err
+		}
+	}
+	if c.member == "" {
+		return nil
+	}
+
+	rank, err := c.rank(ctx, entries, topN, topNStale, rankStale)
+	if err != nil {
+		// Keep streaming the standings; the rank follows on the next push
+		fmt.Printf("Error reading participant rank: %v\n", err)
+		return nil
+	}
+	if rank == nil || (c.lastRank != nil && *c.lastRank == rank.Entry) {
+		return nil
+	}
+	if err := c.write(ctx, Message{Type: MessageRank, Rank: rank}); err != nil {
+		return err
+	}
+	c.lastRank = &rank.Entry
+
+	return nil
+}
+
+// rank returns the connection's own rank, or nil if it is unknown or was
+// not changed. Participants in the top N are ranked from it; the others
+// are read again only when a write changed them or they just dropped out
+// of it.
+func (c *connection) rank(
+	ctx context.Context,
+	entries []leaderboard.MemberScore,
+	topN *leaderboard.TopNResponse,
+	topNStale bool,
+	rankStale bool,
+) (*leaderboard.RankResponse, error) {
+	droppedOut := topNStale && c.inTopN
+	c.inTopN = false
+	for i, entry := range entries {
+		if entry.Member == c.member {
+			c.inTopN = true
+			return &leaderboard.RankResponse{
+				Version:       topN.Version,
+				LeaderboardID: topN.LeaderboardID,
+				Entry:         topN.Entries[i],
+			}, nil
+		}
+	}
+
+	if !rankStale && !droppedOut {
+		return nil, nil
+	}
+
+	// The rank is read on the member's behalf, as Attach identified the
+	// connection's caller
+	entry, err := c.hub.helper.GetParticipantScoreAndRank(ctx, c.member)
+	if errors.Is(err, leaderboard.ErrParticipantNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c.hub.helper.NewRankResponse(entry), nil
+}
+
+// write writes one message within the write timeout
+func (c *connection) write(ctx context.Context, message Message) error {
+	writeCtx, cancel := context.WithTimeout(ctx, c.hub.cfg.WriteTimeout)
+	defer cancel()
+
+	if err := c.conn.WriteJSON(writeCtx, message); err != nil {
+		return fmt.Errorf(
+			"failed to write %s message: %w",
+			message.Type,
+			err,
+		)
+	}
+
+	return nil
+}
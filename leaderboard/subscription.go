@@ -0,0 +1,203 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// SubscribeTopN streams the leaderboard's top n participants: the current
+// standings first, then the new standings whenever a write changes them,
+// until ctx is done. Writers must use WithChangeNotifications. Bursts of
+// writes are coalesced into one read, so a slow consumer only ever gets the
+// latest standings.
+func (l *IndividualLeaderboardHelper) SubscribeTopN(
+	ctx context.Context,
+	n int64,
+) (<-chan []customTypes.MemberScore, error) {
+	// Subscribe before the first read so no change is missed in between
+	pubsub, err := l.repo.SubscribeChanges(ctx, l.storageID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := l.GetTopNParticipants(ctx, n)
+	if err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+	if current == nil {
+		current = []customTypes.MemberScore{}
+	}
+
+	standings := make(chan []customTypes.MemberScore)
+	go func() {
+		defer close(standings)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		pending := current
+		for {
+			// Offer the latest unsent standings while watching for changes
+			var out chan []customTypes.MemberScore
+			if pending != nil {
+				out = standings
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- pending:
+				current, pending = pending, nil
+			case _, ok := <-messages:
+				if !ok {
+					return
+				}
+				drain(messages)
+
+				latest, err := l.GetTopNParticipants(ctx, n)
+				if err != nil {
+					// Log the error and wait for the next change
+					fmt.Printf("Error reading top participants: %v\n", err)
+					continue
+				}
+				pending = nil
+				if !sameStandings(latest, current) {
+					pending = append([]customTypes.MemberScore{}, latest...)
+				}
+			}
+		}
+	}()
+
+	return standings, nil
+}
+
+// SubscribeTopN streams the top n participants of one of the client's
+// leaderboards, as IndividualLeaderboardHelper.SubscribeTopN
+func (m *Manager) SubscribeTopN(
+	ctx context.Context,
+	leaderboardID string,
+	n int64,
+) (<-chan []customTypes.MemberScore, error) {
+	helper, err := m.Helper(ctx, leaderboardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.SubscribeTopN(ctx, n)
+}
+
+// ParticipantChanges names the participants writes to a leaderboard changed.
+// Changes without members may have changed any participant.
+type ParticipantChanges struct {
+	Members []string
+}
+
+// SubscribeParticipantChanges streams which participants writes to the
+// leaderboard changed until ctx is done, without reading the leaderboard.
+// Writers must use WithChangeNotifications. Bursts of writes are coalesced,
+// so a slow consumer gets every participant changed since its last receive
+// at once.
+func (l *IndividualLeaderboardHelper) SubscribeParticipantChanges(
+	ctx context.Context,
+) (<-chan ParticipantChanges, error) {
+	pubsub, err := l.repo.SubscribeChanges(ctx, l.storageID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan ParticipantChanges)
+	go func() {
+		defer close(changes)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		pending := make(map[string]struct{})
+		all := false
+		for {
+			// Offer the participants changed so far while watching for more
+			var out chan ParticipantChanges
+			var next ParticipantChanges
+			if all || len(pending) > 0 {
+				out = changes
+				if !all {
+					next.Members = make([]string, 0, len(pending))
+					for member := range pending {
+						next.Members = append(next.Members, member)
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- next:
+				pending = make(map[string]struct{})
+				all = false
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				members, named := repos.ChangedParticipants(message.Payload)
+				if !named {
+					all = true
+				}
+				for _, member := range members {
+					pending[member] = struct{}{}
+				}
+			}
+		}
+	}()
+
+	return changes, nil
+}
+
+// publishChanges announces a write of the given participants to subscribers
+// of the leaderboard and, when it was credited too, the lifetime
+// leaderboard. Writes that may have changed any participant name none.
+func (l *IndividualLeaderboardHelper) publishChanges(ctx context.Context, namespacedUserIDs ...string) {
+	if !l.changeNotifications {
+		return
+	}
+
+	storageIDs := []string{l.storageID}
+	if l.lifetimeLeaderboard {
+		storageIDs = append(storageIDs, lifetimeStorageID(l.clientID))
+	}
+	for _, storageID := range storageIDs {
+		if err := l.repo.PublishChange(ctx, storageID, namespacedUserIDs...); err != nil {
+			// The write is durable, so only log; subscribers catch up on
+			// the next change
+			fmt.Printf("%sError publishing leaderboard change: %v\n", utils.LogPrefix(ctx), err)
+		}
+	}
+}
+
+// drain discards the messages already queued on a channel
+func drain[T any](messages <-chan T) {
+	for {
+		select {
+		case <-messages:
+		default:
+			return
+		}
+	}
+}
+
+// sameStandings reports whether two standings list the same members with
+// the same scores and ranks
+func sameStandings(a, b []customTypes.MemberScore) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Member != b[i].Member || a[i].Score != b[i].Score || a[i].Rank != b[i].Rank {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,201 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/repos"
+	"github.com/redis/go-redis/v9"
+)
+
+// TenantStorage is where a client's leaderboards are stored. The zero value
+// is the shared default storage.
+type TenantStorage struct {
+	// TableName is the DynamoDB table holding the client's participants.
+	// Empty uses the table the router was configured with.
+	TableName string
+	// RedisKeyPrefix is prepended to every Redis key of the client's
+	// leaderboards, after the prefix the router was configured with
+	RedisKeyPrefix string
+	// RoleARN is an IAM role the client's DynamoDB requests are made as,
+	// assumed with the router's DynamoDB credentials. Scoping its policy to
+	// the client's table ensures the client's operations cannot touch any
+	// other tenant's data. Empty uses the router's credentials.
+	RoleARN string
+	// ExternalID is passed when assuming RoleARN, if the role's trust
+	// policy requires one
+	ExternalID string
+	// RedisDB selects a Redis database for the client on a connection pool
+	// of its own. Zero uses the router's Redis client as is. A standby
+	// configured with WithRedisStandby mirrors it to the same database.
+	RedisDB int
+}
+
+// TableResolver maps a clientID to the storage of its leaderboards, so
+// enterprise clients can be physically isolated from the shared table
+type TableResolver interface {
+	ResolveTable(ctx context.Context, clientID string) (TenantStorage, error)
+}
+
+// TableResolverFunc adapts a function to the TableResolver interface
+type TableResolverFunc func(ctx context.Context, clientID string) (TenantStorage, error)
+
+// ResolveTable calls f
+func (f TableResolverFunc) ResolveTable(
+	ctx context.Context,
+	clientID string,
+) (TenantStorage, error) {
+	return f(ctx, clientID)
+}
+
+// TenantRouter hands out helpers and managers whose storage is chosen per
+// clientID by a TableResolver. Each client's storage is resolved once. The
+// pending, outbox, rank history and snapshot tables stay shared. It is safe
+// for concurrent use.
+type TenantRouter struct {
+	dynamoClient *dynamodb.Client
+	redisClient  *redis.Client
+	resolver     TableResolver
+	options      *helperOptions
+
+	mu           sync.Mutex
+	repos        map[string]*repos.ParticipantRepo
+	redisClients map[int]*redis.Client
+}
+
+// NewTenantRouter creates a router building helpers with the given options
+// against the storage resolver reports for each client
+func NewTenantRouter(
+	dynamoClient *dynamodb.Client,
+	redisClient *redis.Client,
+	resolver TableResolver,
+	opts ...Option,
+) *TenantRouter {
+	return &TenantRouter{
+		dynamoClient: dynamoClient,
+		redisClient:  redisClient,
+		resolver:     resolver,
+		options:      applyOptions(opts),
+		repos:        make(map[string]*repos.ParticipantRepo),
+		redisClients: make(map[int]*redis.Client),
+	}
+}
+
+// repo returns the repo serving a client, resolving its storage on first
+// use. Failed lookups are not cached.
+func (t *TenantRouter) repo(
+	ctx context.Context,
+	clientID string,
+) (*repos.ParticipantRepo, error) {
+	t.mu.Lock()
+	repo, ok := t.repos[clientID]
+	t.mu.Unlock()
+	if ok {
+		return repo, nil
+	}
+
+	storage, err := t.resolver.ResolveTable(ctx, clientID)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to resolve storage of client %s: %w",
+			clientID,
+			err,
+		)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Another caller may have resolved the client meanwhile
+	if repo, ok := t.repos[clientID]; ok {
+		return repo, nil
+	}
+
+	config := t.options.repoConfig
+	if storage.TableName != "" {
+		config.TableName = storage.TableName
+	}
+	config.RedisKeyPrefix += storage.RedisKeyPrefix
+	if storage.RoleARN != "" {
+		config.AssumeRoleARN = storage.RoleARN
+		config.AssumeRoleExternalID = storage.ExternalID
+	}
+
+	redisClient := t.redisClient
+	if storage.RedisDB != 0 {
+		redisClient, ok = t.redisClients[storage.RedisDB]
+		if !ok {
+			redisOptions := *t.redisClient.Options()
+			redisOptions.DB = storage.RedisDB
+			redisClient = redis.NewClient(&redisOptions)
+			t.redisClients[storage.RedisDB] = redisClient
+		}
+		config.RedisHooks = hooksForDB(config.RedisHooks, storage.RedisDB)
+	}
+
+	repo = repos.NewParticipantRepo(t.dynamoClient, redisClient, config)
+	t.repos[clientID] = repo
+	return repo, nil
+}
+
+// Helper returns a helper for one of a client's leaderboards
+func (t *TenantRouter) Helper(
+	ctx context.Context,
+	clientID string,
+	leaderboardID string,
+	leaderboardEndTime time.Time,
+) (*IndividualLeaderboardHelper, error) {
+	repo, err := t.repo(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newHelper(repo, t.options, clientID, leaderboardID, leaderboardEndTime), nil
+}
+
+// Manager returns a manager for a client's leaderboards
+func (t *TenantRouter) Manager(
+	ctx context.Context,
+	clientID string,
+	endTimes EndTimeResolver,
+) (*Manager, error) {
+	repo, err := t.repo(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newManager(repo, t.options, clientID, endTimes), nil
+}
+
+// ListenForCacheUpdates applies updates published by other regions to the
+// local Redis cache of each update's client until ctx is done, like the
+// package level ListenForCacheUpdates
+func (t *TenantRouter) ListenForCacheUpdates(
+	ctx context.Context,
+	bus InvalidationBus,
+	localRegion string,
+) error {
+	return applyCacheUpdates(ctx, bus, localRegion, t.repo)
+}
+
+// Close closes the Redis connection pools the router opened for clients on
+// their own Redis database. The router's own clients are left open. Helpers
+// of those clients must not be used afterwards.
+func (t *TenantRouter) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var errs []error
+	for db, client := range t.redisClients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(t.redisClients, db)
+	}
+
+	return errors.Join(errs...)
+}
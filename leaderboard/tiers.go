@@ -0,0 +1,9 @@
+package leaderboard
+
+import (
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+)
+
+// Tier labels the participants ranked within the top TopPercent percent of
+// the leaderboard (see WithTiers)
+type Tier = customTypes.Tier
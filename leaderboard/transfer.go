@@ -0,0 +1,53 @@
+package leaderboard
+
+import (
+	"context"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// TransferScore moves amount from one of the helper's client's users to
+// another, e.g. to gift score or fold a linked account into another. The
+// debit and credit are one DynamoDB transaction that fails with
+// ErrInsufficientScore, leaving both scores untouched, unless the sender
+// has at least amount, so the same score can never be spent twice. It
+// returns both users' new standings; ranks are zero while the cached
+// leaderboard is being rebuilt. Only this leaderboard's scores move: update
+// hooks, the anomaly detector and the lifetime leaderboard are not involved.
+func (l *IndividualLeaderboardHelper) TransferScore(
+	ctx context.Context,
+	fromUserID string,
+	toUserID string,
+	amount float64,
+) (*customTypes.MemberScore, *customTypes.MemberScore, error) {
+	// Scheduled leaderboards only take joins until they open
+	if l.stateAt(utils.GetCurrTimeStamp()) == LeaderboardScheduled {
+		return nil, nil, ErrLeaderboardNotStarted
+	}
+
+	from, err := l.memberID(fromUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := l.memberID(toUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fromResult, toResult, err := l.repo.TransferScore(ctx, l.storageID, from, to, amount, l.endTime())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Let caches in other regions apply the same deltas
+	l.publishCacheUpdate(ctx, from, -amount)
+	l.publishCacheUpdate(ctx, to, amount)
+	l.recordMilestones(ctx, to, amount, &toResult)
+	l.creditBracket(ctx, from, -amount)
+	l.creditBracket(ctx, to, amount)
+	l.recordWrites(ctx, 2)
+	l.publishChanges(ctx, from, to)
+
+	return &fromResult, &toResult, nil
+}
@@ -0,0 +1,133 @@
+package leaderboard
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kgen-protocol/platform-libs/leaderboard/customTypes"
+	"github.com/kgen-protocol/platform-libs/leaderboard/internal/utils"
+)
+
+// Visibility decides who may read a leaderboard's standings
+type Visibility = customTypes.Visibility
+
+const (
+	// VisibilityPublic lets anyone read the standings
+	VisibilityPublic = customTypes.VisibilityPublic
+	// VisibilityParticipantsOnly lets only callers identified with
+	// WithCaller as participants read the standings
+	VisibilityParticipantsOnly = customTypes.VisibilityParticipantsOnly
+	// VisibilityHiddenUntilFinalized hides the standings until the
+	// leaderboard has ended and its end time grace period, if any, has
+	// passed. Leaderboards without an end time stay hidden.
+	VisibilityHiddenUntilFinalized = customTypes.VisibilityHiddenUntilFinalized
+)
+
+// callerKey carries the identity of the caller of a read
+type callerKey struct{}
+
+// trustedCallerKey marks contexts of reads made by trusted callers
+type trustedCallerKey struct{}
+
+// WithCaller returns a context identifying the participant on whose behalf
+// reads made with it are served, e.g. the user behind a shared read
+// endpoint. Participants-only leaderboards only serve such callers when
+// they are on the leaderboard.
+func WithCaller(ctx context.Context, namespacedUserID string) context.Context {
+	return context.WithValue(ctx, callerKey{}, namespacedUserID)
+}
+
+// WithTrustedCaller returns a context whose reads ignore the leaderboard's
+// visibility, for internal tooling such as admin dashboards and reward
+// payouts
+func WithTrustedCaller(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trustedCallerKey{}, true)
+}
+
+// leaderboardVisibility holds a helper's visibility and shadow mode, which
+// SetVisibility and SetShadowMode may change while the helper is in use
+type leaderboardVisibility struct {
+	mu         sync.RWMutex
+	visibility Visibility
+	shadow     bool
+}
+
+// Visibility returns who may read the leaderboard's standings
+func (l *IndividualLeaderboardHelper) Visibility() Visibility {
+	l.visibility.mu.RLock()
+	defer l.visibility.mu.RUnlock()
+
+	if l.visibility.visibility == "" {
+		return VisibilityPublic
+	}
+	return l.visibility.visibility
+}
+
+// SetVisibility changes who may read the leaderboard's standings, e.g. to
+// publish a test event. It only applies to this helper; other instances
+// keep the visibility they were created with.
+func (l *IndividualLeaderboardHelper) SetVisibility(visibility Visibility) {
+	l.visibility.mu.Lock()
+	defer l.visibility.mu.Unlock()
+
+	l.visibility.visibility = visibility
+}
+
+// checkVisibility returns ErrLeaderboardNotVisible unless the caller of a
+// standings read made with ctx may see the leaderboard, or ErrAccessDenied
+// if the access policy refuses the read
+func (l *IndividualLeaderboardHelper) checkVisibility(ctx context.Context) error {
+	// Shadow leaderboards are only read when asked for explicitly
+	if l.ShadowMode() && !shadowReads(ctx) {
+		return ErrShadowLeaderboard
+	}
+	if err := l.checkAccess(ctx); err != nil {
+		return err
+	}
+
+	visibility := l.Visibility()
+	if visibility == VisibilityPublic {
+		return nil
+	}
+	if trusted, _ := ctx.Value(trustedCallerKey{}).(bool); trusted {
+		return nil
+	}
+
+	switch visibility {
+	case VisibilityParticipantsOnly:
+		caller, _ := ctx.Value(callerKey{}).(string)
+		if caller == "" {
+			return ErrLeaderboardNotVisible
+		}
+		if _, _, err := l.validateNamespacedUserID(caller); err != nil {
+			return ErrLeaderboardNotVisible
+		}
+		participant, err := l.repo.IsParticipant(ctx, l.storageID, caller, l.endTime())
+		if err != nil {
+			return err
+		}
+		if !participant {
+			return ErrLeaderboardNotVisible
+		}
+		return nil
+	case VisibilityHiddenUntilFinalized:
+		endTime := l.endTime()
+		if endTime.IsZero() || !utils.GetCurrTimeStamp().After(endTime.Add(l.finalizationDelay)) {
+			return ErrLeaderboardNotVisible
+		}
+		return nil
+	default:
+		return ErrLeaderboardNotVisible
+	}
+}
+
+// finalizationDelay returns how long after the end time the standings may
+// still change under the options' end time policy
+func finalizationDelay(options *helperOptions) time.Duration {
+	if options.repoConfig.EndTimePolicy == EndTimeGrace {
+		return options.repoConfig.EndTimeGracePeriod
+	}
+
+	return 0
+}
@@ -0,0 +1,117 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// WarmProgress reports how far a cache warm-up has progressed
+type WarmProgress struct {
+	LeaderboardID string
+	ItemsLoaded   int64
+	Done          bool
+	Err           error
+}
+
+// WarmCache loads the leaderboard from DynamoDB into Redis ahead of
+// traffic, so the first reader does not pay the sync cost. An already
+// cached leaderboard is left as is unless force is set. progress, if not
+// nil, is called after each loaded page and once more when done.
+func (l *IndividualLeaderboardHelper) WarmCache(
+	ctx context.Context,
+	force bool,
+	progress func(WarmProgress),
+) error {
+	var report func(int64)
+	var loaded int64
+	if progress != nil {
+		report = func(itemsLoaded int64) {
+			loaded = itemsLoaded
+			progress(WarmProgress{
+				LeaderboardID: l.leaderboardID,
+				ItemsLoaded:   itemsLoaded,
+			})
+		}
+	}
+
+	err := l.repo.WarmLeaderboard(
+		ctx,
+		l.storageID,
+		l.endTime(),
+		force,
+		report,
+	)
+	if progress != nil {
+		progress(WarmProgress{
+			LeaderboardID: l.leaderboardID,
+			ItemsLoaded:   loaded,
+			Done:          true,
+			Err:           err,
+		})
+	}
+
+	return err
+}
+
+// WarmCaches warms several leaderboards with at most concurrency syncs in
+// flight. Every leaderboard is attempted; the returned error joins all
+// failures. progress may be called from multiple goroutines.
+func WarmCaches(
+	ctx context.Context,
+	helpers []*IndividualLeaderboardHelper,
+	concurrency int,
+	force bool,
+	progress func(WarmProgress),
+) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	slots := make(chan struct{}, concurrency)
+
+	for _, helper := range helpers {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			errs = append(errs, ctx.Err())
+			mu.Unlock()
+		case slots <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(helper *IndividualLeaderboardHelper) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := helper.WarmCache(ctx, force, progress); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf(
+					"failed to warm leaderboard %s: %w",
+					helper.leaderboardID,
+					err,
+				))
+				mu.Unlock()
+			}
+		}(helper)
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// RefreshCache brings an already cached leaderboard up to date with
+// DynamoDB without clearing it. With WithIncrementalSync only items changed
+// since the previous sync are read; otherwise the board is fully reloaded.
+func (l *IndividualLeaderboardHelper) RefreshCache(ctx context.Context) error {
+	return l.repo.RefreshLeaderboard(ctx, l.storageID, l.endTime())
+}